@@ -23,11 +23,7 @@ func main() {
 		switch m := msg.(type) {
 		case *types.AssistantMessage:
 			fmt.Println("Assistant:")
-			for _, block := range m.Content {
-				if text, ok := block.(*types.TextBlock); ok {
-					fmt.Println(text.Text)
-				}
-			}
+			fmt.Println(m.Text())
 		case *types.ResultMessage:
 			fmt.Printf("\nSession: %s, Duration: %dms\n", m.SessionID, m.DurationMS)
 		}
@@ -61,7 +57,7 @@ func main() {
 		case *types.UserMessage:
 			// Tool results from Claude
 		case *types.ResultMessage:
-			fmt.Printf("\nCompleted in %dms\n", m.DurationMS)
+			fmt.Printf("\nCompleted in %dms, $%.4f\n", m.DurationMS, m.Cost())
 		}
 	}
 }
@@ -0,0 +1,113 @@
+package claudecode
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// permissionAuditor numbers, chains and delivers the permission audit
+// records of a client, in the order the decisions were made
+type permissionAuditor struct {
+	client   *ClaudeSDKClient
+	w        io.Writer
+	callback types.PermissionAuditCallback
+
+	mu       sync.Mutex
+	seq      uint64
+	prevHash string
+}
+
+// newPermissionAuditor returns nil when no audit sink is configured
+func (c *ClaudeSDKClient) newPermissionAuditor() *permissionAuditor {
+	if c.options.PermissionAuditLog == nil && c.options.OnPermissionAudit == nil {
+		return nil
+	}
+	return &permissionAuditor{
+		client:   c,
+		w:        c.options.PermissionAuditLog,
+		callback: c.options.OnPermissionAudit,
+	}
+}
+
+// record completes a record from the query and delivers it
+func (a *permissionAuditor) record(record *types.PermissionAuditRecord) {
+	a.client.resumeMu.Lock()
+	sessionID := a.client.sessionID
+	a.client.resumeMu.Unlock()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.seq++
+	record.Seq = a.seq
+	record.Time = record.Time.UTC()
+	record.SessionID = sessionID
+	record.PrevHash = a.prevHash
+	record.Hash = hashAuditRecord(record)
+	a.prevHash = record.Hash
+
+	if a.w != nil {
+		line, _ := json.Marshal(record)
+		if _, err := a.w.Write(append(line, '\n')); err != nil {
+			a.client.logger().Warn("failed to write permission audit record", "seq", record.Seq, "error", err)
+		}
+	}
+	if a.callback != nil {
+		a.callback(record)
+	}
+}
+
+// hashAuditRecord returns the hex SHA-256 of the record encoded without
+// its Hash
+func hashAuditRecord(record *types.PermissionAuditRecord) string {
+	unhashed := *record
+	unhashed.Hash = ""
+	data, _ := json.Marshal(&unhashed)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyPermissionAudit checks a JSONL permission audit log written by
+// PermissionAuditLog and returns the number of records. It fails on the
+// first record that was modified or does not follow the one before it,
+// which reveals edited, removed and reordered records; a truncated end is
+// not detectable. Every client starts a new chain at Seq 1, so a log may
+// hold several clients' trails one after another but not interleaved.
+func VerifyPermissionAudit(r io.Reader) (int, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	count := 0
+	var prev *types.PermissionAuditRecord
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var record types.PermissionAuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return count, fmt.Errorf("permission audit line %d: %w", line, err)
+		}
+		if record.Hash != hashAuditRecord(&record) {
+			return count, fmt.Errorf("permission audit line %d: hash does not match the record", line)
+		}
+
+		switch {
+		case record.Seq == 1 && record.PrevHash == "":
+			// Start of a client's trail
+		case prev == nil || record.Seq != prev.Seq+1 || record.PrevHash != prev.Hash:
+			return count, fmt.Errorf("permission audit line %d: record %d does not follow the previous record", line, record.Seq)
+		}
+
+		prev = &record
+		count++
+	}
+	return count, scanner.Err()
+}
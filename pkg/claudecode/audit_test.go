@@ -0,0 +1,90 @@
+package claudecode
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/transporttest"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+func TestPermissionAudit(t *testing.T) {
+	var log syncBuffer
+	records := make(chan *types.PermissionAuditRecord, 3)
+	options := types.NewOptions().
+		WithPermissionAudit(&log).
+		WithPermissionAuditCallback(func(record *types.PermissionAuditRecord) { records <- record }).
+		WithCanUseTool(func(toolName string, input map[string]interface{}, ctx *types.ToolPermissionContext) (types.PermissionResult, error) {
+			switch toolName {
+			case "Read":
+				return &types.PermissionResultAllow{Behavior: types.PermissionBehaviorAllow, UpdatedInput: map[string]interface{}{"file_path": "/safe"}}, nil
+			case "Bash":
+				return &types.PermissionResultDeny{Behavior: types.PermissionBehaviorDeny, Message: "no shell"}, nil
+			}
+			return nil, fmt.Errorf("no policy for %s", toolName)
+		})
+	mock := transporttest.NewMockTransport()
+	client := NewClaudeSDKClientWithTransport(options, mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	want := map[string]types.PermissionDecision{
+		"Read":  types.PermissionDecisionAllow,
+		"Bash":  types.PermissionDecisionDeny,
+		"Write": types.PermissionDecisionError,
+	}
+	for i, tool := range []string{"Read", "Bash", "Write"} {
+		mock.Emit(map[string]interface{}{
+			"type":       "control_request",
+			"request_id": fmt.Sprintf("cli_%d", i),
+			"request": map[string]interface{}{
+				"subtype":     "can_use_tool",
+				"tool_name":   tool,
+				"tool_use_id": "toolu_" + tool,
+				"input":       map[string]interface{}{"file_path": "/etc/passwd"},
+			},
+		})
+
+		select {
+		case record := <-records:
+			if record.ToolName != tool || record.Decision != want[tool] || record.Seq != uint64(i+1) || record.ToolUseID != "toolu_"+tool {
+				t.Errorf("record %d = %+v", i+1, record)
+			}
+			if tool == "Read" && !record.InputUpdated {
+				t.Error("updated input not recorded")
+			}
+			if tool == "Bash" && record.Reason != "no shell" {
+				t.Errorf("deny reason = %q", record.Reason)
+			}
+		case <-ctx.Done():
+			t.Fatalf("no audit record for %s", tool)
+		}
+	}
+
+	// The log holds a verifiable chain that does not quote the input
+	output := log.String()
+	if strings.Contains(output, "/etc/passwd") {
+		t.Error("audit log contains the raw tool input")
+	}
+	if n, err := VerifyPermissionAudit(strings.NewReader(output)); err != nil || n != 3 {
+		t.Errorf("VerifyPermissionAudit = %d, %v", n, err)
+	}
+
+	lines := strings.SplitAfter(output, "\n")
+	edited := strings.Replace(output, `"decision":"deny"`, `"decision":"allow"`, 1)
+	removed := lines[0] + lines[2]
+	for name, log := range map[string]string{"edited": edited, "removed": removed} {
+		if _, err := VerifyPermissionAudit(strings.NewReader(log)); err == nil {
+			t.Errorf("%s record not detected", name)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package claudecode
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// defaultBatchConcurrency is used when QueryBatch is given no limit
+const defaultBatchConcurrency = 4
+
+// BatchResult is the outcome of one prompt run by QueryBatch
+type BatchResult struct {
+	Index    int // Position of the prompt in the batch
+	Prompt   string
+	Messages []types.Message      // Every message received, including the result
+	Result   *types.ResultMessage // Nil if the query failed before a result
+	Err      error
+}
+
+// QueryBatch runs independent one-shot queries for prompts, at most
+// concurrency at a time (default 4), each in its own CLI process. The CLI
+// is located once for the whole batch.
+//
+// Results are returned in prompt order. The error joins the errors of all
+// failed prompts and is nil when every prompt produced a result; results
+// with IsError set are not counted as failures.
+//
+// Example:
+//
+//	prompts := []string{"Review a.go", "Review b.go", "Review c.go"}
+//	results, err := claudecode.QueryBatch(ctx, prompts, options, 2)
+//	for _, r := range results {
+//	    if r.Err == nil {
+//	        fmt.Printf("%s: $%.4f\n", r.Prompt, *r.Result.TotalCostUSD)
+//	    }
+//	}
+func QueryBatch(ctx context.Context, prompts []string, options *types.ClaudeCodeOptions, concurrency int) ([]BatchResult, error) {
+	if options == nil {
+		options = &types.ClaudeCodeOptions{}
+	}
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+
+	cliPath := options.CLIPath
+	if cliPath == "" {
+		var err error
+		if cliPath, err = transport.FindCLI(); err != nil {
+			return nil, err
+		}
+	}
+
+	os.Setenv("CLAUDE_CODE_ENTRYPOINT", "sdk-go")
+
+	return runBatch(ctx, prompts, concurrency, func(ctx context.Context, result *BatchResult) {
+		runQuery(ctx, result.Prompt, options, cliPath, func(msg types.Message, err error) bool {
+			if err != nil {
+				result.Err = err
+				return false
+			}
+			result.Messages = append(result.Messages, msg)
+			if r, ok := msg.(*types.ResultMessage); ok {
+				result.Result = r
+			}
+			return true
+		})
+	})
+}
+
+// runBatch runs one call of run per prompt on a pool of concurrency workers
+func runBatch(ctx context.Context, prompts []string, concurrency int, run func(context.Context, *BatchResult)) ([]BatchResult, error) {
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+
+	results := make([]BatchResult, len(prompts))
+	for i, prompt := range prompts {
+		results[i] = BatchResult{Index: i, Prompt: prompt}
+	}
+
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency && w < len(prompts); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				run(ctx, &results[i])
+			}
+		}()
+	}
+
+	for i := range prompts {
+		if ctx.Err() != nil {
+			results[i].Err = ctx.Err()
+			continue
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var errs []error
+	for i := range results {
+		r := &results[i]
+		if r.Err == nil && r.Result == nil {
+			r.Err = fmt.Errorf("query ended without a result")
+		}
+		if r.Err != nil {
+			errs = append(errs, fmt.Errorf("prompt %d: %w", r.Index, r.Err))
+		}
+	}
+	return results, stderrors.Join(errs...)
+}
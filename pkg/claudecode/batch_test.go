@@ -0,0 +1,119 @@
+package claudecode
+
+import (
+	"context"
+	stderrors "errors"
+	"os/exec"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+func TestRunBatch(t *testing.T) {
+	var running, peak atomic.Int32
+	failure := stderrors.New("boom")
+
+	prompts := []string{"a", "b", "fail", "d", "e"}
+	results, err := runBatch(context.Background(), prompts, 2, func(ctx context.Context, r *BatchResult) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		if r.Prompt == "fail" {
+			r.Err = failure
+			return
+		}
+		r.Result = &types.ResultMessage{Subtype: "success", Result: &r.Prompt}
+		r.Messages = []types.Message{r.Result}
+	})
+
+	if peak.Load() > 2 {
+		t.Errorf("ran %d prompts at once, limit is 2", peak.Load())
+	}
+	if !stderrors.Is(err, failure) {
+		t.Errorf("expected joined error to contain the failure, got %v", err)
+	}
+	for i, r := range results {
+		if r.Index != i || r.Prompt != prompts[i] {
+			t.Errorf("result %d out of order: %+v", i, r)
+		}
+		if (r.Err != nil) != (r.Prompt == "fail") {
+			t.Errorf("unexpected error for %q: %v", r.Prompt, r.Err)
+		}
+	}
+}
+
+// batchCLI answers the prompt it reads from stdin, or fails for "fail"
+const batchCLI = `
+IFS= read -r prompt
+if [ "$prompt" = fail ]; then
+  echo 'boom' >&2
+  exit 1
+fi
+echo '{"type":"assistant","model":"sonnet","content":[{"type":"text","text":"'"$prompt"'"}]}'
+echo '{"type":"result","subtype":"success","result":"'"$prompt"'","session_id":"s1"}'
+`
+
+func TestQueryBatch(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	var started atomic.Int32
+	options := types.NewOptions().
+		WithCLIPath("/nonexistent/claude").
+		WithCommandFactory(func(ctx context.Context, path string, args []string) *exec.Cmd {
+			if cmd := versionCommand(ctx, args); cmd != nil {
+				return cmd
+			}
+			started.Add(1)
+			return exec.CommandContext(ctx, "sh", "-c", batchCLI)
+		})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	prompts := []string{"a", "b", "fail", "d"}
+	results, err := QueryBatch(ctx, prompts, options, 2)
+
+	var processErr *ProcessError
+	if !stderrors.As(err, &processErr) {
+		t.Errorf("QueryBatch error = %v, want the failed prompt's ProcessError", err)
+	}
+	if n := started.Load(); n != 4 {
+		t.Errorf("started %d CLIs, want 4", n)
+	}
+	if len(results) != len(prompts) {
+		t.Fatalf("got %d results, want %d", len(results), len(prompts))
+	}
+	for i, r := range results {
+		if r.Index != i || r.Prompt != prompts[i] {
+			t.Errorf("result %d out of order: %+v", i, r)
+		}
+		if r.Prompt == "fail" {
+			if r.Err == nil || r.Result != nil {
+				t.Errorf("failed prompt = %+v", r)
+			}
+			continue
+		}
+		if r.Err != nil || r.Result == nil || *r.Result.Result != r.Prompt || len(r.Messages) != 2 {
+			t.Errorf("result %d = %+v", i, r)
+		}
+	}
+
+	// Invalid options fail the whole batch
+	invalid := types.NewOptions().WithCLIPath("/nonexistent/claude")
+	invalid.MaxConcurrentPermissionRequests = -1
+	if results, err := QueryBatch(ctx, prompts, invalid, 2); err == nil || results != nil {
+		t.Errorf("QueryBatch with invalid options = %v, %v", results, err)
+	}
+}
@@ -0,0 +1,206 @@
+package claudecode
+
+import (
+	"sync"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// defaultBudgetWarningRatio is the fraction of a budget that triggers
+// OnBudgetWarning when BudgetWarningRatio is unset
+const defaultBudgetWarningRatio = 0.8
+
+// budgetTracker accumulates spend and enforces MaxCostUSD and MaxTokens.
+//
+// Results carry the authoritative cost and usage of a turn. With partial
+// messages enabled, stream events additionally report the tokens of the
+// turn in progress, so a runaway turn is stopped before its result.
+type budgetTracker struct {
+	maxCost   float64
+	maxTokens int
+	warnRatio float64
+	onWarning types.BudgetWarningCallback
+
+	mu        sync.Mutex
+	cost      float64
+	tokens    int
+	totalCost costDelta
+
+	// Tokens of the turn in progress, from stream events
+	streamed     int // Messages completed in this turn
+	streamInput  int // Current message
+	streamOutput int
+
+	warned   bool
+	exceeded error
+}
+
+// newBudgetTracker returns nil when no budget is configured
+func newBudgetTracker(options *types.ClaudeCodeOptions) *budgetTracker {
+	if options == nil || (options.MaxCostUSD <= 0 && options.MaxTokens <= 0) {
+		return nil
+	}
+
+	b := &budgetTracker{
+		maxCost:   options.MaxCostUSD,
+		maxTokens: options.MaxTokens,
+		warnRatio: options.BudgetWarningRatio,
+		onWarning: options.OnBudgetWarning,
+	}
+	if b.warnRatio == 0 {
+		b.warnRatio = defaultBudgetWarningRatio
+	}
+	return b
+}
+
+// observe updates spend from a message. It returns a BudgetExceededError
+// the first time a budget is reached, and nil otherwise.
+func (b *budgetTracker) observe(msg types.Message) error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+
+	switch m := msg.(type) {
+	case *types.ResultMessage:
+		if m.TotalCostUSD != nil {
+			b.cost += b.totalCost.next(*m.TotalCostUSD)
+		}
+		b.tokens += m.Usage.TotalTokens()
+		b.streamed, b.streamInput, b.streamOutput = 0, 0, 0
+	case *types.StreamEvent:
+		if !b.observeStreamEvent(m.Event) {
+			b.mu.Unlock()
+			return nil
+		}
+	default:
+		b.mu.Unlock()
+		return nil
+	}
+
+	usage := b.usage()
+	warn := !b.warned && b.onWarning != nil && b.reached(usage, b.warnRatio)
+	if warn {
+		b.warned = true
+	}
+
+	var exceeded error
+	if b.exceeded == nil {
+		if b.maxCost > 0 && usage.CostUSD >= b.maxCost {
+			b.exceeded = errors.NewBudgetExceededError("cost", b.maxCost, usage.CostUSD)
+		} else if b.maxTokens > 0 && usage.Tokens >= b.maxTokens {
+			b.exceeded = errors.NewBudgetExceededError("tokens", float64(b.maxTokens), float64(usage.Tokens))
+		}
+		exceeded = b.exceeded
+	}
+	b.mu.Unlock()
+
+	// Called without the lock so the callback may inspect the client
+	if warn {
+		b.onWarning(usage)
+	}
+	return exceeded
+}
+
+// observeStreamEvent tracks the tokens of the message being streamed.
+// Returns false if the event carries no usage. Callers hold b.mu.
+func (b *budgetTracker) observeStreamEvent(event map[string]interface{}) bool {
+	switch event["type"] {
+	case "message_start":
+		message, _ := event["message"].(map[string]interface{})
+		usage, ok := message["usage"].(map[string]interface{})
+		if !ok {
+			return false
+		}
+		b.streamed += b.streamInput + b.streamOutput
+		b.streamInput = getInt(usage, "input_tokens") +
+			getInt(usage, "cache_creation_input_tokens") +
+			getInt(usage, "cache_read_input_tokens")
+		b.streamOutput = getInt(usage, "output_tokens")
+		return true
+	case "message_delta":
+		usage, ok := event["usage"].(map[string]interface{})
+		if !ok {
+			return false
+		}
+		// Output tokens are cumulative within a message
+		b.streamOutput = getInt(usage, "output_tokens")
+		return true
+	}
+	return false
+}
+
+// check returns the BudgetExceededError once a budget has been reached
+func (b *budgetTracker) check() error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.exceeded
+}
+
+// usage returns the current spend. Callers hold b.mu.
+func (b *budgetTracker) usage() types.BudgetUsage {
+	return types.BudgetUsage{
+		CostUSD:    b.cost,
+		Tokens:     b.tokens + b.streamed + b.streamInput + b.streamOutput,
+		MaxCostUSD: b.maxCost,
+		MaxTokens:  b.maxTokens,
+	}
+}
+
+// reached reports whether usage has reached ratio of any budget
+func (b *budgetTracker) reached(usage types.BudgetUsage, ratio float64) bool {
+	if b.maxCost > 0 && usage.CostUSD >= b.maxCost*ratio {
+		return true
+	}
+	return b.maxTokens > 0 && float64(usage.Tokens) >= float64(b.maxTokens)*ratio
+}
+
+// costDelta turns the session totals in TotalCostUSD, which grow with
+// every result, into the cost each result added
+type costDelta struct {
+	last float64
+}
+
+// next returns what total adds to the previous total. A total below the
+// previous one belongs to a new session and is all new.
+func (d *costDelta) next(total float64) float64 {
+	delta := total - d.last
+	if delta < 0 {
+		delta = total
+	}
+	d.last = total
+	return delta
+}
+
+// getInt reads a JSON number from a decoded object
+func getInt(data map[string]interface{}, key string) int {
+	if v, ok := data[key].(float64); ok {
+		return int(v)
+	}
+	return 0
+}
+
+// checkBudget enforces the budgets after a message. When one is reached the
+// session is interrupted and the BudgetExceededError is reported.
+func (c *ClaudeSDKClient) checkBudget(msg types.Message) {
+	err := c.budget.observe(msg)
+	if err == nil {
+		return
+	}
+
+	c.logger().Warn("budget exceeded, interrupting", "error", err)
+	go func() {
+		if interruptErr := c.Interrupt(); interruptErr != nil {
+			c.logger().Warn("failed to interrupt after budget exceeded", "error", interruptErr)
+		}
+	}()
+
+	c.sendError(err)
+}
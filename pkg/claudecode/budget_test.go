@@ -0,0 +1,127 @@
+package claudecode
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/transporttest"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+func TestBudgetExceeded(t *testing.T) {
+	// Results carry the session's running total
+	totals := []float64{0.5, 0.85, 1.1}
+	mock := transporttest.NewMockTransport()
+	mock.RespondFunc(transporttest.MatchType("user"), func(map[string]interface{}) []interface{} {
+		total := totals[0]
+		totals = totals[1:]
+		return []interface{}{
+			map[string]interface{}{"type": "result", "subtype": "success", "session_id": "s1", "total_cost_usd": total},
+		}
+	})
+
+	warnings := make(chan types.BudgetUsage, 2)
+	options := types.NewOptions().WithBudget(1.0, 0)
+	options.OnBudgetWarning = func(usage types.BudgetUsage) { warnings <- usage }
+	client := NewClaudeSDKClientWithTransport(options, mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	waitResult := func() {
+		t.Helper()
+		select {
+		case <-client.Messages():
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for result")
+		}
+	}
+
+	if err := client.SendMessage("first", "default"); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	waitResult()
+
+	if err := client.SendMessage("second", "default"); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	waitResult()
+
+	select {
+	case usage := <-warnings:
+		if usage.CostUSD != 0.85 || usage.MaxCostUSD != 1.0 {
+			t.Errorf("unexpected warning usage: %+v", usage)
+		}
+	default:
+		t.Error("expected a budget warning")
+	}
+
+	if err := client.SendMessage("third", "default"); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+
+	select {
+	case err := <-client.Errors():
+		var budgetErr *BudgetExceededError
+		if !stderrors.As(err, &budgetErr) || budgetErr.Limit != "cost" {
+			t.Fatalf("error = %v, want cost BudgetExceededError", err)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for BudgetExceededError")
+	}
+	waitResult()
+
+	if err := client.SendMessage("fourth", "default"); !stderrors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("SendMessage error = %v, want ErrBudgetExceeded", err)
+	}
+}
+
+func TestBudgetStreamedTokens(t *testing.T) {
+	b := newBudgetTracker(&types.ClaudeCodeOptions{MaxTokens: 100})
+
+	start := &types.StreamEvent{Event: map[string]interface{}{
+		"type":    "message_start",
+		"message": map[string]interface{}{"usage": map[string]interface{}{"input_tokens": float64(40)}},
+	}}
+	delta := &types.StreamEvent{Event: map[string]interface{}{
+		"type":  "message_delta",
+		"usage": map[string]interface{}{"output_tokens": float64(30)},
+	}}
+
+	if err := b.observe(start); err != nil {
+		t.Fatalf("unexpected error after message_start: %v", err)
+	}
+	if err := b.observe(delta); err != nil {
+		t.Fatalf("unexpected error after 70 tokens: %v", err)
+	}
+
+	// A second message in the same turn pushes it over the budget
+	if err := b.observe(start); !stderrors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("error = %v, want ErrBudgetExceeded", err)
+	}
+	if err := b.observe(delta); err != nil {
+		t.Errorf("budget exceeded should only be reported once, got %v", err)
+	}
+}
+
+func TestCostDelta(t *testing.T) {
+	var d costDelta
+	for _, step := range []struct{ total, want float64 }{
+		{0.5, 0.5},
+		{0.75, 0.25},
+		{0.75, 0},
+		{0.25, 0.25}, // A new session starts from zero
+		{1.25, 1},
+	} {
+		if got := d.next(step.total); got != step.want {
+			t.Errorf("next(%v) = %v, want %v", step.total, got, step.want)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package claudecode
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/hooks"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/tools"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// defaultMaxTrackedContent is the largest file content a ChangeTracker keeps
+const defaultMaxTrackedContent = 1024 * 1024 // 1MB
+
+// ChangeTracker follows the tool uses and results of a conversation and
+// records, per session, the files that Write, Edit, MultiEdit and
+// NotebookEdit changed. A file is read by the PreToolUse hook returned by
+// Hook, before the tool runs, and again when the result is seen. Without
+// the hook it is first read when the tool use is seen, which may be after
+// the tool ran.
+//
+// ClaudeSDKClient keeps one and registers its hook when TrackFileChanges is
+// set; with QueryIter, pass every message to Observe:
+//
+//	tracker := claudecode.NewChangeTracker()
+//	for msg, err := range claudecode.QueryIter(ctx, prompt, options) {
+//	    ...
+//	    tracker.Observe(msg)
+//	}
+//	for _, file := range tracker.Changes(tracker.Session()) {
+//	    fmt.Println(file.Kind, file.Path)
+//	}
+type ChangeTracker struct {
+	// Directory relative paths are resolved against (default the working
+	// directory of the process)
+	Dir string
+
+	// Largest content kept for a file (default 1MB)
+	MaxContentBytes int
+
+	mu       sync.Mutex
+	session  string
+	pending  map[string]pendingChange // By tool use ID
+	captured map[string]pendingChange // Read by Hook before the tool use was seen
+	sessions map[string]*sessionChanges
+}
+
+// pendingChange is a tool use waiting for its result
+type pendingChange struct {
+	session string
+	path    string
+	existed bool
+	before  *string
+}
+
+// sessionChanges holds the files changed in a session, in order of first
+// change
+type sessionChanges struct {
+	files map[string]*trackedFile
+	order []string
+}
+
+// trackedFile is a file's state before its first change and after its last
+type trackedFile struct {
+	existed    bool
+	before     *string
+	exists     bool
+	after      *string
+	toolUseIDs []string
+}
+
+// NewChangeTracker creates an empty ChangeTracker
+func NewChangeTracker() *ChangeTracker {
+	return &ChangeTracker{
+		pending:  make(map[string]pendingChange),
+		captured: make(map[string]pendingChange),
+		sessions: make(map[string]*sessionChanges),
+	}
+}
+
+// Observe records the tool uses and results of msg. Messages without a
+// session ID belong to the session last seen.
+func (t *ChangeTracker) Observe(msg types.Message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch m := msg.(type) {
+	case *types.InitMessage:
+		t.setSession(m.SessionID)
+	case *types.ResultMessage:
+		t.setSession(m.SessionID)
+	case *types.AssistantMessage:
+		t.setSession(m.SessionID)
+		for _, use := range m.ToolUses() {
+			t.toolUsed(use)
+		}
+	case *types.UserMessage:
+		t.setSession(m.SessionID)
+		blocks, _ := m.Content.([]types.ContentBlock)
+		for _, block := range blocks {
+			if result, ok := block.(*types.ToolResultBlock); ok {
+				t.toolFinished(result)
+			}
+		}
+	}
+}
+
+// Hook returns a PreToolUse hook reading the files tool uses are about to
+// change. Hooks run for every tool use, including those the CLI allows
+// without asking.
+func (t *ChangeTracker) Hook() types.HookMatcher {
+	matcher := strings.Join([]string{tools.NameWrite, tools.NameEdit, tools.NameMultiEdit, tools.NameNotebookEdit}, "|")
+	return types.HookMatcher{Matcher: &matcher, Hooks: []types.HookCallback{
+		func(input map[string]interface{}, toolUseID *string, context *types.HookContext) (*types.HookJSONOutput, error) {
+			toolName, _ := input["tool_name"].(string)
+			toolInput, _ := input["tool_input"].(map[string]interface{})
+			if toolUseID != nil {
+				t.toolStarting(*toolUseID, toolName, toolInput)
+			}
+			return hooks.Continue(), nil
+		},
+	}}
+}
+
+// Session returns the ID of the session last seen
+func (t *ChangeTracker) Session() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.session
+}
+
+// Changes returns the files changed in a session, in order of their first
+// change. Files whose content ended up as it started are left out, as are
+// files created and deleted again.
+func (t *ChangeTracker) Changes(sessionID string) []types.ChangedFile {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	session := t.sessions[sessionID]
+	if session == nil {
+		return nil
+	}
+
+	var changes []types.ChangedFile
+	for _, path := range session.order {
+		file := session.files[path]
+
+		change := types.ChangedFile{
+			Path:       path,
+			Before:     file.before,
+			After:      file.after,
+			ToolUseIDs: append([]string(nil), file.toolUseIDs...),
+		}
+		switch {
+		case !file.existed && file.exists:
+			change.Kind = types.ChangeCreated
+		case file.existed && !file.exists:
+			change.Kind = types.ChangeDeleted
+		case file.existed && file.exists:
+			if file.before != nil && file.after != nil && *file.before == *file.after {
+				continue
+			}
+			change.Kind = types.ChangeModified
+		default:
+			continue
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+func (t *ChangeTracker) setSession(sessionID string) {
+	if sessionID != "" {
+		t.session = sessionID
+	}
+}
+
+// toolStarting reads the file a tool use is about to change, called by the
+// hook before the tool runs
+func (t *ChangeTracker) toolStarting(toolUseID, toolName string, input map[string]interface{}) {
+	path := changedPath(toolName, input)
+	if path == "" {
+		return
+	}
+	path = t.resolve(path)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	existed, before := t.read(path)
+	if pending, ok := t.pending[toolUseID]; ok {
+		pending.existed, pending.before = existed, before
+		t.pending[toolUseID] = pending
+		return
+	}
+	t.captured[toolUseID] = pendingChange{path: path, existed: existed, before: before}
+}
+
+// toolUsed reads the file a tool use is about to change, unless the hook
+// already did
+func (t *ChangeTracker) toolUsed(use types.ToolUseBlock) {
+	if captured, ok := t.captured[use.ID]; ok {
+		delete(t.captured, use.ID)
+		captured.session = t.session
+		t.pending[use.ID] = captured
+		return
+	}
+
+	path := changedPath(use.Name, use.Input)
+	if path == "" {
+		return
+	}
+	path = t.resolve(path)
+
+	existed, before := t.read(path)
+	t.pending[use.ID] = pendingChange{session: t.session, path: path, existed: existed, before: before}
+}
+
+// toolFinished records the file a successful tool use changed
+func (t *ChangeTracker) toolFinished(result *types.ToolResultBlock) {
+	pending, ok := t.pending[result.ToolUseID]
+	if !ok {
+		return
+	}
+	delete(t.pending, result.ToolUseID)
+	if result.IsError != nil && *result.IsError {
+		return
+	}
+
+	session := t.sessions[pending.session]
+	if session == nil {
+		session = &sessionChanges{files: make(map[string]*trackedFile)}
+		t.sessions[pending.session] = session
+	}
+	file := session.files[pending.path]
+	if file == nil {
+		file = &trackedFile{existed: pending.existed, before: pending.before}
+		session.files[pending.path] = file
+		session.order = append(session.order, pending.path)
+	}
+
+	file.exists, file.after = t.read(pending.path)
+	file.toolUseIDs = append(file.toolUseIDs, result.ToolUseID)
+}
+
+// resolve makes a relative path absolute against Dir
+func (t *ChangeTracker) resolve(path string) string {
+	if filepath.IsAbs(path) || t.Dir == "" {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(t.Dir, path)
+}
+
+// read reports whether path exists and returns its content, or nil if it
+// cannot be read or is too large to keep
+func (t *ChangeTracker) read(path string) (bool, *string) {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+
+	limit := t.MaxContentBytes
+	if limit <= 0 {
+		limit = defaultMaxTrackedContent
+	}
+	if err != nil || info.Size() > int64(limit) {
+		return true, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return true, nil
+	}
+	content := string(data)
+	return true, &content
+}
+
+// changedPath returns the file a tool use changes, or "" for tools that
+// change no file
+func changedPath(toolName string, input map[string]interface{}) string {
+	var path string
+	switch toolName {
+	case tools.NameWrite, tools.NameEdit, tools.NameMultiEdit:
+		path, _ = input["file_path"].(string)
+	case tools.NameNotebookEdit:
+		path, _ = input["notebook_path"].(string)
+	}
+	return path
+}
+
+// Changes returns the files changed in the current session, or nil unless
+// TrackFileChanges is set
+func (c *ClaudeSDKClient) Changes() []types.ChangedFile {
+	if c.changes == nil {
+		return nil
+	}
+	return c.changes.Changes(c.changes.Session())
+}
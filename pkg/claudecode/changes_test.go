@@ -0,0 +1,149 @@
+package claudecode
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/transporttest"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+func TestChanges(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "edited.txt"), []byte("old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	mock := transporttest.NewMockTransport()
+	client := NewClaudeSDKClientWithTransport(types.NewOptions().WithCWD(dir).WithFileChanges(), mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	toolUse := func(id, name, path string) map[string]interface{} {
+		return map[string]interface{}{"type": "tool_use", "id": id, "name": name, "input": map[string]interface{}{"file_path": path}}
+	}
+	mock.Emit(map[string]interface{}{
+		"type": "assistant", "model": "sonnet", "session_id": "s1",
+		"content": []interface{}{
+			toolUse("toolu_1", "Edit", "edited.txt"),
+			toolUse("toolu_2", "Write", filepath.Join(dir, "created.txt")),
+			toolUse("toolu_3", "Write", filepath.Join(dir, "failed.txt")),
+			toolUse("toolu_4", "Read", filepath.Join(dir, "edited.txt")),
+		},
+	})
+	select {
+	case <-client.Messages():
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for the tool uses")
+	}
+
+	// The CLI runs the tools
+	os.WriteFile(filepath.Join(dir, "edited.txt"), []byte("new\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, "created.txt"), []byte("hello\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, "failed.txt"), []byte("partial\n"), 0o644)
+
+	mock.Emit(map[string]interface{}{
+		"type": "user", "session_id": "s1",
+		"content": []interface{}{
+			map[string]interface{}{"type": "tool_result", "tool_use_id": "toolu_1", "content": "ok"},
+			map[string]interface{}{"type": "tool_result", "tool_use_id": "toolu_2", "content": "ok"},
+			map[string]interface{}{"type": "tool_result", "tool_use_id": "toolu_3", "content": "denied", "is_error": true},
+			map[string]interface{}{"type": "tool_result", "tool_use_id": "toolu_4", "content": "new"},
+		},
+	})
+	mock.Emit(map[string]interface{}{"type": "result", "subtype": "success", "session_id": "s1"})
+	if _, err := WaitForResult(ctx, client.Messages()); err != nil {
+		t.Fatalf("WaitForResult: %v", err)
+	}
+
+	changes := client.Changes()
+	if len(changes) != 2 {
+		t.Fatalf("Changes = %+v, want the edited and created files", changes)
+	}
+	edited, created := changes[0], changes[1]
+	if edited.Kind != types.ChangeModified || edited.Path != filepath.Join(dir, "edited.txt") ||
+		edited.Before == nil || *edited.Before != "old\n" || edited.After == nil || *edited.After != "new\n" {
+		t.Errorf("edited file = %+v", edited)
+	}
+	if created.Kind != types.ChangeCreated || created.Before != nil || *created.After != "hello\n" || created.ToolUseIDs[0] != "toolu_2" {
+		t.Errorf("created file = %+v", created)
+	}
+}
+
+func TestChangesReadBeforeToolRuns(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "edited.txt")
+	if err := os.WriteFile(path, []byte("old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	mock := transporttest.NewMockTransport()
+	answered := make(chan struct{}, 1)
+	mock.RespondFunc(transporttest.MatchType("control_response"), func(msg map[string]interface{}) []interface{} {
+		answered <- struct{}{}
+		return nil
+	})
+	client := NewClaudeSDKClientWithTransport(types.NewOptions().WithCWD(dir).WithFileChanges(), mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	request := mock.WrittenMessages()[0]["request"].(map[string]interface{})
+	matcher := request["hooks"].(map[string]interface{})["PreToolUse"].([]interface{})[0].(map[string]interface{})
+	id := matcher["hookCallbackIds"].([]interface{})[0]
+
+	// The tool runs right after its PreToolUse hook, before the tool use is
+	// observed
+	mock.Emit(map[string]interface{}{
+		"type":       "control_request",
+		"request_id": "cli_1",
+		"request": map[string]interface{}{
+			"subtype":     "hook_callback",
+			"callback_id": id,
+			"input":       map[string]interface{}{"tool_name": "Edit", "tool_input": map[string]interface{}{"file_path": "edited.txt"}},
+			"tool_use_id": "toolu_1",
+		},
+	})
+	select {
+	case <-answered:
+	case <-ctx.Done():
+		t.Fatal("hook not answered")
+	}
+	os.WriteFile(path, []byte("new\n"), 0o644)
+
+	mock.Emit(map[string]interface{}{
+		"type": "assistant", "model": "sonnet", "session_id": "s1",
+		"content": []interface{}{
+			map[string]interface{}{"type": "tool_use", "id": "toolu_1", "name": "Edit", "input": map[string]interface{}{"file_path": "edited.txt"}},
+		},
+	})
+	mock.Emit(map[string]interface{}{
+		"type": "user", "session_id": "s1",
+		"content": []interface{}{
+			map[string]interface{}{"type": "tool_result", "tool_use_id": "toolu_1", "content": "ok"},
+		},
+	})
+	mock.Emit(map[string]interface{}{"type": "result", "subtype": "success", "session_id": "s1"})
+	if _, err := WaitForResult(ctx, client.Messages()); err != nil {
+		t.Fatalf("WaitForResult: %v", err)
+	}
+
+	changes := client.Changes()
+	if len(changes) != 1 || changes[0].Before == nil || *changes[0].Before != "old\n" {
+		t.Errorf("Changes = %+v, want edited.txt read before the edit", changes)
+	}
+}
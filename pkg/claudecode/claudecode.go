@@ -9,6 +9,12 @@
 //	    // Process messages
 //	}
 //
+// Query also has an iterator form that surfaces errors directly:
+//
+//	for msg, err := range claudecode.QueryIter(ctx, "What is 2+2?", nil) {
+//	    // Process messages and errors
+//	}
+//
 // 2. ClaudeSDKClient - For interactive, stateful conversations:
 //
 //	client := claudecode.NewClaudeSDKClient(nil)
@@ -30,6 +36,7 @@ package claudecode
 
 import (
 	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/internal"
 	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
 )
 
@@ -46,8 +53,54 @@ type (
 	UserMessage      = types.UserMessage
 	AssistantMessage = types.AssistantMessage
 	SystemMessage    = types.SystemMessage
+	AnySystemMessage = types.AnySystemMessage
 	ResultMessage    = types.ResultMessage
 	StreamEvent      = types.StreamEvent
+	CustomMessage    = types.CustomMessage
+	Usage            = types.Usage
+	ModelUsage       = types.ModelUsage
+
+	InitMessage            = types.InitMessage
+	MCPServerStatus        = types.MCPServerStatus
+	CompactBoundaryMessage = types.CompactBoundaryMessage
+	ErrorMessage           = types.ErrorMessage
+	ReconnectedMessage     = types.ReconnectedMessage
+	ModelFallbackMessage   = types.ModelFallbackMessage
+	ServerInfo             = types.ServerInfo
+	SessionInfo            = types.SessionInfo
+	SlashCommand           = types.SlashCommand
+
+	// Stream events
+	APIStreamEvent         = types.APIStreamEvent
+	StreamMessage          = types.StreamMessage
+	StreamDelta            = types.StreamDelta
+	MessageStartEvent      = types.MessageStartEvent
+	ContentBlockStartEvent = types.ContentBlockStartEvent
+	ContentBlockDeltaEvent = types.ContentBlockDeltaEvent
+	ContentBlockStopEvent  = types.ContentBlockStopEvent
+	MessageDeltaEvent      = types.MessageDeltaEvent
+	MessageStopEvent       = types.MessageStopEvent
+	UnknownStreamEvent     = types.UnknownStreamEvent
+
+	// Parsing
+	MessageParserFunc = internal.MessageParserFunc
+	DeadLetter        = types.DeadLetter
+	ParseWarning      = types.ParseWarning
+
+	// File changes
+	ChangeKind  = types.ChangeKind
+	ChangedFile = types.ChangedFile
+
+	// Permission audit
+	PermissionAuditRecord   = types.PermissionAuditRecord
+	PermissionAuditCallback = types.PermissionAuditCallback
+	PermissionDecision      = types.PermissionDecision
+
+	// Transcripts
+	TranscriptRecorder = types.TranscriptRecorder
+
+	// Rate limiting
+	RateLimiter = types.RateLimiter
 
 	// Content blocks
 	ContentBlock    = types.ContentBlock
@@ -55,15 +108,30 @@ type (
 	ThinkingBlock   = types.ThinkingBlock
 	ToolUseBlock    = types.ToolUseBlock
 	ToolResultBlock = types.ToolResultBlock
+	ImageBlock      = types.ImageBlock
+	ImageSource     = types.ImageSource
 
 	// Permissions
 	PermissionMode        = types.PermissionMode
+	SettingSource         = types.SettingSource
 	PermissionResult      = types.PermissionResult
 	PermissionResultAllow = types.PermissionResultAllow
 	PermissionResultDeny  = types.PermissionResultDeny
 	PermissionUpdate      = types.PermissionUpdate
+	PermissionRuleValue   = types.PermissionRuleValue
 	ToolPermissionContext = types.ToolPermissionContext
 	CanUseTool            = types.CanUseTool
+	HostToolHandler       = types.HostToolHandler
+
+	// Progress
+	ProgressEvent     = types.ProgressEvent
+	ProgressEventType = types.ProgressEventType
+	ProgressCallback  = types.ProgressCallback
+	TodoItem          = types.TodoItem
+
+	// Plan mode
+	Plan         = types.Plan
+	PlanApprover = types.PlanApprover
 
 	// Hooks
 	HookEvent      = types.HookEvent
@@ -72,19 +140,68 @@ type (
 	HookJSONOutput = types.HookJSONOutput
 	HookContext    = types.HookContext
 
+	PreToolUseHookSpecificOutput       = types.PreToolUseHookSpecificOutput
+	PostToolUseHookSpecificOutput      = types.PostToolUseHookSpecificOutput
+	UserPromptSubmitHookSpecificOutput = types.UserPromptSubmitHookSpecificOutput
+
+	PreCompactHookInput = types.PreCompactHookInput
+	AutoCompactPolicy   = types.AutoCompactPolicy
+	ReconnectPolicy     = types.ReconnectPolicy
+	RetryPolicy         = types.RetryPolicy
+
+	// Extended thinking
+	ThinkingType   = types.ThinkingType
+	ThinkingConfig = types.ThinkingConfig
+
+	Turn = types.Turn
+
+	ConversationState = types.ConversationState
+
+	// Budgets
+	BudgetUsage           = types.BudgetUsage
+	BudgetWarningCallback = types.BudgetWarningCallback
+
+	// Delivery
+	OverflowPolicy = types.OverflowPolicy
+	OutputFormat   = types.OutputFormat
+
+	// Process
+	CommandFactory   = types.CommandFactory
+	CLIFlag          = types.CLIFlag
+	CommandLine      = types.CommandLine
+	ExecutionSandbox = types.ExecutionSandbox
+	SandboxKind      = types.SandboxKind
+
+	// Agents
+	AgentDefinition = types.AgentDefinition
+
 	// MCP
 	MCPServerConfig      = types.MCPServerConfig
 	MCPStdioServerConfig = types.MCPStdioServerConfig
 	MCPSSEServerConfig   = types.MCPSSEServerConfig
 	MCPHTTPServerConfig  = types.MCPHTTPServerConfig
 	MCPSDKServerConfig   = types.MCPSDKServerConfig
+	MCPContent           = types.MCPContent
+	MCPToolResult        = types.MCPToolResult
+	MCPToolHandler       = types.MCPToolHandler
 
 	// Errors
-	CLINotFoundError   = errors.CLINotFoundError
-	CLIConnectionError = errors.CLIConnectionError
-	ProcessError       = errors.ProcessError
-	JSONDecodeError    = errors.JSONDecodeError
-	MessageParseError  = errors.MessageParseError
+	CLINotFoundError        = errors.CLINotFoundError
+	CLIConnectionError      = errors.CLIConnectionError
+	ProcessError            = errors.ProcessError
+	JSONDecodeError         = errors.JSONDecodeError
+	MessageParseError       = errors.MessageParseError
+	ControlRequestError     = errors.ControlRequestError
+	BufferOverflowError     = errors.BufferOverflowError
+	UnsupportedFeatureError = errors.UnsupportedFeatureError
+	ValidationError         = errors.ValidationError
+	CallbackTimeoutError    = errors.CallbackTimeoutError
+	BudgetExceededError     = errors.BudgetExceededError
+	QueueOverflowError      = errors.QueueOverflowError
+	DryRunError             = errors.DryRunError
+	MaxTurnsExceededError   = errors.MaxTurnsExceededError
+	ExecutionError          = errors.ExecutionError
+	StalledError            = errors.StalledError
 )
 
 // Re-export constants
@@ -95,12 +212,42 @@ const (
 	PermissionModePlan              = types.PermissionModePlan
 	PermissionModeBypassPermissions = types.PermissionModeBypassPermissions
 
+	// Setting sources
+	SettingSourceUser    = types.SettingSourceUser
+	SettingSourceProject = types.SettingSourceProject
+	SettingSourceLocal   = types.SettingSourceLocal
+
 	// Message types
-	MessageTypeUser      = types.MessageTypeUser
-	MessageTypeAssistant = types.MessageTypeAssistant
-	MessageTypeSystem    = types.MessageTypeSystem
-	MessageTypeResult    = types.MessageTypeResult
-	MessageTypeStream    = types.MessageTypeStream
+	MessageTypeUser        = types.MessageTypeUser
+	MessageTypeAssistant   = types.MessageTypeAssistant
+	MessageTypeSystem      = types.MessageTypeSystem
+	MessageTypeResult      = types.MessageTypeResult
+	MessageTypeStream      = types.MessageTypeStream
+	MessageTypeStreamEvent = types.MessageTypeStreamEvent
+
+	// System message subtypes
+	SystemSubtypeInit            = types.SystemSubtypeInit
+	SystemSubtypeCompactBoundary = types.SystemSubtypeCompactBoundary
+	SystemSubtypeError           = types.SystemSubtypeError
+	SystemSubtypeReconnected     = types.SystemSubtypeReconnected
+	SystemSubtypeModelFallback   = types.SystemSubtypeModelFallback
+
+	// Result message subtypes
+	ResultSubtypeSuccess              = types.ResultSubtypeSuccess
+	ResultSubtypeErrorMaxTurns        = types.ResultSubtypeErrorMaxTurns
+	ResultSubtypeErrorDuringExecution = types.ResultSubtypeErrorDuringExecution
+
+	// Stream event and delta types
+	StreamEventMessageStart      = types.StreamEventMessageStart
+	StreamEventContentBlockStart = types.StreamEventContentBlockStart
+	StreamEventContentBlockDelta = types.StreamEventContentBlockDelta
+	StreamEventContentBlockStop  = types.StreamEventContentBlockStop
+	StreamEventMessageDelta      = types.StreamEventMessageDelta
+	StreamEventMessageStop       = types.StreamEventMessageStop
+	DeltaTypeText                = types.DeltaTypeText
+	DeltaTypeThinking            = types.DeltaTypeThinking
+	DeltaTypeSignature           = types.DeltaTypeSignature
+	DeltaTypeInputJSON           = types.DeltaTypeInputJSON
 
 	// Hook events
 	HookEventPreToolUse       = types.HookEventPreToolUse
@@ -109,21 +256,111 @@ const (
 	HookEventStop             = types.HookEventStop
 	HookEventSubagentStop     = types.HookEventSubagentStop
 	HookEventPreCompact       = types.HookEventPreCompact
+
+	// Progress events
+	ProgressEventToolStart  = types.ProgressEventToolStart
+	ProgressEventToolStop   = types.ProgressEventToolStop
+	ProgressEventTodoUpdate = types.ProgressEventTodoUpdate
+	ProgressEventTurn       = types.ProgressEventTurn
+	ProgressEventDone       = types.ProgressEventDone
+
+	// Overflow policies
+	OverflowBlock      = types.OverflowBlock
+	OverflowDropOldest = types.OverflowDropOldest
+	OverflowError      = types.OverflowError
+
+	// Output formats
+	OutputFormatStreamJSON = types.OutputFormatStreamJSON
+	OutputFormatJSON       = types.OutputFormatJSON
+
+	// Sandboxes
+	SandboxBubblewrap = types.SandboxBubblewrap
+	SandboxFirejail   = types.SandboxFirejail
+	SandboxDocker     = types.SandboxDocker
+
+	// Agent models
+	AgentModelSonnet  = types.AgentModelSonnet
+	AgentModelOpus    = types.AgentModelOpus
+	AgentModelHaiku   = types.AgentModelHaiku
+	AgentModelInherit = types.AgentModelInherit
+
+	// Thinking types
+	ThinkingAdaptive = types.ThinkingAdaptive
+	ThinkingEnabled  = types.ThinkingEnabled
+	ThinkingDisabled = types.ThinkingDisabled
 )
 
 // Error constructors
 var (
 	// Error base types
-	ErrCLINotFound   = errors.ErrCLINotFound
-	ErrCLIConnection = errors.ErrCLIConnection
-	ErrProcess       = errors.ErrProcess
-	ErrJSONDecode    = errors.ErrJSONDecode
-	ErrMessageParse  = errors.ErrMessageParse
+	ErrCLINotFound        = errors.ErrCLINotFound
+	ErrCLIConnection      = errors.ErrCLIConnection
+	ErrProcess            = errors.ErrProcess
+	ErrJSONDecode         = errors.ErrJSONDecode
+	ErrMessageParse       = errors.ErrMessageParse
+	ErrControlRequest     = errors.ErrControlRequest
+	ErrBufferOverflow     = errors.ErrBufferOverflow
+	ErrUnsupportedFeature = errors.ErrUnsupportedFeature
+	ErrInvalidOptions     = errors.ErrInvalidOptions
+	ErrCallbackTimeout    = errors.ErrCallbackTimeout
+	ErrBudgetExceeded     = errors.ErrBudgetExceeded
+	ErrQueueOverflow      = errors.ErrQueueOverflow
+	ErrDryRun             = errors.ErrDryRun
+	ErrMaxTurnsExceeded   = errors.ErrMaxTurnsExceeded
+	ErrExecution          = errors.ErrExecution
+	ErrStalled            = errors.ErrStalled
 
 	// Error constructors
-	NewCLINotFoundError   = errors.NewCLINotFoundError
-	NewCLIConnectionError = errors.NewCLIConnectionError
-	NewProcessError       = errors.NewProcessError
-	NewJSONDecodeError    = errors.NewJSONDecodeError
-	NewMessageParseError  = errors.NewMessageParseError
+	NewCLINotFoundError        = errors.NewCLINotFoundError
+	NewCLIConnectionError      = errors.NewCLIConnectionError
+	NewProcessError            = errors.NewProcessError
+	NewJSONDecodeError         = errors.NewJSONDecodeError
+	NewMessageParseError       = errors.NewMessageParseError
+	NewControlRequestError     = errors.NewControlRequestError
+	NewBufferOverflowError     = errors.NewBufferOverflowError
+	NewUnsupportedFeatureError = errors.NewUnsupportedFeatureError
+	NewValidationError         = errors.NewValidationError
+	NewCallbackTimeoutError    = errors.NewCallbackTimeoutError
+	NewBudgetExceededError     = errors.NewBudgetExceededError
+	NewQueueOverflowError      = errors.NewQueueOverflowError
+	NewDryRunError             = errors.NewDryRunError
+	NewMaxTurnsExceededError   = errors.NewMaxTurnsExceededError
+	NewExecutionError          = errors.NewExecutionError
+	NewStalledError            = errors.NewStalledError
+
+	// Error classification
+	IsTransient = errors.IsTransient
 )
+
+// RegisterMessageParser registers a parser for a custom top-level message type.
+//
+// Example:
+//
+//	claudecode.RegisterMessageParser("tool_progress", func(data map[string]interface{}) (claudecode.Message, error) {
+//	    return &claudecode.CustomMessage{Type: "tool_progress", Data: data}, nil
+//	})
+var RegisterMessageParser = internal.RegisterMessageParser
+
+// NewOptions returns empty options configured with chained With* methods.
+//
+// Example:
+//
+//	options := claudecode.NewOptions().
+//	    WithModel("sonnet").
+//	    WithSystemPrompt("You are an expert Go developer").
+//	    WithAllowedTools("Read", "Grep")
+//	messages, err := claudecode.Query(ctx, "Review main.go", options)
+var NewOptions = types.NewOptions
+
+// UnmarshalMessage decodes a message encoded with json.Marshal, restoring
+// its concrete type and content blocks.
+var UnmarshalMessage = types.UnmarshalMessage
+
+// UnmarshalContentBlock decodes a content block encoded with json.Marshal
+var UnmarshalContentBlock = types.UnmarshalContentBlock
+
+// Flag returns an extra CLI flag without a value, for ExtraFlags
+var Flag = types.Flag
+
+// FlagWithValue returns an extra CLI flag followed by value, for ExtraFlags
+var FlagWithValue = types.FlagWithValue
@@ -0,0 +1,38 @@
+package claudecode
+
+import (
+	"context"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport"
+)
+
+// CLI detection types
+type (
+	CLIInfo    = transport.CLIInfo
+	CLIVersion = transport.CLIVersion
+	CLIFeature = transport.CLIFeature
+)
+
+// CLI features with a minimum required version
+const (
+	FeatureIncludePartialMessages = transport.FeatureIncludePartialMessages
+	FeatureForkSession            = transport.FeatureForkSession
+	FeatureSDKMCP                 = transport.FeatureSDKMCP
+	FeatureDebugToStderr          = transport.FeatureDebugToStderr
+)
+
+// DetectCLI locates the Claude CLI, runs `claude --version` and reports the
+// installed version together with its capability matrix.
+//
+// Example:
+//
+//	info, err := claudecode.DetectCLI(ctx)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if !info.Supports(claudecode.FeatureIncludePartialMessages) {
+//	    log.Printf("CLI %s cannot stream partial messages", info.Version)
+//	}
+func DetectCLI(ctx context.Context) (*CLIInfo, error) {
+	return transport.DetectCLI(ctx, "")
+}
@@ -6,6 +6,7 @@ import (
 	stderrors "errors"
 	"os"
 	"sync"
+	"sync/atomic"
 
 	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
 	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/internal"
@@ -43,15 +44,111 @@ type ClaudeSDKClient struct {
 	options   *types.ClaudeCodeOptions
 	transport transport.Transport
 	query     *internal.Query
-
-	connected bool
-	mu        sync.RWMutex
+	progress  *progressTracker
+	dead      *deadLetterSink
+	parser    *messageParser
+	telemetry *telemetry
+	budget    *budgetTracker
+	stall     *stallMonitor
+
+	// Transport supplied with NewClaudeSDKClientWithTransport, used instead
+	// of spawning the CLI
+	customTransport transport.Transport
+
+	// Middleware added with Use, and the transport wrapped in it that all
+	// messages go through
+	middleware []Middleware
+	wire       transport.Transport
+
+	// Callbacks added with OnToolUse and OnToolResult
+	tools toolSubscriptions
+
+	// Permission callback passed to every query, including intercepted tools
+	canUseTool types.CanUseTool
+
+	// Audit trail of permission decisions, nil without a sink; it spans
+	// reconnects
+	audit *permissionAuditor
+
+	// Files changed by tool uses, nil unless TrackFileChanges is set
+	changes *ChangeTracker
+
+	// Resumed session and unacknowledged user messages, for reconnects
+	sessionID string
+	unacked   [][]byte
+	resumeMu  sync.Mutex
+
+	// Latest system/init message, for GetServerInfo
+	initMsg *types.InitMessage
+	initMu  sync.Mutex
+
+	// In-process MCP server for tools added with RegisterTool
+	localTools *internal.SDKMCPServer
+
+	// Token usage accumulated across results, and the last session total
+	// cost, from which each result's TurnCostUSD is derived
+	usage     types.Usage
+	totalCost costDelta
+	usageMu   sync.Mutex
+
+	// Model, permission mode and permission updates changed while
+	// connected, for Snapshot
+	model       *string
+	mode        *types.PermissionMode
+	permissions []types.PermissionUpdate
+	stateMu     sync.Mutex
+
+	// Messages discarded by the overflow policy, and raw frames
+	// RawMessages had no room for
+	dropped    atomic.Uint64
+	rawDropped atomic.Uint64
+
+	// Sessions created with NewSession, keyed by session ID
+	sessions   map[string]*Session
+	sessionsMu sync.RWMutex
+
+	connected    bool
+	shuttingDown atomic.Bool
+	mu           sync.RWMutex
+
+	// User messages written and results received, and the turn of a
+	// running compaction (-1 while it is being sent, 0 when none)
+	turnsSent   atomic.Int64
+	turnsDone   atomic.Int64
+	compactTurn atomic.Int64
+
+	// Turns waiting for their result, oldest first, and the session_id of
+	// the last message sent
+	turns     []*turn
+	lastLabel string
+	turnsMu   sync.Mutex
+
+	// Closed once all output of the CLI has been delivered
+	outputDone chan struct{}
+	outputOnce sync.Once
+
+	// Errors held for the next result with TurnErrors, the last connection
+	// error and the error that ended the conversation, for Wait
+	turnErrs []error
+	connErr  error
+	fatalErr error
+	errsMu   sync.Mutex
 
 	// Message handling
 	messages chan types.Message
 	errors   chan error
+	raw      chan json.RawMessage
 	ctx      context.Context
 	cancel   context.CancelFunc
+
+	// Stops cancelling ctx along with the context passed to Connect
+	unlinkCtx func() bool
+
+	// Held for reading while sending on messages and errors. Senders give up
+	// once ctx is done, so Close can take them to close the channels.
+	messagesMu sync.RWMutex
+	errorsMu   sync.RWMutex
+	closeOnce  sync.Once
 }
 
 // NewClaudeSDKClient creates a new Claude SDK client
@@ -65,16 +162,42 @@ func NewClaudeSDKClient(options *types.ClaudeCodeOptions) *ClaudeSDKClient {
 
 	ctx, cancel := context.WithCancel(context.Background())
 
-	return &ClaudeSDKClient{
+	client := &ClaudeSDKClient{
 		options:  options,
-		messages: make(chan types.Message, 100),
+		messages: make(chan types.Message, messageBufferSize(options)),
 		errors:   make(chan error, 10),
 		ctx:      ctx,
 		cancel:   cancel,
 	}
+
+	if options.EnableRawMessages {
+		client.raw = make(chan json.RawMessage, rawMessageBufferSize)
+	}
+	if options.TrackFileChanges {
+		client.changes = NewChangeTracker()
+		if options.CWD != nil {
+			client.changes.Dir = *options.CWD
+		}
+	}
+
+	return client
+}
+
+// NewClaudeSDKClientWithTransport creates a client that talks to Claude Code
+// over the given transport instead of spawning a local CLI subprocess, e.g. a
+// WebSocketTransport connected to a remote instance.
+func NewClaudeSDKClientWithTransport(options *types.ClaudeCodeOptions, t transport.Transport) *ClaudeSDKClient {
+	client := NewClaudeSDKClient(options)
+	client.customTransport = t
+	return client
 }
 
-// Connect establishes a connection to Claude with an optional prompt
+// Connect establishes a connection to Claude with an optional prompt: a
+// string sent as the first user message, or a channel streamed like Query's.
+//
+// ctx bounds the whole connection, not just the handshake: once it is done
+// the CLI is stopped, pending control requests fail, running callbacks are
+// signalled and no more messages are delivered. Close must still be called.
 func (c *ClaudeSDKClient) Connect(ctx context.Context, prompt interface{}) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -82,14 +205,30 @@ func (c *ClaudeSDKClient) Connect(ctx context.Context, prompt interface{}) error
 	if c.connected {
 		return stderrors.New("already connected")
 	}
+	if c.ctx.Err() != nil {
+		return errors.NewCLIConnectionError("client closed", nil)
+	}
 
-	// Validate options for streaming mode requirements
-	if c.options.CanUseTool != nil {
-		// CanUseTool requires streaming mode
-		if _, ok := prompt.(string); ok {
-			return stderrors.New("can_use_tool callback requires streaming mode. Please provide prompt as a channel instead of a string")
+	if err := c.options.Validate(); err != nil {
+		return err
+	}
+	if c.options.OutputFormat == types.OutputFormatJSON {
+		return errors.NewValidationError("OutputFormat", "the client requires stream-json output")
+	}
+
+	// The query, reconnects and every sender follow c.ctx
+	c.unlinkCtx = context.AfterFunc(ctx, c.cancel)
+	defer func() {
+		if !c.connected {
+			c.unlinkCtx()
 		}
+	}()
 
+	// Tools are intercepted at the permission layer
+	canUseTool := c.interceptedToolPermissions(c.recordPermissions(c.options.CanUseTool))
+
+	// Validate options for streaming mode requirements
+	if canUseTool != nil {
 		// CanUseTool and permission_prompt_tool_name are mutually exclusive
 		if c.options.PermissionPromptToolName != nil {
 			return stderrors.New("can_use_tool callback cannot be used with permission_prompt_tool_name. Please use one or the other")
@@ -99,90 +238,246 @@ func (c *ClaudeSDKClient) Connect(ctx context.Context, prompt interface{}) error
 		c.options.PermissionPromptToolName = stringPtr("stdio")
 	}
 
+	c.canUseTool = canUseTool
+	if c.audit == nil {
+		c.audit = c.newPermissionAuditor()
+	}
+
+	// Advertise tools added with RegisterTool
+	c.registerLocalTools()
+
 	// Create transport
-	c.transport = transport.NewSubprocessTransport(prompt, c.options, "")
+	if c.customTransport != nil {
+		c.transport = c.customTransport
+		if c.options.Logger != nil {
+			c.transport.SetLogger(c.options.Logger)
+		}
+	} else {
+		// The client always streams; a string prompt is sent once connected
+		c.transport = transport.NewSubprocessTransport(nil, c.options, "")
+	}
 
 	// Connect transport
 	if err := c.transport.Connect(ctx); err != nil {
 		return err
 	}
 
-	// Extract SDK MCP servers
-	sdkMCPServers := make(map[string]interface{})
-	if c.options.MCPServers != nil {
-		for name, config := range c.options.MCPServers {
-			if sdkConfig, ok := config.(types.MCPSDKServerConfig); ok {
-				sdkMCPServers[name] = sdkConfig.Instance
-			}
+	c.telemetry = newTelemetry(c.options)
+	if err := c.startQuery(); err != nil {
+		c.telemetry.end()
+		c.transport.Close()
+		return err
+	}
+
+	c.connected = true
+	c.shuttingDown.Store(false)
+	c.outputDone = make(chan struct{})
+	c.outputOnce = sync.Once{}
+	c.progress = newProgressTracker(c.options)
+	c.dead = newDeadLetterSink(c.options)
+	c.parser = newMessageParser(c.options)
+	c.telemetry.observeQueue(c.messages)
+	c.budget = newBudgetTracker(c.options)
+	c.stall = newStallMonitor(c.options)
+
+	c.logger().InfoContext(ctx, "connected to Claude Code")
+
+	// Start message processing
+	go c.processMessages()
+	if c.stall != nil {
+		go c.watchStalls(c.stall)
+	}
+
+	switch p := prompt.(type) {
+	case string:
+		if p == "" {
+			break
+		}
+		message, _ := promptMessage(p)
+		data, err := json.Marshal(message)
+		if err == nil {
+			err = c.writeUserMessage(ctx, "default", append(data, '\n'))
 		}
+		if err != nil {
+			c.closeLocked()
+			return err
+		}
+	case chan interface{}:
+		// If we have a channel prompt, start streaming it
+		go c.streamPrompt(p)
 	}
 
-	// Convert hooks format
-	hooks := c.convertHooks()
+	return nil
+}
+
+// startQuery creates, starts and initializes the query handler for the
+// current transport
+func (c *ClaudeSDKClient) startQuery() error {
+	c.wire = c.intercept(c.transport)
 
 	// Create query handler
 	c.query = internal.NewQuery(
-		c.transport,
+		c.ctx,
+		c.wire,
 		true, // ClaudeSDKClient always uses streaming mode
-		c.options.CanUseTool,
-		hooks,
-		sdkMCPServers,
+		c.canUseTool,
+		c.convertHooks(),
+		extractSDKMCPServers(c.options),
 	)
 
+	if c.raw != nil {
+		c.query.SetRawTap(c.raw, func() { c.rawDropped.Add(1) })
+	}
+
+	c.query.SetLogger(c.options.Logger)
+	c.query.SetTelemetry(c.telemetry.controlRequests())
+	c.query.SetMaxLineSize(c.options.MaxMessageSize)
+	c.query.SetRawOutput(c.options.RawOutput)
+	if c.audit != nil {
+		c.query.SetPermissionAudit(c.audit.record)
+	}
+
+	if c.options.ControlRequestTimeout != 0 {
+		c.query.SetControlTimeout(c.options.ControlRequestTimeout)
+	}
+	c.query.SetCallbackTimeout(c.options.CallbackTimeout)
+
+	// Bound concurrent permission callbacks
+	if c.options.SerializePermissionRequests {
+		c.query.SetPermissionConcurrency(1)
+	} else {
+		c.query.SetPermissionConcurrency(c.options.MaxConcurrentPermissionRequests)
+	}
+
 	// Start query handler
 	if err := c.query.Start(); err != nil {
-		c.transport.Close()
 		return err
 	}
 
 	// Initialize
 	if err := c.query.Initialize(); err != nil {
 		c.query.Stop()
-		c.transport.Close()
 		return err
 	}
 
-	c.connected = true
-
-	// Start message processing
-	go c.processMessages()
-
-	// If we have a channel prompt, start streaming it
-	if ch, ok := prompt.(chan interface{}); ok {
-		go c.streamPrompt(ch)
+	// Session-scoped permissions do not outlive the CLI process
+	if updates := c.sessionPermissions(); len(updates) > 0 {
+		if err := c.query.UpdatePermissions(c.ctx, updates); err != nil {
+			c.query.Stop()
+			return err
+		}
 	}
 
 	return nil
 }
 
-// Close terminates the connection
+// Close terminates the connection and closes the Messages, Errors and
+// RawMessages channels; values already buffered can still be received. It
+// is safe to call more than once and concurrently. A closed client cannot
+// be connected again.
 func (c *ClaudeSDKClient) Close() error {
+	// Abort writes first; they hold c.mu for reading
+	c.cancel()
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	return c.closeLocked()
+}
+
+// closeLocked implements Close with c.mu held
+func (c *ClaudeSDKClient) closeLocked() error {
 	if !c.connected {
 		return nil
 	}
 
 	c.connected = false
 	c.cancel()
+	c.unlinkCtx()
+	c.logger().Debug("closing client")
+	c.telemetry.end()
 
+	// Close the transport before stopping the query so that a read blocked
+	// on the subprocess returns instead of stalling Stop; queued permission
+	// requests are answered first
+	if c.query != nil {
+		c.query.Cancel()
+	}
+	var err error
+	if c.transport != nil {
+		err = c.transport.Close()
+	}
 	if c.query != nil {
 		c.query.Stop()
 	}
 
-	if c.transport != nil {
-		return c.transport.Close()
+	c.closeOutputs()
+	return err
+}
+
+// closeOutputs closes the channels the client delivers on. It must be
+// called after c.ctx is cancelled, so blocked senders let go of the locks,
+// and after the query is stopped, as its read loop feeds RawMessages.
+func (c *ClaudeSDKClient) closeOutputs() {
+	c.closeOnce.Do(func() {
+		c.messagesMu.Lock()
+		close(c.messages)
+		c.messagesMu.Unlock()
+
+		c.errorsMu.Lock()
+		close(c.errors)
+		c.errorsMu.Unlock()
+
+		if c.raw != nil {
+			close(c.raw)
+		}
+	})
+}
+
+// sendError delivers err on the Errors channel. Returns false if the client
+// is closing.
+func (c *ClaudeSDKClient) sendError(err error) bool {
+	c.errorsMu.RLock()
+	defer c.errorsMu.RUnlock()
+
+	if c.ctx.Err() != nil {
+		return false
+	}
+	select {
+	case c.errors <- err:
+		return true
+	case <-c.ctx.Done():
+		return false
 	}
+}
 
-	close(c.messages)
-	close(c.errors)
+// sendMessage delivers msg on the Messages channel following the overflow
+// policy. Returns false if the client is closing.
+func (c *ClaudeSDKClient) sendMessage(msg types.Message) bool {
+	c.messagesMu.RLock()
+	defer c.messagesMu.RUnlock()
 
-	return nil
+	if c.ctx.Err() != nil {
+		return false
+	}
+	return c.deliver(c.messages, msg, nil)
 }
 
-// SendMessage sends a message to Claude
+// SendMessage sends a message to Claude. See SendMessageContext.
 func (c *ClaudeSDKClient) SendMessage(prompt string, sessionID string) error {
+	return c.sendText(context.Background(), prompt, sessionID)
+}
+
+// SendMessageContext sends a message to Claude. The write is abandoned,
+// and ctx.Err() returned, if ctx is done before the CLI has taken the
+// message, e.g. because it stopped reading its input. WriteTimeout bounds
+// it too.
+func (c *ClaudeSDKClient) SendMessageContext(ctx context.Context, prompt string, sessionID string) error {
+	return c.sendText(ctx, prompt, sessionID)
+}
+
+// sendText writes a plain text user message
+func (c *ClaudeSDKClient) sendText(ctx context.Context, prompt string, sessionID string) error {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
@@ -205,11 +500,17 @@ func (c *ClaudeSDKClient) SendMessage(prompt string, sessionID string) error {
 		return err
 	}
 
-	return c.transport.Write(append(data, '\n'))
+	return c.writeUserMessage(ctx, sessionID, append(data, '\n'))
 }
 
-// SendRawMessage sends a raw message map
+// SendRawMessage sends a raw message map. See SendRawMessageContext.
 func (c *ClaudeSDKClient) SendRawMessage(message map[string]interface{}) error {
+	return c.SendRawMessageContext(context.Background(), message)
+}
+
+// SendRawMessageContext sends a raw message map, abandoning the write if
+// ctx is done or WriteTimeout passes first like SendMessageContext
+func (c *ClaudeSDKClient) SendRawMessageContext(ctx context.Context, message map[string]interface{}) error {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
@@ -222,7 +523,38 @@ func (c *ClaudeSDKClient) SendRawMessage(message map[string]interface{}) error {
 		return err
 	}
 
-	return c.transport.Write(append(data, '\n'))
+	if message["type"] == "user" {
+		sessionID, _ := message["session_id"].(string)
+		return c.writeUserMessage(ctx, sessionID, append(data, '\n'))
+	}
+	return c.write(ctx, append(data, '\n'))
+}
+
+// write sends data over the wire. It is abandoned once ctx is done, the
+// client closes or WriteTimeout passes.
+func (c *ClaudeSDKClient) write(ctx context.Context, data []byte) error {
+	writeCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(c.ctx, cancel)
+	defer stop()
+
+	if c.options.WriteTimeout > 0 {
+		var cancelTimeout context.CancelFunc
+		writeCtx, cancelTimeout = context.WithTimeout(writeCtx, c.options.WriteTimeout)
+		defer cancelTimeout()
+	}
+
+	err := c.wire.Write(writeCtx, data)
+	switch {
+	case err == nil || ctx.Err() != nil:
+		return err
+	case c.ctx.Err() != nil:
+		return errors.NewCLIConnectionError("client closed while writing", err)
+	case writeCtx.Err() == context.DeadlineExceeded:
+		return errors.NewCLIConnectionError("timed out writing message", err)
+	default:
+		return err
+	}
 }
 
 // Messages returns the message channel
@@ -230,7 +562,35 @@ func (c *ClaudeSDKClient) Messages() <-chan types.Message {
 	return c.messages
 }
 
-// Errors returns the error channel
+// RawMessages returns a channel mirroring every inbound frame before parsing.
+//
+// It is only populated when ClaudeCodeOptions.EnableRawMessages is set and
+// returns nil otherwise. Frames are delivered in addition to the typed
+// Messages channel. The channel buffers rawMessageBufferSize frames; once
+// it is full new frames are dropped rather than stalling Messages, and
+// counted by RawMessagesDropped.
+func (c *ClaudeSDKClient) RawMessages() <-chan json.RawMessage {
+	return c.raw
+}
+
+// RawMessagesDropped returns the number of frames RawMessages dropped
+// because its buffer was full
+func (c *ClaudeSDKClient) RawMessagesDropped() uint64 {
+	return c.rawDropped.Load()
+}
+
+// DeadLetterCount returns the number of frames routed to
+// ClaudeCodeOptions.DeadLetters, including dropped ones
+func (c *ClaudeSDKClient) DeadLetterCount() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.dead.total()
+}
+
+// Errors returns the error channel. Besides transport and parse errors it
+// carries the error of every failed result (see ResultMessage.Err), sent
+// after the result itself is delivered.
 func (c *ClaudeSDKClient) Errors() <-chan error {
 	return c.errors
 }
@@ -247,6 +607,113 @@ func (c *ClaudeSDKClient) Interrupt() error {
 	return c.query.Interrupt()
 }
 
+// InterruptAndWait sends an interrupt signal and blocks until the CLI
+// acknowledges it or ctx is done. A *ControlRequestError is returned if the
+// CLI rejects the interrupt.
+func (c *ClaudeSDKClient) InterruptAndWait(ctx context.Context) error {
+	query, err := c.activeQuery()
+	if err != nil {
+		return err
+	}
+
+	return query.InterruptAndWait(ctx)
+}
+
+// SetPermissionMode switches the permission mode mid-conversation, e.g. from
+// plan to acceptEdits, and waits for the CLI to acknowledge the change
+func (c *ClaudeSDKClient) SetPermissionMode(ctx context.Context, mode types.PermissionMode) error {
+	query, err := c.activeQuery()
+	if err != nil {
+		return err
+	}
+
+	if err := query.SetPermissionMode(ctx, mode); err != nil {
+		return err
+	}
+
+	c.stateMu.Lock()
+	c.mode = &mode
+	c.stateMu.Unlock()
+	return nil
+}
+
+// SetModel switches the model used for subsequent turns, e.g. a cheaper
+// model for simple follow-ups. An empty model resets to the default.
+func (c *ClaudeSDKClient) SetModel(ctx context.Context, model string) error {
+	query, err := c.activeQuery()
+	if err != nil {
+		return err
+	}
+
+	if err := query.SetModel(ctx, model); err != nil {
+		return err
+	}
+
+	c.stateMu.Lock()
+	c.model = &model
+	c.stateMu.Unlock()
+	return nil
+}
+
+// UpdatePermissions applies permission updates, such as added rules or
+// directories, to the running session and waits for the CLI to acknowledge
+// them
+func (c *ClaudeSDKClient) UpdatePermissions(ctx context.Context, updates ...types.PermissionUpdate) error {
+	query, err := c.activeQuery()
+	if err != nil {
+		return err
+	}
+
+	if err := query.UpdatePermissions(ctx, updates); err != nil {
+		return err
+	}
+
+	c.permissionsApplied(updates)
+	return nil
+}
+
+// TotalUsage returns the token usage accumulated across all results
+// received by this client
+func (c *ClaudeSDKClient) TotalUsage() types.Usage {
+	c.usageMu.Lock()
+	defer c.usageMu.Unlock()
+
+	total := types.Usage{}
+	total.Add(&c.usage)
+	return total
+}
+
+// recordUsage adds the usage reported by a result to the running total
+// and sets the cost of its turn
+func (c *ClaudeSDKClient) recordUsage(msg types.Message) {
+	result, ok := msg.(*types.ResultMessage)
+	if !ok {
+		return
+	}
+
+	c.usageMu.Lock()
+	defer c.usageMu.Unlock()
+
+	if result.TotalCostUSD != nil {
+		result.TurnCostUSD = c.totalCost.next(*result.TotalCostUSD)
+	}
+	if result.Usage != nil {
+		c.usage.Add(result.Usage)
+	}
+}
+
+// activeQuery returns the query handler of a connected client. Control
+// requests wait without holding c.mu so Close is never blocked by them.
+func (c *ClaudeSDKClient) activeQuery() (*internal.Query, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if !c.connected {
+		return nil, errors.NewCLIConnectionError("not connected. Call Connect() first", nil)
+	}
+	return c.query, nil
+}
+
 // IsConnected returns true if the client is connected
 func (c *ClaudeSDKClient) IsConnected() bool {
 	c.mu.RLock()
@@ -257,44 +724,145 @@ func (c *ClaudeSDKClient) IsConnected() bool {
 
 // processMessages processes incoming messages from the query handler
 func (c *ClaudeSDKClient) processMessages() {
+	c.mu.RLock()
+	query := c.query
+	c.mu.RUnlock()
+
+	done := query.Done()
 	for {
 		select {
 		case <-c.ctx.Done():
 			return
-		case data, ok := <-c.query.ReceiveMessages():
-			if !ok {
+		case data, ok := <-query.ReceiveMessages():
+			if !ok || !c.drainErrors(query) || !c.handleMessage(data) {
+				return
+			}
+		case err, ok := <-query.Errors():
+			if !ok || !c.handleError(err) {
+				return
+			}
+		case <-done:
+			// The transport is gone; deliver what was read before it ended
+			if !c.drain(query) {
 				return
 			}
 
-			msg, err := internal.ParseMessage(data)
-			if err != nil {
-				select {
-				case c.errors <- err:
-				case <-c.ctx.Done():
-					return
-				}
+			if c.options.Reconnect == nil || c.shuttingDown.Load() {
+				c.endOutput(nil)
+				done = nil
 				continue
 			}
 
-			select {
-			case c.messages <- msg:
-			case <-c.ctx.Done():
+			next, err := c.reconnect(query)
+			if err != nil {
+				c.handleError(err)
+				c.endOutput(err)
 				return
 			}
-		case err, ok := <-c.query.Errors():
-			if !ok {
-				return
+			c.reconnected()
+			query = next
+			done = query.Done()
+		}
+	}
+}
+
+// drain handles the messages and errors still buffered in a finished query.
+// Returns false if the client is shutting down.
+func (c *ClaudeSDKClient) drain(query *internal.Query) bool {
+	for {
+		select {
+		case data, ok := <-query.ReceiveMessages():
+			if !ok || !c.handleMessage(data) {
+				return false
 			}
+		case err, ok := <-query.Errors():
+			if !ok || !c.handleError(err) {
+				return false
+			}
+		default:
+			return true
+		}
+	}
+}
 
-			select {
-			case c.errors <- err:
-			case <-c.ctx.Done():
-				return
+// drainErrors handles the errors a query has already reported, which the
+// read loop sent before any message still to be handled, so that they keep
+// their order. Returns false if the client is shutting down.
+func (c *ClaudeSDKClient) drainErrors(query *internal.Query) bool {
+	for {
+		select {
+		case err, ok := <-query.Errors():
+			if !ok || !c.handleError(err) {
+				return false
 			}
+		default:
+			return true
 		}
 	}
 }
 
+// handleMessage parses and delivers one message. Returns false if the
+// client is shutting down.
+func (c *ClaudeSDKClient) handleMessage(data map[string]interface{}) bool {
+	msg, err := c.parser.parse(c.logger(), data)
+	if err != nil {
+		c.logger().Warn("failed to parse message", "type", data["type"], "error", err)
+		if c.dead.parseFailed(data, err) {
+			return true
+		}
+		return c.handleError(err)
+	}
+
+	if c.options.Transcript != nil {
+		c.options.Transcript.Record(data)
+	}
+	c.progress.observe(msg)
+	c.telemetry.observe(msg)
+	c.recordUsage(msg)
+	c.checkBudget(msg)
+	c.stall.observe(msg)
+	c.trackSession(msg)
+	c.recordInit(msg)
+	if init, ok := msg.(*types.InitMessage); ok {
+		c.logger().Info("session started", "model", init.Model)
+	}
+	c.checkAutoCompact(msg)
+	c.tools.notify(msg)
+	if c.changes != nil {
+		c.changes.Observe(msg)
+	}
+
+	result, isResult := msg.(*types.ResultMessage)
+	if isResult {
+		c.attachTurnErrors(result)
+	}
+
+	if !c.routeToSession(msg) && !c.sendMessage(msg) {
+		return false
+	}
+
+	// Failed results are also reported as errors, unless they carry them
+	if isResult && !c.options.TurnErrors {
+		if err := result.Err(); err != nil {
+			return c.handleError(err)
+		}
+	}
+	return true
+}
+
+// handleError delivers one error. Returns false if the client is shutting down.
+func (c *ClaudeSDKClient) handleError(err error) bool {
+	if c.dead.readFailed(err) {
+		return true
+	}
+
+	c.noteConnError(err)
+	if c.holdTurnError(err) {
+		return true
+	}
+	return c.sendError(err)
+}
+
 // streamPrompt streams prompt messages from a channel
 func (c *ClaudeSDKClient) streamPrompt(ch chan interface{}) {
 	for {
@@ -306,56 +874,33 @@ func (c *ClaudeSDKClient) streamPrompt(ch chan interface{}) {
 				return
 			}
 
-			// Convert to map if needed
-			var message map[string]interface{}
-			switch v := msg.(type) {
-			case map[string]interface{}:
-				message = v
-			case string:
-				message = map[string]interface{}{
-					"type": "user",
-					"message": map[string]interface{}{
-						"role":    "user",
-						"content": v,
-					},
-					"parent_tool_use_id": nil,
-					"session_id":         "default",
-				}
-			default:
-				continue
+			message, err := promptMessage(msg)
+			if err == nil {
+				err = c.SendRawMessage(message)
 			}
-
-			if err := c.SendRawMessage(message); err != nil {
-				select {
-				case c.errors <- err:
-				case <-c.ctx.Done():
-					return
-				}
+			if err != nil && !c.sendError(err) {
+				return
 			}
 		}
 	}
 }
 
-// convertHooks converts ClaudeCodeOptions hooks to internal format
+// convertHooks converts ClaudeCodeOptions hooks to internal format, adding
+// the hook reading files before tools change them for TrackFileChanges
 func (c *ClaudeSDKClient) convertHooks() map[types.HookEvent][]types.HookMatcher {
-	if c.options.Hooks == nil {
-		return nil
+	if c.changes == nil {
+		if c.options.Hooks == nil {
+			return nil
+		}
+		return c.options.Hooks
 	}
-	return c.options.Hooks
-}
 
-// GetServerInfo returns server initialization info
-func (c *ClaudeSDKClient) GetServerInfo() (map[string]interface{}, error) {
-	// This would be implemented based on the first system message received
-	// For now, return a placeholder
-	return map[string]interface{}{
-		"commands": []string{},
-		"output_styles": []string{
-			"text",
-			"json",
-			"stream-json",
-		},
-	}, nil
+	merged := make(map[types.HookEvent][]types.HookMatcher, len(c.options.Hooks)+1)
+	for event, matchers := range c.options.Hooks {
+		merged[event] = append([]types.HookMatcher(nil), matchers...)
+	}
+	merged[types.HookEventPreToolUse] = append(merged[types.HookEventPreToolUse], c.changes.Hook())
+	return merged
 }
 
 // Helper function to get string pointer
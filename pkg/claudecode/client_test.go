@@ -0,0 +1,142 @@
+package claudecode
+
+import (
+	"context"
+	stderrors "errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/transporttest"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// stuckInput blocks user messages as a CLI that stopped reading stdin would
+func stuckInput(next MessageHandler) MessageHandler {
+	return func(ctx context.Context, dir Direction, msg map[string]interface{}) error {
+		if dir == Outbound && msg["type"] == "user" {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return next(ctx, dir, msg)
+	}
+}
+
+func TestSendMessageContext(t *testing.T) {
+	connect := func(options *types.ClaudeCodeOptions) *ClaudeSDKClient {
+		t.Helper()
+		client := NewClaudeSDKClientWithTransport(options, transporttest.NewMockTransport())
+		client.Use(stuckInput)
+		if err := client.Connect(context.Background(), nil); err != nil {
+			t.Fatalf("Connect: %v", err)
+		}
+		return client
+	}
+
+	client := connect(nil)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := client.SendMessageContext(ctx, "hello", "default"); err != context.DeadlineExceeded {
+		t.Errorf("SendMessageContext = %v, want context.DeadlineExceeded", err)
+	}
+	raw := map[string]interface{}{"type": "user", "message": map[string]interface{}{"role": "user", "content": "hi"}}
+	if err := client.SendRawMessageContext(ctx, raw); err != context.DeadlineExceeded {
+		t.Errorf("SendRawMessageContext = %v, want context.DeadlineExceeded", err)
+	}
+
+	// WriteTimeout bounds writes without a deadline of their own
+	timed := connect(types.NewOptions().WithWriteTimeout(50 * time.Millisecond))
+	defer timed.Close()
+	if err := timed.SendMessage("hello", "default"); !stderrors.Is(err, errors.ErrCLIConnection) {
+		t.Errorf("SendMessage = %v, want a connection error", err)
+	}
+
+	// Close aborts a blocked write instead of waiting for it
+	blocked := connect(nil)
+	sent := make(chan error, 1)
+	go func() { sent <- blocked.SendMessage("hello", "default") }()
+	time.Sleep(20 * time.Millisecond)
+
+	closed := make(chan struct{})
+	go func() {
+		blocked.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close blocked on a pending write")
+	}
+	if err := <-sent; err == nil {
+		t.Error("blocked SendMessage succeeded")
+	}
+}
+
+func TestRawOutput(t *testing.T) {
+	var raw syncBuffer
+	mock := transporttest.NewMockTransport()
+	client := NewClaudeSDKClientWithTransport(types.NewOptions().WithRawOutput(&raw), mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	mock.Emit(`{"type":"result","subtype":"success","session_id":"s1"}`)
+	if _, err := WaitForResult(ctx, client.Messages()); err != nil {
+		t.Fatalf("WaitForResult: %v", err)
+	}
+
+	// Control responses are copied along with messages
+	output := raw.String()
+	if !strings.Contains(output, `"type":"control_response"`) ||
+		!strings.Contains(output, "{\"type\":\"result\",\"subtype\":\"success\",\"session_id\":\"s1\"}\n") {
+		t.Errorf("raw output lacks the CLI's lines:\n%s", output)
+	}
+}
+
+func TestParseWarnings(t *testing.T) {
+	warnings := make(chan types.ParseWarning, 1)
+	mock := transporttest.NewMockTransport()
+	client := NewClaudeSDKClientWithTransport(types.NewOptions().WithParseWarnings(warnings), mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	// A message without its model is still delivered
+	mock.Emit(map[string]interface{}{"type": "assistant", "content": []interface{}{map[string]interface{}{"type": "text", "text": "hi"}}})
+	select {
+	case msg := <-client.Messages():
+		if assistant, ok := msg.(*types.AssistantMessage); !ok || assistant.Text() != "hi" {
+			t.Errorf("first message = %+v", msg)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for the message")
+	}
+
+	select {
+	case warning := <-warnings:
+		if warning.MessageType != "assistant" || len(warning.Warnings) != 1 {
+			t.Errorf("unexpected warning %+v", warning)
+		}
+	default:
+		t.Error("no parse warning")
+	}
+
+	// Strict parsing rejects it
+	strict := newMessageParser(types.NewOptions().WithStrictParsing())
+	if _, err := strict.parse(nopLogger, map[string]interface{}{"type": "assistant", "content": []interface{}{}}); !stderrors.Is(err, errors.ErrMessageParse) {
+		t.Errorf("strict parse error = %v, want ErrMessageParse", err)
+	}
+}
@@ -0,0 +1,101 @@
+package claudecode
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// Compact asks Claude Code to summarize the conversation so far, freeing up
+// context. Optional instructions guide what the summary should focus on.
+// A CompactBoundaryMessage is delivered on Messages() once compaction is done.
+// It compacts the session of the last message sent, "default" before any.
+func (c *ClaudeSDKClient) Compact(ctx context.Context, instructions string) error {
+	return c.compact(ctx, instructions, c.lastSession())
+}
+
+// Compact compacts the conversation of this session
+func (s *Session) Compact(ctx context.Context, instructions string) error {
+	return s.client.compact(ctx, instructions, s.id)
+}
+
+func (c *ClaudeSDKClient) compact(ctx context.Context, instructions string, sessionID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	command := "/compact"
+	if instructions = strings.TrimSpace(instructions); instructions != "" {
+		command += " " + instructions
+	}
+
+	// Recorded before sending, as the result may arrive before sendText returns
+	turn := c.turnsSent.Load() + 1
+	c.compactTurn.Store(turn)
+	if err := c.sendText(ctx, command, sessionID); err != nil {
+		c.compactTurn.CompareAndSwap(turn, 0)
+		return err
+	}
+	return nil
+}
+
+// Compacting reports whether a compaction was requested, by Compact or the
+// AutoCompactPolicy, and is still running: neither its
+// CompactBoundaryMessage nor the result of its turn has arrived yet
+func (c *ClaudeSDKClient) Compacting() bool {
+	return c.compactTurn.Load() != 0
+}
+
+// checkAutoCompact tracks compaction and triggers it when a result reports
+// that the context has crossed the configured AutoCompactPolicy threshold
+func (c *ClaudeSDKClient) checkAutoCompact(msg types.Message) {
+	switch msg.(type) {
+	case *types.CompactBoundaryMessage, *types.ErrorMessage:
+		c.compactTurn.Store(0)
+		return
+	case *types.ResultMessage:
+		// Results arrive in the order turns were sent; a failed /compact
+		// ends with a result but no boundary
+		done := c.turnsDone.Add(1)
+		if turn := c.compactTurn.Load(); turn > 0 && done >= turn {
+			c.compactTurn.CompareAndSwap(turn, 0)
+		}
+	}
+
+	policy := c.options.AutoCompact
+	if policy == nil || policy.ContextTokenThreshold <= 0 {
+		return
+	}
+
+	result, ok := msg.(*types.ResultMessage)
+	if !ok || result.Usage.ContextTokens() < policy.ContextTokenThreshold {
+		return
+	}
+	// Claim the compaction until Compact records its turn
+	if !c.compactTurn.CompareAndSwap(0, -1) {
+		return
+	}
+
+	go func() {
+		if err := c.Compact(c.ctx, policy.Instructions); err != nil {
+			c.compactTurn.CompareAndSwap(-1, 0)
+			c.sendError(err)
+		}
+	}()
+}
+
+// ParsePreCompactHookInput decodes the input passed to a PreCompact hook
+func ParsePreCompactHookInput(input map[string]interface{}) (*types.PreCompactHookInput, error) {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed types.PreCompactHookInput
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
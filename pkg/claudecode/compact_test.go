@@ -0,0 +1,121 @@
+package claudecode
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/transporttest"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+func TestCompact(t *testing.T) {
+	mock := transporttest.NewMockTransport()
+	client := NewClaudeSDKClientWithTransport(nil, mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.Compact(ctx, "  keep the test failures  "); err != nil {
+		t.Fatalf("Compact: %v", err)
+	}
+	if !client.Compacting() {
+		t.Error("Compacting should report a requested compaction")
+	}
+
+	var prompt interface{}
+	for _, msg := range mock.WrittenMessages() {
+		if msg["type"] == "user" {
+			prompt = msg["message"].(map[string]interface{})["content"]
+		}
+	}
+	if prompt != "/compact keep the test failures" {
+		t.Errorf("sent %q", prompt)
+	}
+
+	mock.Emit(map[string]interface{}{
+		"type":    "system",
+		"subtype": "compact_boundary",
+		"compact_metadata": map[string]interface{}{
+			"trigger":    "manual",
+			"pre_tokens": float64(90000),
+		},
+	})
+
+	select {
+	case msg := <-client.Messages():
+		boundary, ok := msg.(*types.CompactBoundaryMessage)
+		if !ok {
+			t.Fatalf("expected *types.CompactBoundaryMessage, got %T", msg)
+		}
+		if boundary.Trigger != types.CompactTriggerManual || boundary.PreTokens != 90000 {
+			t.Errorf("unexpected boundary: %+v", boundary)
+		}
+	case <-ctx.Done():
+		t.Fatal("no compact boundary delivered")
+	}
+	if client.Compacting() {
+		t.Error("Compacting should be cleared by the compact boundary")
+	}
+
+	cancelled, cancelNow := context.WithCancel(ctx)
+	cancelNow()
+	if err := client.Compact(cancelled, ""); err != context.Canceled {
+		t.Errorf("Compact with cancelled context = %v", err)
+	}
+}
+
+func TestCompactSessionAndResult(t *testing.T) {
+	mock := transporttest.NewMockTransport()
+	client := NewClaudeSDKClientWithTransport(nil, mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	// A turn in flight, then the compaction of its session
+	if err := client.SendMessageContext(ctx, "hello", "work"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Compact(ctx, ""); err != nil {
+		t.Fatalf("Compact: %v", err)
+	}
+
+	written := mock.WrittenMessages()
+	if last := written[len(written)-1]; last["session_id"] != "work" {
+		t.Errorf("compacted session %v, want work", last["session_id"])
+	}
+
+	// The earlier turn's result leaves it running, its own result ends it
+	// even though no boundary arrived
+	result := map[string]interface{}{"type": "result", "subtype": "success", "session_id": "s1"}
+	mock.Emit(result)
+	<-client.Messages()
+	if !client.Compacting() {
+		t.Error("Compacting cleared by the result of an earlier turn")
+	}
+	mock.Emit(result)
+	<-client.Messages()
+	if client.Compacting() {
+		t.Error("Compacting should be cleared by the result of the /compact turn")
+	}
+
+	// A system error ends it too
+	if err := client.Compact(ctx, ""); err != nil {
+		t.Fatalf("Compact: %v", err)
+	}
+	mock.Emit(map[string]interface{}{"type": "system", "subtype": types.SystemSubtypeError, "error": "overloaded"})
+	<-client.Messages()
+	if client.Compacting() {
+		t.Error("Compacting should be cleared by a system error")
+	}
+}
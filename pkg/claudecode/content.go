@@ -0,0 +1,94 @@
+package claudecode
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// SendContent sends a user message made of structured content blocks, such
+// as text alongside images, or tool results injected by the caller.
+//
+// Example:
+//
+//	err := client.SendContent(ctx, "default", []claudecode.ContentBlock{
+//	    &claudecode.TextBlock{Text: "Summarise the attached log"},
+//	    &claudecode.ToolResultBlock{ToolUseID: "toolu_1", Content: "exit status 0"},
+//	})
+func (c *ClaudeSDKClient) SendContent(ctx context.Context, sessionID string, blocks []types.ContentBlock) error {
+	if len(blocks) == 0 {
+		return errors.NewValidationError("blocks", "at least one content block is required")
+	}
+
+	content := make([]interface{}, 0, len(blocks))
+	for i, block := range blocks {
+		payload, err := contentBlockPayload(block)
+		if err != nil {
+			return errors.NewValidationError(fmt.Sprintf("blocks[%d]", i), err.Error())
+		}
+		content = append(content, payload)
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if !c.connected {
+		return errors.NewCLIConnectionError("not connected. Call Connect() first", nil)
+	}
+
+	message := map[string]interface{}{
+		"type": "user",
+		"message": map[string]interface{}{
+			"role":    "user",
+			"content": content,
+		},
+		"parent_tool_use_id": nil,
+		"session_id":         sessionID,
+	}
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	return c.writeUserMessage(ctx, sessionID, append(data, '\n'))
+}
+
+// contentBlockPayload converts a content block to its stream-json form
+func contentBlockPayload(block types.ContentBlock) (map[string]interface{}, error) {
+	switch b := block.(type) {
+	case *types.TextBlock:
+		return map[string]interface{}{"type": "text", "text": b.Text}, nil
+	case types.TextBlock:
+		return contentBlockPayload(&b)
+	case *types.ToolResultBlock:
+		payload := map[string]interface{}{"type": "tool_result", "tool_use_id": b.ToolUseID}
+		if b.Content != nil {
+			payload["content"] = b.Content
+		}
+		if b.IsError != nil {
+			payload["is_error"] = *b.IsError
+		}
+		return payload, nil
+	case types.ToolResultBlock:
+		return contentBlockPayload(&b)
+	case *types.ImageBlock:
+		return map[string]interface{}{
+			"type": "image",
+			"source": map[string]interface{}{
+				"type":       b.Source.Type,
+				"media_type": b.Source.MediaType,
+				"data":       b.Source.Data,
+			},
+		}, nil
+	case types.ImageBlock:
+		return contentBlockPayload(&b)
+	case nil:
+		return nil, fmt.Errorf("content block is nil")
+	default:
+		return nil, fmt.Errorf("content block type %T cannot be sent", block)
+	}
+}
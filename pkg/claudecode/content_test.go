@@ -0,0 +1,93 @@
+package claudecode
+
+import (
+	"context"
+	stderrors "errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/transporttest"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+func TestSendContent(t *testing.T) {
+	mock := transporttest.NewMockTransport()
+	client := NewClaudeSDKClientWithTransport(nil, mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	isError := true
+	err := client.SendContent(ctx, "default", []types.ContentBlock{
+		&types.TextBlock{Text: "see result"},
+		types.ToolResultBlock{ToolUseID: "toolu_1", Content: "boom", IsError: &isError},
+	})
+	if err != nil {
+		t.Fatalf("SendContent: %v", err)
+	}
+
+	var content []interface{}
+	for _, msg := range mock.WrittenMessages() {
+		if msg["type"] == "user" {
+			content = msg["message"].(map[string]interface{})["content"].([]interface{})
+		}
+	}
+	if len(content) != 2 {
+		t.Fatalf("unexpected content: %v", content)
+	}
+	text := content[0].(map[string]interface{})
+	result := content[1].(map[string]interface{})
+	if text["type"] != "text" || text["text"] != "see result" {
+		t.Errorf("unexpected text block: %v", text)
+	}
+	if result["type"] != "tool_result" || result["tool_use_id"] != "toolu_1" || result["content"] != "boom" || result["is_error"] != true {
+		t.Errorf("unexpected tool result block: %v", result)
+	}
+
+	err = client.SendContent(ctx, "default", []types.ContentBlock{&types.ToolUseBlock{ID: "toolu_2"}})
+	if !stderrors.Is(err, errors.ErrInvalidOptions) {
+		t.Errorf("expected validation error for tool_use block, got %v", err)
+	}
+}
+
+func TestImageFromFile(t *testing.T) {
+	// Smallest valid PNG header is enough for content sniffing
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	path := filepath.Join(t.TempDir(), "screenshot")
+	if err := os.WriteFile(path, png, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := ImageFromFile(path)
+	if err != nil {
+		t.Fatalf("ImageFromFile: %v", err)
+	}
+	if img.Source.Type != "base64" || img.Source.MediaType != "image/png" {
+		t.Errorf("unexpected source: %+v", img.Source)
+	}
+	if data, _ := img.Bytes(); string(data) != string(png) {
+		t.Errorf("image data did not round trip")
+	}
+
+	payload, err := contentBlockPayload(img)
+	if err != nil {
+		t.Fatalf("contentBlockPayload: %v", err)
+	}
+	if payload["type"] != "image" || payload["source"].(map[string]interface{})["media_type"] != "image/png" {
+		t.Errorf("unexpected payload: %v", payload)
+	}
+
+	text := filepath.Join(t.TempDir(), "notes.txt")
+	os.WriteFile(text, []byte("hello"), 0o600)
+	if _, err := ImageFromFile(text); !stderrors.Is(err, errors.ErrInvalidOptions) {
+		t.Errorf("expected validation error for text file, got %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package claudecode
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"strings"
+	"sync/atomic"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// deadLetterSink routes unparseable frames to ClaudeCodeOptions.DeadLetters
+type deadLetterSink struct {
+	ch      chan<- types.DeadLetter
+	count   atomic.Uint64
+	dropped atomic.Uint64
+}
+
+// newDeadLetterSink returns nil when no dead-letter channel is configured
+func newDeadLetterSink(options *types.ClaudeCodeOptions) *deadLetterSink {
+	if options == nil || options.DeadLetters == nil {
+		return nil
+	}
+	return &deadLetterSink{ch: options.DeadLetters}
+}
+
+// parseFailed records a frame that decoded as JSON but failed to parse
+func (s *deadLetterSink) parseFailed(data map[string]interface{}, err error) bool {
+	if s == nil {
+		return false
+	}
+
+	raw, _ := json.Marshal(data)
+	s.send(raw, err)
+	return true
+}
+
+// readFailed records a transport error if it is a JSON decode failure
+func (s *deadLetterSink) readFailed(err error) bool {
+	if s == nil {
+		return false
+	}
+
+	var decodeErr *errors.JSONDecodeError
+	if !stderrors.As(err, &decodeErr) {
+		return false
+	}
+
+	s.send(json.RawMessage(strings.TrimSpace(decodeErr.Line)), err)
+	return true
+}
+
+// send delivers a dead letter without blocking the message pipeline
+func (s *deadLetterSink) send(raw json.RawMessage, err error) {
+	letter := types.DeadLetter{
+		Raw:     raw,
+		Err:     err,
+		Seq:     s.count.Add(1),
+		Dropped: s.dropped.Load(),
+	}
+
+	select {
+	case s.ch <- letter:
+	default:
+		s.dropped.Add(1)
+	}
+}
+
+// total returns the number of dead letters seen
+func (s *deadLetterSink) total() uint64 {
+	if s == nil {
+		return 0
+	}
+	return s.count.Load()
+}
@@ -0,0 +1,94 @@
+package claudecode
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/transporttest"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+func TestDeadLetters(t *testing.T) {
+	mock := transporttest.NewMockTransport()
+	letters := make(chan types.DeadLetter, 1)
+	options := types.NewOptions()
+	options.DeadLetters = letters
+	client := NewClaudeSDKClientWithTransport(options, mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	// A frame that is not JSON and one without content, then one that
+	// finds the channel full
+	mock.EmitMalformed()
+	mock.Emit(map[string]interface{}{"type": "assistant"})
+	mock.Emit(map[string]interface{}{"type": "assistant"})
+	mock.Emit(textMessage("after"))
+
+	// Dead letters do not reach Messages or Errors
+	msg := (<-client.Messages()).(*types.AssistantMessage)
+	if got := msg.Content[0].(*types.TextBlock).Text; got != "after" {
+		t.Fatalf("got message %q, want after", got)
+	}
+	select {
+	case err := <-client.Errors():
+		t.Fatalf("unexpected error: %v", err)
+	default:
+	}
+
+	letter := <-letters
+	var decodeErr *errors.JSONDecodeError
+	if !stderrors.As(letter.Err, &decodeErr) || string(letter.Raw) != "{not valid json" || letter.Seq != 1 {
+		t.Errorf("first dead letter = %+v", letter)
+	}
+	if count := client.DeadLetterCount(); count != 3 {
+		t.Errorf("DeadLetterCount = %d, want 3", count)
+	}
+
+	// The unparseable frames found the channel full
+	mock.Emit(map[string]interface{}{"type": "assistant"})
+	letter = <-letters
+	var parseErr *errors.MessageParseError
+	if !stderrors.As(letter.Err, &parseErr) || letter.Seq != 4 || letter.Dropped != 2 {
+		t.Errorf("last dead letter = %+v", letter)
+	}
+	if string(letter.Raw) != `{"type":"assistant"}` {
+		t.Errorf("Raw = %s", letter.Raw)
+	}
+}
+
+func TestDeadLettersDisabled(t *testing.T) {
+	mock := transporttest.NewMockTransport()
+	client := NewClaudeSDKClientWithTransport(nil, mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	// Without a dead-letter channel parse failures are reported as errors
+	mock.Emit(map[string]interface{}{"type": "assistant"})
+	select {
+	case err := <-client.Errors():
+		var parseErr *errors.MessageParseError
+		if !stderrors.As(err, &parseErr) {
+			t.Errorf("got error %v, want a MessageParseError", err)
+		}
+	case <-ctx.Done():
+		t.Fatal("no error for the unparseable frame")
+	}
+	if count := client.DeadLetterCount(); count != 0 {
+		t.Errorf("DeadLetterCount = %d, want 0", count)
+	}
+}
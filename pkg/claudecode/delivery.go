@@ -0,0 +1,82 @@
+package claudecode
+
+import (
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// defaultMessageBufferSize is the Messages() channel capacity when
+// ClaudeCodeOptions.MessageBufferSize is unset
+const defaultMessageBufferSize = 100
+
+// Frames RawMessages holds before new ones are dropped
+const rawMessageBufferSize = 100
+
+func messageBufferSize(options *types.ClaudeCodeOptions) int {
+	if options == nil || options.MessageBufferSize <= 0 {
+		return defaultMessageBufferSize
+	}
+	return options.MessageBufferSize
+}
+
+// deliver sends msg on ch following the overflow policy. closed, if not
+// nil, abandons a blocked send when it is closed. Returns false if the
+// client is shutting down.
+func (c *ClaudeSDKClient) deliver(ch chan types.Message, msg types.Message, closed <-chan struct{}) bool {
+	switch c.options.OverflowPolicy {
+	case types.OverflowDropOldest:
+		for {
+			select {
+			case ch <- msg:
+				return true
+			default:
+			}
+
+			// Make room; the consumer may have emptied the channel meanwhile
+			select {
+			case dropped := <-ch:
+				c.messageDropped()
+				c.logger().Debug("message queue full, dropped oldest message", "type", dropped.GetType())
+			default:
+			}
+		}
+	case types.OverflowError:
+		select {
+		case ch <- msg:
+			return true
+		default:
+		}
+
+		err := errors.NewQueueOverflowError(cap(ch), c.messageDropped())
+		c.logger().Warn("message queue full, dropped message", "type", msg.GetType(), "error", err)
+		return c.handleError(err)
+	default:
+		select {
+		case ch <- msg:
+			return true
+		case <-closed:
+			return true
+		case <-c.ctx.Done():
+			return false
+		}
+	}
+}
+
+// messageDropped counts a discarded message and returns the new total
+func (c *ClaudeSDKClient) messageDropped() uint64 {
+	c.telemetry.messageDropped()
+	return c.dropped.Add(1)
+}
+
+// DroppedMessages returns the number of messages discarded because the
+// consumer fell behind (OverflowDropOldest and OverflowError policies)
+func (c *ClaudeSDKClient) DroppedMessages() uint64 {
+	return c.dropped.Load()
+}
+
+// QueueDepth returns the number of messages waiting on Messages(). A depth
+// close to ClaudeCodeOptions.MessageBufferSize means the consumer is not
+// keeping up.
+func (c *ClaudeSDKClient) QueueDepth() int {
+	return len(c.messages)
+}
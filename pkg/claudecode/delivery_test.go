@@ -0,0 +1,110 @@
+package claudecode
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/transporttest"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+func textMessage(text string) map[string]interface{} {
+	return map[string]interface{}{"type": "assistant", "model": "sonnet", "content": []interface{}{
+		map[string]interface{}{"type": "text", "text": text},
+	}}
+}
+
+func TestOverflowDropOldest(t *testing.T) {
+	mock := transporttest.NewMockTransport()
+	client := NewClaudeSDKClientWithTransport(types.NewOptions().WithMessageBuffer(2, types.OverflowDropOldest), mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	for _, text := range []string{"1", "2", "3", "4"} {
+		mock.Emit(textMessage(text))
+	}
+	for client.DroppedMessages() < 2 {
+		select {
+		case <-ctx.Done():
+			t.Fatalf("dropped %d messages, want 2", client.DroppedMessages())
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+
+	if depth := client.QueueDepth(); depth != 2 {
+		t.Errorf("QueueDepth = %d, want 2", depth)
+	}
+	for _, want := range []string{"3", "4"} {
+		msg := (<-client.Messages()).(*types.AssistantMessage)
+		if got := msg.Content[0].(*types.TextBlock).Text; got != want {
+			t.Errorf("got message %q, want %q", got, want)
+		}
+	}
+}
+
+func TestOverflowError(t *testing.T) {
+	mock := transporttest.NewMockTransport()
+	client := NewClaudeSDKClientWithTransport(types.NewOptions().WithMessageBuffer(1, types.OverflowError), mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	mock.Emit(textMessage("kept"))
+	mock.Emit(textMessage("dropped"))
+
+	select {
+	case err := <-client.Errors():
+		var overflow *errors.QueueOverflowError
+		if !stderrors.As(err, &overflow) || overflow.Capacity != 1 || overflow.Dropped != 1 {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-ctx.Done():
+		t.Fatal("no overflow error reported")
+	}
+
+	msg := (<-client.Messages()).(*types.AssistantMessage)
+	if got := msg.Content[0].(*types.TextBlock).Text; got != "kept" {
+		t.Errorf("got message %q, want kept", got)
+	}
+}
+
+func TestFailedResultError(t *testing.T) {
+	mock := transporttest.NewMockTransport()
+	client := NewClaudeSDKClientWithTransport(nil, mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	mock.Emit(map[string]interface{}{"type": "result", "subtype": "error_max_turns", "is_error": true, "session_id": "s1", "num_turns": 2})
+
+	if _, ok := (<-client.Messages()).(*types.ResultMessage); !ok {
+		t.Fatal("expected the result to be delivered")
+	}
+	select {
+	case err := <-client.Errors():
+		if !stderrors.Is(err, errors.ErrMaxTurnsExceeded) {
+			t.Errorf("got error %v, want ErrMaxTurnsExceeded", err)
+		}
+	case <-ctx.Done():
+		t.Fatal("no error for the failed result")
+	}
+}
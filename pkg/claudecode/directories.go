@@ -0,0 +1,46 @@
+package claudecode
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// AddDirectory grants the session access to another directory, as AddDirs
+// does at startup. Relative paths are resolved against CWD.
+func (c *ClaudeSDKClient) AddDirectory(ctx context.Context, path string) error {
+	return c.updateDirectories(ctx, types.PermissionUpdateAddDirectories, path)
+}
+
+// RemoveDirectory revokes access to a directory added with AddDirs or
+// AddDirectory
+func (c *ClaudeSDKClient) RemoveDirectory(ctx context.Context, path string) error {
+	return c.updateDirectories(ctx, types.PermissionUpdateRemoveDirectories, path)
+}
+
+func (c *ClaudeSDKClient) updateDirectories(ctx context.Context, typ types.PermissionUpdateType, path string) error {
+	dir, err := c.resolveDirectory(path)
+	if err != nil {
+		return err
+	}
+
+	destination := types.PermissionDestinationSession
+	return c.UpdatePermissions(ctx, types.PermissionUpdate{
+		Type:        typ,
+		Directories: []string{dir},
+		Destination: &destination,
+	})
+}
+
+// resolveDirectory makes path absolute relative to the session's CWD, so the
+// CLI does not resolve it against its own working directory
+func (c *ClaudeSDKClient) resolveDirectory(path string) (string, error) {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path), nil
+	}
+	if c.options != nil && c.options.CWD != nil {
+		return filepath.Join(*c.options.CWD, path), nil
+	}
+	return filepath.Abs(path)
+}
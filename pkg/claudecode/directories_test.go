@@ -0,0 +1,61 @@
+package claudecode
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/transporttest"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+func TestAddRemoveDirectory(t *testing.T) {
+	workspace := t.TempDir()
+	mock := transporttest.NewMockTransport()
+	client := NewClaudeSDKClientWithTransport(types.NewOptions().WithCWD(workspace), mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.AddDirectory(ctx, "data"); err == nil {
+		t.Fatal("expected an error before Connect")
+	}
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.AddDirectory(ctx, "data"); err != nil {
+		t.Fatalf("AddDirectory: %v", err)
+	}
+	if err := client.RemoveDirectory(ctx, "/tmp/scratch/"); err != nil {
+		t.Fatalf("RemoveDirectory: %v", err)
+	}
+
+	var updates []map[string]interface{}
+	for _, msg := range mock.WrittenMessages() {
+		request, ok := msg["request"].(map[string]interface{})
+		if !ok || request["subtype"] != "update_permissions" {
+			continue
+		}
+		for _, update := range request["updates"].([]interface{}) {
+			updates = append(updates, update.(map[string]interface{}))
+		}
+	}
+	if len(updates) != 2 {
+		t.Fatalf("got %d permission updates, want 2", len(updates))
+	}
+
+	want := []struct{ typ, dir string }{
+		{"addDirectories", filepath.Join(workspace, "data")},
+		{"removeDirectories", "/tmp/scratch"},
+	}
+	for i, w := range want {
+		dirs := updates[i]["directories"].([]interface{})
+		if updates[i]["type"] != w.typ || len(dirs) != 1 || dirs[0] != w.dir || updates[i]["destination"] != "session" {
+			t.Errorf("update %d = %v, want %s of %s", i, updates[i], w.typ, w.dir)
+		}
+	}
+}
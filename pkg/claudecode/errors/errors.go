@@ -3,6 +3,8 @@ package errors
 import (
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 )
 
 // Base error types
@@ -21,6 +23,41 @@ var (
 	
 	// ErrMessageParse is returned when message parsing fails
 	ErrMessageParse = errors.New("message parse error")
+	
+	// ErrControlRequest is returned when the CLI rejects a control request
+	ErrControlRequest = errors.New("control request error")
+	
+	// ErrBufferOverflow is returned when a message exceeds the maximum size
+	ErrBufferOverflow = errors.New("buffer overflow")
+
+	// ErrUnsupportedFeature is reported when an option requires a newer CLI
+	ErrUnsupportedFeature = errors.New("unsupported feature")
+
+	// ErrInvalidOptions is returned when options fail validation
+	ErrInvalidOptions = errors.New("invalid options")
+
+	// ErrCallbackTimeout is reported when a callback exceeds its timeout
+	ErrCallbackTimeout = errors.New("callback timeout")
+
+	// ErrBudgetExceeded is returned once a cost or token budget is spent
+	ErrBudgetExceeded = errors.New("budget exceeded")
+
+	// ErrQueueOverflow is reported when a message is dropped because the
+	// consumer is not keeping up
+	ErrQueueOverflow = errors.New("message queue overflow")
+
+	// ErrDryRun is returned by Connect when ClaudeCodeOptions.DryRun is set
+	ErrDryRun = errors.New("dry run")
+
+	// ErrMaxTurnsExceeded is returned when a conversation stops at MaxTurns
+	ErrMaxTurnsExceeded = errors.New("max turns exceeded")
+
+	// ErrExecution is returned when the CLI ends a turn with an error result
+	ErrExecution = errors.New("execution error")
+
+	// ErrStalled is reported when the CLI stays silent during a turn for
+	// longer than StallTimeout
+	ErrStalled = errors.New("CLI stalled")
 )
 
 // CLINotFoundError indicates the Claude CLI binary was not found
@@ -61,14 +98,32 @@ func (e *CLIConnectionError) Unwrap() error {
 type ProcessError struct {
 	Message  string
 	ExitCode int
-	Stderr   string
+	Stderr   string // Last StderrBufferSize bytes of stderr
+
+	// Command line and working directory of the process, and how long it ran
+	Args    []string
+	Dir     string
+	Elapsed time.Duration
 }
 
 func (e *ProcessError) Error() string {
-	if e.Stderr != "" {
-		return fmt.Sprintf("%s (exit code: %d): %s", e.Message, e.ExitCode, e.Stderr)
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (exit code: %d", e.Message, e.ExitCode)
+	if e.Elapsed > 0 {
+		fmt.Fprintf(&b, ", after %s", e.Elapsed.Round(time.Millisecond))
+	}
+	b.WriteString(")")
+	if stderr := strings.TrimSpace(e.Stderr); stderr != "" {
+		fmt.Fprintf(&b, ": %s", stderr)
+	}
+	if len(e.Args) > 0 {
+		fmt.Fprintf(&b, " [command: %s", strings.Join(e.Args, " "))
+		if e.Dir != "" {
+			fmt.Fprintf(&b, "; dir: %s", e.Dir)
+		}
+		b.WriteString("]")
 	}
-	return fmt.Sprintf("%s (exit code: %d)", e.Message, e.ExitCode)
+	return b.String()
 }
 
 func (e *ProcessError) Is(target error) bool {
@@ -111,6 +166,182 @@ func (e *MessageParseError) Is(target error) bool {
 	return target == ErrMessageParse
 }
 
+// ControlRequestError indicates the CLI rejected a control request
+type ControlRequestError struct {
+	RequestID string
+	Subtype   string
+	Message   string
+}
+
+func (e *ControlRequestError) Error() string {
+	return fmt.Sprintf("control request %s (%s) failed: %s", e.RequestID, e.Subtype, e.Message)
+}
+
+func (e *ControlRequestError) Is(target error) bool {
+	return target == ErrControlRequest
+}
+
+// BufferOverflowError indicates a single message exceeded the maximum size
+type BufferOverflowError struct {
+	Limit int
+	Size  int
+}
+
+func (e *BufferOverflowError) Error() string {
+	return fmt.Sprintf("message of %d bytes exceeds maximum size of %d bytes", e.Size, e.Limit)
+}
+
+func (e *BufferOverflowError) Is(target error) bool {
+	return target == ErrBufferOverflow
+}
+
+// UnsupportedFeatureError indicates an option requires a newer CLI than the
+// one installed. The subprocess transport's Connect returns it, except for
+// options such as DebugStderr whose flag it can leave out, which it logs.
+type UnsupportedFeatureError struct {
+	Feature          string
+	RequiredVersion  string
+	InstalledVersion string
+}
+
+func (e *UnsupportedFeatureError) Error() string {
+	return fmt.Sprintf("%s requires Claude Code CLI %s or newer (installed: %s)", e.Feature, e.RequiredVersion, e.InstalledVersion)
+}
+
+func (e *UnsupportedFeatureError) Is(target error) bool {
+	return target == ErrUnsupportedFeature
+}
+
+// ValidationError indicates an invalid option or combination of options
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid option %s: %s", e.Field, e.Message)
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalidOptions
+}
+
+// CallbackTimeoutError indicates a CanUseTool or hook callback did not
+// return within Options.CallbackTimeout
+type CallbackTimeoutError struct {
+	Subtype string // "can_use_tool" or "hook_callback"
+	Name    string // Tool name or hook callback ID
+	Timeout time.Duration
+}
+
+func (e *CallbackTimeoutError) Error() string {
+	return fmt.Sprintf("%s callback for %s timed out after %s", e.Subtype, e.Name, e.Timeout)
+}
+
+func (e *CallbackTimeoutError) Is(target error) bool {
+	return target == ErrCallbackTimeout
+}
+
+// BudgetExceededError indicates the MaxCostUSD or MaxTokens budget is spent
+type BudgetExceededError struct {
+	Limit string // "cost" or "tokens"
+	Max   float64
+	Used  float64
+}
+
+func (e *BudgetExceededError) Error() string {
+	if e.Limit == "cost" {
+		return fmt.Sprintf("cost budget exceeded: $%.4f of $%.4f used", e.Used, e.Max)
+	}
+	return fmt.Sprintf("token budget exceeded: %.0f of %.0f tokens used", e.Used, e.Max)
+}
+
+func (e *BudgetExceededError) Is(target error) bool {
+	return target == ErrBudgetExceeded
+}
+
+// QueueOverflowError indicates a message was dropped because the message
+// channel was full and OverflowPolicy is OverflowError
+type QueueOverflowError struct {
+	Capacity int
+	Dropped  uint64 // Messages dropped so far, including this one
+}
+
+func (e *QueueOverflowError) Error() string {
+	return fmt.Sprintf("message queue full (capacity %d): %d messages dropped", e.Capacity, e.Dropped)
+}
+
+func (e *QueueOverflowError) Is(target error) bool {
+	return target == ErrQueueOverflow
+}
+
+// DryRunError carries the command a dry run would have started
+type DryRunError struct {
+	Args []string // Program and arguments
+	Env  []string // Full environment, as KEY=value
+	Dir  string
+}
+
+func (e *DryRunError) Error() string {
+	return fmt.Sprintf("dry run: %s", strings.Join(e.Args, " "))
+}
+
+func (e *DryRunError) Is(target error) bool {
+	return target == ErrDryRun
+}
+
+// MaxTurnsExceededError indicates the CLI stopped a conversation because it
+// reached ClaudeCodeOptions.MaxTurns
+type MaxTurnsExceededError struct {
+	SessionID string
+	NumTurns  int
+}
+
+func (e *MaxTurnsExceededError) Error() string {
+	return fmt.Sprintf("max turns exceeded after %d turns", e.NumTurns)
+}
+
+func (e *MaxTurnsExceededError) Is(target error) bool {
+	return target == ErrMaxTurnsExceeded
+}
+
+// ExecutionError indicates the CLI ended a turn with an error result, e.g.
+// subtype "error_during_execution"
+type ExecutionError struct {
+	SessionID string
+	Subtype   string
+	Message   string // The result text, if any
+}
+
+func (e *ExecutionError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("execution error: %s", e.Subtype)
+	}
+	return fmt.Sprintf("execution error (%s): %s", e.Subtype, e.Message)
+}
+
+func (e *ExecutionError) Is(target error) bool {
+	return target == ErrExecution
+}
+
+// StalledError indicates the CLI produced no output for Idle while a turn was
+// in progress, e.g. because it hung on a tool or a network call
+type StalledError struct {
+	Idle        time.Duration
+	Interrupted bool // An interrupt was sent to end the turn
+}
+
+func (e *StalledError) Error() string {
+	if e.Interrupted {
+		return fmt.Sprintf("CLI stalled: no output for %s, turn interrupted", e.Idle)
+	}
+	return fmt.Sprintf("CLI stalled: no output for %s", e.Idle)
+}
+
+func (e *StalledError) Is(target error) bool {
+	return target == ErrStalled
+}
+
 // Helper functions
 func NewCLINotFoundError(message string) error {
 	return &CLINotFoundError{Message: message}
@@ -130,4 +361,48 @@ func NewJSONDecodeError(message string, line string, cause error) error {
 
 func NewMessageParseError(message string, data interface{}) error {
 	return &MessageParseError{Message: message, Data: data}
-}
\ No newline at end of file
+}
+
+func NewControlRequestError(requestID string, subtype string, message string) error {
+	return &ControlRequestError{RequestID: requestID, Subtype: subtype, Message: message}
+}
+
+func NewBufferOverflowError(limit int, size int) error {
+	return &BufferOverflowError{Limit: limit, Size: size}
+}
+
+func NewUnsupportedFeatureError(feature string, requiredVersion string, installedVersion string) error {
+	return &UnsupportedFeatureError{Feature: feature, RequiredVersion: requiredVersion, InstalledVersion: installedVersion}
+}
+
+func NewValidationError(field string, message string) error {
+	return &ValidationError{Field: field, Message: message}
+}
+
+func NewCallbackTimeoutError(subtype string, name string, timeout time.Duration) error {
+	return &CallbackTimeoutError{Subtype: subtype, Name: name, Timeout: timeout}
+}
+
+func NewBudgetExceededError(limit string, max float64, used float64) error {
+	return &BudgetExceededError{Limit: limit, Max: max, Used: used}
+}
+
+func NewQueueOverflowError(capacity int, dropped uint64) error {
+	return &QueueOverflowError{Capacity: capacity, Dropped: dropped}
+}
+
+func NewDryRunError(args, env []string, dir string) error {
+	return &DryRunError{Args: args, Env: env, Dir: dir}
+}
+
+func NewMaxTurnsExceededError(sessionID string, numTurns int) error {
+	return &MaxTurnsExceededError{SessionID: sessionID, NumTurns: numTurns}
+}
+
+func NewExecutionError(sessionID string, subtype string, message string) error {
+	return &ExecutionError{SessionID: sessionID, Subtype: subtype, Message: message}
+}
+
+func NewStalledError(idle time.Duration, interrupted bool) error {
+	return &StalledError{Idle: idle, Interrupted: interrupted}
+}
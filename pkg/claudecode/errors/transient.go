@@ -0,0 +1,51 @@
+package errors
+
+import (
+	"errors"
+	"strings"
+)
+
+// transientMarkers are substrings of CLI output that indicate a failure
+// likely to go away on its own
+var transientMarkers = []string{
+	"rate limit",
+	"rate_limit",
+	"429",
+	"overloaded",
+	"529",
+	"econnreset",
+	"econnrefused",
+	"etimedout",
+	"enotfound",
+	"eai_again",
+	"socket hang up",
+	"network error",
+	"connection error",
+	"fetch failed",
+}
+
+// IsTransient reports whether err looks temporary: the API being rate
+// limited or overloaded, or a network failure, as reported by a failed result
+// (ExecutionError) or in the stderr of a CLI that exited (ProcessError).
+func IsTransient(err error) bool {
+	var text string
+
+	var execErr *ExecutionError
+	var procErr *ProcessError
+	switch {
+	case errors.As(err, &execErr):
+		text = execErr.Message
+	case errors.As(err, &procErr):
+		text = procErr.Message + "\n" + procErr.Stderr
+	default:
+		return false
+	}
+
+	text = strings.ToLower(text)
+	for _, marker := range transientMarkers {
+		if strings.Contains(text, marker) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package claudecode
+
+import (
+	"context"
+	"strings"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// CollectText reads msgs until a ResultMessage arrives or the channel is
+// closed, and returns the text of all assistant messages joined by newlines.
+//
+// Example:
+//
+//	messages, err := claudecode.Query(ctx, "Explain main.go", nil)
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Println(claudecode.CollectText(messages))
+func CollectText(msgs <-chan types.Message) string {
+	var parts []string
+	for msg := range msgs {
+		switch m := msg.(type) {
+		case *types.AssistantMessage:
+			for _, block := range m.Content {
+				if text, ok := block.(*types.TextBlock); ok {
+					parts = append(parts, text.Text)
+				}
+			}
+		case *types.ResultMessage:
+			return strings.Join(parts, "\n")
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// WaitForResult discards messages until a ResultMessage arrives. It fails if
+// ctx is done or msgs is closed first.
+func WaitForResult(ctx context.Context, msgs <-chan types.Message) (*types.ResultMessage, error) {
+	for {
+		select {
+		case msg, ok := <-msgs:
+			if !ok {
+				return nil, errors.NewCLIConnectionError("message channel closed before a result", nil)
+			}
+			if result, ok := msg.(*types.ResultMessage); ok {
+				return result, nil
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// FilterType returns a channel of the messages in msgs that have type T. The
+// returned channel is closed when msgs is closed and must be drained until
+// then.
+//
+// Example:
+//
+//	for msg := range claudecode.FilterType[*claudecode.AssistantMessage](client.Messages()) {
+//	    fmt.Println(msg.Model)
+//	}
+func FilterType[T types.Message](msgs <-chan types.Message) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		for msg := range msgs {
+			if typed, ok := msg.(T); ok {
+				out <- typed
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,56 @@
+package claudecode
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+func messageChannel(msgs ...types.Message) <-chan types.Message {
+	ch := make(chan types.Message, len(msgs))
+	for _, msg := range msgs {
+		ch <- msg
+	}
+	close(ch)
+	return ch
+}
+
+func TestFilterHelpers(t *testing.T) {
+	conversation := []types.Message{
+		&types.AssistantMessage{Content: []types.ContentBlock{&types.TextBlock{Text: "one"}}},
+		&types.UserMessage{Content: "ignored"},
+		&types.AssistantMessage{Content: []types.ContentBlock{
+			&types.ToolUseBlock{ID: "toolu_1", Name: "Read"},
+			&types.TextBlock{Text: "two"},
+		}},
+		&types.ResultMessage{Subtype: "success"},
+		&types.AssistantMessage{Content: []types.ContentBlock{&types.TextBlock{Text: "after result"}}},
+	}
+
+	if got := CollectText(messageChannel(conversation...)); got != "one\ntwo" {
+		t.Errorf("CollectText = %q", got)
+	}
+
+	result, err := WaitForResult(context.Background(), messageChannel(conversation...))
+	if err != nil || result.Subtype != "success" {
+		t.Errorf("WaitForResult = %v, %v", result, err)
+	}
+	_, err = WaitForResult(context.Background(), messageChannel(conversation[:2]...))
+	if !stderrors.Is(err, errors.ErrCLIConnection) {
+		t.Errorf("expected connection error without a result, got %v", err)
+	}
+
+	var count int
+	for msg := range FilterType[*types.AssistantMessage](messageChannel(conversation...)) {
+		if msg.GetType() != types.MessageTypeAssistant {
+			t.Errorf("unexpected message %T", msg)
+		}
+		count++
+	}
+	if count != 3 {
+		t.Errorf("FilterType yielded %d assistant messages, want 3", count)
+	}
+}
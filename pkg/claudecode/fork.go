@@ -0,0 +1,87 @@
+package claudecode
+
+import (
+	"context"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/internal/ids"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// forkPrompt is the turn that makes the CLI write out a fork
+const forkPrompt = "The conversation was forked here. Reply with OK only."
+
+// Fork copies the conversation so far into a new session and returns its
+// ID, leaving this client's session as it is. A new CLI resumes the session
+// with --fork-session under an ID chosen up front. The CLI only writes the
+// fork out with a turn, so Fork sends a short one in plan mode: a model
+// call billed as usual, whose prompt and reply become the last exchange of
+// the fork's history. Continue the fork in a client created with
+// WithResume(id), e.g. to explore an alternative path from the same point.
+//
+// CLIs too old for --fork-session would resume this session instead and
+// add the turn to it; with them Fork fails with an
+// errors.UnsupportedFeatureError before the session is resumed.
+//
+// The fork's turn is not part of this conversation: the Transcript,
+// hooks, permission callbacks and audit trail, intercepted tools, progress
+// reporting and file change tracking do not see it.
+//
+// The session ID is known once the CLI sent its init message or a result.
+// Clients using a custom transport cannot fork, since Fork starts a CLI.
+//
+// Example:
+//
+//	forkID, err := client.Fork(ctx)
+//	if err != nil {
+//	    return err
+//	}
+//	alternative := claudecode.NewClaudeSDKClient(claudecode.NewOptions().WithResume(forkID))
+func (c *ClaudeSDKClient) Fork(ctx context.Context) (string, error) {
+	if c.customTransport != nil {
+		return "", errors.NewCLIConnectionError("cannot fork a session over a custom transport", nil)
+	}
+
+	state := c.Snapshot()
+	if state.SessionID == "" {
+		return "", errors.NewCLIConnectionError("no session to fork yet", nil)
+	}
+
+	forkID := ids.NewUUID()
+	options := *state.Options
+	options.Resume = &state.SessionID
+	options.ContinueConversation = false
+	options.ForkSession = true
+	options.SessionID = &forkID
+
+	// The turn must not run tools or reach anything observing this client
+	mode := types.PermissionModePlan
+	maxTurns := 1
+	options.PermissionMode = &mode
+	options.MaxTurns = &maxTurns
+	options.Transcript = nil
+	options.Hooks = nil
+	options.CanUseTool = nil
+	options.InterceptedTools = nil
+	options.PermissionAuditLog = nil
+	options.OnPermissionAudit = nil
+	options.OnProgress = nil
+	options.TrackFileChanges = false
+
+	fork := NewClaudeSDKClient(&options)
+	if err := fork.Connect(ctx, nil); err != nil {
+		return "", err
+	}
+	defer fork.Close()
+
+	if _, err := fork.SendAndWait(ctx, forkPrompt, "default"); err != nil {
+		return "", err
+	}
+	// Let the CLI exit on its own so the fork is written out
+	if err := fork.Shutdown(ctx); err != nil {
+		return "", err
+	}
+
+	c.logger().InfoContext(ctx, "forked session", "session_id", state.SessionID, "fork_id", forkID)
+	return forkID, nil
+}
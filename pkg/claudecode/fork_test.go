@@ -0,0 +1,161 @@
+package claudecode
+
+import (
+	"context"
+	stderrors "errors"
+	"os/exec"
+	"runtime"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/transporttest"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+func TestFork(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	var mu sync.Mutex
+	var runs [][]string
+	transcript := &frameCounter{}
+	options := types.NewOptions().
+		WithCLIPath("/nonexistent/claude").
+		WithCommandFactory(func(ctx context.Context, path string, args []string) *exec.Cmd {
+			if cmd := versionCommand(ctx, args); cmd != nil {
+				return cmd
+			}
+			mu.Lock()
+			runs = append(runs, args)
+			mu.Unlock()
+			return exec.CommandContext(ctx, "sh", "-c", echoCLI)
+		})
+	options.Transcript = transcript
+	client := NewClaudeSDKClient(options)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Nothing to fork before the CLI reported the session
+	if _, err := client.Fork(ctx); err == nil {
+		t.Error("expected an error forking before the session is known")
+	}
+
+	if err := client.Connect(ctx, "hello"); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+	if _, err := WaitForResult(ctx, client.Messages()); err != nil {
+		t.Fatalf("WaitForResult: %v", err)
+	}
+
+	recorded := transcript.count()
+	forkID, err := client.Fork(ctx)
+	if err != nil {
+		t.Fatalf("Fork: %v", err)
+	}
+	if err := types.NewOptions().WithForkSession("s1").WithSessionID(forkID).Validate(); err != nil {
+		t.Errorf("fork ID %q is not a UUID: %v", forkID, err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(runs) != 2 {
+		t.Fatalf("CLI started %d times, want 2", len(runs))
+	}
+	args := runs[1]
+	resume := slices.Index(args, "--resume")
+	if resume < 0 || args[resume+1] != "s1" || !slices.Contains(args, "--fork-session") {
+		t.Errorf("fork does not resume s1 with --fork-session: %v", args)
+	}
+	if i := slices.Index(args, "--session-id"); i < 0 || args[i+1] != forkID {
+		t.Errorf("fork not started as %s: %v", forkID, args)
+	}
+	if i := slices.Index(args, "--permission-mode"); i < 0 || args[i+1] != "plan" {
+		t.Errorf("fork's turn not in plan mode: %v", args)
+	}
+	if n := transcript.count(); n != recorded {
+		t.Errorf("fork's turn added %d frames to the transcript", n-recorded)
+	}
+
+	// The original session is untouched
+	if state := client.Snapshot(); state.SessionID != "s1" {
+		t.Errorf("session ID = %q after fork, want s1", state.SessionID)
+	}
+}
+
+// frameCounter is a TranscriptRecorder counting the frames it is given
+type frameCounter struct {
+	mu     sync.Mutex
+	frames int
+}
+
+func (f *frameCounter) Record(frame map[string]interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.frames++
+}
+
+func (f *frameCounter) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.frames
+}
+
+func TestForkUnsupportedCLI(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	// The CLI is downgraded to one without --fork-session after the
+	// session started
+	var mu sync.Mutex
+	version := "2.0.0"
+	runs := 0
+	options := types.NewOptions().
+		WithCLIPath("/nonexistent/claude").
+		WithCommandFactory(func(ctx context.Context, path string, args []string) *exec.Cmd {
+			mu.Lock()
+			defer mu.Unlock()
+			if slices.Contains(args, "--version") {
+				return exec.CommandContext(ctx, "echo", version)
+			}
+			runs++
+			return exec.CommandContext(ctx, "sh", "-c", echoCLI)
+		})
+	client := NewClaudeSDKClient(options)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, "hello"); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+	if _, err := WaitForResult(ctx, client.Messages()); err != nil {
+		t.Fatalf("WaitForResult: %v", err)
+	}
+
+	mu.Lock()
+	version = "1.0.90"
+	mu.Unlock()
+	if _, err := client.Fork(ctx); !stderrors.Is(err, ErrUnsupportedFeature) {
+		t.Errorf("Fork = %v, want ErrUnsupportedFeature", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if runs != 1 {
+		t.Errorf("CLI started %d times, want only the parent", runs)
+	}
+}
+
+func TestForkCustomTransport(t *testing.T) {
+	client := NewClaudeSDKClientWithTransport(nil, transporttest.NewMockTransport())
+	if _, err := client.Fork(context.Background()); err == nil {
+		t.Error("expected an error forking over a custom transport")
+	}
+}
@@ -0,0 +1,422 @@
+// Package git snapshots the git working tree Claude edits, so what a turn
+// changed can be shown, undone or committed:
+//
+//	repo, err := git.Open(ctx, dir)
+//	...
+//	snap, err := repo.Snapshot(ctx, "before turn")
+//	turn, err := client.SendAndWait(ctx, prompt, "default")
+//	...
+//	diff, err := repo.Diff(ctx, snap)  // What the turn changed
+//	err = repo.Rollback(ctx, snap)     // Undo it
+//	_, err = repo.Commit(ctx, git.CommitMessage("Fix the parser", turn.Result.SessionID, turn))
+//
+// Snapshots are commits kept under RefPrefix; taking one leaves the index,
+// the working tree and the current branch untouched. They include untracked
+// files but not ignored ones, which rollbacks leave alone.
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/tools"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// DefaultRefPrefix is where snapshots are kept by default
+const DefaultRefPrefix = "refs/claudecode/snapshots"
+
+// Snapshot identity, used so snapshots work without user.name configured
+const (
+	snapshotName  = "claudecode"
+	snapshotEmail = "claudecode@localhost"
+)
+
+// Repo is a git working tree
+type Repo struct {
+	// Top-level directory of the working tree
+	Dir string
+
+	// Ref namespace snapshots are kept under (default DefaultRefPrefix)
+	RefPrefix string
+
+	// Git executable (default "git" on PATH)
+	GitPath string
+
+	mu        sync.Mutex
+	snapshots []*Snapshot
+}
+
+// Snapshot is the state of the working tree at one moment
+type Snapshot struct {
+	Label  string
+	Time   time.Time
+	Commit string // Snapshot commit, its parent the HEAD of the time
+	Tree   string
+	Ref    string
+}
+
+// Open finds the working tree dir belongs to
+func Open(ctx context.Context, dir string) (*Repo, error) {
+	r := &Repo{Dir: dir}
+	top, err := r.run(ctx, nil, nil, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	r.Dir = strings.TrimSpace(top)
+	return r, nil
+}
+
+// Snapshot records the working tree, tracked and untracked files alike
+func (r *Repo) Snapshot(ctx context.Context, label string) (*Snapshot, error) {
+	tree, err := r.writeTree(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{"commit-tree", tree, "-m", "claudecode snapshot: " + label}
+	if head := r.head(ctx); head != "" {
+		args = append(args, "-p", head)
+	}
+	commit, err := r.run(ctx, snapshotIdentity(), nil, args...)
+	if err != nil {
+		return nil, err
+	}
+	commit = strings.TrimSpace(commit)
+
+	snap := &Snapshot{
+		Label:  label,
+		Time:   time.Now(),
+		Commit: commit,
+		Tree:   tree,
+		Ref:    r.refPrefix() + "/" + commit,
+	}
+	if _, err := r.run(ctx, nil, nil, "update-ref", snap.Ref, commit); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.snapshots = append(r.snapshots, snap)
+	r.mu.Unlock()
+	return snap, nil
+}
+
+// Snapshots returns the snapshots this Repo took, oldest first
+func (r *Repo) Snapshots() []*Snapshot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*Snapshot(nil), r.snapshots...)
+}
+
+// Diff returns the changes to the working tree since snap as a unified
+// diff, empty if there are none
+func (r *Repo) Diff(ctx context.Context, snap *Snapshot) (string, error) {
+	tree, err := r.writeTree(ctx)
+	if err != nil {
+		return "", err
+	}
+	return r.run(ctx, nil, nil, "diff", "--no-color", "--no-ext-diff", "--binary", snap.Tree, tree)
+}
+
+// Changed returns the paths changed since snap, relative to Dir
+func (r *Repo) Changed(ctx context.Context, snap *Snapshot) ([]string, error) {
+	tree, err := r.writeTree(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.changedPaths(ctx, snap.Tree, tree, "")
+}
+
+// Rollback restores the working tree to snap: changed and deleted files get
+// their content back and files created since are removed. Ignored files,
+// the index and HEAD are left alone.
+func (r *Repo) Rollback(ctx context.Context, snap *Snapshot) error {
+	tree, err := r.writeTree(ctx)
+	if err != nil {
+		return err
+	}
+
+	added, err := r.changedPaths(ctx, snap.Tree, tree, "A")
+	if err != nil {
+		return err
+	}
+	for _, path := range added {
+		if err := os.Remove(filepath.Join(r.Dir, path)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	restore, err := r.changedPaths(ctx, snap.Tree, tree, "DMT")
+	if err != nil || len(restore) == 0 {
+		return err
+	}
+	return r.withIndex(ctx, func(env []string) error {
+		if _, err := r.run(ctx, env, nil, "read-tree", snap.Tree); err != nil {
+			return err
+		}
+		paths := strings.Join(restore, "\x00") + "\x00"
+		_, err := r.run(ctx, env, strings.NewReader(paths), "checkout-index", "--force", "-z", "--stdin")
+		return err
+	})
+}
+
+// Commit commits every change in the working tree, untracked files
+// included, to the current branch. It returns the new commit, or "" when
+// there is nothing to commit.
+func (r *Repo) Commit(ctx context.Context, message string) (string, error) {
+	if _, err := r.run(ctx, nil, nil, "add", "--all"); err != nil {
+		return "", err
+	}
+	if _, err := r.run(ctx, nil, nil, "diff", "--cached", "--quiet"); err == nil && r.head(ctx) != "" {
+		return "", nil
+	}
+	if _, err := r.run(ctx, nil, nil, "commit", "--quiet", "--no-verify", "-m", message); err != nil {
+		return "", err
+	}
+	head, err := r.run(ctx, nil, nil, "rev-parse", "HEAD")
+	return strings.TrimSpace(head), err
+}
+
+// CommitToBranch commits the working tree to branch without checking it
+// out or touching the index, e.g. to keep one commit per turn on a side
+// branch. A new branch starts at HEAD. It returns the new commit, or ""
+// when the branch already holds the working tree.
+func (r *Repo) CommitToBranch(ctx context.Context, branch, message string) (string, error) {
+	tree, err := r.writeTree(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	ref := "refs/heads/" + branch
+	old := r.resolve(ctx, ref)
+	parent := old
+	if parent == "" {
+		parent = r.head(ctx)
+	}
+
+	args := []string{"commit-tree", tree, "-m", message}
+	if parent != "" {
+		if r.resolve(ctx, parent+"^{tree}") == tree {
+			if old == "" {
+				_, err = r.run(ctx, nil, nil, "update-ref", ref, parent, "")
+			}
+			return "", err
+		}
+		args = append(args, "-p", parent)
+	}
+	commit, err := r.run(ctx, nil, nil, args...)
+	if err != nil {
+		return "", err
+	}
+	commit = strings.TrimSpace(commit)
+
+	// Fails if the branch moved since it was read
+	if _, err := r.run(ctx, nil, nil, "update-ref", ref, commit, old); err != nil {
+		return "", err
+	}
+	return commit, nil
+}
+
+// DeleteSnapshots removes the refs of the snapshots this Repo took, so git
+// can collect them
+func (r *Repo) DeleteSnapshots(ctx context.Context) error {
+	r.mu.Lock()
+	snapshots := r.snapshots
+	r.snapshots = nil
+	r.mu.Unlock()
+
+	var errs []error
+	for _, snap := range snapshots {
+		if _, err := r.run(ctx, nil, nil, "update-ref", "-d", snap.Ref); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SnapshotBeforeEdits returns a PreToolUse hook that snapshots the working
+// tree before every Write, Edit, MultiEdit and NotebookEdit, so each file
+// change can be undone:
+//
+//	options := claudecode.NewOptions().
+//	    WithHook(types.HookEventPreToolUse, repo.SnapshotBeforeEdits())
+//
+// A failed snapshot fails the hook, and with it the edit.
+func (r *Repo) SnapshotBeforeEdits() types.HookMatcher {
+	matcher := strings.Join([]string{tools.NameWrite, tools.NameEdit, tools.NameMultiEdit, tools.NameNotebookEdit}, "|")
+	return types.HookMatcher{
+		Matcher: &matcher,
+		Hooks: []types.HookCallback{func(input map[string]interface{}, toolUseID *string, hookCtx *types.HookContext) (*types.HookJSONOutput, error) {
+			ctx := context.Background()
+			if hookCtx != nil && hookCtx.Signal != nil {
+				ctx = hookCtx.Signal
+			}
+
+			label, _ := input["tool_name"].(string)
+			if toolUseID != nil {
+				label += " " + *toolUseID
+			}
+			if _, err := r.Snapshot(ctx, strings.TrimSpace(label)); err != nil {
+				return nil, fmt.Errorf("snapshot before edit: %w", err)
+			}
+			return &types.HookJSONOutput{}, nil
+		}},
+	}
+}
+
+// CommitMessage generates a commit message for the changes of a turn: the
+// summary, then the session ID, cost and tools used. turn may be nil.
+func CommitMessage(summary, sessionID string, turn *types.Turn) string {
+	if summary = strings.TrimSpace(summary); summary == "" {
+		summary = "Apply changes made by Claude"
+	}
+
+	var b strings.Builder
+	b.WriteString(summary)
+	b.WriteString("\n\n")
+	if sessionID != "" {
+		fmt.Fprintf(&b, "Session: %s\n", sessionID)
+	}
+	if turn != nil {
+		fmt.Fprintf(&b, "Cost: $%.4f\n", turn.CostUSD)
+
+		seen := make(map[string]bool)
+		var names []string
+		for _, use := range turn.ToolUses {
+			if !seen[use.Name] {
+				seen[use.Name] = true
+				names = append(names, use.Name)
+			}
+		}
+		sort.Strings(names)
+		if len(names) > 0 {
+			fmt.Fprintf(&b, "Tools: %s\n", strings.Join(names, ", "))
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// writeTree writes the working tree as a tree object through a copy of the
+// index, so the index itself is not changed
+func (r *Repo) writeTree(ctx context.Context) (string, error) {
+	var tree string
+	err := r.withIndex(ctx, func(env []string) error {
+		if _, err := r.run(ctx, env, nil, "add", "--all"); err != nil {
+			return err
+		}
+		out, err := r.run(ctx, env, nil, "write-tree")
+		tree = strings.TrimSpace(out)
+		return err
+	})
+	return tree, err
+}
+
+// withIndex runs fn with GIT_INDEX_FILE set to a temporary copy of the
+// index, which keeps its cached file stats
+func (r *Repo) withIndex(ctx context.Context, fn func(env []string) error) error {
+	dir, err := os.MkdirTemp("", "claudecode-git-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	index := filepath.Join(dir, "index")
+
+	out, err := r.run(ctx, nil, nil, "rev-parse", "--path-format=absolute", "--git-path", "index")
+	if err != nil {
+		return err
+	}
+	if data, err := os.ReadFile(strings.TrimSpace(out)); err == nil {
+		if err := os.WriteFile(index, data, 0o600); err != nil {
+			return err
+		}
+	}
+	return fn([]string{"GIT_INDEX_FILE=" + index})
+}
+
+// changedPaths lists the paths differing between two trees, limited to the
+// diff filter when one is given
+func (r *Repo) changedPaths(ctx context.Context, from, to, filter string) ([]string, error) {
+	args := []string{"diff", "--name-only", "--no-renames", "-z"}
+	if filter != "" {
+		args = append(args, "--diff-filter="+filter)
+	}
+	out, err := r.run(ctx, nil, nil, append(args, from, to)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, path := range strings.Split(out, "\x00") {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+// head returns the commit HEAD points to, or "" in a repository without
+// commits
+func (r *Repo) head(ctx context.Context) string {
+	return r.resolve(ctx, "HEAD")
+}
+
+// resolve returns the object a revision names, or "" if there is none
+func (r *Repo) resolve(ctx context.Context, rev string) string {
+	out, err := r.run(ctx, nil, nil, "rev-parse", "--verify", "--quiet", rev)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
+
+// run runs git in Dir and returns its output
+func (r *Repo) run(ctx context.Context, env []string, stdin io.Reader, args ...string) (string, error) {
+	gitPath := r.GitPath
+	if gitPath == "" {
+		gitPath = "git"
+	}
+
+	cmd := exec.CommandContext(ctx, gitPath, args...)
+	cmd.Dir = r.Dir
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin = stdin
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %w: %s", args[0], err, msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return stdout.String(), nil
+}
+
+func (r *Repo) refPrefix() string {
+	if r.RefPrefix != "" {
+		return strings.TrimSuffix(r.RefPrefix, "/")
+	}
+	return DefaultRefPrefix
+}
+
+// snapshotIdentity sets the author and committer of snapshot commits
+func snapshotIdentity() []string {
+	return []string{
+		"GIT_AUTHOR_NAME=" + snapshotName,
+		"GIT_AUTHOR_EMAIL=" + snapshotEmail,
+		"GIT_COMMITTER_NAME=" + snapshotName,
+		"GIT_COMMITTER_EMAIL=" + snapshotEmail,
+	}
+}
@@ -0,0 +1,222 @@
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// newRepo creates a repository with one commit holding a.txt and b.txt
+func newRepo(t *testing.T) *Repo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+
+	dir := t.TempDir()
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	ctx := context.Background()
+	r := &Repo{Dir: dir}
+	if _, err := r.run(ctx, nil, nil, "init", "--quiet"); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, dir, "a.txt", "a\n")
+	writeFile(t, dir, "b.txt", "b\n")
+	writeFile(t, dir, ".gitignore", "*.log\n")
+	if _, err := r.Commit(ctx, "initial"); err != nil {
+		t.Fatal(err)
+	}
+
+	repo, err := Open(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, dir, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return "<" + err.Error() + ">"
+	}
+	return string(data)
+}
+
+func TestSnapshotRollback(t *testing.T) {
+	repo := newRepo(t)
+	ctx := context.Background()
+
+	// An untracked file before the snapshot belongs to it
+	writeFile(t, repo.Dir, "notes.txt", "notes\n")
+	status, _ := repo.run(ctx, nil, nil, "status", "--porcelain")
+
+	snap, err := repo.Snapshot(ctx, "before turn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after, _ := repo.run(ctx, nil, nil, "status", "--porcelain"); after != status {
+		t.Errorf("Snapshot changed the status from %q to %q", status, after)
+	}
+	if got := repo.resolve(ctx, snap.Ref); got != snap.Commit {
+		t.Errorf("Snapshot ref points to %q, want %q", got, snap.Commit)
+	}
+
+	writeFile(t, repo.Dir, "a.txt", "changed\n")
+	os.Remove(filepath.Join(repo.Dir, "b.txt"))
+	os.Remove(filepath.Join(repo.Dir, "notes.txt"))
+	writeFile(t, repo.Dir, "new/c.txt", "c\n")
+	writeFile(t, repo.Dir, "debug.log", "ignored\n")
+
+	changed, err := repo.Changed(ctx, snap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(changed, " "); got != "a.txt b.txt new/c.txt notes.txt" {
+		t.Errorf("Changed = %q", got)
+	}
+
+	diff, err := repo.Diff(ctx, snap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(diff, "-a\n+changed\n") || !strings.Contains(diff, "+++ b/new/c.txt") {
+		t.Errorf("Unexpected diff:\n%s", diff)
+	}
+
+	if err := repo.Rollback(ctx, snap); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{
+		"a.txt":     "a\n",
+		"b.txt":     "b\n",
+		"notes.txt": "notes\n",
+		"debug.log": "ignored\n",
+	} {
+		if got := readFile(t, repo.Dir, name); got != want {
+			t.Errorf("%s = %q after rollback, want %q", name, got, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(repo.Dir, "new/c.txt")); !os.IsNotExist(err) {
+		t.Errorf("Created file survived the rollback: %v", err)
+	}
+	if diff, _ := repo.Diff(ctx, snap); diff != "" {
+		t.Errorf("Diff after rollback:\n%s", diff)
+	}
+
+	if err := repo.DeleteSnapshots(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if repo.resolve(ctx, snap.Ref) != "" || len(repo.Snapshots()) != 0 {
+		t.Error("Snapshot not deleted")
+	}
+}
+
+func TestCommit(t *testing.T) {
+	repo := newRepo(t)
+	ctx := context.Background()
+	head := repo.head(ctx)
+
+	if commit, err := repo.Commit(ctx, "nothing"); err != nil || commit != "" {
+		t.Fatalf("Commit without changes = %q, %v", commit, err)
+	}
+
+	writeFile(t, repo.Dir, "a.txt", "changed\n")
+	writeFile(t, repo.Dir, "c.txt", "c\n")
+	turn := &types.Turn{
+		CostUSD:  0.0123,
+		ToolUses: []*types.ToolUseBlock{{Name: "Write"}, {Name: "Edit"}, {Name: "Write"}},
+	}
+	commit, err := repo.Commit(ctx, CommitMessage("Update a", "session-1", turn))
+	if err != nil || commit == "" || commit == head {
+		t.Fatalf("Commit = %q, %v", commit, err)
+	}
+
+	message, _ := repo.run(ctx, nil, nil, "log", "-1", "--format=%B")
+	want := "Update a\n\nSession: session-1\nCost: $0.0123\nTools: Edit, Write"
+	if strings.TrimSpace(message) != want {
+		t.Errorf("Commit message = %q, want %q", message, want)
+	}
+	if status, _ := repo.run(ctx, nil, nil, "status", "--porcelain"); status != "" {
+		t.Errorf("Changes left after commit: %q", status)
+	}
+}
+
+func TestCommitToBranch(t *testing.T) {
+	repo := newRepo(t)
+	ctx := context.Background()
+	head := repo.head(ctx)
+
+	// Nothing changed: the branch starts at HEAD without a commit
+	if commit, err := repo.CommitToBranch(ctx, "claude/turns", "turn 0"); err != nil || commit != "" {
+		t.Fatalf("CommitToBranch without changes = %q, %v", commit, err)
+	}
+	if got := repo.resolve(ctx, "refs/heads/claude/turns"); got != head {
+		t.Errorf("New branch at %q, want HEAD %q", got, head)
+	}
+
+	writeFile(t, repo.Dir, "a.txt", "turn 1\n")
+	first, err := repo.CommitToBranch(ctx, "claude/turns", "turn 1")
+	if err != nil || first == "" {
+		t.Fatalf("CommitToBranch = %q, %v", first, err)
+	}
+	writeFile(t, repo.Dir, "a.txt", "turn 2\n")
+	second, err := repo.CommitToBranch(ctx, "claude/turns", "turn 2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if parent := repo.resolve(ctx, second+"^"); parent != first {
+		t.Errorf("Second commit's parent = %q, want %q", parent, first)
+	}
+	if got := repo.head(ctx); got != head {
+		t.Errorf("HEAD moved to %q", got)
+	}
+	if status, _ := repo.run(ctx, nil, nil, "status", "--porcelain"); status != " M a.txt\n" {
+		t.Errorf("Status = %q, want only a.txt modified", status)
+	}
+}
+
+func TestSnapshotBeforeEdits(t *testing.T) {
+	repo := newRepo(t)
+
+	matcher := repo.SnapshotBeforeEdits()
+	pattern, err := types.CompileHookMatcher(matcher.Matcher)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pattern.Match("Edit") || !pattern.Match("NotebookEdit") || pattern.Match("Bash") {
+		t.Errorf("Unexpected matcher %q", *matcher.Matcher)
+	}
+
+	toolUseID := "toolu_1"
+	input := map[string]interface{}{"tool_name": "Edit"}
+	if _, err := matcher.Hooks[0](input, &toolUseID, &types.HookContext{Signal: context.Background()}); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshots := repo.Snapshots()
+	if len(snapshots) != 1 || snapshots[0].Label != "Edit toolu_1" {
+		t.Fatalf("Snapshots = %+v", snapshots)
+	}
+}
@@ -0,0 +1,284 @@
+package claudecode
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/hooks"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/tools"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// guardedInputKeys are the inputs of any tool that name a path
+var guardedInputKeys = []string{"file_path", "notebook_path", "path", "cwd"}
+
+// shellSeparators split a shell command into the words checked for paths
+const shellSeparators = " \t\n'\"`=<>|;&()"
+
+// shellAllowedPaths may appear in shell commands outside the roots
+var shellAllowedPaths = map[string]bool{"/dev/null": true, "/dev/stdin": true, "/dev/stdout": true, "/dev/stderr": true}
+
+// Guardrails confines tool uses to a set of directories. It checks the
+// paths in tool inputs on the client and denies tool uses reaching outside
+// the roots, whatever the CLI's permission mode, allow rules or
+// settings say: a second line of defense for services running agents on
+// behalf of users.
+//
+//	guardrails, err := claudecode.NewGuardrails(workspace)
+//	...
+//	options = claudecode.WithGuardrails(options, guardrails)
+//
+// Every tool's file_path, notebook_path, path and cwd inputs are checked,
+// as are Glob patterns and the paths in Bash commands. Symbolic links are
+// followed, so a link inside a root cannot lead out of it. Paths in shell
+// commands are found on a best-effort basis only; variables, scripts and
+// subshells can reach any file, so disallow Bash where isolation matters.
+type Guardrails struct {
+	// Directory relative paths are resolved against (default the session's
+	// working directory)
+	Dir string
+
+	// OnViolation is called with every tool use denied
+	OnViolation func(violation *GuardrailViolation)
+
+	roots []string
+}
+
+// GuardrailViolation is a tool use reaching outside the Guardrails' roots
+type GuardrailViolation struct {
+	ToolName string
+	Path     string // As given in the input
+}
+
+func (v *GuardrailViolation) Error() string {
+	return fmt.Sprintf("%s may not access %s: it is outside the allowed directories", v.ToolName, v.Path)
+}
+
+// NewGuardrails creates Guardrails allowing access to roots and everything
+// below them. Relative roots are resolved against the working directory of
+// the process.
+func NewGuardrails(roots ...string) (*Guardrails, error) {
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("guardrails need at least one root")
+	}
+
+	g := &Guardrails{}
+	for _, root := range roots {
+		abs, err := filepath.Abs(root)
+		if err != nil {
+			return nil, err
+		}
+		g.roots = append(g.roots, realPath(abs))
+	}
+	return g, nil
+}
+
+// Roots returns the directories the Guardrails allow, symbolic links
+// resolved
+func (g *Guardrails) Roots() []string {
+	return append([]string(nil), g.roots...)
+}
+
+// Check returns a *GuardrailViolation if a tool use with input would reach
+// outside the roots
+func (g *Guardrails) Check(toolName string, input map[string]interface{}) error {
+	return g.check(toolName, input, "")
+}
+
+// Hook returns a PreToolUse hook denying tool uses outside the roots. Hooks
+// run for every tool use, including those the CLI allows without asking.
+func (g *Guardrails) Hook() types.HookMatcher {
+	return types.HookMatcher{Hooks: []types.HookCallback{
+		func(input map[string]interface{}, toolUseID *string, context *types.HookContext) (*types.HookJSONOutput, error) {
+			toolName, _ := input["tool_name"].(string)
+			toolInput, _ := input["tool_input"].(map[string]interface{})
+			cwd, _ := input["cwd"].(string)
+
+			if err := g.check(toolName, toolInput, cwd); err != nil {
+				return hooks.BlockToolUse(err.Error()), nil
+			}
+			return hooks.Continue(), nil
+		},
+	}}
+}
+
+// CanUseTool wraps a permission callback so tool uses outside the roots
+// are denied before next is asked. next may be nil to allow the rest.
+func (g *Guardrails) CanUseTool(next types.CanUseTool) types.CanUseTool {
+	return func(toolName string, input map[string]interface{}, context *types.ToolPermissionContext) (types.PermissionResult, error) {
+		if err := g.check(toolName, input, ""); err != nil {
+			return &types.PermissionResultDeny{Behavior: types.PermissionBehaviorDeny, Message: err.Error()}, nil
+		}
+		if next != nil {
+			return next(toolName, input, context)
+		}
+		return &types.PermissionResultAllow{Behavior: types.PermissionBehaviorAllow}, nil
+	}
+}
+
+// WithGuardrails configures options to enforce guardrails: a PreToolUse
+// hook checks every tool use, and CanUseTool, if set, is wrapped to check
+// before asking. Relative paths resolve against CWD unless guardrails.Dir
+// is set.
+//
+// The options are copied; the original options are left untouched.
+func WithGuardrails(options *types.ClaudeCodeOptions, guardrails *Guardrails) *types.ClaudeCodeOptions {
+	var opts types.ClaudeCodeOptions
+	if options != nil {
+		opts = *options
+	}
+
+	g := *guardrails
+	if g.Dir == "" && opts.CWD != nil {
+		g.Dir = *opts.CWD
+	}
+
+	opts.Hooks = make(map[types.HookEvent][]types.HookMatcher, len(opts.Hooks)+1)
+	if options != nil {
+		for event, matchers := range options.Hooks {
+			opts.Hooks[event] = append([]types.HookMatcher(nil), matchers...)
+		}
+	}
+	// First, so no other hook allows the tool use before it is checked
+	opts.Hooks[types.HookEventPreToolUse] = append([]types.HookMatcher{g.Hook()}, opts.Hooks[types.HookEventPreToolUse]...)
+
+	if opts.CanUseTool != nil {
+		opts.CanUseTool = g.CanUseTool(opts.CanUseTool)
+	}
+	return &opts
+}
+
+// check returns the first path of the tool use outside the roots. cwd is
+// the session's working directory, used when Dir is not set.
+func (g *Guardrails) check(toolName string, input map[string]interface{}, cwd string) error {
+	dir := g.Dir
+	if dir == "" {
+		dir = cwd
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+
+	for _, path := range guardedPaths(toolName, input) {
+		if !g.allowed(path, dir) {
+			violation := &GuardrailViolation{ToolName: toolName, Path: path}
+			if g.OnViolation != nil {
+				g.OnViolation(violation)
+			}
+			return violation
+		}
+	}
+	return nil
+}
+
+// allowed reports whether path, relative to dir, lies within a root
+func (g *Guardrails) allowed(path, dir string) bool {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return false
+		}
+		path = home + path[1:]
+	}
+	if !filepath.IsAbs(path) {
+		// Not filepath.Join, which would resolve ".." before symbolic links
+		path = dir + string(filepath.Separator) + path
+	}
+	path = realPath(path)
+
+	for _, root := range g.roots {
+		rel, err := filepath.Rel(root, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// guardedPaths returns the paths a tool use accesses
+func guardedPaths(toolName string, input map[string]interface{}) []string {
+	var paths []string
+	for _, key := range guardedInputKeys {
+		if path, _ := input[key].(string); path != "" {
+			paths = append(paths, path)
+		}
+	}
+
+	switch toolName {
+	case tools.NameGlob, tools.NameGrep:
+		// Both search the working directory without a path
+		if path, _ := input["path"].(string); path == "" {
+			paths = append(paths, ".")
+		}
+		if pattern, _ := input["pattern"].(string); toolName == tools.NameGlob && pattern != "" {
+			// Patterns are relative to the path searched
+			base := globBase(pattern)
+			if path, _ := input["path"].(string); path != "" && !filepath.IsAbs(base) {
+				base = path + string(filepath.Separator) + base
+			}
+			paths = append(paths, base)
+		}
+	case tools.NameBash:
+		command, _ := input["command"].(string)
+		words := strings.FieldsFunc(command, func(r rune) bool { return strings.ContainsRune(shellSeparators, r) })
+		for _, word := range words {
+			if shellPath(word) && !shellAllowedPaths[word] {
+				paths = append(paths, word)
+			}
+		}
+	}
+	return paths
+}
+
+// shellPath reports whether a word of a shell command is an absolute, home
+// or parent relative path
+func shellPath(word string) bool {
+	return word == "~" || word == ".." ||
+		strings.HasPrefix(word, "/") || strings.HasPrefix(word, "~/") || strings.HasPrefix(word, "../")
+}
+
+// globBase returns the directory part of a glob pattern before its first
+// wildcard
+func globBase(pattern string) string {
+	if i := strings.IndexAny(pattern, "*?[{"); i >= 0 {
+		pattern = pattern[:i]
+		if j := strings.LastIndex(pattern, "/"); j >= 0 {
+			return pattern[:j+1]
+		}
+		return "."
+	}
+	return pattern
+}
+
+// realPath resolves the symbolic links of an absolute path, one element at
+// a time so ".." applies to the resolved parent the way the OS applies it.
+// Elements that do not exist yet are kept as they are.
+func realPath(path string) string {
+	volume := filepath.VolumeName(path)
+	current := volume + string(filepath.Separator)
+	missing := false
+
+	for _, part := range strings.Split(path[len(volume):], string(filepath.Separator)) {
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			current = filepath.Dir(current)
+			missing = false
+			continue
+		}
+
+		next := filepath.Join(current, part)
+		if !missing {
+			if real, err := filepath.EvalSymlinks(next); err == nil {
+				next = real
+			} else {
+				missing = true
+			}
+		}
+		current = next
+	}
+	return current
+}
@@ -0,0 +1,204 @@
+package claudecode
+
+import (
+	"context"
+	stderrors "errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+func TestGuardrailsCheck(t *testing.T) {
+	base := t.TempDir()
+	workspace := filepath.Join(base, "workspace")
+	outside := filepath.Join(base, "outside")
+	for _, dir := range []string{filepath.Join(workspace, "src"), outside} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A link inside the workspace leading out of it
+	if err := os.Symlink(outside, filepath.Join(workspace, "escape")); err != nil {
+		t.Fatal(err)
+	}
+
+	guardrails, err := NewGuardrails(workspace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	guardrails.Dir = filepath.Join(workspace, "src")
+
+	var violations []*GuardrailViolation
+	guardrails.OnViolation = func(v *GuardrailViolation) { violations = append(violations, v) }
+
+	tests := []struct {
+		tool    string
+		input   map[string]interface{}
+		allowed bool
+	}{
+		{"Read", map[string]interface{}{"file_path": filepath.Join(workspace, "README.md")}, true},
+		{"Write", map[string]interface{}{"file_path": "new/file.go"}, true},
+		{"Edit", map[string]interface{}{"file_path": "../main.go"}, true},
+		{"Read", map[string]interface{}{"file_path": "/etc/passwd"}, false},
+		{"Read", map[string]interface{}{"file_path": "../../outside/secret"}, false},
+		{"Write", map[string]interface{}{"file_path": filepath.Join(workspace, "escape", "secret")}, false},
+		{"Write", map[string]interface{}{"file_path": workspace + "/escape/../outside/x"}, false},
+		{"Read", map[string]interface{}{"file_path": "~/.ssh/id_rsa"}, false},
+		{"NotebookEdit", map[string]interface{}{"notebook_path": "/tmp/x.ipynb"}, false},
+		{"Grep", map[string]interface{}{"pattern": "TODO"}, true},
+		{"Grep", map[string]interface{}{"pattern": "TODO", "path": "/"}, false},
+		{"Glob", map[string]interface{}{"pattern": "**/*.go"}, true},
+		{"Glob", map[string]interface{}{"pattern": "/etc/**/*.conf"}, false},
+		{"Glob", map[string]interface{}{"pattern": "../../*", "path": "."}, false},
+		{"Bash", map[string]interface{}{"command": "go test ./... 2>/dev/null | tee out.txt"}, true},
+		{"Bash", map[string]interface{}{"command": "cat /etc/passwd"}, false},
+		{"Bash", map[string]interface{}{"command": "cd ../.. && ls"}, false},
+		{"Bash", map[string]interface{}{"command": "ls --dir='" + outside + "'"}, false},
+		{"mcp__files__read", map[string]interface{}{"path": outside}, false},
+		{"WebFetch", map[string]interface{}{"url": "https://example.com/etc/passwd"}, true},
+	}
+	denied := 0
+	for _, tt := range tests {
+		err := guardrails.Check(tt.tool, tt.input)
+		if (err == nil) != tt.allowed {
+			t.Errorf("Check(%s, %v) = %v, want allowed %v", tt.tool, tt.input, err, tt.allowed)
+		}
+		var violation *GuardrailViolation
+		if err != nil {
+			denied++
+			if !stderrors.As(err, &violation) || violation.ToolName != tt.tool {
+				t.Errorf("Check(%s) returned %T %v, want a GuardrailViolation", tt.tool, err, err)
+			}
+		}
+	}
+	if len(violations) != denied {
+		t.Errorf("OnViolation called %d times, want %d", len(violations), denied)
+	}
+
+	if _, err := NewGuardrails(); err == nil {
+		t.Error("NewGuardrails without roots succeeded")
+	}
+}
+
+func TestWithGuardrails(t *testing.T) {
+	workspace := t.TempDir()
+	guardrails, err := NewGuardrails(workspace)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	asked := 0
+	options := types.NewOptions().
+		WithCWD(workspace).
+		WithHook(types.HookEventPreToolUse, types.HookMatcher{}).
+		WithCanUseTool(func(string, map[string]interface{}, *types.ToolPermissionContext) (types.PermissionResult, error) {
+			asked++
+			return &types.PermissionResultAllow{Behavior: types.PermissionBehaviorAllow}, nil
+		})
+	guarded := WithGuardrails(options, guardrails)
+
+	if len(options.Hooks[types.HookEventPreToolUse]) != 1 {
+		t.Error("The original options were changed")
+	}
+	matchers := guarded.Hooks[types.HookEventPreToolUse]
+	if len(matchers) != 2 {
+		t.Fatalf("got %d PreToolUse matchers, want 2", len(matchers))
+	}
+
+	// The hook resolves relative paths against CWD and denies outside paths
+	hook := matchers[0].Hooks[0]
+	output, err := hook(map[string]interface{}{
+		"tool_name":  "Write",
+		"tool_input": map[string]interface{}{"file_path": "notes.txt"},
+	}, nil, nil)
+	if err != nil || output.HookSpecificOutput != nil {
+		t.Errorf("Hook denied a path in the workspace: %+v, %v", output, err)
+	}
+	output, err = hook(map[string]interface{}{
+		"tool_name":  "Write",
+		"tool_input": map[string]interface{}{"file_path": "../notes.txt"},
+	}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	specific, ok := output.HookSpecificOutput.(*types.PreToolUseHookSpecificOutput)
+	if !ok || specific.PermissionDecision != types.PermissionBehaviorDeny {
+		t.Errorf("Hook output = %+v, want a deny", output.HookSpecificOutput)
+	}
+
+	// CanUseTool checks before asking the original callback
+	result, _ := guarded.CanUseTool("Read", map[string]interface{}{"file_path": "/etc/passwd"}, nil)
+	if _, ok := result.(*types.PermissionResultDeny); !ok || asked != 0 {
+		t.Errorf("CanUseTool = %#v after %d asks, want a deny without asking", result, asked)
+	}
+	result, _ = guarded.CanUseTool("Read", map[string]interface{}{"file_path": "main.go"}, nil)
+	if _, ok := result.(*types.PermissionResultAllow); !ok || asked != 1 {
+		t.Errorf("CanUseTool = %#v after %d asks, want the original callback's allow", result, asked)
+	}
+}
+
+// guardedCLI asks its PreToolUse hook and then CanUseTool about writing
+// /etc/passwd, and reports both decisions as its result
+const guardedCLI = `
+echo '{"type":"system","subtype":"init","session_id":"s1"}'
+while IFS= read -r line; do
+  case "$line" in
+  *'"subtype":"initialize"'*)
+    id=$(echo "$line" | sed 's/.*"request_id":"\([^"]*\)".*/\1/')
+    hook=$(echo "$line" | sed 's/.*"hookCallbackIds":\["\([^"]*\)".*/\1/')
+    echo '{"type":"control_response","response":{"subtype":"success","request_id":"'"$id"'","response":{}}}'
+    ;;
+  *'"type":"user"'*)
+    echo '{"type":"control_request","request_id":"cli_1","request":{"subtype":"hook_callback","callback_id":"'"$hook"'","tool_use_id":"toolu_1","input":{"tool_name":"Write","tool_input":{"file_path":"/etc/passwd"}}}}'
+    ;;
+  *'"request_id":"cli_1"'*)
+    case "$line" in *'"deny"'*) hook=deny;; *) hook=allow;; esac
+    echo '{"type":"control_request","request_id":"cli_2","request":{"subtype":"can_use_tool","tool_name":"Write","input":{"file_path":"/etc/passwd"}}}'
+    ;;
+  *'"request_id":"cli_2"'*)
+    case "$line" in *'"deny"'*) tool=deny;; *) tool=allow;; esac
+    echo '{"type":"result","subtype":"success","result":"hook '"$hook"', can_use_tool '"$tool"'","session_id":"s1"}'
+    ;;
+  esac
+done
+`
+
+func TestGuardrailsQuery(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	guardrails, err := NewGuardrails(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	options := types.NewOptions().
+		WithCLIPath("/nonexistent/claude").
+		WithCommandFactory(func(ctx context.Context, path string, args []string) *exec.Cmd {
+			if cmd := versionCommand(ctx, args); cmd != nil {
+				return cmd
+			}
+			return exec.CommandContext(ctx, "sh", "-c", guardedCLI)
+		}).
+		WithCanUseTool(func(toolName string, input map[string]interface{}, context *types.ToolPermissionContext) (types.PermissionResult, error) {
+			return &types.PermissionResultAllow{Behavior: types.PermissionBehaviorAllow}, nil
+		})
+	options = WithGuardrails(options, guardrails)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	messages, err := QuerySync(ctx, "hi", options)
+	if err != nil {
+		t.Fatalf("QuerySync: %v", err)
+	}
+	result, ok := messages[len(messages)-1].(*types.ResultMessage)
+	if !ok || result.Result == nil || *result.Result != "hook deny, can_use_tool deny" {
+		t.Errorf("write outside the roots not denied: %v", messages)
+	}
+}
@@ -0,0 +1,209 @@
+package claudecode
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// ListSessionsOptions selects the sessions returned by ListSessions
+type ListSessionsOptions struct {
+	// Only sessions started in this directory; empty lists every project
+	CWD string
+
+	// CLI state directory (default $CLAUDE_CONFIG_DIR, then ~/.claude)
+	ConfigDir string
+
+	// Maximum number of sessions returned, most recent first (0 = all)
+	Limit int
+}
+
+// ListSessions enumerates the conversations the CLI has stored, most
+// recently updated first, e.g. to offer a "continue previous conversation"
+// picker before resuming one:
+//
+//	sessions, err := claudecode.ListSessions(ctx, claudecode.ListSessionsOptions{CWD: dir, Limit: 10})
+//	...
+//	options := claudecode.NewOptions().WithResume(sessions[0].ID)
+func ListSessions(ctx context.Context, options ListSessionsOptions) ([]types.SessionInfo, error) {
+	root, err := sessionsRoot(options.ConfigDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var dirs []string
+	if options.CWD != "" {
+		cwd, err := filepath.Abs(options.CWD)
+		if err != nil {
+			return nil, err
+		}
+		dirs = []string{filepath.Join(root, projectDirName(cwd))}
+	} else {
+		entries, err := os.ReadDir(root)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				dirs = append(dirs, filepath.Join(root, entry.Name()))
+			}
+		}
+	}
+
+	var sessions []types.SessionInfo
+	for _, dir := range dirs {
+		files, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			info, ok := readSessionInfo(file)
+			if ok {
+				sessions = append(sessions, info)
+			}
+		}
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].Updated.After(sessions[j].Updated)
+	})
+	if options.Limit > 0 && len(sessions) > options.Limit {
+		sessions = sessions[:options.Limit]
+	}
+	return sessions, nil
+}
+
+// sessionsRoot returns the directory holding a subdirectory of session
+// transcripts per project
+func sessionsRoot(configDir string) (string, error) {
+	if configDir == "" {
+		configDir = os.Getenv(types.ConfigDirEnv)
+	}
+	if configDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		configDir = filepath.Join(home, ".claude")
+	}
+	return filepath.Join(configDir, "projects"), nil
+}
+
+// projectDirName is the name the CLI gives the directory of a project's
+// sessions: the path with every non-alphanumeric character replaced by '-'
+func projectDirName(cwd string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '-'
+	}, cwd)
+}
+
+// sessionLine holds the fields of a transcript entry ListSessions needs
+type sessionLine struct {
+	Type        string `json:"type"`
+	CWD         string `json:"cwd"`
+	Timestamp   string `json:"timestamp"`
+	GitBranch   string `json:"gitBranch"`
+	Summary     string `json:"summary"`
+	IsSidechain bool   `json:"isSidechain"`
+	IsMeta      bool   `json:"isMeta"`
+	Message     struct {
+		Content json.RawMessage `json:"content"`
+	} `json:"message"`
+}
+
+// readSessionInfo summarizes a session transcript. Files without any
+// messages are skipped; a damaged tail ends the scan early.
+func readSessionInfo(path string) (types.SessionInfo, bool) {
+	info := types.SessionInfo{
+		ID:   strings.TrimSuffix(filepath.Base(path), ".jsonl"),
+		Path: path,
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return info, false
+	}
+	defer f.Close()
+
+	decoder := json.NewDecoder(f)
+	for {
+		var line sessionLine
+		if err := decoder.Decode(&line); err != nil {
+			// EOF, or a line the CLI is still writing
+			break
+		}
+
+		switch line.Type {
+		case "summary":
+			// The latest summary describes the conversation best
+			info.Summary = line.Summary
+			continue
+		case types.MessageTypeUser, types.MessageTypeAssistant:
+		default:
+			continue
+		}
+		if line.IsSidechain {
+			continue
+		}
+
+		info.MessageCount++
+		if info.CWD == "" {
+			info.CWD = line.CWD
+		}
+		if line.GitBranch != "" {
+			info.GitBranch = line.GitBranch
+		}
+		if ts, err := time.Parse(time.RFC3339Nano, line.Timestamp); err == nil {
+			if info.Created.IsZero() {
+				info.Created = ts
+			}
+			info.Updated = ts
+		}
+		if info.FirstPrompt == "" && line.Type == types.MessageTypeUser && !line.IsMeta {
+			info.FirstPrompt = promptText(line.Message.Content)
+		}
+	}
+
+	if info.MessageCount == 0 {
+		return info, false
+	}
+	if info.Updated.IsZero() {
+		if stat, err := f.Stat(); err == nil {
+			info.Updated = stat.ModTime()
+		}
+	}
+	return info, true
+}
+
+// promptText extracts the text a user typed from message content, which is
+// either a string or a list of content blocks. Tool results yield "".
+func promptText(content json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(content, &text); err == nil {
+		return strings.TrimSpace(text)
+	}
+
+	var blocks []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	json.Unmarshal(content, &blocks)
+	for _, block := range blocks {
+		if block.Type == types.ContentBlockTypeText {
+			return strings.TrimSpace(block.Text)
+		}
+	}
+	return ""
+}
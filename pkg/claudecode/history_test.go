@@ -0,0 +1,78 @@
+package claudecode
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeSession(t *testing.T, dir, id string, lines ...string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	data := strings.Join(lines, "\n") + "\n"
+	if err := os.WriteFile(filepath.Join(dir, id+".jsonl"), []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestListSessions(t *testing.T) {
+	configDir := t.TempDir()
+	project := filepath.Join(configDir, "projects", projectDirName("/work/my.app"))
+	other := filepath.Join(configDir, "projects", projectDirName("/work/other"))
+
+	writeSession(t, project, "older",
+		`{"type":"user","sessionId":"older","cwd":"/work/my.app","gitBranch":"main","timestamp":"2025-01-01T10:00:00Z","message":{"role":"user","content":"  fix the build "}}`,
+		`{"type":"assistant","sessionId":"older","cwd":"/work/my.app","timestamp":"2025-01-01T10:01:00Z","message":{"role":"assistant","content":[{"type":"text","text":"done"}]}}`,
+	)
+	writeSession(t, project, "newer",
+		`{"type":"summary","summary":"Add retry logic","leafUuid":"x"}`,
+		`{"type":"user","isMeta":true,"cwd":"/work/my.app","timestamp":"2025-02-01T09:00:00Z","message":{"role":"user","content":"<caveat>"}}`,
+		`{"type":"user","cwd":"/work/my.app","timestamp":"2025-02-01T09:00:01Z","message":{"role":"user","content":[{"type":"text","text":"add retries"}]}}`,
+		`{"type":"assistant","isSidechain":true,"timestamp":"2025-02-01T09:05:00Z","message":{"content":[]}}`,
+		`{"type":"assistant","cwd":"/work/my.app","timestamp":"2025-02-01T09:02:00Z","message":{"content":[]}}`,
+		`{"type":"user","cwd":"/work/my.app","timestamp":"2025-02-01T09:03:00Z","mess`,
+	)
+	writeSession(t, project, "empty", `{"type":"summary","summary":"nothing"}`)
+	writeSession(t, other, "elsewhere",
+		`{"type":"user","cwd":"/work/other","timestamp":"2025-03-01T00:00:00Z","message":{"content":"hi"}}`,
+	)
+
+	ctx := context.Background()
+	sessions, err := ListSessions(ctx, ListSessionsOptions{ConfigDir: configDir, CWD: "/work/my.app"})
+	if err != nil {
+		t.Fatalf("ListSessions: %v", err)
+	}
+	if len(sessions) != 2 || sessions[0].ID != "newer" || sessions[1].ID != "older" {
+		t.Fatalf("unexpected sessions: %+v", sessions)
+	}
+
+	newer := sessions[0]
+	if newer.Title() != "Add retry logic" || newer.FirstPrompt != "add retries" || newer.MessageCount != 3 {
+		t.Errorf("unexpected newer session: %+v", newer)
+	}
+	if newer.CWD != "/work/my.app" || newer.Updated.Format("15:04") != "09:02" {
+		t.Errorf("unexpected newer metadata: %+v", newer)
+	}
+
+	older := sessions[1]
+	if older.Title() != "fix the build" || older.GitBranch != "main" || older.Created.Format("15:04") != "10:00" {
+		t.Errorf("unexpected older session: %+v", older)
+	}
+
+	all, err := ListSessions(ctx, ListSessionsOptions{ConfigDir: configDir, Limit: 2})
+	if err != nil {
+		t.Fatalf("ListSessions: %v", err)
+	}
+	if len(all) != 2 || all[0].ID != "elsewhere" || all[1].ID != "newer" {
+		t.Errorf("unexpected sessions across projects: %+v", all)
+	}
+
+	t.Setenv("CLAUDE_CONFIG_DIR", filepath.Join(configDir, "missing"))
+	if none, err := ListSessions(ctx, ListSessionsOptions{}); err != nil || len(none) != 0 {
+		t.Errorf("missing config dir: %v, %v", none, err)
+	}
+}
@@ -0,0 +1,89 @@
+// Package hooks builds HookJSONOutput values for hook callbacks.
+//
+// Each event understands a different subset of the output schema. The
+// constructors here produce the right shape so hook authors don't have to:
+//
+//	func(input map[string]interface{}, toolUseID *string, ctx *types.HookContext) (*types.HookJSONOutput, error) {
+//	    if input["tool_name"] == "Bash" {
+//	        return hooks.BlockToolUse("shell access is disabled"), nil
+//	    }
+//	    return hooks.Continue(), nil
+//	}
+package hooks
+
+import (
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// Continue lets Claude proceed as if the hook had not run
+func Continue() *types.HookJSONOutput {
+	return &types.HookJSONOutput{}
+}
+
+// AllowToolUse approves a tool call from a PreToolUse hook, bypassing the
+// permission prompt
+func AllowToolUse(reason string) *types.HookJSONOutput {
+	return preToolUse(types.PermissionBehaviorAllow, reason)
+}
+
+// BlockToolUse denies a tool call from a PreToolUse hook. The reason is
+// shown to Claude.
+func BlockToolUse(reason string) *types.HookJSONOutput {
+	return preToolUse(types.PermissionBehaviorDeny, reason)
+}
+
+// AskToolUse asks the user to confirm a tool call from a PreToolUse hook
+func AskToolUse(reason string) *types.HookJSONOutput {
+	return preToolUse(types.PermissionBehaviorAsk, reason)
+}
+
+func preToolUse(decision types.PermissionBehavior, reason string) *types.HookJSONOutput {
+	return &types.HookJSONOutput{
+		HookSpecificOutput: &types.PreToolUseHookSpecificOutput{
+			HookEventName:            types.HookEventPreToolUse,
+			PermissionDecision:       decision,
+			PermissionDecisionReason: reason,
+		},
+	}
+}
+
+// AddToolResultContext adds context for Claude from a PostToolUse hook
+func AddToolResultContext(context string) *types.HookJSONOutput {
+	return &types.HookJSONOutput{
+		HookSpecificOutput: &types.PostToolUseHookSpecificOutput{
+			HookEventName:     types.HookEventPostToolUse,
+			AdditionalContext: context,
+		},
+	}
+}
+
+// AddPromptContext adds context to the prompt from a UserPromptSubmit hook
+func AddPromptContext(context string) *types.HookJSONOutput {
+	return &types.HookJSONOutput{
+		HookSpecificOutput: &types.UserPromptSubmitHookSpecificOutput{
+			HookEventName:     types.HookEventUserPromptSubmit,
+			AdditionalContext: context,
+		},
+	}
+}
+
+// Block returns a block decision. From PostToolUse it feeds the reason back
+// to Claude, from UserPromptSubmit it rejects the prompt and from Stop or
+// SubagentStop it makes Claude keep working.
+func Block(reason string) *types.HookJSONOutput {
+	decision := types.HookDecisionBlock
+	return &types.HookJSONOutput{
+		Decision: &decision,
+		Reason:   &reason,
+	}
+}
+
+// Stop halts Claude after the hook runs. The reason is shown to the user,
+// not to Claude.
+func Stop(reason string) *types.HookJSONOutput {
+	cont := false
+	return &types.HookJSONOutput{
+		Continue:   &cont,
+		StopReason: &reason,
+	}
+}
@@ -0,0 +1,75 @@
+package hooks_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/hooks"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/transporttest"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+func TestBlockToolUseResponse(t *testing.T) {
+	mock := transporttest.NewMockTransport()
+	options := types.NewOptions().WithHook(types.HookEventPreToolUse, types.HookMatcher{
+		Hooks: []types.HookCallback{
+			func(input map[string]interface{}, toolUseID *string, ctx *types.HookContext) (*types.HookJSONOutput, error) {
+				return hooks.BlockToolUse("shell access is disabled"), nil
+			},
+		},
+	})
+	client := claudecode.NewClaudeSDKClientWithTransport(options, mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	mock.Emit(map[string]interface{}{
+		"type":       "control_request",
+		"request_id": "cli_1",
+		"request": map[string]interface{}{
+			"subtype":     "hook_callback",
+			"callback_id": "hook_0",
+			"input":       map[string]interface{}{"tool_name": "Bash"},
+		},
+	})
+
+	for {
+		for _, msg := range mock.WrittenMessages() {
+			if msg["type"] != "control_response" {
+				continue
+			}
+			response := msg["response"].(map[string]interface{})
+			output := response["response"].(map[string]interface{})
+			specific := output["hookSpecificOutput"].(map[string]interface{})
+			if specific["hookEventName"] != "PreToolUse" || specific["permissionDecision"] != "deny" ||
+				specific["permissionDecisionReason"] != "shell access is disabled" {
+				t.Fatalf("unexpected hook output: %v", specific)
+			}
+			return
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatal("no hook response written")
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+}
+
+func TestStopAndBlock(t *testing.T) {
+	stop := hooks.Stop("budget exhausted")
+	if stop.Continue == nil || *stop.Continue || *stop.StopReason != "budget exhausted" {
+		t.Errorf("unexpected stop output: %+v", stop)
+	}
+
+	block := hooks.Block("tests are failing")
+	if block.Decision == nil || *block.Decision != types.HookDecisionBlock || *block.Reason != "tests are failing" {
+		t.Errorf("unexpected block output: %+v", block)
+	}
+}
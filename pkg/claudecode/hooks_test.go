@@ -0,0 +1,358 @@
+package claudecode
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/transporttest"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+func TestInitializeRegistersHooks(t *testing.T) {
+	mock := transporttest.NewMockTransport()
+	mock.RespondFunc(transporttest.MatchControl("initialize"), func(msg map[string]interface{}) []interface{} {
+		return []interface{}{transporttest.ControlSuccess(msg, map[string]interface{}{
+			"commands": []interface{}{map[string]interface{}{"name": "compact"}},
+		})}
+	})
+
+	called := make(chan string, 1)
+	bash := "Bash"
+	options := types.NewOptions().WithHook(types.HookEventPreToolUse, types.HookMatcher{
+		Matcher: &bash,
+		Hooks: []types.HookCallback{
+			func(input map[string]interface{}, toolUseID *string, ctx *types.HookContext) (*types.HookJSONOutput, error) {
+				called <- *toolUseID
+				return &types.HookJSONOutput{}, nil
+			},
+		},
+	})
+	client := NewClaudeSDKClientWithTransport(options, mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	info, err := client.GetServerInfo()
+	if err != nil {
+		t.Fatalf("GetServerInfo: %v", err)
+	}
+	if !info.HasCommand("compact") {
+		t.Errorf("server info missing commands: %+v", info)
+	}
+
+	init := mock.WrittenMessages()[0]
+	request := init["request"].(map[string]interface{})
+	hooks := request["hooks"].(map[string]interface{})
+	matchers := hooks["PreToolUse"].([]interface{})
+	matcher := matchers[0].(map[string]interface{})
+	ids := matcher["hookCallbackIds"].([]interface{})
+	if matcher["matcher"] != "Bash" || len(ids) != 1 {
+		t.Fatalf("unexpected hook registration: %v", matcher)
+	}
+
+	// The CLI invokes the registered callback
+	mock.Emit(map[string]interface{}{
+		"type":       "control_request",
+		"request_id": "cli_1",
+		"request": map[string]interface{}{
+			"subtype":     "hook_callback",
+			"callback_id": ids[0],
+			"input":       map[string]interface{}{},
+			"tool_use_id": "toolu_1",
+		},
+	})
+
+	select {
+	case id := <-called:
+		if id != "toolu_1" {
+			t.Errorf("tool use ID = %q, want toolu_1", id)
+		}
+	case <-ctx.Done():
+		t.Fatal("hook callback was not invoked")
+	}
+}
+
+func TestHookMatcherFiltersCallbacks(t *testing.T) {
+	mock := transporttest.NewMockTransport()
+	mock.RespondFunc(transporttest.MatchControl("initialize"), func(msg map[string]interface{}) []interface{} {
+		return []interface{}{transporttest.ControlSuccess(msg, map[string]interface{}{})}
+	})
+
+	called := make(chan string, 2)
+	github := "mcp__github__*"
+	options := types.NewOptions().WithHook(types.HookEventPreToolUse, types.HookMatcher{
+		Matcher: &github,
+		Hooks: []types.HookCallback{
+			func(input map[string]interface{}, toolUseID *string, ctx *types.HookContext) (*types.HookJSONOutput, error) {
+				called <- *toolUseID
+				return &types.HookJSONOutput{}, nil
+			},
+		},
+	})
+	client := NewClaudeSDKClientWithTransport(options, mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	request := mock.WrittenMessages()[0]["request"].(map[string]interface{})
+	matcher := request["hooks"].(map[string]interface{})["PreToolUse"].([]interface{})[0].(map[string]interface{})
+	if matcher["matcher"] != "^(?:mcp__github__.*)$" {
+		t.Fatalf("registered matcher = %v", matcher["matcher"])
+	}
+	id := matcher["hookCallbackIds"].([]interface{})[0]
+
+	for i, tool := range []string{"Bash", "mcp__github__list_issues"} {
+		mock.Emit(map[string]interface{}{
+			"type":       "control_request",
+			"request_id": fmt.Sprintf("cli_%d", i),
+			"request": map[string]interface{}{
+				"subtype":     "hook_callback",
+				"callback_id": id,
+				"input":       map[string]interface{}{"tool_name": tool},
+				"tool_use_id": tool,
+			},
+		})
+	}
+
+	select {
+	case tool := <-called:
+		if tool != "mcp__github__list_issues" {
+			t.Errorf("callback invoked for %q", tool)
+		}
+	case <-ctx.Done():
+		t.Fatal("hook callback was not invoked")
+	}
+}
+
+func TestInvalidHookMatcher(t *testing.T) {
+	malformed := "mcp__(github"
+	options := types.NewOptions().WithHook(types.HookEventPreToolUse, types.HookMatcher{
+		Matcher: &malformed,
+		Hooks: []types.HookCallback{
+			func(map[string]interface{}, *string, *types.HookContext) (*types.HookJSONOutput, error) {
+				return nil, nil
+			},
+		},
+	})
+	client := NewClaudeSDKClientWithTransport(options, transporttest.NewMockTransport())
+
+	err := client.Connect(context.Background(), nil)
+	if !stderrors.Is(err, errors.ErrInvalidOptions) {
+		t.Fatalf("Connect error = %v, want ErrInvalidOptions", err)
+	}
+}
+
+func TestCallbackSignalCancelled(t *testing.T) {
+	mock := transporttest.NewMockTransport()
+
+	started := make(chan struct{}, 2)
+	aborted := make(chan error, 2)
+	options := types.NewOptions().
+		WithHook(types.HookEventPreToolUse, types.HookMatcher{
+			Hooks: []types.HookCallback{
+				func(input map[string]interface{}, toolUseID *string, ctx *types.HookContext) (*types.HookJSONOutput, error) {
+					started <- struct{}{}
+					<-ctx.Signal.Done()
+					aborted <- ctx.Signal.Err()
+					return nil, ctx.Signal.Err()
+				},
+			},
+		}).
+		WithCanUseTool(func(toolName string, input map[string]interface{}, ctx *types.ToolPermissionContext) (types.PermissionResult, error) {
+			started <- struct{}{}
+			<-ctx.Signal.Done()
+			aborted <- ctx.Signal.Err()
+			return nil, ctx.Signal.Err()
+		})
+	client := NewClaudeSDKClientWithTransport(options, mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	wait := func(ch <-chan struct{}) {
+		t.Helper()
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			t.Fatal("timed out")
+		}
+	}
+	waitAbort := func() {
+		t.Helper()
+		select {
+		case err := <-aborted:
+			if err != context.Canceled {
+				t.Errorf("Signal error = %v, want context.Canceled", err)
+			}
+		case <-ctx.Done():
+			t.Fatal("callback was not aborted")
+		}
+	}
+
+	// The CLI cancels a hook callback
+	mock.Emit(map[string]interface{}{
+		"type":       "control_request",
+		"request_id": "cli_1",
+		"request": map[string]interface{}{
+			"subtype":     "hook_callback",
+			"callback_id": "hook_0",
+			"input":       map[string]interface{}{},
+		},
+	})
+	wait(started)
+	mock.Emit(map[string]interface{}{"type": "control_cancel_request", "request_id": "cli_1"})
+	waitAbort()
+
+	// Interrupting the turn aborts a pending permission prompt
+	mock.Emit(map[string]interface{}{
+		"type":       "control_request",
+		"request_id": "cli_2",
+		"request": map[string]interface{}{
+			"subtype":   "can_use_tool",
+			"tool_name": "Bash",
+			"input":     map[string]interface{}{},
+		},
+	})
+	wait(started)
+	if err := client.Interrupt(); err != nil {
+		t.Fatalf("Interrupt: %v", err)
+	}
+	waitAbort()
+}
+
+func TestCallbackTimeout(t *testing.T) {
+	mock := transporttest.NewMockTransport()
+
+	release := make(chan struct{})
+	defer close(release)
+
+	options := types.NewOptions().
+		WithCanUseTool(func(toolName string, input map[string]interface{}, ctx *types.ToolPermissionContext) (types.PermissionResult, error) {
+			<-release
+			return &types.PermissionResultAllow{Behavior: types.PermissionBehaviorAllow}, nil
+		})
+	options.CallbackTimeout = 20 * time.Millisecond
+	client := NewClaudeSDKClientWithTransport(options, mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	mock.Emit(map[string]interface{}{
+		"type":       "control_request",
+		"request_id": "cli_1",
+		"request": map[string]interface{}{
+			"subtype":   "can_use_tool",
+			"tool_name": "Bash",
+			"input":     map[string]interface{}{},
+		},
+	})
+
+	select {
+	case err := <-client.Errors():
+		var timeoutErr *CallbackTimeoutError
+		if !stderrors.As(err, &timeoutErr) || timeoutErr.Name != "Bash" {
+			t.Fatalf("error = %v, want CallbackTimeoutError for Bash", err)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for CallbackTimeoutError")
+	}
+
+	for _, msg := range mock.WrittenMessages() {
+		if msg["type"] != "control_response" {
+			continue
+		}
+		response := msg["response"].(map[string]interface{})["response"].(map[string]interface{})
+		if response["behavior"] != "deny" {
+			t.Errorf("behavior = %v, want deny", response["behavior"])
+		}
+		return
+	}
+	t.Error("no permission response written")
+}
+
+func TestConnectContextCancelsQuery(t *testing.T) {
+	mock := transporttest.NewMockTransport()
+	started := make(chan struct{}, 1)
+	aborted := make(chan error, 1)
+	options := types.NewOptions().WithHook(types.HookEventPreToolUse, types.HookMatcher{
+		Hooks: []types.HookCallback{
+			func(input map[string]interface{}, toolUseID *string, ctx *types.HookContext) (*types.HookJSONOutput, error) {
+				started <- struct{}{}
+				<-ctx.Signal.Done()
+				aborted <- ctx.Signal.Err()
+				return nil, ctx.Signal.Err()
+			},
+		},
+	})
+	client := NewClaudeSDKClientWithTransport(options, mock)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	timeout := time.After(5 * time.Second)
+	mock.Emit(map[string]interface{}{
+		"type":       "control_request",
+		"request_id": "cli_1",
+		"request": map[string]interface{}{
+			"subtype":     "hook_callback",
+			"callback_id": "hook_0",
+			"input":       map[string]interface{}{},
+		},
+	})
+	select {
+	case <-started:
+	case <-timeout:
+		t.Fatal("hook callback was not invoked")
+	}
+
+	// A control request the CLI never answers
+	mock.SetAutoAck(false)
+	pending := make(chan error, 1)
+	go func() { pending <- client.SetModel(context.Background(), "haiku") }()
+
+	cancel()
+
+	select {
+	case err := <-aborted:
+		if err != context.Canceled {
+			t.Errorf("Signal error = %v, want context.Canceled", err)
+		}
+	case <-timeout:
+		t.Fatal("callback was not aborted")
+	}
+	select {
+	case err := <-pending:
+		if err == nil {
+			t.Error("pending control request succeeded")
+		}
+	case <-timeout:
+		t.Fatal("pending control request did not fail")
+	}
+}
@@ -0,0 +1,61 @@
+package claudecode
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// imageMediaTypes are the image formats Claude accepts, by file extension
+var imageMediaTypes = map[string]string{
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".gif":  "image/gif",
+	".webp": "image/webp",
+}
+
+// ImageFromFile reads a PNG, JPEG, GIF or WebP file into an image block
+// that can be sent with SendContent. The media type is taken from the file
+// extension, or sniffed from the contents when the extension is unknown.
+//
+// Example:
+//
+//	img, err := claudecode.ImageFromFile("screenshot.png")
+//	if err != nil {
+//	    return err
+//	}
+//	err = client.SendContent(ctx, "default", []claudecode.ContentBlock{
+//	    &claudecode.TextBlock{Text: "What is wrong with this layout?"},
+//	    img,
+//	})
+func ImageFromFile(path string) (*types.ImageBlock, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	mediaType, ok := imageMediaTypes[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		mediaType = http.DetectContentType(data)
+		if !isSupportedImage(mediaType) {
+			return nil, errors.NewValidationError("path", fmt.Sprintf("%s is not a supported image (%s)", path, mediaType))
+		}
+	}
+
+	return types.NewImageBlock(mediaType, data), nil
+}
+
+func isSupportedImage(mediaType string) bool {
+	for _, supported := range imageMediaTypes {
+		if mediaType == supported {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package claudecode
+
+import (
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// interceptedToolPermissions wraps a CanUseTool callback so the CLI never
+// executes tools listed in ClaudeCodeOptions.InterceptedTools. Returns next
+// unchanged when no tools are intercepted.
+func (c *ClaudeSDKClient) interceptedToolPermissions(next types.CanUseTool) types.CanUseTool {
+	if len(c.options.InterceptedTools) == 0 {
+		return next
+	}
+
+	return func(toolName string, input map[string]interface{}, context *types.ToolPermissionContext) (types.PermissionResult, error) {
+		if handler, ok := c.options.InterceptedTools[toolName]; ok {
+			return c.runInterceptedTool(handler, input, context), nil
+		}
+
+		if next != nil {
+			return next(toolName, input, context)
+		}
+		return &types.PermissionResultAllow{Behavior: types.PermissionBehaviorAllow}, nil
+	}
+}
+
+// runInterceptedTool runs an intercepted tool's handler while the CLI waits
+// for permission. The tool use is denied with the handler's output as the
+// message: Claude sees a denied tool use whose error text is the output,
+// not a tool result.
+func (c *ClaudeSDKClient) runInterceptedTool(handler types.HostToolHandler, input map[string]interface{}, permission *types.ToolPermissionContext) types.PermissionResult {
+	ctx := c.ctx
+	if permission != nil && permission.Signal != nil {
+		ctx = permission.Signal
+	}
+
+	content, err := handler(ctx, input)
+	if err != nil {
+		content = err.Error()
+	}
+	return &types.PermissionResultDeny{
+		Behavior: types.PermissionBehaviorDeny,
+		Message:  content,
+	}
+}
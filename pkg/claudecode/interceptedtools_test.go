@@ -0,0 +1,115 @@
+package claudecode
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/transporttest"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+func TestInterceptedToolPermissions(t *testing.T) {
+	var forwarded []string
+	options := types.NewOptions().WithCanUseTool(func(toolName string, input map[string]interface{}, ctx *types.ToolPermissionContext) (types.PermissionResult, error) {
+		forwarded = append(forwarded, toolName)
+		return &types.PermissionResultAllow{Behavior: types.PermissionBehaviorAllow}, nil
+	})
+	options.InterceptedTools = map[string]types.HostToolHandler{
+		"query_db": func(ctx context.Context, input map[string]interface{}) (string, error) {
+			return fmt.Sprintf("rows for %v", input["sql"]), nil
+		},
+		"deploy": func(ctx context.Context, input map[string]interface{}) (string, error) {
+			return "", fmt.Errorf("deploys are frozen")
+		},
+	}
+	client := NewClaudeSDKClient(options)
+	canUseTool := client.interceptedToolPermissions(options.CanUseTool)
+
+	// Intercepted tools answer with their output and never reach the callback
+	result, err := canUseTool("query_db", map[string]interface{}{"sql": "select 1"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deny, ok := result.(*types.PermissionResultDeny); !ok || deny.Message != "rows for select 1" {
+		t.Errorf("query_db = %#v", result)
+	}
+
+	result, _ = canUseTool("deploy", nil, nil)
+	if deny, ok := result.(*types.PermissionResultDeny); !ok || deny.Message != "deploys are frozen" {
+		t.Errorf("deploy = %#v", result)
+	}
+
+	// Other tools go to the callback
+	result, _ = canUseTool("Read", nil, nil)
+	if _, ok := result.(*types.PermissionResultAllow); !ok {
+		t.Errorf("Read = %#v", result)
+	}
+	if len(forwarded) != 1 || forwarded[0] != "Read" {
+		t.Errorf("forwarded %v, want only Read", forwarded)
+	}
+
+	// Without intercepted tools the callback is used as is
+	if canUseTool := NewClaudeSDKClient(nil).interceptedToolPermissions(nil); canUseTool != nil {
+		t.Error("expected no callback without intercepted tools")
+	}
+}
+
+func TestRunInterceptedTool(t *testing.T) {
+	options := types.NewOptions()
+	handled := make(chan context.Context, 1)
+	options.InterceptedTools = map[string]types.HostToolHandler{
+		"query_db": func(ctx context.Context, input map[string]interface{}) (string, error) {
+			handled <- ctx
+			return "42 rows", nil
+		},
+	}
+	mock := transporttest.NewMockTransport()
+	client := NewClaudeSDKClientWithTransport(options, mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	mock.Emit(map[string]interface{}{
+		"type":       "control_request",
+		"request_id": "cli_1",
+		"request": map[string]interface{}{
+			"subtype":     "can_use_tool",
+			"tool_name":   "query_db",
+			"tool_use_id": "toolu_1",
+			"input":       map[string]interface{}{"sql": "select 1"},
+		},
+	})
+
+	select {
+	case handlerCtx := <-handled:
+		if handlerCtx == nil {
+			t.Error("handler got no context")
+		}
+	case <-ctx.Done():
+		t.Fatal("intercepted tool not run")
+	}
+
+	// The output is the permission response; no tool_result is injected
+	var response map[string]interface{}
+	for response == nil && ctx.Err() == nil {
+		for _, msg := range mock.WrittenMessages() {
+			if msg["type"] == "user" {
+				t.Fatalf("unexpected user message %v", msg)
+			}
+			if body, ok := msg["response"].(map[string]interface{}); ok && body["request_id"] == "cli_1" {
+				response, _ = body["response"].(map[string]interface{})
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if response["behavior"] != "deny" || response["message"] != "42 rows" {
+		t.Errorf("permission response = %v", response)
+	}
+}
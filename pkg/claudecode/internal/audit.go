@@ -0,0 +1,62 @@
+package internal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// newAuditRecord starts the audit record of a can_use_tool request. Time
+// keeps its monotonic reading until the decision is recorded.
+func newAuditRecord(requestID, toolName string, input map[string]interface{}, request map[string]interface{}) *types.PermissionAuditRecord {
+	record := &types.PermissionAuditRecord{
+		Time:      time.Now(),
+		RequestID: requestID,
+		ToolName:  toolName,
+		InputHash: hashToolInput(input),
+	}
+	record.ToolUseID, _ = request["tool_use_id"].(string)
+	return record
+}
+
+// hashToolInput returns the hex SHA-256 of the JSON-encoded input. Map
+// keys are encoded in sorted order, so equal inputs hash alike.
+func hashToolInput(input map[string]interface{}) string {
+	data, _ := json.Marshal(input)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// decide completes a record with the decision sent to the CLI. A nil
+// record is ignored.
+func decide(record *types.PermissionAuditRecord, decision types.PermissionDecision, reason string) {
+	if record == nil {
+		return
+	}
+	record.Decision = decision
+	record.Reason = reason
+	record.DurationMS = time.Since(record.Time).Milliseconds()
+}
+
+// auditResult completes a record from the result of the CanUseTool
+// callback
+func auditResult(record *types.PermissionAuditRecord, result types.PermissionResult) {
+	if record == nil {
+		return
+	}
+
+	switch r := result.(type) {
+	case *types.PermissionResultAllow:
+		decide(record, types.PermissionDecisionAllow, "")
+		record.InputUpdated = r.UpdatedInput != nil
+		record.UpdatedPermissions = r.UpdatedPermissions
+	case *types.PermissionResultDeny:
+		decide(record, types.PermissionDecisionDeny, r.Message)
+		record.Interrupt = r.Interrupt
+	default:
+		decide(record, types.PermissionDecisionAllow, "")
+	}
+}
@@ -0,0 +1,293 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/internal/ids"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// defaultControlTimeout bounds how long an outbound control request waits
+// for its control_response
+const defaultControlTimeout = 60 * time.Second
+
+// controlResult is the outcome of an outbound control request
+type controlResult struct {
+	response map[string]interface{}
+	err      error
+}
+
+// pendingRequest is an outbound control request awaiting its response
+type pendingRequest struct {
+	subtype string
+	result  chan controlResult
+}
+
+// SetControlTimeout sets how long outbound control requests wait for a
+// response. A timeout <= 0 waits until the caller's context is done.
+func (q *Query) SetControlTimeout(timeout time.Duration) {
+	q.controlTimeout = timeout
+}
+
+// request sends a control request and waits for the matching response.
+// The wait is bounded by ctx and the control timeout.
+func (q *Query) request(ctx context.Context, subtype string, request interface{}) (map[string]interface{}, error) {
+	if q.controlTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, q.controlTimeout)
+		defer cancel()
+	}
+
+	requestID := ids.NewRequestID()
+	pending := &pendingRequest{
+		subtype: subtype,
+		result:  make(chan controlResult, 1),
+	}
+
+	q.pendingMu.Lock()
+	q.pending[requestID] = pending
+	q.pendingMu.Unlock()
+
+	defer func() {
+		q.pendingMu.Lock()
+		delete(q.pending, requestID)
+		q.pendingMu.Unlock()
+	}()
+
+	logger := q.logger.With("subtype", subtype, "request_id", requestID)
+	logger.Debug("sending control request")
+	start := time.Now()
+
+	done := func(error) {}
+	if q.telemetry != nil {
+		ctx, done = q.telemetry.ControlRequest(ctx, subtype, requestID)
+	}
+
+	err := q.sendControlRequest(types.SDKControlRequest{
+		Type:      "control_request",
+		RequestID: requestID,
+		Request:   request,
+	})
+	if err != nil {
+		logger.Warn("failed to send control request", "error", err)
+		done(err)
+		return nil, err
+	}
+
+	var result controlResult
+	select {
+	case result = <-pending.result:
+	case <-ctx.Done():
+		result.err = ctx.Err()
+	case <-q.ctx.Done():
+		result.err = errors.NewCLIConnectionError("query stopped while waiting for control response", nil)
+	}
+
+	if result.err != nil {
+		logger.Warn("control request failed", "error", result.err, "duration", time.Since(start))
+	} else {
+		logger.Debug("control request completed", "duration", time.Since(start))
+	}
+	done(result.err)
+	return result.response, result.err
+}
+
+// requestInto sends a control request and decodes the response into out.
+// A nil out discards the response.
+func (q *Query) requestInto(ctx context.Context, subtype string, request interface{}, out interface{}) error {
+	response, err := q.request(ctx, subtype, request)
+	if err != nil || out == nil {
+		return err
+	}
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return errors.NewMessageParseError("failed to decode "+subtype+" control response", response)
+	}
+	return nil
+}
+
+// handleControlResponse delivers a control_response to the waiting request
+func (q *Query) handleControlResponse(data map[string]interface{}) {
+	response, _ := data["response"].(map[string]interface{})
+	requestID, _ := response["request_id"].(string)
+
+	q.pendingMu.Lock()
+	pending, ok := q.pending[requestID]
+	q.pendingMu.Unlock()
+	if !ok {
+		return
+	}
+
+	var result controlResult
+	if subtype, _ := response["subtype"].(string); subtype == "error" {
+		message, _ := response["error"].(string)
+		result.err = errors.NewControlRequestError(requestID, pending.subtype, message)
+	} else {
+		result.response, _ = response["response"].(map[string]interface{})
+	}
+
+	// Buffered; the waiter may already have given up
+	select {
+	case pending.result <- result:
+	default:
+	}
+}
+
+// failPending fails every outstanding control request with err
+func (q *Query) failPending(err error) {
+	q.pendingMu.Lock()
+	defer q.pendingMu.Unlock()
+
+	for _, pending := range q.pending {
+		select {
+		case pending.result <- controlResult{err: err}:
+		default:
+		}
+	}
+}
+
+// callbackContext returns the context passed to the callback handling an
+// inbound control request. release must be called once it is handled.
+func (q *Query) callbackContext(requestID string) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(q.ctx)
+
+	q.inflightMu.Lock()
+	q.inflight[requestID] = cancel
+	q.inflightMu.Unlock()
+
+	return ctx, func() {
+		q.inflightMu.Lock()
+		delete(q.inflight, requestID)
+		q.inflightMu.Unlock()
+		cancel()
+	}
+}
+
+// CallbacksRunning reports whether callbacks handling inbound control
+// requests are in progress, during which the CLI waits without writing
+func (q *Query) CallbacksRunning() bool {
+	q.inflightMu.Lock()
+	defer q.inflightMu.Unlock()
+
+	return len(q.inflight) > 0
+}
+
+// cancelCallback aborts the callback handling one inbound control request
+func (q *Query) cancelCallback(requestID string) {
+	q.inflightMu.Lock()
+	cancel, ok := q.inflight[requestID]
+	q.inflightMu.Unlock()
+
+	if ok {
+		cancel()
+	}
+}
+
+// cancelCallbacks aborts every running callback
+func (q *Query) cancelCallbacks() {
+	q.inflightMu.Lock()
+	defer q.inflightMu.Unlock()
+
+	for _, cancel := range q.inflight {
+		cancel()
+	}
+}
+
+// SetCallbackTimeout bounds how long CanUseTool and hook callbacks may run.
+// A timeout <= 0 lets them run until they return. Must be called before Start.
+func (q *Query) SetCallbackTimeout(timeout time.Duration) {
+	q.callbackTimeout = timeout
+}
+
+// withCallbackTimeout applies the callback timeout to ctx
+func (q *Query) withCallbackTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if q.callbackTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, q.callbackTimeout)
+}
+
+// runCallback runs fn and reports whether it returned before ctx's deadline.
+// A cancelled ctx is not a timeout: the callback is expected to notice its
+// signal and return.
+func (q *Query) runCallback(ctx context.Context, fn func()) bool {
+	if q.callbackTimeout <= 0 {
+		fn()
+		return true
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn()
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		if ctx.Err() != context.DeadlineExceeded {
+			<-done
+			return true
+		}
+		return false
+	}
+}
+
+// callbackTimedOut reports that a callback exceeded the callback timeout
+func (q *Query) callbackTimedOut(requestID string, err error) {
+	q.logger.Warn("callback timed out", "request_id", requestID, "error", err)
+	q.reportError(err)
+}
+
+// reportError delivers err on the error channel unless the query is
+// stopping. Returns false if it is.
+func (q *Query) reportError(err error) bool {
+	q.errorsMu.RLock()
+	defer q.errorsMu.RUnlock()
+
+	// Stop cancels before closing the channel
+	if q.ctx.Err() != nil {
+		return false
+	}
+	select {
+	case q.errors <- err:
+		return true
+	case <-q.ctx.Done():
+		return false
+	}
+}
+
+// hookRoute is the event and matcher a hook callback was registered for
+type hookRoute struct {
+	event   types.HookEvent
+	pattern *types.HookPattern
+}
+
+// matches reports whether a hook callback applies to input. Events without a
+// matchable field always apply.
+func (r hookRoute) matches(input map[string]interface{}) bool {
+	if r.pattern == nil {
+		return true
+	}
+
+	var field string
+	switch r.event {
+	case types.HookEventPreToolUse, types.HookEventPostToolUse:
+		field = "tool_name"
+	case types.HookEventPreCompact:
+		field = "trigger"
+	default:
+		return true
+	}
+
+	name, ok := input[field].(string)
+	return !ok || r.pattern.Match(name)
+}
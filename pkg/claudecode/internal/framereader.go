@@ -0,0 +1,58 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// utf8BOM is the byte order mark some writers put before a line
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// frameReader splits the CLI's output into JSON frames. The CLI writes one
+// object per line, but some versions write several concatenated objects on
+// one line, end lines with CRLF or start them with a byte order mark.
+// Lines are framed by a lineReader, so the size limit applies per line and
+// a malformed line never swallows the lines after it.
+type frameReader struct {
+	lines *lineReader
+
+	// The line being split and its decoder, nil between lines
+	line []byte
+	dec  *json.Decoder
+}
+
+// newFrameReader creates a frameReader with the given line size limit
+func newFrameReader(r io.Reader, maxSize int) *frameReader {
+	return &frameReader{lines: newLineReader(r, maxSize)}
+}
+
+// Next returns the next frame. Blank lines are skipped. Text that is not
+// valid JSON is returned as a single frame up to the end of its line, so
+// the caller reports it and carries on with the next line.
+func (f *frameReader) Next() ([]byte, error) {
+	for {
+		if f.dec == nil {
+			line, err := f.lines.ReadLine()
+			if err != nil {
+				return nil, err
+			}
+			f.line = bytes.TrimPrefix(line, utf8BOM)
+			f.dec = json.NewDecoder(bytes.NewReader(f.line))
+		}
+
+		start := f.dec.InputOffset()
+		var frame json.RawMessage
+		err := f.dec.Decode(&frame)
+		if err == nil {
+			return frame, nil
+		}
+
+		rest := bytes.TrimSpace(f.line[start:])
+		f.line, f.dec = nil, nil
+		if err == io.EOF || len(rest) == 0 {
+			continue
+		}
+		return rest, nil
+	}
+}
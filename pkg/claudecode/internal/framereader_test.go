@@ -0,0 +1,84 @@
+package internal
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// readFrames returns every frame of r as a string
+func readFrames(t *testing.T, r io.Reader) []string {
+	t.Helper()
+
+	f := newFrameReader(r, 0)
+	var frames []string
+	for {
+		frame, err := f.Next()
+		if err == io.EOF {
+			return frames
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		frames = append(frames, string(frame))
+	}
+}
+
+func TestFrameReaderTranscripts(t *testing.T) {
+	want := []string{
+		types.MessageTypeSystem,
+		types.MessageTypeAssistant,
+		types.MessageTypeAssistant,
+		types.MessageTypeUser,
+		types.MessageTypeAssistant,
+		types.MessageTypeResult,
+	}
+
+	var reference []string
+	for _, name := range []string{"stream_json.jsonl", "stream_json_crlf.jsonl", "stream_json_concatenated.jsonl"} {
+		file, err := os.Open("testdata/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		frames := readFrames(t, file)
+		file.Close()
+
+		var got []string
+		for _, frame := range frames {
+			var data map[string]interface{}
+			if err := json.Unmarshal([]byte(frame), &data); err != nil {
+				t.Fatalf("%s: frame does not decode: %v\n%s", name, err, frame)
+			}
+			msgType, _ := data["type"].(string)
+			got = append(got, msgType)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: message types = %v, want %v", name, got, want)
+		}
+
+		// Every variant yields the frames of the plain transcript
+		if reference == nil {
+			reference = frames
+		} else if !reflect.DeepEqual(frames, reference) {
+			t.Errorf("%s: frames differ from stream_json.jsonl", name)
+		}
+	}
+}
+
+func TestFrameReaderMalformed(t *testing.T) {
+	input := "\xEF\xBB\xBF{\"a\":1} {not json\r\n" +
+		"   \n" +
+		"{\"b\":2}{\"c\":\r\n" +
+		"\xEF\xBB\xBF{\"d\":4}"
+
+	got := readFrames(t, strings.NewReader(input))
+	want := []string{`{"a":1}`, `{not json`, `{"b":2}`, `{"c":`, `{"d":4}`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("frames = %q, want %q", got, want)
+	}
+}
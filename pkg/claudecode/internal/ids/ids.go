@@ -0,0 +1,27 @@
+// Package ids generates the identifiers the SDK puts on the wire. They are
+// random rather than counted, so IDs from several queries or processes
+// never collide when their logs are correlated with the CLI's.
+package ids
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// RequestPrefix starts every control request ID issued by the SDK, telling
+// them apart from the CLI's own requests in logs
+const RequestPrefix = "req_sdk_"
+
+// NewUUID returns a random (version 4) UUID
+func NewUUID() string {
+	var b [16]byte
+	rand.Read(b[:]) // Never fails; the runtime aborts instead
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// NewRequestID returns an ID for an outbound control request
+func NewRequestID() string {
+	return RequestPrefix + NewUUID()
+}
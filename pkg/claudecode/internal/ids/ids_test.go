@@ -0,0 +1,39 @@
+package ids
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var uuidV4 = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestNewRequestID(t *testing.T) {
+	const n = 1000
+	var mu sync.Mutex
+	seen := make(map[string]bool, n)
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id := NewRequestID()
+
+			mu.Lock()
+			defer mu.Unlock()
+			if seen[id] {
+				t.Errorf("duplicate request ID %s", id)
+			}
+			seen[id] = true
+		}()
+	}
+	wg.Wait()
+
+	for id := range seen {
+		if !strings.HasPrefix(id, RequestPrefix) || !uuidV4.MatchString(strings.TrimPrefix(id, RequestPrefix)) {
+			t.Fatalf("malformed request ID %q", id)
+		}
+	}
+}
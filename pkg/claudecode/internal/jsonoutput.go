@@ -0,0 +1,52 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+)
+
+// ReadJSONOutput reads what the CLI prints with --output-format json: the
+// result object, or an array of every message when it runs with --verbose.
+// Output larger than maxSize (<= 0 uses the 16MB default) is drained and
+// reported as a BufferOverflowError. Empty output returns no messages.
+// Output within the limit is copied to raw, if set, before it is decoded.
+func ReadJSONOutput(r io.Reader, maxSize int, raw io.Writer) ([]map[string]interface{}, error) {
+	if maxSize <= 0 {
+		maxSize = defaultMaxLineSize
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, int64(maxSize)+1))
+	if err != nil {
+		return nil, errors.NewCLIConnectionError("error reading from transport", err)
+	}
+	if len(data) > maxSize {
+		// Drain the rest so the CLI is not blocked writing it
+		rest, _ := io.Copy(io.Discard, r)
+		return nil, errors.NewBufferOverflowError(maxSize, len(data)+int(rest))
+	}
+
+	if raw != nil && len(data) > 0 {
+		raw.Write(data)
+	}
+
+	data = bytes.TrimSpace(bytes.TrimPrefix(data, utf8BOM))
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var messages []map[string]interface{}
+	if data[0] == '[' {
+		err = json.Unmarshal(data, &messages)
+	} else {
+		var message map[string]interface{}
+		err = json.Unmarshal(data, &message)
+		messages = append(messages, message)
+	}
+	if err != nil {
+		return nil, errors.NewJSONDecodeError("failed to decode JSON output", string(data), err)
+	}
+	return messages, nil
+}
@@ -0,0 +1,44 @@
+package internal
+
+import (
+	stderrors "errors"
+	"strings"
+	"testing"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+)
+
+func TestReadJSONOutput(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		types []string
+	}{
+		{name: "result", input: "\xEF\xBB\xBF{\"type\":\"result\"}\r\n", types: []string{"result"}},
+		{name: "verbose array", input: `[{"type":"system"},{"type":"assistant"},{"type":"result"}]`, types: []string{"system", "assistant", "result"}},
+		{name: "empty", input: "\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages, err := ReadJSONOutput(strings.NewReader(tt.input), 0, nil)
+			if err != nil {
+				t.Fatalf("ReadJSONOutput: %v", err)
+			}
+			var got []string
+			for _, msg := range messages {
+				got = append(got, msg["type"].(string))
+			}
+			if strings.Join(got, ",") != strings.Join(tt.types, ",") {
+				t.Errorf("types = %v, want %v", got, tt.types)
+			}
+		})
+	}
+
+	if _, err := ReadJSONOutput(strings.NewReader(`{"type":`), 0, nil); !stderrors.Is(err, errors.ErrJSONDecode) {
+		t.Errorf("truncated output error = %v, want ErrJSONDecode", err)
+	}
+	if _, err := ReadJSONOutput(strings.NewReader(`{"type":"result"}`), 8, nil); !stderrors.Is(err, errors.ErrBufferOverflow) {
+		t.Errorf("oversized output error = %v, want ErrBufferOverflow", err)
+	}
+}
@@ -0,0 +1,94 @@
+package internal
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+)
+
+// defaultMaxLineSize is the largest JSON message accepted from the CLI
+const defaultMaxLineSize = 16 * 1024 * 1024 // 16MB
+
+// lineReader reads newline-delimited JSON frames. Lines longer than the
+// internal buffer are accumulated across reads; lines longer than maxSize
+// are skipped and reported as a BufferOverflowError.
+type lineReader struct {
+	reader  *bufio.Reader
+	maxSize int
+
+	// Receives every line within maxSize as read, if set
+	raw io.Writer
+}
+
+// newLineReader creates a lineReader with the given size limit
+func newLineReader(r io.Reader, maxSize int) *lineReader {
+	if maxSize <= 0 {
+		maxSize = defaultMaxLineSize
+	}
+	return &lineReader{
+		reader:  bufio.NewReaderSize(r, 64*1024),
+		maxSize: maxSize,
+	}
+}
+
+// ReadLine returns the next line without its trailing newline. A final
+// line without a newline is returned at EOF. After a BufferOverflowError
+// the reader is positioned at the start of the next line.
+func (l *lineReader) ReadLine() ([]byte, error) {
+	var line []byte
+	size := 0
+	overflow := false
+
+	for {
+		chunk, err := l.reader.ReadSlice('\n')
+		size += len(chunk)
+
+		if !overflow {
+			if size > l.maxSize {
+				overflow = true
+				line = nil
+			} else {
+				line = append(line, chunk...)
+			}
+		}
+
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+
+		if overflow {
+			return nil, errors.NewBufferOverflowError(l.maxSize, size)
+		}
+
+		if err != nil {
+			if err == io.EOF && len(line) > 0 {
+				l.tee(line)
+				return trimNewline(line), nil
+			}
+			return nil, err
+		}
+
+		l.tee(line)
+		return trimNewline(line), nil
+	}
+}
+
+// tee copies a line to the raw writer. A failing writer must not break
+// the protocol, so its errors are dropped.
+func (l *lineReader) tee(line []byte) {
+	if l.raw != nil {
+		l.raw.Write(line)
+	}
+}
+
+// trimNewline strips a trailing \n or \r\n
+func trimNewline(line []byte) []byte {
+	if n := len(line); n > 0 && line[n-1] == '\n' {
+		line = line[:n-1]
+	}
+	if n := len(line); n > 0 && line[n-1] == '\r' {
+		line = line[:n-1]
+	}
+	return line
+}
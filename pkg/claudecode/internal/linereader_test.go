@@ -0,0 +1,62 @@
+package internal
+
+import (
+	stderrors "errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+)
+
+func TestLineReader(t *testing.T) {
+	long := strings.Repeat("x", 200*1024)
+	input := `{"a":1}` + "\n" + `{"b":"` + long + `"}` + "\n" + strings.Repeat("y", 64) + "\n" + `{"c":3}`
+
+	r := newLineReader(strings.NewReader(input), 300*1024)
+
+	line, err := r.ReadLine()
+	if err != nil || string(line) != `{"a":1}` {
+		t.Fatalf("Unexpected first line: %q, %v", line, err)
+	}
+
+	// Longer than the internal buffer but within the limit
+	line, err = r.ReadLine()
+	if err != nil || len(line) != len(long)+8 {
+		t.Fatalf("Expected long line of %d bytes, got %d, %v", len(long)+8, len(line), err)
+	}
+
+	r.maxSize = 32
+	_, err = r.ReadLine()
+	if !stderrors.Is(err, errors.ErrBufferOverflow) {
+		t.Fatalf("Expected buffer overflow, got %v", err)
+	}
+
+	// Final line without a trailing newline
+	line, err = r.ReadLine()
+	if err != nil || string(line) != `{"c":3}` {
+		t.Fatalf("Unexpected last line: %q, %v", line, err)
+	}
+
+	if _, err = r.ReadLine(); err != io.EOF {
+		t.Errorf("Expected EOF, got %v", err)
+	}
+}
+
+func TestLineReaderRawOutput(t *testing.T) {
+	input := "{\"a\":1}\r\n" + strings.Repeat("x", 64) + "\n{\"b\":2}"
+
+	var raw strings.Builder
+	r := newLineReader(strings.NewReader(input), 32)
+	r.raw = &raw
+	for {
+		if _, err := r.ReadLine(); err == io.EOF {
+			break
+		}
+	}
+
+	// Lines keep their endings; the oversized line is not copied
+	if want := "{\"a\":1}\r\n{\"b\":2}"; raw.String() != want {
+		t.Errorf("raw output = %q, want %q", raw.String(), want)
+	}
+}
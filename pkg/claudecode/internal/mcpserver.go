@@ -0,0 +1,192 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// mcpProtocolVersion is the MCP protocol revision spoken by SDK servers
+const mcpProtocolVersion = "2024-11-05"
+
+// JSON-RPC error codes
+const (
+	jsonRPCMethodNotFound = -32601
+	jsonRPCInvalidParams  = -32602
+)
+
+// MCPMessageHandler is implemented by in-process MCP server instances.
+// HandleMCPMessage returns nil for notifications, which get no response.
+type MCPMessageHandler interface {
+	HandleMCPMessage(ctx context.Context, message map[string]interface{}) map[string]interface{}
+}
+
+// SDKMCPTool is a tool served by an SDKMCPServer
+type SDKMCPTool struct {
+	Name        string
+	Description string
+	InputSchema map[string]interface{}
+	Handler     types.MCPToolHandler
+}
+
+// SDKMCPServer is an in-process MCP server answering JSON-RPC messages
+// relayed by the CLI over the control protocol
+type SDKMCPServer struct {
+	name    string
+	version string
+
+	tools []*SDKMCPTool
+	mu    sync.RWMutex
+}
+
+// NewSDKMCPServer creates an in-process MCP server
+func NewSDKMCPServer(name, version string, tools ...*SDKMCPTool) *SDKMCPServer {
+	s := &SDKMCPServer{
+		name:    name,
+		version: version,
+	}
+	for _, tool := range tools {
+		s.AddTool(tool)
+	}
+	return s
+}
+
+// Name returns the server name
+func (s *SDKMCPServer) Name() string {
+	return s.name
+}
+
+// AddTool registers a tool, replacing any tool with the same name
+func (s *SDKMCPServer) AddTool(tool *SDKMCPTool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, existing := range s.tools {
+		if existing.Name == tool.Name {
+			s.tools[i] = tool
+			return
+		}
+	}
+	s.tools = append(s.tools, tool)
+}
+
+// Tools returns the registered tools
+func (s *SDKMCPServer) Tools() []*SDKMCPTool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tools := make([]*SDKMCPTool, len(s.tools))
+	copy(tools, s.tools)
+	return tools
+}
+
+// tool looks up a tool by name
+func (s *SDKMCPServer) tool(name string) *SDKMCPTool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, tool := range s.tools {
+		if tool.Name == name {
+			return tool
+		}
+	}
+	return nil
+}
+
+// HandleMCPMessage processes a JSON-RPC request and returns the response,
+// or nil for a notification such as notifications/initialized
+func (s *SDKMCPServer) HandleMCPMessage(ctx context.Context, message map[string]interface{}) map[string]interface{} {
+	id := message["id"]
+	method, _ := message["method"].(string)
+	params, _ := message["params"].(map[string]interface{})
+
+	if strings.HasPrefix(method, "notifications/") {
+		return nil
+	}
+
+	switch method {
+	case "initialize":
+		return jsonRPCResult(id, map[string]interface{}{
+			"protocolVersion": mcpProtocolVersion,
+			"capabilities": map[string]interface{}{
+				"tools": map[string]interface{}{},
+			},
+			"serverInfo": map[string]interface{}{
+				"name":    s.name,
+				"version": s.version,
+			},
+		})
+	case "tools/list":
+		tools := []interface{}{}
+		for _, tool := range s.Tools() {
+			schema := tool.InputSchema
+			if schema == nil {
+				schema = map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+			}
+			tools = append(tools, map[string]interface{}{
+				"name":        tool.Name,
+				"description": tool.Description,
+				"inputSchema": schema,
+			})
+		}
+		return jsonRPCResult(id, map[string]interface{}{"tools": tools})
+	case "tools/call":
+		return s.callTool(ctx, id, params)
+	default:
+		return jsonRPCError(id, jsonRPCMethodNotFound, fmt.Sprintf("method not found: %s", method))
+	}
+}
+
+// callTool invokes a tool handler
+func (s *SDKMCPServer) callTool(ctx context.Context, id interface{}, params map[string]interface{}) map[string]interface{} {
+	name, _ := params["name"].(string)
+	tool := s.tool(name)
+	if tool == nil {
+		return jsonRPCError(id, jsonRPCInvalidParams, fmt.Sprintf("tool not found: %s", name))
+	}
+
+	args, _ := params["arguments"].(map[string]interface{})
+	if args == nil {
+		args = make(map[string]interface{})
+	}
+
+	result, err := tool.Handler(ctx, args)
+	if err != nil {
+		result = &types.MCPToolResult{
+			Content: []types.MCPContent{{Type: "text", Text: err.Error()}},
+			IsError: true,
+		}
+	}
+	if result == nil {
+		result = &types.MCPToolResult{}
+	}
+	if result.Content == nil {
+		result.Content = []types.MCPContent{}
+	}
+
+	return jsonRPCResult(id, result)
+}
+
+// jsonRPCResult builds a JSON-RPC success response
+func jsonRPCResult(id interface{}, result interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"result":  result,
+	}
+}
+
+// jsonRPCError builds a JSON-RPC error response
+func jsonRPCError(id interface{}, code int, message string) map[string]interface{} {
+	return map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"error": map[string]interface{}{
+			"code":    code,
+			"message": message,
+		},
+	}
+}
@@ -0,0 +1,56 @@
+package internal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+func TestSDKMCPServer(t *testing.T) {
+	server := NewSDKMCPServer("calc", "1.0.0", &SDKMCPTool{
+		Name:        "add",
+		Description: "Add two numbers",
+		Handler: func(ctx context.Context, args map[string]interface{}) (*types.MCPToolResult, error) {
+			sum := args["a"].(float64) + args["b"].(float64)
+			if sum != 3 {
+				t.Errorf("Expected sum 3, got %v", sum)
+			}
+			return &types.MCPToolResult{Content: []types.MCPContent{{Type: "text", Text: "3"}}}, nil
+		},
+	})
+	ctx := context.Background()
+
+	resp := server.HandleMCPMessage(ctx, map[string]interface{}{"jsonrpc": "2.0", "id": float64(1), "method": "tools/list"})
+	result, ok := resp["result"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected result, got %+v", resp)
+	}
+	if tools := result["tools"].([]interface{}); len(tools) != 1 {
+		t.Errorf("Expected 1 tool, got %d", len(tools))
+	}
+
+	resp = server.HandleMCPMessage(ctx, map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      float64(2),
+		"method":  "tools/call",
+		"params": map[string]interface{}{
+			"name":      "add",
+			"arguments": map[string]interface{}{"a": float64(1), "b": float64(2)},
+		},
+	})
+	callResult, ok := resp["result"].(*types.MCPToolResult)
+	if !ok || len(callResult.Content) != 1 || callResult.Content[0].Text != "3" {
+		t.Errorf("Unexpected tool call response: %+v", resp)
+	}
+
+	resp = server.HandleMCPMessage(ctx, map[string]interface{}{"jsonrpc": "2.0", "id": float64(3), "method": "resources/list"})
+	if _, ok := resp["error"]; !ok {
+		t.Errorf("Expected error for unknown method, got %+v", resp)
+	}
+
+	resp = server.HandleMCPMessage(ctx, map[string]interface{}{"jsonrpc": "2.0", "method": "notifications/initialized"})
+	if resp != nil {
+		t.Errorf("Expected no response to a notification, got %+v", resp)
+	}
+}
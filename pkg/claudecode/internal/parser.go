@@ -3,35 +3,117 @@ package internal
 import (
 	"encoding/json"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
 	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
 )
 
-// ParseMessage parses a raw message into the appropriate typed message
+// MessageParserFunc parses a raw message of a registered type
+type MessageParserFunc func(data map[string]interface{}) (types.Message, error)
+
+var (
+	messageParsers   = make(map[string]MessageParserFunc)
+	messageParsersMu sync.RWMutex
+)
+
+// RegisterMessageParser registers a parser for a top-level message type.
+// Registered parsers take precedence over the built-in ones, which allows
+// newer CLI message formats to be handled without changing the SDK.
+// Passing a nil parser removes the registration.
+func RegisterMessageParser(msgType string, parser MessageParserFunc) {
+	messageParsersMu.Lock()
+	defer messageParsersMu.Unlock()
+
+	if parser == nil {
+		delete(messageParsers, msgType)
+		return
+	}
+	messageParsers[msgType] = parser
+}
+
+// ParseOptions controls how strictly ParseMessageWith treats CLI output
+type ParseOptions struct {
+	// Fail on message types without a built-in or registered parser instead
+	// of returning them as a *types.CustomMessage
+	StrictTypes bool
+
+	// Fail on missing optional fields, such as an assistant message's model
+	// or a thinking block's signature, and on unknown content blocks. When
+	// false these are returned as warnings and the blocks are skipped.
+	StrictFields bool
+}
+
+// ParseMessage parses a raw message into the appropriate typed message.
+// Types without a built-in or registered parser, e.g. ones added by a newer
+// CLI, are returned as a *types.CustomMessage.
 func ParseMessage(data map[string]interface{}) (types.Message, error) {
+	msg, _, err := ParseMessageWith(data, ParseOptions{StrictFields: true})
+	return msg, err
+}
+
+// ParseMessageWith parses a raw message like ParseMessage with the given
+// strictness. The warnings describe what lenient parsing tolerated.
+func ParseMessageWith(data map[string]interface{}, options ParseOptions) (types.Message, []string, error) {
+	s := &parseState{strict: options.StrictFields}
+	msg, err := parseMessage(data, options.StrictTypes, s)
+	if err != nil {
+		return nil, nil, err
+	}
+	return msg, s.warnings, nil
+}
+
+// parseState carries the strictness of a parse and what it tolerated
+type parseState struct {
+	strict   bool
+	warnings []string
+}
+
+// tolerate returns a parse error for problem in strict mode and records it
+// as a warning otherwise
+func (s *parseState) tolerate(problem string, data interface{}) error {
+	if s.strict {
+		return errors.NewMessageParseError(problem, data)
+	}
+	s.warnings = append(s.warnings, problem)
+	return nil
+}
+
+func parseMessage(data map[string]interface{}, strict bool, s *parseState) (types.Message, error) {
 	msgType, ok := data["type"].(string)
 	if !ok {
 		return nil, errors.NewMessageParseError("message missing 'type' field", data)
 	}
 
+	messageParsersMu.RLock()
+	parser, registered := messageParsers[msgType]
+	messageParsersMu.RUnlock()
+
+	if registered {
+		return parser(data)
+	}
+
 	switch msgType {
 	case types.MessageTypeUser:
-		return parseUserMessage(data)
+		return parseUserMessage(data, s)
 	case types.MessageTypeAssistant:
-		return parseAssistantMessage(data)
+		return parseAssistantMessage(data, s)
 	case types.MessageTypeSystem:
 		return parseSystemMessage(data)
 	case types.MessageTypeResult:
-		return parseResultMessage(data)
-	case types.MessageTypeStream:
-		return parseStreamEvent(data)
+		return parseResultMessage(data, s)
+	case types.MessageTypeStream, types.MessageTypeStreamEvent:
+		return parseStreamEvent(data, s)
 	default:
-		return nil, errors.NewMessageParseError(fmt.Sprintf("unknown message type: %s", msgType), data)
+		if strict {
+			return nil, errors.NewMessageParseError(fmt.Sprintf("unknown message type: %s", msgType), data)
+		}
+		return &types.CustomMessage{Type: msgType, Data: data}, nil
 	}
 }
 
-func parseUserMessage(data map[string]interface{}) (*types.UserMessage, error) {
+func parseUserMessage(data map[string]interface{}, s *parseState) (*types.UserMessage, error) {
 	msg := &types.UserMessage{}
 
 	// Parse content - can be string or array of content blocks
@@ -43,11 +125,13 @@ func parseUserMessage(data map[string]interface{}) (*types.UserMessage, error) {
 			blocks := make([]types.ContentBlock, 0, len(v))
 			for _, block := range v {
 				if blockMap, ok := block.(map[string]interface{}); ok {
-					parsed, err := parseContentBlock(blockMap)
+					parsed, err := parseContentBlock(blockMap, s)
 					if err != nil {
 						return nil, err
 					}
-					blocks = append(blocks, parsed)
+					if parsed != nil {
+						blocks = append(blocks, parsed)
+					}
 				}
 			}
 			msg.Content = blocks
@@ -61,17 +145,20 @@ func parseUserMessage(data map[string]interface{}) (*types.UserMessage, error) {
 		msg.ParentToolUseID = &parentID
 	}
 
+	msg.SessionID, _ = data["session_id"].(string)
+	msg.UUID, msg.Timestamp = parseMessageIdentity(data)
+
 	return msg, nil
 }
 
-func parseAssistantMessage(data map[string]interface{}) (*types.AssistantMessage, error) {
+func parseAssistantMessage(data map[string]interface{}, s *parseState) (*types.AssistantMessage, error) {
 	msg := &types.AssistantMessage{}
 
 	// Parse model
 	if model, ok := data["model"].(string); ok {
 		msg.Model = model
-	} else {
-		return nil, errors.NewMessageParseError("assistant message missing 'model' field", data)
+	} else if err := s.tolerate("assistant message missing 'model' field", data); err != nil {
+		return nil, err
 	}
 
 	// Parse content blocks
@@ -79,11 +166,13 @@ func parseAssistantMessage(data map[string]interface{}) (*types.AssistantMessage
 		blocks := make([]types.ContentBlock, 0, len(content))
 		for _, block := range content {
 			if blockMap, ok := block.(map[string]interface{}); ok {
-				parsed, err := parseContentBlock(blockMap)
+				parsed, err := parseContentBlock(blockMap, s)
 				if err != nil {
 					return nil, err
 				}
-				blocks = append(blocks, parsed)
+				if parsed != nil {
+					blocks = append(blocks, parsed)
+				}
 			}
 		}
 		msg.Content = blocks
@@ -96,10 +185,13 @@ func parseAssistantMessage(data map[string]interface{}) (*types.AssistantMessage
 		msg.ParentToolUseID = &parentID
 	}
 
+	msg.SessionID, _ = data["session_id"].(string)
+	msg.UUID, msg.Timestamp = parseMessageIdentity(data)
+
 	return msg, nil
 }
 
-func parseSystemMessage(data map[string]interface{}) (*types.SystemMessage, error) {
+func parseSystemMessage(data map[string]interface{}) (types.Message, error) {
 	msg := &types.SystemMessage{}
 
 	// Parse subtype
@@ -116,10 +208,125 @@ func parseSystemMessage(data map[string]interface{}) (*types.SystemMessage, erro
 		msg.Data = make(map[string]interface{})
 	}
 
+	msg.UUID, msg.Timestamp = parseMessageIdentity(data)
+
+	switch msg.Subtype {
+	case types.SystemSubtypeInit:
+		return parseInitMessage(msg, data), nil
+	case types.SystemSubtypeCompactBoundary:
+		return parseCompactBoundary(msg, data), nil
+	case types.SystemSubtypeError:
+		return parseErrorMessage(msg, data), nil
+	case types.SystemSubtypeModelFallback:
+		return parseModelFallback(msg, data), nil
+	}
+
 	return msg, nil
 }
 
-func parseResultMessage(data map[string]interface{}) (*types.ResultMessage, error) {
+func parseInitMessage(msg *types.SystemMessage, data map[string]interface{}) *types.InitMessage {
+	init := &types.InitMessage{SystemMessage: *msg}
+
+	// The CLI sends init fields at the top level; fall back to data
+	fields := data
+	if _, ok := fields["tools"]; !ok && len(msg.Data) > 0 {
+		fields = msg.Data
+	}
+
+	init.SessionID, _ = fields["session_id"].(string)
+	init.CWD, _ = fields["cwd"].(string)
+	init.Model, _ = fields["model"].(string)
+	init.OutputStyle, _ = fields["output_style"].(string)
+	init.APIKeySource, _ = fields["apiKeySource"].(string)
+	if mode, ok := fields["permissionMode"].(string); ok {
+		init.PermissionMode = types.PermissionMode(mode)
+	}
+
+	init.Tools = getStringSlice(fields, "tools")
+	init.Commands = getStringSlice(fields, "slash_commands")
+
+	if servers, ok := fields["mcp_servers"].([]interface{}); ok {
+		for _, server := range servers {
+			serverMap, ok := server.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			status := types.MCPServerStatus{}
+			status.Name, _ = serverMap["name"].(string)
+			status.Status, _ = serverMap["status"].(string)
+			init.MCPServers = append(init.MCPServers, status)
+		}
+	}
+
+	return init
+}
+
+func parseCompactBoundary(msg *types.SystemMessage, data map[string]interface{}) *types.CompactBoundaryMessage {
+	boundary := &types.CompactBoundaryMessage{SystemMessage: *msg}
+
+	// The CLI sends compact_metadata at the top level; fall back to data
+	metadata, ok := data["compact_metadata"].(map[string]interface{})
+	if !ok {
+		metadata, _ = msg.Data["compact_metadata"].(map[string]interface{})
+	}
+
+	if trigger, ok := metadata["trigger"].(string); ok {
+		boundary.Trigger = trigger
+	}
+	boundary.PreTokens = getIntField(metadata, "pre_tokens", 0)
+
+	return boundary
+}
+
+func parseErrorMessage(msg *types.SystemMessage, data map[string]interface{}) *types.ErrorMessage {
+	errMsg := &types.ErrorMessage{SystemMessage: *msg}
+
+	// Fields may be at the top level or in data; the error is either a
+	// string or an API error object
+	for _, fields := range []map[string]interface{}{data, msg.Data} {
+		if errMsg.SessionID == "" {
+			errMsg.SessionID, _ = fields["session_id"].(string)
+		}
+		if errMsg.Error != "" {
+			continue
+		}
+		switch e := fields["error"].(type) {
+		case string:
+			errMsg.Error = e
+		case map[string]interface{}:
+			errMsg.Error, _ = e["message"].(string)
+		}
+		if errMsg.Error == "" {
+			errMsg.Error, _ = fields["message"].(string)
+		}
+	}
+
+	return errMsg
+}
+
+func parseModelFallback(msg *types.SystemMessage, data map[string]interface{}) *types.ModelFallbackMessage {
+	fallback := &types.ModelFallbackMessage{SystemMessage: *msg}
+
+	// Fields may be at the top level or in data
+	first := func(keys ...string) string {
+		for _, fields := range []map[string]interface{}{data, msg.Data} {
+			for _, key := range keys {
+				if value, ok := fields[key].(string); ok && value != "" {
+					return value
+				}
+			}
+		}
+		return ""
+	}
+	fallback.FromModel = first("from_model", "original_model")
+	fallback.ToModel = first("to_model", "fallback_model", "model")
+	fallback.Reason = first("reason", "message")
+	fallback.SessionID = first("session_id")
+
+	return fallback
+}
+
+func parseResultMessage(data map[string]interface{}, s *parseState) (*types.ResultMessage, error) {
 	msg := &types.ResultMessage{}
 
 	// Parse required fields
@@ -142,8 +349,8 @@ func parseResultMessage(data map[string]interface{}) (*types.ResultMessage, erro
 	// Parse session_id
 	if sessionID, ok := data["session_id"].(string); ok {
 		msg.SessionID = sessionID
-	} else {
-		return nil, errors.NewMessageParseError("result message missing 'session_id' field", data)
+	} else if err := s.tolerate("result message missing 'session_id' field", data); err != nil {
+		return nil, err
 	}
 
 	// Parse optional fields
@@ -152,30 +359,67 @@ func parseResultMessage(data map[string]interface{}) (*types.ResultMessage, erro
 	}
 
 	if usage, ok := data["usage"].(map[string]interface{}); ok {
-		msg.Usage = usage
+		msg.Usage = parseUsage(usage, data["modelUsage"])
 	}
 
 	if result, ok := data["result"].(string); ok {
 		msg.Result = &result
 	}
 
+	msg.UUID, msg.Timestamp = parseMessageIdentity(data)
+
 	return msg, nil
 }
 
-func parseStreamEvent(data map[string]interface{}) (*types.StreamEvent, error) {
+func parseUsage(usage map[string]interface{}, modelUsage interface{}) *types.Usage {
+	parsed := &types.Usage{
+		InputTokens:              getIntField(usage, "input_tokens", 0),
+		OutputTokens:             getIntField(usage, "output_tokens", 0),
+		CacheCreationInputTokens: getIntField(usage, "cache_creation_input_tokens", 0),
+		CacheReadInputTokens:     getIntField(usage, "cache_read_input_tokens", 0),
+		Raw:                      usage,
+	}
+	parsed.ServiceTier, _ = usage["service_tier"].(string)
+
+	models, ok := modelUsage.(map[string]interface{})
+	if !ok {
+		return parsed
+	}
+
+	parsed.ByModel = make(map[string]types.ModelUsage, len(models))
+	for model, raw := range models {
+		m, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		mu := types.ModelUsage{
+			InputTokens:              getIntField(m, "inputTokens", 0),
+			OutputTokens:             getIntField(m, "outputTokens", 0),
+			CacheCreationInputTokens: getIntField(m, "cacheCreationInputTokens", 0),
+			CacheReadInputTokens:     getIntField(m, "cacheReadInputTokens", 0),
+			WebSearchRequests:        getIntField(m, "webSearchRequests", 0),
+		}
+		mu.CostUSD, _ = m["costUSD"].(float64)
+		parsed.ByModel[model] = mu
+	}
+
+	return parsed
+}
+
+func parseStreamEvent(data map[string]interface{}, s *parseState) (*types.StreamEvent, error) {
 	msg := &types.StreamEvent{}
 
 	// Parse required fields
 	if uuid, ok := data["uuid"].(string); ok {
 		msg.UUID = uuid
-	} else {
-		return nil, errors.NewMessageParseError("stream event missing 'uuid' field", data)
+	} else if err := s.tolerate("stream event missing 'uuid' field", data); err != nil {
+		return nil, err
 	}
 
 	if sessionID, ok := data["session_id"].(string); ok {
 		msg.SessionID = sessionID
-	} else {
-		return nil, errors.NewMessageParseError("stream event missing 'session_id' field", data)
+	} else if err := s.tolerate("stream event missing 'session_id' field", data); err != nil {
+		return nil, err
 	}
 
 	if event, ok := data["event"].(map[string]interface{}); ok {
@@ -189,22 +433,44 @@ func parseStreamEvent(data map[string]interface{}) (*types.StreamEvent, error) {
 		msg.ParentToolUseID = &parentID
 	}
 
+	_, msg.Timestamp = parseMessageIdentity(data)
+
 	return msg, nil
 }
 
-func parseContentBlock(data map[string]interface{}) (types.ContentBlock, error) {
+// parseMessageIdentity returns the uuid and timestamp the CLI attaches to a
+// message. A missing or malformed timestamp is returned as the zero time.
+func parseMessageIdentity(data map[string]interface{}) (string, time.Time) {
+	uuid, _ := data["uuid"].(string)
+
+	var timestamp time.Time
+	switch v := data["timestamp"].(type) {
+	case string:
+		timestamp, _ = time.Parse(time.RFC3339Nano, v)
+	case float64:
+		// Milliseconds since the epoch
+		timestamp = time.UnixMilli(int64(v)).UTC()
+	}
+	return uuid, timestamp
+}
+
+// parseContentBlock returns nil for an unknown block skipped in lenient mode
+func parseContentBlock(data map[string]interface{}, s *parseState) (types.ContentBlock, error) {
 	// Determine block type
 	if _, ok := data["text"]; ok {
 		return parseTextBlock(data)
 	} else if _, ok := data["thinking"]; ok {
-		return parseThinkingBlock(data)
+		return parseThinkingBlock(data, s)
 	} else if _, ok := data["name"]; ok {
 		return parseToolUseBlock(data)
 	} else if _, ok := data["tool_use_id"]; ok {
 		return parseToolResultBlock(data)
+	} else if data["type"] == "image" {
+		return parseImageBlock(data)
 	}
 
-	return nil, errors.NewMessageParseError("unknown content block type", data)
+	blockType, _ := data["type"].(string)
+	return nil, s.tolerate(fmt.Sprintf("unknown content block type %q", blockType), data)
 }
 
 func parseTextBlock(data map[string]interface{}) (*types.TextBlock, error) {
@@ -219,7 +485,7 @@ func parseTextBlock(data map[string]interface{}) (*types.TextBlock, error) {
 	return block, nil
 }
 
-func parseThinkingBlock(data map[string]interface{}) (*types.ThinkingBlock, error) {
+func parseThinkingBlock(data map[string]interface{}, s *parseState) (*types.ThinkingBlock, error) {
 	block := &types.ThinkingBlock{}
 
 	if thinking, ok := data["thinking"].(string); ok {
@@ -230,8 +496,8 @@ func parseThinkingBlock(data map[string]interface{}) (*types.ThinkingBlock, erro
 
 	if signature, ok := data["signature"].(string); ok {
 		block.Signature = signature
-	} else {
-		return nil, errors.NewMessageParseError("thinking block missing 'signature' field", data)
+	} else if err := s.tolerate("thinking block missing 'signature' field", data); err != nil {
+		return nil, err
 	}
 
 	return block, nil
@@ -282,6 +548,21 @@ func parseToolResultBlock(data map[string]interface{}) (*types.ToolResultBlock,
 	return block, nil
 }
 
+func parseImageBlock(data map[string]interface{}) (*types.ImageBlock, error) {
+	block := &types.ImageBlock{}
+
+	source, ok := data["source"].(map[string]interface{})
+	if !ok {
+		return nil, errors.NewMessageParseError("image block missing 'source' field", data)
+	}
+
+	block.Source.Type, _ = source["type"].(string)
+	block.Source.MediaType, _ = source["media_type"].(string)
+	block.Source.Data, _ = source["data"].(string)
+
+	return block, nil
+}
+
 // Helper function to get int field with type conversion
 func getIntField(data map[string]interface{}, key string, defaultVal int) int {
 	if val, ok := data[key]; ok {
@@ -298,3 +579,69 @@ func getIntField(data map[string]interface{}, key string, defaultVal int) int {
 	}
 	return defaultVal
 }
+
+// Helper function to get a string slice field
+func getStringSlice(data map[string]interface{}, key string) []string {
+	values, ok := data[key].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if str, ok := v.(string); ok {
+			result = append(result, str)
+		}
+	}
+	return result
+}
+
+// parsePermissionUpdate decodes a permission suggestion sent with can_use_tool
+func parsePermissionUpdate(data map[string]interface{}) types.PermissionUpdate {
+	update := types.PermissionUpdate{}
+
+	if typ, ok := data["type"].(string); ok {
+		update.Type = types.PermissionUpdateType(typ)
+	}
+
+	if rules, ok := data["rules"].([]interface{}); ok {
+		for _, r := range rules {
+			ruleMap, ok := r.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			rule := types.PermissionRuleValue{}
+			rule.ToolName = getStringField(ruleMap, "toolName", "tool_name")
+			if content := getStringField(ruleMap, "ruleContent", "rule_content"); content != "" {
+				rule.RuleContent = &content
+			}
+			update.Rules = append(update.Rules, rule)
+		}
+	}
+
+	if behavior, ok := data["behavior"].(string); ok {
+		b := types.PermissionBehavior(behavior)
+		update.Behavior = &b
+	}
+	if mode, ok := data["mode"].(string); ok {
+		m := types.PermissionMode(mode)
+		update.Mode = &m
+	}
+	update.Directories = getStringSlice(data, "directories")
+	if destination, ok := data["destination"].(string); ok {
+		d := types.PermissionUpdateDestination(destination)
+		update.Destination = &d
+	}
+
+	return update
+}
+
+// Helper function to get the first present string field among keys
+func getStringField(data map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		if val, ok := data[key].(string); ok {
+			return val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,360 @@
+package internal
+
+import (
+	stderrors "errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+func TestParseInitMessage(t *testing.T) {
+	data := map[string]interface{}{
+		"type":           "system",
+		"subtype":        "init",
+		"session_id":     "abc",
+		"model":          "claude-sonnet-4",
+		"permissionMode": "plan",
+		"tools":          []interface{}{"Read", "Bash"},
+		"slash_commands": []interface{}{"compact"},
+		"mcp_servers": []interface{}{
+			map[string]interface{}{"name": "db", "status": "connected"},
+		},
+	}
+
+	msg, err := ParseMessage(data)
+	if err != nil {
+		t.Fatalf("Failed to parse init message: %v", err)
+	}
+
+	init, ok := msg.(*types.InitMessage)
+	if !ok {
+		t.Fatalf("Expected *types.InitMessage, got %T", msg)
+	}
+
+	if init.SessionID != "abc" || init.Model != "claude-sonnet-4" {
+		t.Errorf("Unexpected session or model: %s, %s", init.SessionID, init.Model)
+	}
+	if init.PermissionMode != types.PermissionModePlan {
+		t.Errorf("Expected permission mode plan, got %s", init.PermissionMode)
+	}
+	if !init.HasTool("Bash") || init.HasTool("Write") {
+		t.Errorf("Unexpected tools: %v", init.Tools)
+	}
+	if !init.HasCommand("compact") {
+		t.Errorf("Expected compact command, got %v", init.Commands)
+	}
+	if len(init.MCPServers) != 1 || init.MCPServers[0].Status != "connected" {
+		t.Errorf("Unexpected MCP servers: %+v", init.MCPServers)
+	}
+}
+
+func TestParseCompactBoundary(t *testing.T) {
+	data := map[string]interface{}{
+		"type":    "system",
+		"subtype": "compact_boundary",
+		"compact_metadata": map[string]interface{}{
+			"trigger":    "auto",
+			"pre_tokens": float64(120000),
+		},
+	}
+
+	msg, err := ParseMessage(data)
+	if err != nil {
+		t.Fatalf("Failed to parse compact boundary: %v", err)
+	}
+
+	boundary, ok := msg.(*types.CompactBoundaryMessage)
+	if !ok {
+		t.Fatalf("Expected *types.CompactBoundaryMessage, got %T", msg)
+	}
+	if boundary.Trigger != types.CompactTriggerAuto || boundary.PreTokens != 120000 {
+		t.Errorf("Unexpected compact metadata: %+v", boundary)
+	}
+}
+
+func TestParseSystemError(t *testing.T) {
+	tests := []map[string]interface{}{
+		{"type": "system", "subtype": "error", "session_id": "s1", "error": "overloaded"},
+		{"type": "system", "subtype": "error", "session_id": "s1",
+			"error": map[string]interface{}{"type": "overloaded_error", "message": "overloaded"}},
+		{"type": "system", "subtype": "error", "data": map[string]interface{}{"session_id": "s1", "message": "overloaded"}},
+	}
+
+	for _, data := range tests {
+		msg, err := ParseMessage(data)
+		if err != nil {
+			t.Fatalf("Failed to parse %v: %v", data, err)
+		}
+		errMsg, ok := msg.(*types.ErrorMessage)
+		if !ok {
+			t.Fatalf("Expected *types.ErrorMessage, got %T", msg)
+		}
+		if errMsg.Error != "overloaded" || errMsg.SessionID != "s1" {
+			t.Errorf("Unexpected error message from %v: %+v", data, errMsg)
+		}
+	}
+
+	// Unknown subtypes stay generic
+	msg, err := ParseMessage(map[string]interface{}{"type": "system", "subtype": "status"})
+	if err != nil {
+		t.Fatalf("Failed to parse status: %v", err)
+	}
+	if _, ok := msg.(*types.SystemMessage); !ok {
+		t.Errorf("Expected *types.SystemMessage, got %T", msg)
+	}
+}
+
+func TestParseModelFallback(t *testing.T) {
+	msg, err := ParseMessage(map[string]interface{}{
+		"type":       "system",
+		"subtype":    "model_fallback",
+		"session_id": "s1",
+		"from_model": "claude-opus-4",
+		"to_model":   "claude-sonnet-4",
+		"reason":     "overloaded",
+	})
+	if err != nil {
+		t.Fatalf("ParseMessage failed: %v", err)
+	}
+
+	fallback, ok := msg.(*types.ModelFallbackMessage)
+	if !ok {
+		t.Fatalf("Expected *ModelFallbackMessage, got %T", msg)
+	}
+	if fallback.FromModel != "claude-opus-4" || fallback.ToModel != "claude-sonnet-4" || fallback.Reason != "overloaded" || fallback.SessionID != "s1" {
+		t.Errorf("Unexpected fallback: %+v", fallback)
+	}
+}
+
+func TestParseStreamEvent(t *testing.T) {
+	data := map[string]interface{}{
+		"type":       "stream_event",
+		"uuid":       "u1",
+		"session_id": "s1",
+		"event": map[string]interface{}{
+			"type":  "content_block_delta",
+			"index": float64(0),
+			"delta": map[string]interface{}{"type": "text_delta", "text": "Hi"},
+		},
+	}
+
+	msg, err := ParseMessage(data)
+	if err != nil {
+		t.Fatalf("Failed to parse stream event: %v", err)
+	}
+	ev, ok := msg.(*types.StreamEvent)
+	if !ok {
+		t.Fatalf("Expected *types.StreamEvent, got %T", msg)
+	}
+
+	event, err := ev.DecodeEvent()
+	if err != nil {
+		t.Fatalf("DecodeEvent: %v", err)
+	}
+	if delta, ok := event.(*types.ContentBlockDeltaEvent); !ok || delta.Delta.Text != "Hi" {
+		t.Errorf("Unexpected event: %#v", event)
+	}
+}
+
+func TestParseMessageIdentity(t *testing.T) {
+	want := time.Date(2025, 6, 1, 12, 30, 0, 123000000, time.UTC)
+	frames := []map[string]interface{}{
+		{"type": "user", "content": "hi"},
+		{"type": "assistant", "model": "sonnet", "content": []interface{}{}},
+		{"type": "system", "subtype": "init"},
+		{"type": "system", "subtype": "status"},
+		{"type": "result", "subtype": "success", "session_id": "s1"},
+	}
+
+	for _, data := range frames {
+		data["uuid"] = "u1"
+		data["timestamp"] = "2025-06-01T12:30:00.123Z"
+
+		msg, err := ParseMessage(data)
+		if err != nil {
+			t.Fatalf("Failed to parse %v: %v", data, err)
+		}
+
+		var uuid string
+		var timestamp time.Time
+		switch m := msg.(type) {
+		case *types.UserMessage:
+			uuid, timestamp = m.UUID, m.Timestamp
+		case *types.AssistantMessage:
+			uuid, timestamp = m.UUID, m.Timestamp
+		case types.AnySystemMessage:
+			uuid, timestamp = m.System().UUID, m.System().Timestamp
+		case *types.ResultMessage:
+			uuid, timestamp = m.UUID, m.Timestamp
+		}
+		if uuid != "u1" || !timestamp.Equal(want) {
+			t.Errorf("%T: uuid = %q, timestamp = %v", msg, uuid, timestamp)
+		}
+	}
+
+	// Epoch milliseconds are accepted, malformed timestamps ignored
+	msg, _ := ParseMessage(map[string]interface{}{"type": "user", "content": "hi", "timestamp": float64(want.UnixMilli())})
+	if got := msg.(*types.UserMessage).Timestamp; !got.Equal(want) {
+		t.Errorf("timestamp from epoch milliseconds = %v", got)
+	}
+	msg, err := ParseMessage(map[string]interface{}{"type": "user", "content": "hi", "timestamp": "yesterday"})
+	if err != nil || !msg.(*types.UserMessage).Timestamp.IsZero() {
+		t.Errorf("malformed timestamp: %v, %v", msg, err)
+	}
+}
+
+func TestParsePermissionUpdate(t *testing.T) {
+	data := map[string]interface{}{
+		"type": "addRules",
+		"rules": []interface{}{
+			map[string]interface{}{"toolName": "Bash", "ruleContent": "npm test:*"},
+			map[string]interface{}{"tool_name": "Read"},
+		},
+		"behavior":    "allow",
+		"destination": "localSettings",
+	}
+
+	update := parsePermissionUpdate(data)
+	if update.Type != types.PermissionUpdateAddRules {
+		t.Errorf("Expected addRules, got %s", update.Type)
+	}
+	if len(update.Rules) != 2 || update.Rules[0].ToolName != "Bash" || update.Rules[1].ToolName != "Read" {
+		t.Fatalf("Unexpected rules: %+v", update.Rules)
+	}
+	if update.Rules[0].RuleContent == nil || *update.Rules[0].RuleContent != "npm test:*" {
+		t.Errorf("Unexpected rule content: %v", update.Rules[0].RuleContent)
+	}
+	if update.Rules[1].RuleContent != nil {
+		t.Errorf("Expected no rule content, got %q", *update.Rules[1].RuleContent)
+	}
+	if update.Behavior == nil || *update.Behavior != types.PermissionBehaviorAllow {
+		t.Errorf("Unexpected behavior: %v", update.Behavior)
+	}
+	if update.Destination == nil || *update.Destination != types.PermissionDestinationLocalSettings {
+		t.Errorf("Unexpected destination: %v", update.Destination)
+	}
+
+	dirs := parsePermissionUpdate(map[string]interface{}{
+		"type":        "addDirectories",
+		"directories": []interface{}{"/tmp", "/var"},
+	})
+	if len(dirs.Directories) != 2 || dirs.Behavior != nil {
+		t.Errorf("Unexpected directories update: %+v", dirs)
+	}
+
+	mode := parsePermissionUpdate(map[string]interface{}{"type": "setMode", "mode": "acceptEdits"})
+	if mode.Mode == nil || *mode.Mode != types.PermissionModeAcceptEdits {
+		t.Errorf("Unexpected mode: %v", mode.Mode)
+	}
+}
+
+func TestParseImageBlock(t *testing.T) {
+	image := map[string]interface{}{
+		"type":   "image",
+		"source": map[string]interface{}{"type": "base64", "media_type": "image/png", "data": "aGk="},
+	}
+	msg, err := ParseMessage(map[string]interface{}{
+		"type": "user",
+		"content": []interface{}{
+			image,
+			map[string]interface{}{"type": "tool_result", "tool_use_id": "toolu_1", "content": []interface{}{image}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ParseMessage: %v", err)
+	}
+
+	blocks := msg.(*types.UserMessage).Content.([]types.ContentBlock)
+	block, ok := blocks[0].(*types.ImageBlock)
+	if !ok || block.Source.MediaType != "image/png" || block.Source.Data != "aGk=" {
+		t.Fatalf("unexpected image block: %#v", blocks[0])
+	}
+
+	images := blocks[1].(*types.ToolResultBlock).Images()
+	if len(images) != 1 || images[0].Source != block.Source {
+		t.Errorf("unexpected tool result images: %#v", images)
+	}
+}
+
+func TestParseUnknownMessageType(t *testing.T) {
+	data := map[string]interface{}{"type": "tool_progress", "tool_use_id": "toolu_1"}
+
+	msg, err := ParseMessage(data)
+	if err != nil {
+		t.Fatalf("ParseMessage: %v", err)
+	}
+	custom, ok := msg.(*types.CustomMessage)
+	if !ok || custom.GetType() != "tool_progress" || custom.Data["tool_use_id"] != "toolu_1" {
+		t.Errorf("unexpected message %#v", msg)
+	}
+
+	if _, _, err := ParseMessageWith(data, ParseOptions{StrictTypes: true}); !stderrors.Is(err, errors.ErrMessageParse) {
+		t.Errorf("strict types error = %v, want ErrMessageParse", err)
+	}
+
+	// A registered parser handles the type in both modes
+	RegisterMessageParser("tool_progress", func(data map[string]interface{}) (types.Message, error) {
+		return &types.CustomMessage{Type: "progress"}, nil
+	})
+	defer RegisterMessageParser("tool_progress", nil)
+	if msg, _, err := ParseMessageWith(data, ParseOptions{StrictTypes: true}); err != nil || msg.GetType() != "progress" {
+		t.Errorf("registered parser: %v, %v", msg, err)
+	}
+}
+
+func TestParseLenient(t *testing.T) {
+	data := map[string]interface{}{
+		"type": "assistant",
+		"content": []interface{}{
+			map[string]interface{}{"type": "thinking", "thinking": "Let me see"},
+			map[string]interface{}{"type": "server_tool_use", "id": "srvtoolu_1"},
+			map[string]interface{}{"type": "text", "text": "Done"},
+		},
+	}
+
+	if _, err := ParseMessage(data); !stderrors.Is(err, errors.ErrMessageParse) {
+		t.Errorf("ParseMessage error = %v, want ErrMessageParse", err)
+	}
+
+	msg, warnings, err := ParseMessageWith(data, ParseOptions{})
+	if err != nil {
+		t.Fatalf("ParseMessageWith: %v", err)
+	}
+	want := []string{
+		"assistant message missing 'model' field",
+		"thinking block missing 'signature' field",
+		`unknown content block type "server_tool_use"`,
+	}
+	if !reflect.DeepEqual(warnings, want) {
+		t.Errorf("warnings = %q, want %q", warnings, want)
+	}
+	assistant := msg.(*types.AssistantMessage)
+	if len(assistant.Content) != 2 || assistant.Text() != "Done" || !assistant.HasThinking() {
+		t.Errorf("unexpected content %+v", assistant.Content)
+	}
+
+	// Required fields fail in both modes
+	if _, _, err := ParseMessageWith(map[string]interface{}{"type": "result"}, ParseOptions{}); !stderrors.Is(err, errors.ErrMessageParse) {
+		t.Errorf("result without subtype: %v, want ErrMessageParse", err)
+	}
+}
+
+func TestAnySystemMessage(t *testing.T) {
+	for _, subtype := range []string{"init", "compact_boundary", "error", "model_fallback", "status"} {
+		msg, err := ParseMessage(map[string]interface{}{"type": "system", "subtype": subtype})
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", subtype, err)
+		}
+		system, ok := msg.(types.AnySystemMessage)
+		if !ok {
+			t.Errorf("%s: %T is not an AnySystemMessage", subtype, msg)
+			continue
+		}
+		if system.System().Subtype != subtype {
+			t.Errorf("%s: System().Subtype = %q", subtype, system.System().Subtype)
+		}
+	}
+}
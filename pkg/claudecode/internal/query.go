@@ -1,50 +1,94 @@
 package internal
 
 import (
-	"bufio"
 	"context"
 	"encoding/json"
+	stderrors "errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"sync"
+	"time"
 
 	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/internal/ids"
 	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport"
 	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
 )
 
+// permissionQueueSize is how many can_use_tool requests wait for a worker
+// before further ones are denied
+const permissionQueueSize = 100
+
+// stopResponseTimeout bounds the responses Stop sends to requests it
+// abandons
+const stopResponseTimeout = time.Second
+
 // Query handles the control protocol and message processing
 type Query struct {
 	transport       transport.Transport
 	isStreamingMode bool
 	canUseTool      types.CanUseTool
+	audit           func(record *types.PermissionAuditRecord)
 	hooks           map[types.HookEvent][]types.HookMatcher
 	sdkMCPServers   map[string]interface{} // SDK MCP server instances
 
-	reader *bufio.Reader
-	ctx    context.Context
-	cancel context.CancelFunc
+	reader      *frameReader
+	maxLineSize int
+	rawOutput   io.Writer
+	ctx         context.Context
+	cancel      context.CancelFunc
+	done        chan struct{} // Closed when the read loop exits
 
 	// Channel for messages
-	messages chan map[string]interface{}
-	errors   chan error
+	messages   chan map[string]interface{}
+	errors     chan error
+	raw        chan<- json.RawMessage // Optional tap of inbound frames
+	rawDropped func()                 // Called for every frame raw had no room for
+
+	// Held for reading while sending on errors, which control handlers do
+	// from goroutines Stop does not wait for. Senders give up once ctx is
+	// done, so Stop can take it to close the channel.
+	errorsMu sync.RWMutex
+
+	// Permission request queue, nil when callbacks run unbounded
+	permissionQueue   chan map[string]interface{}
+	permissionWorkers int
+
+	// Outbound control requests awaiting a control_response, by request ID
+	pending        map[string]*pendingRequest
+	pendingMu      sync.Mutex
+	controlTimeout time.Duration
+	logger         *slog.Logger
+	telemetry      types.ConversationTelemetry
+
+	// Inbound control requests being handled, cancelled on abort
+	inflight        map[string]context.CancelFunc
+	inflightMu      sync.Mutex
+	callbackTimeout time.Duration
 
 	// Control state
 	initialized   bool
+	serverInfo    map[string]interface{}
 	hookCallbacks map[string]types.HookCallback
+	hookRoutes    map[string]hookRoute // Matcher of each hook callback
 	mu            sync.RWMutex
 	wg            sync.WaitGroup
+	stopOnce      sync.Once
 }
 
-// NewQuery creates a new Query handler
+// NewQuery creates a new Query handler. Cancelling ctx ends the read loop
+// at the next message, fails pending control requests and signals running
+// callbacks; Stop must still be called to release the query.
 func NewQuery(
+	ctx context.Context,
 	transport transport.Transport,
 	isStreamingMode bool,
 	canUseTool types.CanUseTool,
 	hooks map[types.HookEvent][]types.HookMatcher,
 	sdkMCPServers map[string]interface{},
 ) *Query {
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(ctx)
 
 	return &Query{
 		transport:       transport,
@@ -54,16 +98,86 @@ func NewQuery(
 		sdkMCPServers:   sdkMCPServers,
 		ctx:             ctx,
 		cancel:          cancel,
+		done:            make(chan struct{}),
 		messages:        make(chan map[string]interface{}, 100),
 		errors:          make(chan error, 10),
 		hookCallbacks:   make(map[string]types.HookCallback),
+		hookRoutes:      make(map[string]hookRoute),
+		pending:         make(map[string]*pendingRequest),
+		inflight:        make(map[string]context.CancelFunc),
+		controlTimeout:  defaultControlTimeout,
+		logger:          slog.New(slog.DiscardHandler),
+	}
+}
+
+// SetTelemetry reports outbound control requests to telemetry, if not nil.
+// Must be called before Start.
+func (q *Query) SetTelemetry(telemetry types.ConversationTelemetry) {
+	q.telemetry = telemetry
+}
+
+// SetLogger sets the logger for protocol lines (debug level), control
+// requests and decode failures. Must be called before Start.
+func (q *Query) SetLogger(logger *slog.Logger) {
+	if logger != nil {
+		q.logger = logger
+	}
+}
+
+// SetPermissionConcurrency limits how many can_use_tool requests are handled
+// at once. Requests beyond the limit are queued and dispatched in arrival
+// order, so a limit of 1 presents permission prompts one at a time. When
+// the queue is full further requests are denied, and requests still queued
+// when the query stops are denied too. A limit <= 0 runs every request in
+// its own goroutine. Must be called before Start.
+func (q *Query) SetPermissionConcurrency(limit int) {
+	if limit <= 0 {
+		q.permissionQueue = nil
+		q.permissionWorkers = 0
+		return
 	}
+
+	q.permissionQueue = make(chan map[string]interface{}, permissionQueueSize)
+	q.permissionWorkers = limit
+}
+
+// SetRawTap mirrors every inbound frame to ch before it is parsed. Frames
+// that do not fit in ch are dropped, and onDrop is called for each, so a
+// slow reader never stalls the read loop. Must be called before Start.
+func (q *Query) SetRawTap(ch chan<- json.RawMessage, onDrop func()) {
+	q.raw = ch
+	q.rawDropped = onDrop
+}
+
+// SetMaxLineSize sets the largest message accepted from the transport.
+// A size <= 0 uses the 16MB default. Must be called before Start.
+func (q *Query) SetMaxLineSize(size int) {
+	q.maxLineSize = size
+}
+
+// SetRawOutput sets a writer that receives every line read from the
+// transport before it is parsed. Must be called before Start.
+func (q *Query) SetRawOutput(w io.Writer) {
+	q.rawOutput = w
+}
+
+// SetPermissionAudit sets a function that receives every can_use_tool
+// request with the decision sent back. It is called from the goroutine
+// handling the request. Must be called before Start.
+func (q *Query) SetPermissionAudit(audit func(record *types.PermissionAuditRecord)) {
+	q.audit = audit
 }
 
 // Start begins reading messages from the transport
 func (q *Query) Start() error {
 	if q.reader == nil {
-		q.reader = bufio.NewReader(q.transport.Reader())
+		q.reader = newFrameReader(q.transport.Reader(), q.maxLineSize)
+		q.reader.lines.raw = q.rawOutput
+	}
+
+	for i := 0; i < q.permissionWorkers; i++ {
+		q.wg.Add(1)
+		go q.permissionWorker()
 	}
 
 	q.wg.Add(1)
@@ -72,50 +186,124 @@ func (q *Query) Start() error {
 	return nil
 }
 
-// Stop stops the query handler
-func (q *Query) Stop() {
+// Done returns a channel that is closed once the transport stops delivering
+// messages, e.g. because the CLI exited. Messages read before that may still
+// be buffered in ReceiveMessages.
+func (q *Query) Done() <-chan struct{} {
+	return q.done
+}
+
+// Cancel cancels the query and denies the queued permission requests, as
+// the CLI waits for an answer to each. Call it before closing the
+// transport, which must still carry the responses, and Stop after.
+func (q *Query) Cancel() {
 	q.cancel()
-	q.wg.Wait()
-	close(q.messages)
-	close(q.errors)
+	q.drainPermissionQueue()
 }
 
-// Initialize sends the initialization message
+// Stop stops the query handler and closes the message and error channels.
+// Messages and errors already buffered can still be received. It is safe to
+// call more than once and concurrently.
+func (q *Query) Stop() {
+	q.stopOnce.Do(func() {
+		q.cancel()
+
+		// The read loop is the only sender on messages
+		q.wg.Wait()
+		close(q.messages)
+
+		// Requests queued since Cancel, or without it
+		q.drainPermissionQueue()
+
+		q.errorsMu.Lock()
+		close(q.errors)
+		q.errorsMu.Unlock()
+	})
+}
+
+// Initialize performs the initialize handshake in streaming mode: it
+// registers hook callback IDs with the CLI and waits for the response, which
+// carries the server info (commands, output styles, ...)
 func (q *Query) Initialize() error {
 	if q.initialized {
 		return nil
 	}
 
+	// One-shot queries have no control channel
+	if !q.isStreamingMode {
+		q.initialized = true
+		return nil
+	}
+
 	// Build hooks map for initialization
-	hooksMap := make(map[string]interface{})
-	if q.hooks != nil {
-		for event, matchers := range q.hooks {
-			var matchersList []map[string]interface{}
-			for _, matcher := range matchers {
-				// Register callbacks
-				for _, callback := range matcher.Hooks {
-					callbackID := fmt.Sprintf("hook_%s_%d", event, len(q.hookCallbacks))
-					q.mu.Lock()
-					q.hookCallbacks[callbackID] = callback
-					q.mu.Unlock()
-
-					matcherMap := map[string]interface{}{
-						"matcher":     matcher.Matcher,
-						"callback_id": callbackID,
-					}
-					matchersList = append(matchersList, matcherMap)
-				}
+	hooksMap := make(map[types.HookEvent]interface{})
+	for event, matchers := range q.hooks {
+		var matchersList []map[string]interface{}
+		for _, matcher := range matchers {
+			if len(matcher.Hooks) == 0 {
+				continue
+			}
+			pattern, err := types.CompileHookMatcher(matcher.Matcher)
+			if err != nil {
+				return errors.NewValidationError("Hooks", fmt.Sprintf("invalid %s matcher: %v", event, err))
+			}
+
+			// Register callbacks
+			callbackIDs := make([]string, 0, len(matcher.Hooks))
+			q.mu.Lock()
+			for _, callback := range matcher.Hooks {
+				callbackID := fmt.Sprintf("hook_%d", len(q.hookCallbacks))
+				q.hookCallbacks[callbackID] = callback
+				q.hookRoutes[callbackID] = hookRoute{event: event, pattern: pattern}
+				callbackIDs = append(callbackIDs, callbackID)
+			}
+			q.mu.Unlock()
+
+			// Globs are registered compiled, as the CLI does not know them
+			var cliMatcher *string
+			if !pattern.MatchesAll() {
+				expr := pattern.String()
+				cliMatcher = &expr
 			}
-			hooksMap[string(event)] = matchersList
+			matchersList = append(matchersList, map[string]interface{}{
+				"matcher":         cliMatcher,
+				"hookCallbackIds": callbackIDs,
+			})
 		}
+		if len(matchersList) > 0 {
+			hooksMap[event] = matchersList
+		}
+	}
+
+	request := types.SDKControlInitializeRequest{
+		Subtype: string(types.SDKControlInitialize),
+	}
+	if len(hooksMap) > 0 {
+		request.Hooks = hooksMap
 	}
 
-	// Wait for initialization to complete
-	// In streaming mode, we don't send an explicit init message
+	response, err := q.request(q.ctx, string(types.SDKControlInitialize), request)
+	if err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	q.serverInfo = response
+	q.mu.Unlock()
+
 	q.initialized = true
 	return nil
 }
 
+// ServerInfo returns the response to the initialize request, or nil before
+// Initialize has completed
+func (q *Query) ServerInfo() map[string]interface{} {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	return q.serverInfo
+}
+
 // ReceiveMessages returns a channel of received messages
 func (q *Query) ReceiveMessages() <-chan map[string]interface{} {
 	return q.messages
@@ -126,55 +314,138 @@ func (q *Query) Errors() <-chan error {
 	return q.errors
 }
 
-// Interrupt sends an interrupt request
+// Interrupt sends an interrupt request and aborts running callbacks
 func (q *Query) Interrupt() error {
+	q.cancelCallbacks()
+
 	request := types.SDKControlRequest{
 		Type:      "control_request",
-		RequestID: generateRequestID(),
+		RequestID: ids.NewRequestID(),
 		Request: types.SDKControlInterruptRequest{
 			Subtype: "interrupt",
 		},
 	}
 
+	q.logger.Debug("sending control request", "subtype", "interrupt", "request_id", request.RequestID)
 	return q.sendControlRequest(request)
 }
 
+// InterruptAndWait sends an interrupt request and waits for the CLI to
+// acknowledge it. Running callbacks are aborted.
+func (q *Query) InterruptAndWait(ctx context.Context) error {
+	q.cancelCallbacks()
+
+	_, err := q.request(ctx, "interrupt", types.SDKControlInterruptRequest{
+		Subtype: "interrupt",
+	})
+	return err
+}
+
+// SetPermissionMode changes the permission mode of the running session
+func (q *Query) SetPermissionMode(ctx context.Context, mode types.PermissionMode) error {
+	return q.requestInto(ctx, string(types.SDKControlSetPermissionMode), types.SDKControlSetPermissionModeRequest{
+		Subtype: string(types.SDKControlSetPermissionMode),
+		Mode:    string(mode),
+	}, nil)
+}
+
+// SetModel changes the model used for subsequent turns. An empty model
+// resets to the default.
+func (q *Query) SetModel(ctx context.Context, model string) error {
+	request := types.SDKControlSetModelRequest{
+		Subtype: string(types.SDKControlSetModel),
+	}
+	if model != "" {
+		request.Model = &model
+	}
+	return q.requestInto(ctx, string(types.SDKControlSetModel), request, nil)
+}
+
+// UpdatePermissions applies permission updates to the running session
+func (q *Query) UpdatePermissions(ctx context.Context, updates []types.PermissionUpdate) error {
+	return q.requestInto(ctx, string(types.SDKControlUpdatePermissions), types.SDKControlUpdatePermissionsRequest{
+		Subtype: string(types.SDKControlUpdatePermissions),
+		Updates: updates,
+	}, nil)
+}
+
 // readLoop continuously reads messages from the transport
 func (q *Query) readLoop() {
 	defer q.wg.Done()
+	defer close(q.done)
+	defer q.failPending(errors.NewCLIConnectionError("transport closed while waiting for control response", nil))
 
 	for {
 		select {
 		case <-q.ctx.Done():
 			return
 		default:
-			line, err := q.reader.ReadString('\n')
+			raw, err := q.reader.Next()
+			if stderrors.Is(err, errors.ErrBufferOverflow) {
+				// The oversized line was skipped; keep reading
+				q.logger.Warn("skipped oversized message", "error", err)
+				if !q.reportError(err) {
+					return
+				}
+				continue
+			}
 			if err != nil {
 				if err != io.EOF {
-					select {
-					case q.errors <- errors.NewCLIConnectionError("error reading from transport", err):
-					case <-q.ctx.Done():
-					}
+					q.logger.Error("error reading from transport", "error", err)
+					q.reportError(errors.NewCLIConnectionError("error reading from transport", err))
 				}
 				return
 			}
 
-			if line == "" {
-				continue
+			line := string(raw)
+			q.logger.Debug("received line", "line", line)
+
+			if q.raw != nil {
+				select {
+				case q.raw <- json.RawMessage(line):
+				default:
+					q.logger.Debug("raw message tap full, dropped frame")
+					if q.rawDropped != nil {
+						q.rawDropped()
+					}
+				}
 			}
 
 			var data map[string]interface{}
 			if err := json.Unmarshal([]byte(line), &data); err != nil {
-				select {
-				case q.errors <- errors.NewJSONDecodeError("failed to decode message", line, err):
-				case <-q.ctx.Done():
-				}
+				q.logger.Warn("failed to decode message", "error", err, "line", line)
+				q.reportError(errors.NewJSONDecodeError("failed to decode message", line, err))
+				continue
+			}
+
+			msgType, _ := data["type"].(string)
+
+			// Responses to our own control requests
+			if msgType == "control_response" {
+				q.handleControlResponse(data)
+				continue
+			}
+
+			// The CLI abandoned one of its control requests
+			if msgType == "control_cancel_request" {
+				requestID, _ := data["request_id"].(string)
+				q.logger.Debug("control request cancelled", "request_id", requestID)
+				q.cancelCallback(requestID)
 				continue
 			}
 
 			// Check if this is a control request
-			if msgType, ok := data["type"].(string); ok && msgType == "control_request" {
-				go q.handleControlRequest(data)
+			if msgType == "control_request" {
+				if q.permissionQueue != nil && isPermissionRequest(data) {
+					// Waiting for room would stall every other message
+					select {
+					case q.permissionQueue <- data:
+					default:
+						go q.rejectPermission(q.ctx, data, "too many pending permission requests")
+					}
+				} else {
+					go q.handleControlRequest(data)
+				}
 			} else {
 				// Regular message
 				select {
@@ -187,6 +458,83 @@ func (q *Query) readLoop() {
 	}
 }
 
+// permissionWorker handles queued can_use_tool requests in FIFO order
+func (q *Query) permissionWorker() {
+	defer q.wg.Done()
+
+	for {
+		select {
+		case <-q.ctx.Done():
+			return
+		case data := <-q.permissionQueue:
+			if q.ctx.Err() != nil {
+				// Picked over Done; too late to ask
+				q.denyStopped(data)
+				return
+			}
+			q.handleControlRequest(data)
+		}
+	}
+}
+
+// drainPermissionQueue denies the requests left in the permission queue
+// once the query's context is cancelled
+func (q *Query) drainPermissionQueue() {
+	for {
+		select {
+		case data := <-q.permissionQueue:
+			q.denyStopped(data)
+		default:
+			return
+		}
+	}
+}
+
+// denyStopped denies a queued request of a stopping query. The query's
+// context is cancelled, so the response gets a short deadline of its own.
+func (q *Query) denyStopped(data map[string]interface{}) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(q.ctx), stopResponseTimeout)
+	defer cancel()
+	q.rejectPermission(ctx, data, "the query was stopped before the permission request was handled")
+}
+
+// rejectPermission denies a can_use_tool request without asking CanUseTool
+func (q *Query) rejectPermission(ctx context.Context, data map[string]interface{}, message string) {
+	requestID, _ := data["request_id"].(string)
+	request, _ := data["request"].(map[string]interface{})
+	toolName, _ := request["tool_name"].(string)
+	q.logger.Warn("denying permission request", "request_id", requestID, "tool", toolName, "reason", message)
+
+	if q.audit != nil {
+		input, _ := request["input"].(map[string]interface{})
+		record := newAuditRecord(requestID, toolName, input, request)
+		decide(record, types.PermissionDecisionDeny, message)
+		defer q.audit(record)
+	}
+
+	q.sendResponse(ctx, types.SDKControlResponse{
+		Type: "control_response",
+		Response: types.ControlResponse{
+			Subtype:   "success",
+			RequestID: requestID,
+			Response: map[string]interface{}{
+				"behavior": string(types.PermissionBehaviorDeny),
+				"message":  message,
+			},
+		},
+	})
+}
+
+// isPermissionRequest reports whether a control request is a can_use_tool request
+func isPermissionRequest(data map[string]interface{}) bool {
+	request, ok := data["request"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	subtype, _ := request["subtype"].(string)
+	return subtype == "can_use_tool"
+}
+
 // handleControlRequest processes control protocol requests
 func (q *Query) handleControlRequest(data map[string]interface{}) {
 	requestID, _ := data["request_id"].(string)
@@ -197,53 +545,82 @@ func (q *Query) handleControlRequest(data map[string]interface{}) {
 	}
 
 	subtype, _ := request["subtype"].(string)
+	q.logger.Debug("handling control request", "subtype", subtype, "request_id", requestID)
+
+	ctx, release := q.callbackContext(requestID)
+	defer release()
 
 	switch subtype {
 	case "can_use_tool":
-		q.handleCanUseTool(requestID, request)
+		q.handleCanUseTool(ctx, requestID, request)
 	case "hook_callback":
-		q.handleHookCallback(requestID, request)
+		q.handleHookCallback(ctx, requestID, request)
 	case "mcp_message":
-		q.handleMCPMessage(requestID, request)
+		q.handleMCPMessage(ctx, requestID, request)
 	default:
 		q.sendErrorResponse(requestID, fmt.Sprintf("unknown control request subtype: %s", subtype))
 	}
 }
 
 // handleCanUseTool processes tool permission requests
-func (q *Query) handleCanUseTool(requestID string, request map[string]interface{}) {
+func (q *Query) handleCanUseTool(ctx context.Context, requestID string, request map[string]interface{}) {
+	toolName, _ := request["tool_name"].(string)
+	input, _ := request["input"].(map[string]interface{})
+
+	var record *types.PermissionAuditRecord
+	if q.audit != nil {
+		record = newAuditRecord(requestID, toolName, input, request)
+		defer q.audit(record)
+	}
+
 	if q.canUseTool == nil {
+		decide(record, types.PermissionDecisionAllow, "")
 		q.sendSuccessResponse(requestID, map[string]interface{}{
 			"behavior": "allow",
 		})
 		return
 	}
 
-	toolName, _ := request["tool_name"].(string)
-	input, _ := request["input"].(map[string]interface{})
+	ctx, cancel := q.withCallbackTimeout(ctx)
+	defer cancel()
 
 	// Build context
-	ctx := &types.ToolPermissionContext{
+	permCtx := &types.ToolPermissionContext{
+		Signal:      ctx,
 		Suggestions: []types.PermissionUpdate{},
 	}
 
 	// Extract suggestions if present
 	if suggestions, ok := request["permission_suggestions"].([]interface{}); ok {
 		for _, s := range suggestions {
-			if _, ok := s.(map[string]interface{}); ok {
-				// Parse suggestion into PermissionUpdate
-				// TODO: full implementation would parse all fields
-				ctx.Suggestions = append(ctx.Suggestions, types.PermissionUpdate{})
+			if suggestion, ok := s.(map[string]interface{}); ok {
+				permCtx.Suggestions = append(permCtx.Suggestions, parsePermissionUpdate(suggestion))
 			}
 		}
 	}
+	if record != nil {
+		record.Suggestions = permCtx.Suggestions
+	}
 
 	// Call the callback
-	result, err := q.canUseTool(toolName, input, ctx)
+	var result types.PermissionResult
+	var err error
+	if !q.runCallback(ctx, func() { result, err = q.canUseTool(toolName, input, permCtx) }) {
+		timeoutErr := errors.NewCallbackTimeoutError("can_use_tool", toolName, q.callbackTimeout)
+		decide(record, types.PermissionDecisionTimeout, timeoutErr.Error())
+		q.sendSuccessResponse(requestID, map[string]interface{}{
+			"behavior": string(types.PermissionBehaviorDeny),
+			"message":  timeoutErr.Error(),
+		})
+		q.callbackTimedOut(requestID, timeoutErr)
+		return
+	}
 	if err != nil {
+		decide(record, types.PermissionDecisionError, err.Error())
 		q.sendErrorResponse(requestID, err.Error())
 		return
 	}
+	auditResult(record, result)
 
 	// Convert result to response
 	var response map[string]interface{}
@@ -276,13 +653,14 @@ func (q *Query) handleCanUseTool(requestID string, request map[string]interface{
 }
 
 // handleHookCallback processes hook callbacks
-func (q *Query) handleHookCallback(requestID string, request map[string]interface{}) {
+func (q *Query) handleHookCallback(ctx context.Context, requestID string, request map[string]interface{}) {
 	callbackID, _ := request["callback_id"].(string)
 	input, _ := request["input"].(map[string]interface{})
 	toolUseID, _ := request["tool_use_id"].(string)
 
 	q.mu.RLock()
 	callback, exists := q.hookCallbacks[callbackID]
+	route := q.hookRoutes[callbackID]
 	q.mu.RUnlock()
 
 	if !exists {
@@ -290,13 +668,29 @@ func (q *Query) handleHookCallback(requestID string, request map[string]interfac
 		return
 	}
 
-	ctx := &types.HookContext{}
+	// The CLI may match differently; skip callbacks the matcher excludes
+	if !route.matches(input) {
+		q.sendSuccessResponse(requestID, map[string]interface{}{})
+		return
+	}
+
+	ctx, cancel := q.withCallbackTimeout(ctx)
+	defer cancel()
+
+	hookCtx := &types.HookContext{Signal: ctx}
 	var toolUseIDPtr *string
 	if toolUseID != "" {
 		toolUseIDPtr = &toolUseID
 	}
 
-	output, err := callback(input, toolUseIDPtr, ctx)
+	var output *types.HookJSONOutput
+	var err error
+	if !q.runCallback(ctx, func() { output, err = callback(input, toolUseIDPtr, hookCtx) }) {
+		timeoutErr := errors.NewCallbackTimeoutError("hook_callback", callbackID, q.callbackTimeout)
+		q.sendErrorResponse(requestID, timeoutErr.Error())
+		q.callbackTimedOut(requestID, timeoutErr)
+		return
+	}
 	if err != nil {
 		q.sendErrorResponse(requestID, err.Error())
 		return
@@ -310,6 +704,15 @@ func (q *Query) handleHookCallback(requestID string, request map[string]interfac
 		if output.SystemMessage != nil {
 			response["systemMessage"] = *output.SystemMessage
 		}
+		if output.Reason != nil {
+			response["reason"] = *output.Reason
+		}
+		if output.Continue != nil {
+			response["continue"] = *output.Continue
+		}
+		if output.StopReason != nil {
+			response["stopReason"] = *output.StopReason
+		}
 		if output.HookSpecificOutput != nil {
 			response["hookSpecificOutput"] = output.HookSpecificOutput
 		}
@@ -319,21 +722,33 @@ func (q *Query) handleHookCallback(requestID string, request map[string]interfac
 }
 
 // handleMCPMessage processes MCP server messages
-func (q *Query) handleMCPMessage(requestID string, request map[string]interface{}) {
+func (q *Query) handleMCPMessage(ctx context.Context, requestID string, request map[string]interface{}) {
 	serverName, _ := request["server_name"].(string)
 
-	_, exists := q.sdkMCPServers[serverName]
+	instance, exists := q.sdkMCPServers[serverName]
 	if !exists {
 		q.sendErrorResponse(requestID, fmt.Sprintf("SDK MCP server not found: %s", serverName))
 		return
 	}
 
-	// TODO: Implement MCP message handling
-	// This would involve calling the appropriate method on the MCP server instance
+	server, ok := instance.(MCPMessageHandler)
+	if !ok {
+		q.sendErrorResponse(requestID, fmt.Sprintf("SDK MCP server %s does not handle MCP messages", serverName))
+		return
+	}
 
-	q.sendSuccessResponse(requestID, map[string]interface{}{
-		"result": "not implemented",
-	})
+	message, _ := request["message"].(map[string]interface{})
+	if message == nil {
+		q.sendErrorResponse(requestID, "mcp_message request missing 'message'")
+		return
+	}
+
+	// Notifications are acknowledged without a JSON-RPC response
+	response := map[string]interface{}{}
+	if reply := server.HandleMCPMessage(ctx, message); reply != nil {
+		response["mcp_response"] = reply
+	}
+	q.sendSuccessResponse(requestID, response)
 }
 
 // sendControlRequest sends a control request
@@ -344,7 +759,7 @@ func (q *Query) sendControlRequest(request types.SDKControlRequest) error {
 	}
 
 	data = append(data, '\n')
-	return q.transport.Write(data)
+	return q.transport.Write(q.ctx, data)
 }
 
 // sendSuccessResponse sends a success control response
@@ -358,8 +773,13 @@ func (q *Query) sendSuccessResponse(requestID string, response map[string]interf
 		},
 	}
 
+	q.sendResponse(q.ctx, resp)
+}
+
+// sendResponse writes a control response
+func (q *Query) sendResponse(ctx context.Context, resp types.SDKControlResponse) {
 	if data, err := json.Marshal(resp); err == nil {
-		q.transport.Write(append(data, '\n'))
+		q.transport.Write(ctx, append(data, '\n'))
 	}
 }
 
@@ -374,18 +794,5 @@ func (q *Query) sendErrorResponse(requestID string, errorMsg string) {
 		},
 	}
 
-	if data, err := json.Marshal(resp); err == nil {
-		q.transport.Write(append(data, '\n'))
-	}
-}
-
-// generateRequestID generates a unique request ID
-var requestCounter int
-var requestCounterMu sync.Mutex
-
-func generateRequestID() string {
-	requestCounterMu.Lock()
-	defer requestCounterMu.Unlock()
-	requestCounter++
-	return fmt.Sprintf("req_%d", requestCounter)
+	q.sendResponse(q.ctx, resp)
 }
@@ -0,0 +1,158 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/transporttest"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// startQuery connects mock and starts a streaming Query on it
+func startQuery(ctx context.Context, t *testing.T, mock *transporttest.MockTransport, canUseTool types.CanUseTool) *Query {
+	t.Helper()
+	if err := mock.Connect(ctx); err != nil {
+		t.Fatal(err)
+	}
+	return NewQuery(ctx, mock, true, canUseTool, nil, nil)
+}
+
+// stopQuery stops q the way the client does: cancel, close the transport,
+// then Stop
+func stopQuery(mock *transporttest.MockTransport, q *Query) {
+	q.Cancel()
+	mock.Close()
+	q.Stop()
+}
+
+func permissionRequest(id string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":       "control_request",
+		"request_id": id,
+		"request": map[string]interface{}{
+			"subtype":   "can_use_tool",
+			"tool_name": "Bash",
+			"input":     map[string]interface{}{"command": "ls"},
+		},
+	}
+}
+
+// permissionResponses returns the behavior sent for every answered request
+func permissionResponses(mock *transporttest.MockTransport) map[string]string {
+	behaviors := make(map[string]string)
+	for _, msg := range mock.WrittenMessages() {
+		if msg["type"] != "control_response" {
+			continue
+		}
+		response, _ := msg["response"].(map[string]interface{})
+		id, _ := response["request_id"].(string)
+		body, _ := response["response"].(map[string]interface{})
+		behavior, _ := body["behavior"].(string)
+		behaviors[id] = behavior
+	}
+	return behaviors
+}
+
+func TestPermissionQueueFull(t *testing.T) {
+	mock := transporttest.NewMockTransport()
+	ctx := context.Background()
+
+	// The only worker blocks in the first request until the query stops
+	started := make(chan struct{}, 1)
+	q := startQuery(ctx, t, mock, func(toolName string, input map[string]interface{}, ctx *types.ToolPermissionContext) (types.PermissionResult, error) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-ctx.Signal.Done()
+		return &types.PermissionResultDeny{Behavior: types.PermissionBehaviorDeny, Message: "stopped"}, nil
+	})
+	q.SetPermissionConcurrency(1)
+
+	var mu sync.Mutex
+	var audited []*types.PermissionAuditRecord
+	q.SetPermissionAudit(func(record *types.PermissionAuditRecord) {
+		mu.Lock()
+		defer mu.Unlock()
+		audited = append(audited, record)
+	})
+	q.Start()
+
+	mock.Emit(permissionRequest("first"))
+	<-started
+
+	// Fill the queue, then overflow it by one
+	for i := 0; i < permissionQueueSize; i++ {
+		mock.Emit(permissionRequest(fmt.Sprintf("queued-%d", i)))
+	}
+	mock.Emit(permissionRequest("overflow"))
+	mock.Emit(map[string]interface{}{"type": "assistant", "content": []interface{}{}})
+
+	// The read loop keeps delivering messages
+	select {
+	case msg := <-q.ReceiveMessages():
+		if msg["type"] != "assistant" {
+			t.Fatalf("got %v, want the assistant message", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the read loop stalled behind the full permission queue")
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for permissionResponses(mock)["overflow"] == "" && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if behavior := permissionResponses(mock)["overflow"]; behavior != "deny" {
+		t.Fatalf("overflowing request answered with %q, want deny", behavior)
+	}
+
+	// Stopping answers every request still queued
+	stopQuery(mock, q)
+	responses := permissionResponses(mock)
+	for i := 0; i < permissionQueueSize; i++ {
+		if behavior := responses[fmt.Sprintf("queued-%d", i)]; behavior != "deny" {
+			t.Fatalf("queued-%d answered with %q after Stop, want deny", i, behavior)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(audited) != permissionQueueSize+2 {
+		t.Errorf("audited %d requests, want %d", len(audited), permissionQueueSize+2)
+	}
+}
+
+func TestRawTapDropsWhenFull(t *testing.T) {
+	mock := transporttest.NewMockTransport()
+	ctx := context.Background()
+
+	q := startQuery(ctx, t, mock, nil)
+	tap := make(chan json.RawMessage, 1)
+	var dropped atomic.Int32
+	q.SetRawTap(tap, func() { dropped.Add(1) })
+	q.Start()
+	defer stopQuery(mock, q)
+
+	// Nobody reads the tap; every message still arrives
+	for i := 0; i < 3; i++ {
+		mock.Emit(map[string]interface{}{"type": "assistant", "content": []interface{}{}})
+	}
+	for i := 0; i < 3; i++ {
+		select {
+		case <-q.ReceiveMessages():
+		case <-time.After(5 * time.Second):
+			t.Fatal("the read loop stalled behind the full raw tap")
+		}
+	}
+
+	if got := dropped.Load(); got != 2 {
+		t.Errorf("dropped %d frames, want 2", got)
+	}
+	if len(tap) != 1 {
+		t.Errorf("tap holds %d frames, want 1", len(tap))
+	}
+}
@@ -0,0 +1,86 @@
+package claudecode
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/transporttest"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+func TestInterruptAndWait(t *testing.T) {
+	mock := transporttest.NewMockTransport()
+	client := NewClaudeSDKClientWithTransport(nil, mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.InterruptAndWait(ctx); err == nil {
+		t.Error("expected an error before Connect")
+	}
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	// The CLI acknowledges the interrupt and ends the turn
+	mock.RespondOnce(transporttest.MatchControl("interrupt"))
+	mock.RespondFunc(transporttest.MatchControl("interrupt"), func(msg map[string]interface{}) []interface{} {
+		return []interface{}{
+			transporttest.ControlSuccess(msg, nil),
+			map[string]interface{}{"type": "result", "subtype": "error_during_execution", "is_error": true, "session_id": "s1"},
+		}
+	})
+
+	// The first interrupt goes unanswered until ctx expires
+	waitCtx, stop := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer stop()
+	if err := client.InterruptAndWait(waitCtx); !stderrors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("unanswered interrupt = %v, want DeadlineExceeded", err)
+	}
+
+	if err := client.InterruptAndWait(ctx); err != nil {
+		t.Fatalf("InterruptAndWait: %v", err)
+	}
+	select {
+	case msg := <-client.Messages():
+		if _, ok := msg.(*types.ResultMessage); !ok {
+			t.Errorf("got %T, want the interrupted turn's result", msg)
+		}
+	case <-ctx.Done():
+		t.Fatal("no result after the interrupt")
+	}
+}
+
+func TestInterruptAndWaitRejected(t *testing.T) {
+	mock := transporttest.NewMockTransport()
+	client := NewClaudeSDKClientWithTransport(nil, mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	mock.RespondFunc(transporttest.MatchControl("interrupt"), func(msg map[string]interface{}) []interface{} {
+		return []interface{}{map[string]interface{}{
+			"type": "control_response",
+			"response": map[string]interface{}{
+				"subtype":    "error",
+				"request_id": msg["request_id"],
+				"error":      "nothing to interrupt",
+			},
+		}}
+	})
+
+	err := client.InterruptAndWait(ctx)
+	var rejected *errors.ControlRequestError
+	if !stderrors.As(err, &rejected) || rejected.Subtype != "interrupt" || rejected.Message != "nothing to interrupt" {
+		t.Errorf("InterruptAndWait = %v, want a ControlRequestError", err)
+	}
+}
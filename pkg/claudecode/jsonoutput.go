@@ -0,0 +1,75 @@
+package claudecode
+
+import (
+	"context"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/internal"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// runJSONOutput drives a query with OutputFormatJSON. The CLI prints the
+// result once it is done, so there is no control protocol and no message
+// loop; the output is decoded once the CLI closes stdout. It returns the
+// usage of the result, or nil if there was none.
+func runJSONOutput(ctx context.Context, prompt string, options *types.ClaudeCodeOptions, cliPath string, yield func(types.Message, error) bool) *types.Usage {
+	t := transport.NewSubprocessTransport(prompt, options, cliPath)
+	if err := t.Connect(ctx); err != nil {
+		yield(nil, err)
+		return nil
+	}
+	defer t.Close()
+
+	// Closing the transport unblocks the read when ctx ends
+	stop := context.AfterFunc(ctx, func() { t.Close() })
+	defer stop()
+
+	telemetry := newTelemetry(options)
+	telemetry.startQuery(ctx)
+	defer telemetry.end()
+
+	messages, err := internal.ReadJSONOutput(t.Reader(), options.MaxMessageSize, options.RawOutput)
+	if ctx.Err() != nil {
+		yield(nil, ctx.Err())
+		return nil
+	}
+	if err != nil {
+		yield(nil, err)
+		return nil
+	}
+
+	progress := newProgressTracker(options)
+	parser := newMessageParser(options)
+	var usage *types.Usage
+	gotResult := false
+	for _, data := range messages {
+		msg, err := parser.parse(optionsLogger(options), data)
+		if err != nil {
+			optionsLogger(options).Warn("failed to parse message", "type", data["type"], "error", err)
+			if !yield(nil, err) {
+				return usage
+			}
+			continue
+		}
+
+		if options.Transcript != nil {
+			options.Transcript.Record(data)
+		}
+		progress.observe(msg)
+		telemetry.observe(msg)
+		if result, ok := msg.(*types.ResultMessage); ok {
+			// The only result; the session cost is its own
+			result.TurnCostUSD = result.Cost()
+			usage = result.Usage
+			gotResult = true
+		}
+		if !yield(msg, nil) {
+			return usage
+		}
+	}
+
+	if !gotResult {
+		yield(nil, exitedEarly(ctx, t))
+	}
+	return usage
+}
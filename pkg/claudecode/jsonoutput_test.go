@@ -0,0 +1,71 @@
+package claudecode
+
+import (
+	"context"
+	stderrors "errors"
+	"os/exec"
+	"runtime"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// jsonCLI prints a single result for the prompt on stdin, like the CLI with
+// --output-format json
+const jsonCLI = `
+read -r prompt
+printf '{"type":"result","subtype":"success","session_id":"s1","result":"You said %s","total_cost_usd":0.25,"usage":{"input_tokens":7,"output_tokens":3}}\n' "$prompt"
+`
+
+func TestQueryJSONOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	var args []string
+	options := types.NewOptions().
+		WithOutputFormat(types.OutputFormatJSON).
+		WithCLIPath("/nonexistent/claude").
+		WithCommandFactory(func(ctx context.Context, path string, cliArgs []string) *exec.Cmd {
+			args = cliArgs
+			return exec.CommandContext(ctx, "sh", "-c", jsonCLI)
+		})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var messages []types.Message
+	for msg, err := range QueryIter(ctx, "hello", options) {
+		if err != nil {
+			t.Fatalf("Query: %v", err)
+		}
+		messages = append(messages, msg)
+	}
+
+	if len(messages) != 1 {
+		t.Fatalf("got %d messages, want the result only", len(messages))
+	}
+	result, ok := messages[0].(*types.ResultMessage)
+	if !ok || result.Result == nil || *result.Result != "You said hello" || result.Cost() != 0.25 {
+		t.Errorf("unexpected result %+v", messages[0])
+	}
+	if !slices.Contains(args, "json") || slices.Contains(args, "stream-json") || slices.Contains(args, "--verbose") {
+		t.Errorf("CLI args = %v, want --output-format json without --verbose", args)
+	}
+}
+
+func TestJSONOutputRequiresStringPrompt(t *testing.T) {
+	options := types.NewOptions().WithOutputFormat(types.OutputFormatJSON)
+
+	for _, err := range QueryIter(context.Background(), make(chan interface{}), options) {
+		if !stderrors.Is(err, errors.ErrInvalidOptions) {
+			t.Errorf("Query error = %v, want ErrInvalidOptions", err)
+		}
+	}
+	if err := NewClaudeSDKClient(options).Connect(context.Background(), nil); !stderrors.Is(err, errors.ErrInvalidOptions) {
+		t.Errorf("Connect error = %v, want ErrInvalidOptions", err)
+	}
+}
@@ -0,0 +1,187 @@
+package claudecode
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// LimiterOptions configures a Limiter. A zero rate leaves that dimension
+// unlimited.
+type LimiterOptions struct {
+	// Turns started per minute
+	RequestsPerMinute float64
+	// Input and output tokens per minute. Cache reads are not counted, as
+	// they do not count towards Anthropic's limits.
+	TokensPerMinute float64
+
+	// Bucket sizes, one minute's worth by default
+	RequestBurst int
+	TokenBurst   int
+
+	// Estimates the tokens of a turn before it starts. By default the larger
+	// of a quarter of the prompt length and the average of previous turns.
+	EstimateTokens func(prompt string) int
+}
+
+// Limiter is a token bucket on requests and estimated tokens, shared by any
+// number of clients, queries and pools through WithRateLimiter so concurrent
+// SDK calls of a process stay under the account's rate limits.
+//
+// A turn waits until both buckets can pay for it. Its token estimate is
+// corrected once the turn reports its usage, so turns that used more than
+// estimated delay the next ones.
+//
+// Example:
+//
+//	limiter := claudecode.NewLimiter(claudecode.LimiterOptions{
+//	    RequestsPerMinute: 50,
+//	    TokensPerMinute:   40000,
+//	})
+//	options := claudecode.NewOptions().WithRateLimiter(limiter)
+type Limiter struct {
+	estimate func(prompt string) int
+
+	mu       sync.Mutex
+	requests bucket
+	tokens   bucket
+
+	// Moving average of the tokens used per turn
+	average float64
+	turns   int
+}
+
+// bucket is a single token bucket refilled continuously
+type bucket struct {
+	rate  float64 // Per second, 0 for unlimited
+	size  float64
+	level float64 // Negative when turns used more than estimated
+	last  time.Time
+}
+
+// averageWindow bounds the number of turns the token average adapts over
+const averageWindow = 20
+
+// NewLimiter returns a Limiter with full buckets
+func NewLimiter(options LimiterOptions) *Limiter {
+	now := time.Now()
+	l := &Limiter{
+		estimate: options.EstimateTokens,
+		requests: newBucket(options.RequestsPerMinute, options.RequestBurst, now),
+		tokens:   newBucket(options.TokensPerMinute, options.TokenBurst, now),
+	}
+	if l.estimate == nil {
+		l.estimate = l.defaultEstimate
+	}
+	return l
+}
+
+func newBucket(perMinute float64, burst int, now time.Time) bucket {
+	size := float64(burst)
+	if size <= 0 {
+		size = perMinute
+	}
+	return bucket{rate: perMinute / 60, size: size, level: size, last: now}
+}
+
+// Acquire waits until a turn for prompt may start, or ctx is done. The
+// returned function settles the turn's actual usage; pass nil if it failed
+// before reporting any.
+func (l *Limiter) Acquire(ctx context.Context, prompt string) (func(usage *types.Usage), error) {
+	estimate := float64(l.estimate(prompt))
+
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		l.requests.refill(now)
+		l.tokens.refill(now)
+
+		// A turn larger than the bucket would never fit; it waits for a full one
+		cost := estimate
+		if l.tokens.rate > 0 {
+			cost = math.Min(cost, l.tokens.size)
+		}
+
+		wait := max(l.requests.wait(1), l.tokens.wait(cost))
+		if wait == 0 {
+			l.requests.take(1)
+			l.tokens.take(cost)
+			l.mu.Unlock()
+
+			var once sync.Once
+			return func(usage *types.Usage) {
+				once.Do(func() { l.settle(cost, usage) })
+			}, nil
+		}
+		l.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// settle charges the difference between the usage of a turn and its estimate
+func (l *Limiter) settle(estimate float64, usage *types.Usage) {
+	if usage == nil {
+		return
+	}
+	used := float64(usage.InputTokens + usage.CacheCreationInputTokens + usage.OutputTokens)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tokens.refill(time.Now())
+	l.tokens.take(used - estimate)
+
+	if l.turns < averageWindow {
+		l.turns++
+	}
+	l.average += (used - l.average) / float64(l.turns)
+}
+
+// defaultEstimate guesses four characters per token, or the average of
+// previous turns if larger, as the context and output usually dominate
+func (l *Limiter) defaultEstimate(prompt string) int {
+	l.mu.Lock()
+	average := l.average
+	l.mu.Unlock()
+	return int(math.Max(float64(len(prompt)/4), average))
+}
+
+func (b *bucket) refill(now time.Time) {
+	if b.rate > 0 {
+		b.level = math.Min(b.size, b.level+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+}
+
+// wait returns how long until the bucket holds n, 0 if it does already
+func (b *bucket) wait(n float64) time.Duration {
+	if b.rate == 0 || b.level >= n {
+		return 0
+	}
+	return time.Duration((n - b.level) / b.rate * float64(time.Second))
+}
+
+func (b *bucket) take(n float64) {
+	if b.rate > 0 {
+		b.level -= n
+	}
+}
+
+// acquireTurn consults the configured RateLimiter before a turn. The
+// returned function is never nil.
+func acquireTurn(ctx context.Context, options *types.ClaudeCodeOptions, prompt string) (func(usage *types.Usage), error) {
+	if options == nil || options.RateLimiter == nil {
+		return func(*types.Usage) {}, nil
+	}
+	return options.RateLimiter.Acquire(ctx, prompt)
+}
@@ -0,0 +1,98 @@
+package claudecode
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/transporttest"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+var _ types.RateLimiter = (*Limiter)(nil)
+
+func TestLimiterRequests(t *testing.T) {
+	// One request per 50ms with a burst of two
+	limiter := NewLimiter(LimiterOptions{RequestsPerMinute: 1200, RequestBurst: 2})
+	ctx := context.Background()
+
+	start := time.Now()
+	for range 3 {
+		done, err := limiter.Acquire(ctx, "hi")
+		if err != nil {
+			t.Fatalf("Acquire: %v", err)
+		}
+		done(nil)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("third request started after %v, want it to wait for a refill", elapsed)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := limiter.Acquire(cancelled, "hi"); !stderrors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestLimiterTokens(t *testing.T) {
+	limiter := NewLimiter(LimiterOptions{
+		TokensPerMinute: 60000, // 1000 per second
+		TokenBurst:      1000,
+		EstimateTokens:  func(string) int { return 100 },
+	})
+	ctx := context.Background()
+
+	// The turn used 1000 tokens more than estimated, emptying the bucket
+	done, err := limiter.Acquire(ctx, "hi")
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	done(&types.Usage{InputTokens: 900, OutputTokens: 200, CacheReadInputTokens: 5000})
+
+	short, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if _, err := limiter.Acquire(short, "hi"); !stderrors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the overdrawn bucket to block, got %v", err)
+	}
+
+	start := time.Now()
+	if _, err := limiter.Acquire(ctx, "hi"); err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("waited %v for 100 tokens", elapsed)
+	}
+}
+
+func TestSendAndWaitRateLimited(t *testing.T) {
+	limiter := NewLimiter(LimiterOptions{RequestsPerMinute: 60, RequestBurst: 1})
+	mock := transporttest.NewMockTransport()
+	client := NewClaudeSDKClientWithTransport(types.NewOptions().WithRateLimiter(limiter), mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	mock.Emit(map[string]interface{}{"type": "result", "subtype": "success", "session_id": "s1"})
+	if _, err := client.SendAndWait(ctx, "first", "default"); err != nil {
+		t.Fatalf("SendAndWait: %v", err)
+	}
+
+	// The bucket is empty for a second, so the second turn is never sent
+	short, cancelShort := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancelShort()
+	if _, err := client.SendAndWait(short, "second", "default"); !stderrors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the limiter to block, got %v", err)
+	}
+	for _, msg := range mock.WrittenMessages() {
+		if msg["type"] == "user" && msg["message"].(map[string]interface{})["content"] == "second" {
+			t.Error("the limited turn was sent")
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package claudecode
+
+import (
+	"log/slog"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// nopLogger is used when ClaudeCodeOptions.Logger is nil
+var nopLogger = slog.New(slog.DiscardHandler)
+
+// optionsLogger returns the configured logger, or one that discards everything
+func optionsLogger(options *types.ClaudeCodeOptions) *slog.Logger {
+	if options.Logger == nil {
+		return nopLogger
+	}
+	return options.Logger
+}
+
+// logger returns the configured logger with the current session ID attached
+func (c *ClaudeSDKClient) logger() *slog.Logger {
+	if c.options.Logger == nil {
+		return nopLogger
+	}
+
+	c.resumeMu.Lock()
+	sessionID := c.sessionID
+	c.resumeMu.Unlock()
+
+	if sessionID == "" {
+		return c.options.Logger
+	}
+	return c.options.Logger.With("session_id", sessionID)
+}
@@ -0,0 +1,79 @@
+package claudecode
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/transporttest"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent writes and reads
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestLogger(t *testing.T) {
+	var logs syncBuffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	mock := transporttest.NewMockTransport()
+	mock.Respond(transporttest.MatchType("user"),
+		map[string]interface{}{"type": "system", "subtype": "init", "session_id": "s1", "model": "sonnet"},
+	)
+	client := NewClaudeSDKClientWithTransport(types.NewOptions().WithLogger(logger), mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.SendMessage("hello", "default"); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	select {
+	case <-client.Messages():
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for init")
+	}
+
+	mock.EmitMalformed()
+	select {
+	case <-client.Errors():
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for decode error")
+	}
+
+	output := logs.String()
+	for _, want := range []string{
+		`msg="control request completed" subtype=initialize request_id=req_`,
+		`msg="received line"`,
+		`msg="session started" session_id=s1 model=sonnet`,
+		`msg="failed to decode message"`,
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("log output missing %q:\n%s", want, output)
+		}
+	}
+}
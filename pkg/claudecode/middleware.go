@@ -0,0 +1,190 @@
+package claudecode
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"io"
+	"sync"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport"
+)
+
+// Direction tells whether an intercepted message is read from or written to
+// the CLI
+type Direction int
+
+const (
+	// Inbound messages are read from the CLI, before they are parsed
+	Inbound Direction = iota
+
+	// Outbound messages are about to be written to the CLI
+	Outbound
+)
+
+// String returns "inbound" or "outbound"
+func (d Direction) String() string {
+	if d == Outbound {
+		return "outbound"
+	}
+	return "inbound"
+}
+
+// MessageHandler processes one protocol message decoded from its JSON line,
+// including control requests and responses. The map may be modified in
+// place before it is passed on.
+type MessageHandler func(ctx context.Context, dir Direction, msg map[string]interface{}) error
+
+// Middleware wraps the handler of the next middleware, or the one finally
+// reading or writing the message. Not calling next drops the message; an
+// error drops it too and is returned from the write, or for inbound messages
+// delivered on Errors().
+type Middleware func(next MessageHandler) MessageHandler
+
+// Use adds middleware that sees every message exchanged with the CLI, e.g.
+// to redact, rewrite or log them. The first middleware added is the
+// outermost. Middleware must be added before Connect.
+//
+// Example:
+//
+//	client.Use(func(next claudecode.MessageHandler) claudecode.MessageHandler {
+//	    return func(ctx context.Context, dir claudecode.Direction, msg map[string]interface{}) error {
+//	        log.Printf("%s %v", dir, msg["type"])
+//	        return next(ctx, dir, msg)
+//	    }
+//	})
+func (c *ClaudeSDKClient) Use(middleware ...Middleware) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.connected {
+		return stderrors.New("middleware must be added before Connect")
+	}
+
+	c.middleware = append(c.middleware, middleware...)
+	return nil
+}
+
+// intercept returns t with the middleware applied, or t itself without any
+func (c *ClaudeSDKClient) intercept(t transport.Transport) transport.Transport {
+	if len(c.middleware) == 0 {
+		return t
+	}
+	return &interceptedTransport{
+		Transport:  t,
+		middleware: c.middleware,
+		ctx:        c.ctx,
+		onError: func(err error) {
+			c.handleError(err)
+		},
+	}
+}
+
+// interceptedTransport runs the lines read from and written to a transport
+// through a middleware chain
+type interceptedTransport struct {
+	transport.Transport
+
+	middleware []Middleware
+	ctx        context.Context
+	onError    func(error)
+
+	reader     io.Reader
+	readerOnce sync.Once
+}
+
+// chain builds the handler running the middleware around final
+func (t *interceptedTransport) chain(final MessageHandler) MessageHandler {
+	handler := final
+	for i := len(t.middleware) - 1; i >= 0; i-- {
+		handler = t.middleware[i](handler)
+	}
+	return handler
+}
+
+// Write runs each line through the middleware and writes what comes out
+func (t *interceptedTransport) Write(ctx context.Context, data []byte) error {
+	var out bytes.Buffer
+	handler := t.chain(func(ctx context.Context, dir Direction, msg map[string]interface{}) error {
+		return appendLine(&out, msg)
+	})
+
+	for _, line := range bytes.Split(data, []byte{'\n'}) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var msg map[string]interface{}
+		if err := json.Unmarshal(line, &msg); err != nil {
+			// Not ours to judge; pass it on unchanged
+			out.Write(line)
+			out.WriteByte('\n')
+			continue
+		}
+		if err := handler(ctx, Outbound, msg); err != nil {
+			return err
+		}
+	}
+
+	if out.Len() == 0 {
+		return nil
+	}
+	return t.Transport.Write(ctx, out.Bytes())
+}
+
+// Reader returns a reader yielding the lines that passed the middleware
+func (t *interceptedTransport) Reader() io.Reader {
+	t.readerOnce.Do(func() {
+		r, w := io.Pipe()
+		t.reader = r
+		go t.readLoop(t.Transport.Reader(), w)
+	})
+	return t.reader
+}
+
+// readLoop runs every line of src through the middleware into w. Lines that
+// are not JSON objects are passed on for the parser to report.
+func (t *interceptedTransport) readLoop(src io.Reader, w *io.PipeWriter) {
+	handler := t.chain(func(ctx context.Context, dir Direction, msg map[string]interface{}) error {
+		var out bytes.Buffer
+		if err := appendLine(&out, msg); err != nil {
+			return err
+		}
+		_, err := w.Write(out.Bytes())
+		return err
+	})
+
+	br := bufio.NewReader(src)
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			var msg map[string]interface{}
+			if jsonErr := json.Unmarshal(line, &msg); jsonErr != nil {
+				if _, err := w.Write(line); err != nil {
+					return
+				}
+			} else if err := handler(t.ctx, Inbound, msg); err != nil {
+				if err == io.ErrClosedPipe {
+					return
+				}
+				t.onError(err)
+			}
+		}
+		if err != nil {
+			w.CloseWithError(err)
+			return
+		}
+	}
+}
+
+// appendLine writes msg to buf as a newline-terminated JSON line
+func appendLine(buf *bytes.Buffer, msg map[string]interface{}) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	buf.Write(data)
+	buf.WriteByte('\n')
+	return nil
+}
@@ -0,0 +1,93 @@
+package claudecode
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/transporttest"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+func TestMiddleware(t *testing.T) {
+	mock := transporttest.NewMockTransport()
+	client := NewClaudeSDKClientWithTransport(nil, mock)
+
+	var mu sync.Mutex
+	var seen []string
+	logging := func(next MessageHandler) MessageHandler {
+		return func(ctx context.Context, dir Direction, msg map[string]interface{}) error {
+			mu.Lock()
+			seen = append(seen, dir.String()+" "+msg["type"].(string))
+			mu.Unlock()
+			return next(ctx, dir, msg)
+		}
+	}
+	redact := func(next MessageHandler) MessageHandler {
+		return func(ctx context.Context, dir Direction, msg map[string]interface{}) error {
+			if dir == Outbound && msg["type"] == "user" {
+				message := msg["message"].(map[string]interface{})
+				message["content"] = strings.ReplaceAll(message["content"].(string), "hunter2", "[REDACTED]")
+			}
+			if dir == Inbound && msg["type"] == "assistant" {
+				content := msg["content"].([]interface{})
+				if content[0].(map[string]interface{})["text"] == "secret" {
+					return nil
+				}
+			}
+			return next(ctx, dir, msg)
+		}
+	}
+	if err := client.Use(logging, redact); err != nil {
+		t.Fatalf("Use: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.Use(logging); err == nil {
+		t.Error("expected Use after Connect to fail")
+	}
+
+	if err := client.SendMessage("my password is hunter2", "default"); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	var sent map[string]interface{}
+	for _, msg := range mock.WrittenMessages() {
+		if msg["type"] == "user" {
+			sent = msg
+		}
+	}
+	if content := sent["message"].(map[string]interface{})["content"]; content != "my password is [REDACTED]" {
+		t.Errorf("outbound content = %v", content)
+	}
+
+	mock.Emit(textMessage("secret"))
+	mock.Emit(textMessage("public"))
+
+	msg := (<-client.Messages()).(*types.AssistantMessage)
+	if got := msg.Content[0].(*types.TextBlock).Text; got != "public" {
+		t.Errorf("got message %q, want the filtered one skipped", got)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := map[string]bool{"outbound control_request": false, "outbound user": false, "inbound assistant": false}
+	for _, entry := range seen {
+		if _, ok := want[entry]; ok {
+			want[entry] = true
+		}
+	}
+	for entry, ok := range want {
+		if !ok {
+			t.Errorf("middleware did not see %q in %v", entry, seen)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+module github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/otel
+
+go 1.24.3
+
+replace github.com/vinaayakha/claude-code-sdk-go => ../../../
+
+require (
+	github.com/vinaayakha/claude-code-sdk-go v0.0.0
+	go.opentelemetry.io/otel v1.40.0
+	go.opentelemetry.io/otel/metric v1.40.0
+	go.opentelemetry.io/otel/trace v1.40.0
+)
+
+require github.com/cespare/xxhash/v2 v2.3.0 // indirect
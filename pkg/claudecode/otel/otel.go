@@ -0,0 +1,328 @@
+// Package otel instruments conversations with OpenTelemetry. It is a
+// separate module so that the SDK itself does not depend on OpenTelemetry.
+//
+// Example:
+//
+//	options := claudecode.NewOptions().
+//	    WithTelemetry(otel.New(tracerProvider, meterProvider))
+package otel
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// instrumentationName identifies the SDK's spans and metrics
+const instrumentationName = "github.com/vinaayakha/claude-code-sdk-go"
+
+// telemetry derives OpenTelemetry spans and metrics from the message stream.
+//
+// A claude.query span covers one prompt up to its result. Each assistant
+// turn, including the tools it runs, is a claude.turn child span, and each
+// tool call a claude.tool_use span below its turn. Control requests are
+// claude.control_request spans.
+type telemetry struct {
+	tracer trace.Tracer
+	meter  metric.Meter
+
+	tokens       metric.Int64Counter
+	cost         metric.Float64Counter
+	turnDuration metric.Float64Histogram
+	toolUses     metric.Int64Counter
+	dropped      metric.Int64Counter
+}
+
+// conversation holds the spans of one conversation
+type conversation struct {
+	*telemetry
+
+	mu       sync.Mutex
+	queries  []span // Prompts awaiting a result, oldest first
+	turn     *span
+	tools    map[string]span // tool use ID -> span
+	lastCost float64         // TotalCostUSD of the previous result
+	queue    metric.Registration
+}
+
+// span is a started span with its context
+type span struct {
+	ctx   context.Context
+	span  trace.Span
+	start time.Time
+}
+
+// New returns telemetry recording spans with tracerProvider and metrics
+// with meterProvider. A nil provider disables the corresponding signal.
+func New(tracerProvider trace.TracerProvider, meterProvider metric.MeterProvider) types.Telemetry {
+	t := &telemetry{}
+
+	if tracerProvider == nil {
+		tracerProvider = tracenoop.NewTracerProvider()
+	}
+	t.tracer = tracerProvider.Tracer(instrumentationName)
+
+	if meterProvider == nil {
+		meterProvider = metricnoop.NewMeterProvider()
+	}
+	meter := meterProvider.Meter(instrumentationName)
+	t.meter = meter
+
+	// Instrument creation only fails for invalid names; the noop
+	// instruments returned alongside the error are still usable
+	t.tokens, _ = meter.Int64Counter("claude.tokens",
+		metric.WithDescription("Tokens consumed, by token type"),
+		metric.WithUnit("{token}"))
+	t.cost, _ = meter.Float64Counter("claude.cost",
+		metric.WithDescription("Cost reported by the CLI"),
+		metric.WithUnit("USD"))
+	t.turnDuration, _ = meter.Float64Histogram("claude.turn.duration",
+		metric.WithDescription("Duration of an assistant turn including its tool calls"),
+		metric.WithUnit("s"))
+	t.toolUses, _ = meter.Int64Counter("claude.tool_uses",
+		metric.WithDescription("Tool calls requested by Claude, by tool name"),
+		metric.WithUnit("{call}"))
+	t.dropped, _ = meter.Int64Counter("claude.messages.dropped",
+		metric.WithDescription("Messages discarded because the consumer fell behind"),
+		metric.WithUnit("{message}"))
+
+	return t
+}
+
+// Conversation implements types.Telemetry
+func (t *telemetry) Conversation() types.ConversationTelemetry {
+	return &conversation{telemetry: t, tools: make(map[string]span)}
+}
+
+// ObserveQueue reports the number of messages waiting for the consumer as
+// the claude.queue.depth gauge until End is called
+func (c *conversation) ObserveQueue(depth func() int) {
+	gauge, _ := c.meter.Int64ObservableGauge("claude.queue.depth",
+		metric.WithDescription("Messages delivered but not yet received by the consumer"),
+		metric.WithUnit("{message}"))
+	registration, err := c.meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		o.ObserveInt64(gauge, int64(depth()))
+		return nil
+	}, gauge)
+	if err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	c.queue = registration
+	c.mu.Unlock()
+}
+
+// MessageDropped counts a message discarded by the overflow policy
+func (c *conversation) MessageDropped() {
+	c.dropped.Add(context.Background(), 1)
+}
+
+// StartQuery starts the span of a prompt sent to the CLI
+func (c *conversation) StartQuery(ctx context.Context) {
+	spanCtx, s := c.tracer.Start(ctx, "claude.query")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.queries = append(c.queries, span{ctx: spanCtx, span: s, start: time.Now()})
+	if len(c.queries) == 1 {
+		c.startTurn()
+	}
+}
+
+// ControlRequest starts the span of a control request
+func (c *conversation) ControlRequest(ctx context.Context, subtype string, requestID string) (context.Context, func(error)) {
+	ctx, s := c.tracer.Start(ctx, "claude.control_request", trace.WithAttributes(
+		attribute.String("claude.control.subtype", subtype),
+		attribute.String("claude.control.request_id", requestID),
+	))
+	return ctx, func(err error) {
+		if err != nil {
+			s.SetStatus(codes.Error, err.Error())
+		}
+		s.End()
+	}
+}
+
+// Observe updates spans and metrics for a received message
+func (c *conversation) Observe(msg types.Message) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch m := msg.(type) {
+	case *types.AssistantMessage:
+		turn := c.startTurn()
+		for _, block := range m.Content {
+			toolUse, ok := block.(*types.ToolUseBlock)
+			if !ok {
+				continue
+			}
+
+			name := attribute.String("claude.tool.name", toolUse.Name)
+			c.toolUses.Add(turn.ctx, 1, metric.WithAttributes(name))
+
+			spanCtx, s := c.tracer.Start(turn.ctx, "claude.tool_use", trace.WithAttributes(
+				name,
+				attribute.String("claude.tool.use_id", toolUse.ID),
+			))
+			c.tools[toolUse.ID] = span{ctx: spanCtx, span: s, start: time.Now()}
+		}
+	case *types.UserMessage:
+		blocks, ok := m.Content.([]types.ContentBlock)
+		if !ok {
+			return
+		}
+
+		results := false
+		for _, block := range blocks {
+			result, ok := block.(*types.ToolResultBlock)
+			if !ok {
+				continue
+			}
+			results = true
+
+			s, ok := c.tools[result.ToolUseID]
+			if !ok {
+				continue
+			}
+			delete(c.tools, result.ToolUseID)
+			if result.IsError != nil && *result.IsError {
+				s.span.SetStatus(codes.Error, "tool returned an error")
+			}
+			s.span.End()
+		}
+
+		// Tool results are fed back to Claude, which starts the next turn
+		if results {
+			c.endTurn()
+			c.startTurn()
+		}
+	case *types.ResultMessage:
+		c.endTurn()
+		c.endTools()
+
+		ctx := context.Background()
+		var query *span
+		if len(c.queries) > 0 {
+			query = &c.queries[0]
+			c.queries = c.queries[1:]
+			ctx = query.ctx
+		}
+
+		if m.Usage != nil {
+			c.addTokens(ctx, "input", m.Usage.InputTokens)
+			c.addTokens(ctx, "output", m.Usage.OutputTokens)
+			c.addTokens(ctx, "cache_read", m.Usage.CacheReadInputTokens)
+			c.addTokens(ctx, "cache_creation", m.Usage.CacheCreationInputTokens)
+		}
+		// TotalCostUSD is the session total so far; record what this
+		// query added
+		var cost float64
+		if m.TotalCostUSD != nil {
+			cost = c.costDelta(*m.TotalCostUSD)
+			c.cost.Add(ctx, cost)
+		}
+
+		// The CLI moves on to the next queued prompt
+		if len(c.queries) > 0 {
+			c.startTurn()
+		}
+
+		if query == nil {
+			return
+		}
+		query.span.SetAttributes(
+			attribute.String("claude.session_id", m.SessionID),
+			attribute.Int("claude.num_turns", m.NumTurns),
+		)
+		if m.TotalCostUSD != nil {
+			query.span.SetAttributes(attribute.Float64("claude.cost_usd", cost))
+		}
+		if m.IsError {
+			query.span.SetStatus(codes.Error, m.Subtype)
+		}
+		query.span.End()
+	}
+}
+
+// End ends all open spans, e.g. when the connection closes mid-query
+func (c *conversation) End() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.endTools()
+	c.endTurn()
+	for _, query := range c.queries {
+		query.span.SetStatus(codes.Error, "connection closed before result")
+		query.span.End()
+	}
+	c.queries = nil
+
+	if c.queue != nil {
+		c.queue.Unregister()
+		c.queue = nil
+	}
+}
+
+// startTurn returns the current turn, starting one if needed. Callers hold c.mu.
+func (c *conversation) startTurn() *span {
+	if c.turn != nil {
+		return c.turn
+	}
+
+	ctx := context.Background()
+	if len(c.queries) > 0 {
+		ctx = c.queries[0].ctx
+	}
+	spanCtx, s := c.tracer.Start(ctx, "claude.turn")
+	c.turn = &span{ctx: spanCtx, span: s, start: time.Now()}
+	return c.turn
+}
+
+// endTurn ends the current turn, if any. Callers hold c.mu.
+func (c *conversation) endTurn() {
+	if c.turn == nil {
+		return
+	}
+
+	c.turnDuration.Record(c.turn.ctx, time.Since(c.turn.start).Seconds())
+	c.turn.span.End()
+	c.turn = nil
+}
+
+// endTools ends tool spans that never got a result. Callers hold c.mu.
+func (c *conversation) endTools() {
+	for id, s := range c.tools {
+		s.span.End()
+		delete(c.tools, id)
+	}
+}
+
+// addTokens records a token count of one type
+func (c *conversation) addTokens(ctx context.Context, tokenType string, count int) {
+	if count > 0 {
+		c.tokens.Add(ctx, int64(count), metric.WithAttributes(attribute.String("claude.token.type", tokenType)))
+	}
+}
+
+// costDelta returns the cost added since the previous result given the
+// session total. A total below the previous one starts a new session.
+// Callers hold c.mu.
+func (c *conversation) costDelta(total float64) float64 {
+	delta := total - c.lastCost
+	if delta < 0 {
+		delta = total
+	}
+	c.lastCost = total
+	return delta
+}
@@ -0,0 +1,218 @@
+package otel_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/otel"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/transporttest"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// recorder collects span names and counter totals
+type recorder struct {
+	mu       sync.Mutex
+	started  []string
+	ended    int
+	counters map[string]int64
+	costs    []float64
+}
+
+type recordingTracerProvider struct {
+	tracenoop.TracerProvider
+	r *recorder
+}
+
+func (p recordingTracerProvider) Tracer(string, ...trace.TracerOption) trace.Tracer {
+	return recordingTracer{r: p.r}
+}
+
+type recordingTracer struct {
+	tracenoop.Tracer
+	r *recorder
+}
+
+func (t recordingTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	t.r.mu.Lock()
+	t.r.started = append(t.r.started, name)
+	t.r.mu.Unlock()
+	return ctx, recordingSpan{r: t.r}
+}
+
+type recordingSpan struct {
+	tracenoop.Span
+	r *recorder
+}
+
+func (s recordingSpan) End(...trace.SpanEndOption) {
+	s.r.mu.Lock()
+	s.r.ended++
+	s.r.mu.Unlock()
+}
+
+type recordingMeterProvider struct {
+	metricnoop.MeterProvider
+	r *recorder
+}
+
+func (p recordingMeterProvider) Meter(string, ...metric.MeterOption) metric.Meter {
+	return recordingMeter{r: p.r}
+}
+
+type recordingMeter struct {
+	metricnoop.Meter
+	r *recorder
+}
+
+func (m recordingMeter) Int64Counter(name string, _ ...metric.Int64CounterOption) (metric.Int64Counter, error) {
+	return recordingCounter{name: name, r: m.r}, nil
+}
+
+func (m recordingMeter) Float64Counter(name string, _ ...metric.Float64CounterOption) (metric.Float64Counter, error) {
+	return recordingFloatCounter{r: m.r}, nil
+}
+
+type recordingFloatCounter struct {
+	metricnoop.Float64Counter
+	r *recorder
+}
+
+func (c recordingFloatCounter) Add(_ context.Context, incr float64, _ ...metric.AddOption) {
+	c.r.mu.Lock()
+	c.r.costs = append(c.r.costs, incr)
+	c.r.mu.Unlock()
+}
+
+type recordingCounter struct {
+	metricnoop.Int64Counter
+	name string
+	r    *recorder
+}
+
+func (c recordingCounter) Add(_ context.Context, incr int64, _ ...metric.AddOption) {
+	c.r.mu.Lock()
+	c.r.counters[c.name] += incr
+	c.r.mu.Unlock()
+}
+
+func TestTelemetry(t *testing.T) {
+	r := &recorder{counters: make(map[string]int64)}
+
+	mock := transporttest.NewMockTransport()
+	mock.Respond(transporttest.MatchType("user"),
+		map[string]interface{}{"type": "assistant", "model": "sonnet", "content": []interface{}{
+			map[string]interface{}{"type": "tool_use", "id": "toolu_1", "name": "Bash", "input": map[string]interface{}{}},
+		}},
+		map[string]interface{}{"type": "user", "content": []interface{}{
+			map[string]interface{}{"type": "tool_result", "tool_use_id": "toolu_1", "content": "ok"},
+		}},
+		map[string]interface{}{
+			"type": "result", "subtype": "success", "session_id": "s1", "num_turns": 2,
+			"usage": map[string]interface{}{"input_tokens": 10, "output_tokens": 5},
+		},
+	)
+
+	options := types.NewOptions().WithTelemetry(otel.New(recordingTracerProvider{r: r}, recordingMeterProvider{r: r}))
+	client := claudecode.NewClaudeSDKClientWithTransport(options, mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.SendMessage("hello", "default"); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	waitResult(t, ctx, client)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	count := map[string]int{}
+	for _, name := range r.started {
+		count[name]++
+	}
+	// initialize is the only control request; the tool result starts a second turn
+	want := map[string]int{"claude.control_request": 1, "claude.query": 1, "claude.turn": 2, "claude.tool_use": 1}
+	for name, n := range want {
+		if count[name] != n {
+			t.Errorf("%s spans = %d, want %d (started: %v)", name, count[name], n, r.started)
+		}
+	}
+	if r.ended != len(r.started) {
+		t.Errorf("ended %d of %d spans", r.ended, len(r.started))
+	}
+	if r.counters["claude.tokens"] != 15 || r.counters["claude.tool_uses"] != 1 {
+		t.Errorf("unexpected counters: %v", r.counters)
+	}
+}
+
+func TestTelemetryCostPerQuery(t *testing.T) {
+	r := &recorder{counters: make(map[string]int64)}
+
+	// The CLI reports the session's total cost with every result
+	mock := transporttest.NewMockTransport()
+	total := 0.0
+	mock.RespondFunc(transporttest.MatchType("user"), func(map[string]interface{}) []interface{} {
+		total += 0.25
+		return []interface{}{map[string]interface{}{
+			"type": "result", "subtype": "success", "session_id": "s1", "total_cost_usd": total,
+		}}
+	})
+
+	options := types.NewOptions().WithTelemetry(otel.New(nil, recordingMeterProvider{r: r}))
+	client := claudecode.NewClaudeSDKClientWithTransport(options, mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	for range 3 {
+		if err := client.SendMessage("hello", "default"); err != nil {
+			t.Fatalf("SendMessage: %v", err)
+		}
+		waitResult(t, ctx, client)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.costs) != 3 {
+		t.Fatalf("recorded %d costs, want 3", len(r.costs))
+	}
+	for i, cost := range r.costs {
+		if cost != 0.25 {
+			t.Errorf("cost %d = %v, want 0.25", i, cost)
+		}
+	}
+}
+
+// waitResult receives messages until a result arrives
+func waitResult(t *testing.T, ctx context.Context, client *claudecode.ClaudeSDKClient) {
+	t.Helper()
+	for {
+		select {
+		case msg := <-client.Messages():
+			if _, ok := msg.(*types.ResultMessage); ok {
+				return
+			}
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for result")
+		}
+	}
+}
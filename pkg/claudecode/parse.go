@@ -0,0 +1,57 @@
+package claudecode
+
+import (
+	"encoding/json"
+	"log/slog"
+	"sync/atomic"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/internal"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// messageParser parses messages read from the CLI as strictly as the
+// options ask and reports what lenient parsing tolerated
+type messageParser struct {
+	options  internal.ParseOptions
+	warnings chan<- types.ParseWarning
+	dropped  atomic.Uint64
+}
+
+func newMessageParser(options *types.ClaudeCodeOptions) *messageParser {
+	return &messageParser{
+		options: internal.ParseOptions{
+			StrictTypes:  options.StrictMessageTypes,
+			StrictFields: options.StrictParsing,
+		},
+		warnings: options.ParseWarnings,
+	}
+}
+
+// parse parses one message, logging any warnings to logger and sending them
+// to ParseWarnings without blocking the message pipeline
+func (p *messageParser) parse(logger *slog.Logger, data map[string]interface{}) (types.Message, error) {
+	msg, warnings, err := internal.ParseMessageWith(data, p.options)
+	if err != nil || len(warnings) == 0 {
+		return msg, err
+	}
+
+	logger.Warn("parsed message leniently", "type", data["type"], "warnings", warnings)
+	if p.warnings == nil {
+		return msg, nil
+	}
+
+	msgType, _ := data["type"].(string)
+	raw, _ := json.Marshal(data)
+	warning := types.ParseWarning{
+		MessageType: msgType,
+		Warnings:    warnings,
+		Raw:         raw,
+		Dropped:     p.dropped.Load(),
+	}
+	select {
+	case p.warnings <- warning:
+	default:
+		p.dropped.Add(1)
+	}
+	return msg, nil
+}
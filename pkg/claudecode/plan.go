@@ -0,0 +1,92 @@
+package claudecode
+
+import (
+	"fmt"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/tools"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// ExitPlanModeToolName is the tool Claude calls when it has finished planning
+const ExitPlanModeToolName = tools.NameExitPlanMode
+
+// WithPlanApproval configures options for the plan-mode workflow.
+//
+// The returned options run in PermissionModePlan. When Claude calls the
+// ExitPlanMode tool, the proposed plan is passed to approve. If the plan is
+// approved the session switches to acceptEdits and execution continues,
+// otherwise the tool call is denied and the turn is interrupted. All other
+// tool calls the CLI asks about are forwarded to the existing CanUseTool
+// callback. Without one they are denied, as the CLI denies tools it cannot
+// ask about, so approving a plan does not approve every tool.
+//
+// The options are copied; the original options are left untouched.
+//
+// Example:
+//
+//	options := claudecode.WithPlanApproval(nil, func(plan *types.Plan) (bool, error) {
+//	    fmt.Println(plan.Content)
+//	    return askUser("Execute this plan?"), nil
+//	})
+//	client := claudecode.NewClaudeSDKClient(options)
+func WithPlanApproval(options *types.ClaudeCodeOptions, approve PlanApprover) *types.ClaudeCodeOptions {
+	var opts types.ClaudeCodeOptions
+	if options != nil {
+		opts = *options
+	}
+
+	mode := types.PermissionModePlan
+	opts.PermissionMode = &mode
+
+	next := opts.CanUseTool
+	opts.CanUseTool = func(toolName string, input map[string]interface{}, context *types.ToolPermissionContext) (types.PermissionResult, error) {
+		if toolName != ExitPlanModeToolName {
+			if next != nil {
+				return next(toolName, input, context)
+			}
+			return &types.PermissionResultDeny{
+				Behavior: types.PermissionBehaviorDeny,
+				Message:  fmt.Sprintf("%s requires permission, which no callback can grant", toolName),
+			}, nil
+		}
+
+		plan := ParsePlan(input)
+		approved, err := approve(plan)
+		if err != nil {
+			return nil, err
+		}
+
+		if !approved {
+			return &types.PermissionResultDeny{
+				Behavior:  types.PermissionBehaviorDeny,
+				Message:   "Plan rejected by user",
+				Interrupt: true,
+			}, nil
+		}
+
+		acceptEdits := types.PermissionModeAcceptEdits
+		destination := types.PermissionDestinationSession
+		return &types.PermissionResultAllow{
+			Behavior:     types.PermissionBehaviorAllow,
+			UpdatedInput: input,
+			UpdatedPermissions: []types.PermissionUpdate{
+				{
+					Type:        types.PermissionUpdateSetMode,
+					Mode:        &acceptEdits,
+					Destination: &destination,
+				},
+			},
+		}, nil
+	}
+
+	return &opts
+}
+
+// ParsePlan extracts the plan from an ExitPlanMode tool input
+func ParsePlan(input map[string]interface{}) *types.Plan {
+	plan := &types.Plan{Input: input}
+	if content, ok := input["plan"].(string); ok {
+		plan.Content = content
+	}
+	return plan
+}
@@ -0,0 +1,109 @@
+package claudecode
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+func TestParsePlan(t *testing.T) {
+	input := map[string]interface{}{"plan": "1. Read the code\n2. Fix the bug"}
+	plan := ParsePlan(input)
+	if plan.Content != "1. Read the code\n2. Fix the bug" {
+		t.Errorf("Content = %q", plan.Content)
+	}
+	if plan.Input["plan"] != input["plan"] {
+		t.Error("Input not kept")
+	}
+
+	if plan := ParsePlan(map[string]interface{}{"plan": 42}); plan.Content != "" {
+		t.Errorf("Content of a non-string plan = %q", plan.Content)
+	}
+}
+
+func TestWithPlanApproval(t *testing.T) {
+	model := "claude-sonnet"
+	options := types.NewOptions().WithModel(model)
+
+	var approved bool
+	var plans []*types.Plan
+	planned := WithPlanApproval(options, func(plan *types.Plan) (bool, error) {
+		plans = append(plans, plan)
+		return approved, nil
+	})
+
+	if options.PermissionMode != nil {
+		t.Error("The original options were changed")
+	}
+	if planned.PermissionMode == nil || *planned.PermissionMode != types.PermissionModePlan {
+		t.Errorf("PermissionMode = %v, want plan", planned.PermissionMode)
+	}
+	if planned.Model == nil || *planned.Model != model {
+		t.Error("Other options were not copied")
+	}
+
+	input := map[string]interface{}{"plan": "Refactor the parser"}
+
+	// Rejected: denied and interrupted
+	result, err := planned.CanUseTool(ExitPlanModeToolName, input, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deny, ok := result.(*types.PermissionResultDeny)
+	if !ok || !deny.Interrupt {
+		t.Errorf("Rejected plan = %#v, want an interrupting deny", result)
+	}
+
+	// Approved: allowed with a switch to acceptEdits
+	approved = true
+	result, err = planned.CanUseTool(ExitPlanModeToolName, input, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	allow, ok := result.(*types.PermissionResultAllow)
+	if !ok || len(allow.UpdatedPermissions) != 1 {
+		t.Fatalf("Approved plan = %#v", result)
+	}
+	update := allow.UpdatedPermissions[0]
+	if update.Type != types.PermissionUpdateSetMode || update.Mode == nil || *update.Mode != types.PermissionModeAcceptEdits {
+		t.Errorf("Unexpected update %+v", update)
+	}
+
+	if len(plans) != 2 || plans[0].Content != "Refactor the parser" {
+		t.Errorf("Approver saw %+v", plans)
+	}
+
+	// Without a callback, other tools the CLI asks about are not allowed,
+	// even once the plan is approved
+	for _, tool := range []string{"Read", "Bash"} {
+		result, _ = planned.CanUseTool(tool, map[string]interface{}{"command": "rm -rf build"}, nil)
+		if deny, ok := result.(*types.PermissionResultDeny); !ok || deny.Interrupt || len(plans) != 2 {
+			t.Errorf("%s = %#v, want a deny", tool, result)
+		}
+	}
+
+	// Approver errors are returned
+	failing := WithPlanApproval(nil, func(*types.Plan) (bool, error) { return false, fmt.Errorf("no approver") })
+	if _, err := failing.CanUseTool(ExitPlanModeToolName, input, nil); err == nil {
+		t.Error("Expected the approver's error")
+	}
+}
+
+func TestWithPlanApprovalForwards(t *testing.T) {
+	var forwarded []string
+	options := types.NewOptions().WithCanUseTool(func(toolName string, input map[string]interface{}, ctx *types.ToolPermissionContext) (types.PermissionResult, error) {
+		forwarded = append(forwarded, toolName)
+		return &types.PermissionResultDeny{Behavior: types.PermissionBehaviorDeny, Message: "no"}, nil
+	})
+	planned := WithPlanApproval(options, func(*types.Plan) (bool, error) { return true, nil })
+
+	result, _ := planned.CanUseTool("Bash", map[string]interface{}{"command": "ls"}, nil)
+	if _, ok := result.(*types.PermissionResultDeny); !ok {
+		t.Errorf("Bash = %#v, want the original callback's deny", result)
+	}
+	planned.CanUseTool(ExitPlanModeToolName, map[string]interface{}{"plan": "x"}, nil)
+	if len(forwarded) != 1 || forwarded[0] != "Bash" {
+		t.Errorf("Forwarded %v, want only Bash", forwarded)
+	}
+}
@@ -0,0 +1,300 @@
+package claudecode
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// Pool defaults
+const (
+	defaultPoolSize           = 2
+	defaultPoolTurnsPerClient = 1
+)
+
+// PoolOptions configures a ClientPool
+type PoolOptions struct {
+	// Number of CLI processes kept connected (default 2)
+	Size int
+
+	// Prompts a client answers before it is replaced (default 1, so each
+	// prompt starts a fresh conversation). Higher values let consecutive
+	// prompts on the same client share conversation context.
+	MaxTurnsPerClient int
+
+	// Replace a client once its CLI uses more resident memory than this
+	// many bytes (0 = no limit). Only enforced on Linux.
+	MaxMemoryBytes uint64
+}
+
+// ClientPool keeps CLI processes connected in streaming mode and hands
+// one-shot prompts to idle ones, hiding the startup latency of the CLI.
+// Retired clients are replaced in the background.
+//
+// Example:
+//
+//	pool, err := claudecode.NewClientPool(ctx, options, claudecode.PoolOptions{Size: 4})
+//	if err != nil {
+//	    return err
+//	}
+//	defer pool.Close()
+//
+//	turn, err := pool.Query(ctx, "Summarize main.go")
+type ClientPool struct {
+	options   PoolOptions
+	newClient func() *ClaudeSDKClient
+	logger    *slog.Logger
+
+	// Slots ready for a prompt; a slot without a client connects on use
+	idle chan *pooledClient
+
+	ctx    context.Context // Cancelled by Close, ends background connects
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu     sync.Mutex
+	closed bool
+	wg     sync.WaitGroup // Background replacements
+}
+
+// pooledClient is one slot of the pool
+type pooledClient struct {
+	client *ClaudeSDKClient
+	turns  int
+}
+
+// NewClientPool connects poolOptions.Size clients configured by options.
+// ctx bounds the connection; the clients run until the pool is closed.
+func NewClientPool(ctx context.Context, options *types.ClaudeCodeOptions, poolOptions PoolOptions) (*ClientPool, error) {
+	if options == nil {
+		options = &types.ClaudeCodeOptions{}
+	}
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+
+	return newClientPool(ctx, poolOptions, optionsLogger(options), func() *ClaudeSDKClient {
+		// Connect adjusts the options it is given, so each client gets a copy
+		clientOptions := *options
+		return NewClaudeSDKClient(&clientOptions)
+	})
+}
+
+func newClientPool(ctx context.Context, options PoolOptions, logger *slog.Logger, newClient func() *ClaudeSDKClient) (*ClientPool, error) {
+	if options.Size <= 0 {
+		options.Size = defaultPoolSize
+	}
+	if options.MaxTurnsPerClient <= 0 {
+		options.MaxTurnsPerClient = defaultPoolTurnsPerClient
+	}
+
+	poolCtx, cancel := context.WithCancel(context.Background())
+	p := &ClientPool{
+		options:   options,
+		newClient: newClient,
+		logger:    logger,
+		idle:      make(chan *pooledClient, options.Size),
+		ctx:       poolCtx,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+
+	// Start all CLIs at once
+	clients := make([]*ClaudeSDKClient, options.Size)
+	errs := make([]error, options.Size)
+	var wg sync.WaitGroup
+	for i := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			clients[i], errs[i] = p.connect(ctx)
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			for _, client := range clients {
+				if client != nil {
+					client.Close()
+				}
+			}
+			cancel()
+			return nil, err
+		}
+	}
+
+	for _, client := range clients {
+		p.idle <- &pooledClient{client: client}
+	}
+	p.logger.Info("client pool started", "size", options.Size)
+	return p, nil
+}
+
+// Query sends prompt to an idle client and waits for its result. It blocks
+// while all clients are busy.
+func (p *ClientPool) Query(ctx context.Context, prompt string) (*types.Turn, error) {
+	var slot *pooledClient
+	select {
+	case slot = <-p.idle:
+	case <-p.done:
+		return nil, errors.NewCLIConnectionError("client pool closed", nil)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if slot.client == nil {
+		client, err := p.connect(ctx)
+		if err != nil {
+			p.put(slot)
+			return nil, err
+		}
+		slot.client = client
+	}
+
+	turn, err := slot.client.SendAndWait(ctx, prompt, "default")
+	slot.turns++
+
+	if err != nil || p.exhausted(slot) {
+		p.replace(slot)
+	} else {
+		p.put(slot)
+	}
+	return turn, err
+}
+
+// Close closes idle clients and stops replacing retired ones. Clients busy
+// with a prompt are closed once their Query returns.
+func (p *ClientPool) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	close(p.done)
+	p.mu.Unlock()
+
+	p.cancel()
+	p.wg.Wait()
+
+	for {
+		select {
+		case slot := <-p.idle:
+			if slot.client != nil {
+				slot.client.Close()
+			}
+		default:
+			return nil
+		}
+	}
+}
+
+// connect starts a client and drains its errors, which a pool has no
+// caller to deliver to. The client lives as long as the pool; ctx only
+// bounds the handshake.
+func (p *ClientPool) connect(ctx context.Context) (*ClaudeSDKClient, error) {
+	client := p.newClient()
+	stop := context.AfterFunc(ctx, func() { client.Close() })
+	err := client.Connect(p.ctx, nil)
+	if !stop() {
+		client.Close()
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	go p.drainErrors(client.errors, client.ctx.Done())
+	return client, nil
+}
+
+// drainErrors logs errors until errs is closed or done
+func (p *ClientPool) drainErrors(errs <-chan error, done <-chan struct{}) {
+	for {
+		select {
+		case err, ok := <-errs:
+			if !ok {
+				return
+			}
+			p.logger.Warn("pooled client error", "error", err)
+		case <-done:
+			return
+		}
+	}
+}
+
+// exhausted reports whether a client has reached its turn or memory limit
+func (p *ClientPool) exhausted(slot *pooledClient) bool {
+	if slot.turns >= p.options.MaxTurnsPerClient {
+		return true
+	}
+	if p.options.MaxMemoryBytes == 0 {
+		return false
+	}
+
+	rss, ok := processRSS(slot.client.processID())
+	if ok && rss > p.options.MaxMemoryBytes {
+		p.logger.Info("retiring pooled client over memory limit", "rss", rss, "limit", p.options.MaxMemoryBytes)
+		return true
+	}
+	return false
+}
+
+// replace closes a slot's client and connects a new one in the background
+func (p *ClientPool) replace(slot *pooledClient) {
+	old := slot.client
+	slot.client = nil
+	slot.turns = 0
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		old.Close()
+		return
+	}
+	p.wg.Add(1)
+	p.mu.Unlock()
+
+	go func() {
+		defer p.wg.Done()
+
+		old.Close()
+		client, err := p.connect(p.ctx)
+		if err != nil {
+			// The slot connects again when it is next used
+			p.logger.Warn("failed to replace pooled client", "error", err)
+		}
+		slot.client = client
+		p.put(slot)
+	}()
+}
+
+// put returns a slot to the idle queue, or closes its client if the pool is
+// closed
+func (p *ClientPool) put(slot *pooledClient) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		if slot.client != nil {
+			slot.client.Close()
+		}
+		return
+	}
+	p.idle <- slot
+}
+
+// processID returns the PID of the CLI, or 0 if unknown
+func (c *ClaudeSDKClient) processID() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if t, ok := c.transport.(interface{ PID() int }); ok {
+		return t.PID()
+	}
+	return 0
+}
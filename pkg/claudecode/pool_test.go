@@ -0,0 +1,169 @@
+package claudecode
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/transporttest"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+func TestClientPoolRecyclesClients(t *testing.T) {
+	var spawned atomic.Int32
+	newClient := func() *ClaudeSDKClient {
+		n := spawned.Add(1)
+		mock := transporttest.NewMockTransport()
+		mock.Respond(transporttest.MatchType("user"),
+			textMessage(string(rune('A'+n-1))),
+			map[string]interface{}{"type": "result", "subtype": "success", "session_id": "s1"},
+		)
+		return NewClaudeSDKClientWithTransport(nil, mock)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pool, err := newClientPool(ctx, PoolOptions{Size: 1, MaxTurnsPerClient: 2}, nopLogger, newClient)
+	if err != nil {
+		t.Fatalf("newClientPool: %v", err)
+	}
+	defer pool.Close()
+
+	var answers string
+	for i := 0; i < 3; i++ {
+		turn, err := pool.Query(ctx, "hello")
+		if err != nil {
+			t.Fatalf("Query %d: %v", i, err)
+		}
+		answers += turn.Text()
+	}
+
+	// The first client answers twice before it is replaced
+	if answers != "AAB" {
+		t.Errorf("answers = %q, want AAB", answers)
+	}
+	if n := spawned.Load(); n != 2 {
+		t.Errorf("spawned %d clients, want 2", n)
+	}
+
+	pool.Close()
+	if _, err := pool.Query(ctx, "hello"); err == nil {
+		t.Error("expected an error from a closed pool")
+	}
+}
+
+func TestNewClientPool(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	var started atomic.Int32
+	options := types.NewOptions().
+		WithCLIPath("/nonexistent/claude").
+		WithCommandFactory(func(ctx context.Context, path string, args []string) *exec.Cmd {
+			if cmd := versionCommand(ctx, args); cmd != nil {
+				return cmd
+			}
+			started.Add(1)
+			return exec.CommandContext(ctx, "sh", "-c", echoCLI)
+		})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// The clients outlive the context the pool was started with
+	startCtx, stopStart := context.WithCancel(ctx)
+	pool, err := NewClientPool(startCtx, options, PoolOptions{Size: 2})
+	stopStart()
+	if err != nil {
+		t.Fatalf("NewClientPool: %v", err)
+	}
+	defer pool.Close()
+
+	if n := started.Load(); n != 2 {
+		t.Errorf("started %d CLIs, want 2", n)
+	}
+	turn, err := pool.Query(ctx, "hello")
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if turn.Text() != "hello" {
+		t.Errorf("Text() = %q, want hello", turn.Text())
+	}
+
+	invalid := types.NewOptions()
+	invalid.MaxConcurrentPermissionRequests = -1
+	if _, err := NewClientPool(ctx, invalid, PoolOptions{}); err == nil {
+		t.Error("expected an error for invalid options")
+	}
+}
+
+func TestClientPoolDrainsErrors(t *testing.T) {
+	var logs syncBuffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+
+	mock := transporttest.NewMockTransport()
+	newClient := func() *ClaudeSDKClient {
+		return NewClaudeSDKClientWithTransport(nil, mock)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pool, err := newClientPool(ctx, PoolOptions{Size: 1}, logger, newClient)
+	if err != nil {
+		t.Fatalf("newClientPool: %v", err)
+	}
+
+	mock.EmitMalformed()
+	for !strings.Contains(logs.String(), "pooled client error") {
+		select {
+		case <-ctx.Done():
+			t.Fatal("error not logged")
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+
+	pool.Close()
+
+	// A closed error channel ends the drain even while the client's
+	// context is still live
+	errs := make(chan error)
+	close(errs)
+	drained := make(chan struct{})
+	go func() {
+		pool.drainErrors(errs, make(chan struct{}))
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		t.Fatal("drainErrors kept reading a closed channel")
+	}
+	if n := strings.Count(logs.String(), "pooled client error"); n != 1 {
+		t.Errorf("logged %d pooled client errors, want 1:\n%s", n, logs.String())
+	}
+}
+
+func TestProcessRSS(t *testing.T) {
+	rss, ok := processRSS(os.Getpid())
+	if runtime.GOOS != "linux" {
+		if ok {
+			t.Errorf("processRSS = %d, true; only Linux is supported", rss)
+		}
+		return
+	}
+	if !ok || rss == 0 {
+		t.Errorf("processRSS(self) = %d, %v", rss, ok)
+	}
+	if _, ok := processRSS(-1); ok {
+		t.Error("expected no RSS for a process that does not exist")
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package claudecode
+
+// processRSS is only implemented on Linux; elsewhere memory limits are not
+// enforced
+func processRSS(pid int) (uint64, bool) {
+	return 0, false
+}
@@ -0,0 +1,126 @@
+package claudecode
+
+import (
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/tools"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// progressTracker derives progress events from the message stream
+type progressTracker struct {
+	callback types.ProgressCallback
+	maxTurns int
+
+	start         time.Time
+	turn          int
+	lastAssistant bool
+	tools         map[string]string // tool use ID -> tool name
+}
+
+// newProgressTracker returns nil when no progress callback is configured
+func newProgressTracker(options *types.ClaudeCodeOptions) *progressTracker {
+	if options == nil || options.OnProgress == nil {
+		return nil
+	}
+
+	p := &progressTracker{
+		callback: options.OnProgress,
+		tools:    make(map[string]string),
+	}
+	if options.MaxTurns != nil {
+		p.maxTurns = *options.MaxTurns
+	}
+	return p
+}
+
+// observe inspects a message and fires the matching progress events
+func (p *progressTracker) observe(msg types.Message) {
+	if p == nil {
+		return
+	}
+
+	if p.start.IsZero() {
+		p.start = time.Now()
+	}
+
+	switch m := msg.(type) {
+	case *types.AssistantMessage:
+		if !p.lastAssistant {
+			p.turn++
+			p.emit(types.ProgressEvent{Type: types.ProgressEventTurn})
+		}
+		p.lastAssistant = true
+
+		for _, block := range m.Content {
+			toolUse, ok := block.(*types.ToolUseBlock)
+			if !ok {
+				continue
+			}
+
+			p.tools[toolUse.ID] = toolUse.Name
+			p.emit(types.ProgressEvent{
+				Type:      types.ProgressEventToolStart,
+				ToolName:  toolUse.Name,
+				ToolUseID: toolUse.ID,
+			})
+
+			if toolUse.Name == tools.NameTodoWrite {
+				p.emit(types.ProgressEvent{
+					Type:  types.ProgressEventTodoUpdate,
+					Todos: parseTodos(toolUse.Input),
+				})
+			}
+		}
+	case *types.UserMessage:
+		p.lastAssistant = false
+
+		blocks, ok := m.Content.([]types.ContentBlock)
+		if !ok {
+			return
+		}
+		for _, block := range blocks {
+			result, ok := block.(*types.ToolResultBlock)
+			if !ok {
+				continue
+			}
+
+			name := p.tools[result.ToolUseID]
+			delete(p.tools, result.ToolUseID)
+			p.emit(types.ProgressEvent{
+				Type:      types.ProgressEventToolStop,
+				ToolName:  name,
+				ToolUseID: result.ToolUseID,
+				IsError:   result.IsError != nil && *result.IsError,
+			})
+		}
+	case *types.ResultMessage:
+		if m.NumTurns > 0 {
+			p.turn = m.NumTurns
+		}
+		p.emit(types.ProgressEvent{Type: types.ProgressEventDone, IsError: m.IsError})
+
+		// The next message starts a new query
+		p.start = time.Time{}
+		p.turn = 0
+		p.lastAssistant = false
+		p.tools = make(map[string]string)
+	}
+}
+
+// emit fills in the turn and timing fields and invokes the callback
+func (p *progressTracker) emit(event types.ProgressEvent) {
+	event.Turn = p.turn
+	event.MaxTurns = p.maxTurns
+	event.Elapsed = time.Since(p.start)
+	p.callback(event)
+}
+
+// parseTodos decodes the todos argument of a TodoWrite tool call
+func parseTodos(input map[string]interface{}) []types.TodoItem {
+	todoWrite, err := tools.DecodeAs[tools.TodoWriteInput](input)
+	if err != nil {
+		return nil
+	}
+	return todoWrite.Todos
+}
@@ -0,0 +1,61 @@
+package claudecode
+
+import (
+	"testing"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+func TestProgressTracker(t *testing.T) {
+	var events []types.ProgressEvent
+	maxTurns := 5
+	tracker := newProgressTracker(&types.ClaudeCodeOptions{
+		MaxTurns:   &maxTurns,
+		OnProgress: func(e types.ProgressEvent) { events = append(events, e) },
+	})
+
+	tracker.observe(&types.AssistantMessage{
+		Content: []types.ContentBlock{
+			&types.ToolUseBlock{ID: "t1", Name: "TodoWrite", Input: map[string]interface{}{
+				"todos": []interface{}{
+					map[string]interface{}{"content": "Write tests", "status": "in_progress"},
+				},
+			}},
+		},
+	})
+	tracker.observe(&types.UserMessage{
+		Content: []types.ContentBlock{&types.ToolResultBlock{ToolUseID: "t1"}},
+	})
+	tracker.observe(&types.AssistantMessage{Content: []types.ContentBlock{&types.TextBlock{Text: "Done"}}})
+	tracker.observe(&types.ResultMessage{NumTurns: 2})
+
+	want := []types.ProgressEventType{
+		types.ProgressEventTurn,
+		types.ProgressEventToolStart,
+		types.ProgressEventTodoUpdate,
+		types.ProgressEventToolStop,
+		types.ProgressEventTurn,
+		types.ProgressEventDone,
+	}
+	if len(events) != len(want) {
+		t.Fatalf("Expected %d events, got %d", len(want), len(events))
+	}
+	for i, e := range events {
+		if e.Type != want[i] {
+			t.Errorf("Event %d: expected %s, got %s", i, want[i], e.Type)
+		}
+		if e.MaxTurns != maxTurns {
+			t.Errorf("Event %d: expected max turns %d, got %d", i, maxTurns, e.MaxTurns)
+		}
+	}
+
+	if len(events[2].Todos) != 1 || events[2].Todos[0].Content != "Write tests" {
+		t.Errorf("Unexpected todos: %+v", events[2].Todos)
+	}
+	if events[3].ToolName != "TodoWrite" {
+		t.Errorf("Expected tool stop for TodoWrite, got %s", events[3].ToolName)
+	}
+	if events[5].Turn != 2 {
+		t.Errorf("Expected final turn 2, got %d", events[5].Turn)
+	}
+}
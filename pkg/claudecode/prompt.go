@@ -0,0 +1,100 @@
+package claudecode
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// promptMessage converts an item of a prompt channel to its stream-json
+// message. Strings and content blocks become user messages of the default
+// session; maps are sent as they are.
+func promptMessage(item interface{}) (map[string]interface{}, error) {
+	var content interface{}
+	switch v := item.(type) {
+	case map[string]interface{}:
+		return v, nil
+	case string:
+		content = v
+	case []types.ContentBlock:
+		blocks := make([]interface{}, 0, len(v))
+		for i, block := range v {
+			payload, err := contentBlockPayload(block)
+			if err != nil {
+				return nil, errors.NewValidationError(fmt.Sprintf("prompt[%d]", i), err.Error())
+			}
+			blocks = append(blocks, payload)
+		}
+		content = blocks
+	default:
+		return nil, errors.NewValidationError("prompt", fmt.Sprintf("unsupported prompt item of type %T", item))
+	}
+
+	return map[string]interface{}{
+		"type": "user",
+		"message": map[string]interface{}{
+			"role":    "user",
+			"content": content,
+		},
+		"parent_tool_use_id": nil,
+		"session_id":         "default",
+	}, nil
+}
+
+// streamInput writes the items of a Query prompt channel to t, calling sent
+// after each one and reporting failures on errs. Once ch is closed stdin is
+// closed too, so the CLI finishes the queued turns and exits. A non-nil hold
+// delays closing until it is closed, while the CLI may still send control
+// requests that need answering.
+func streamInput(ctx context.Context, t transport.Transport, ch chan interface{}, hold <-chan struct{}, sent func(), errs chan<- error) {
+	report := func(err error) bool {
+		select {
+		case errs <- err:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case item, ok := <-ch:
+			if !ok {
+				if hold != nil {
+					select {
+					case <-hold:
+					case <-ctx.Done():
+						return
+					}
+				}
+				if closer, ok := t.(transport.GracefulCloser); ok {
+					if err := closer.CloseInput(); err != nil {
+						report(errors.NewCLIConnectionError("failed to close stdin", err))
+					}
+				}
+				return
+			}
+
+			message, err := promptMessage(item)
+			if err == nil {
+				var data []byte
+				if data, err = json.Marshal(message); err == nil {
+					err = t.Write(ctx, append(data, '\n'))
+				}
+			}
+			if err != nil {
+				if !report(err) {
+					return
+				}
+				continue
+			}
+			sent()
+		}
+	}
+}
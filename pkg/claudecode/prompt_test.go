@@ -0,0 +1,148 @@
+package claudecode
+
+import (
+	"context"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// echoCLI answers control requests and echoes every user message it reads
+// as a turn, exiting once stdin is closed
+const echoCLI = `
+echo '{"type":"system","subtype":"init","session_id":"s1","model":"sonnet"}'
+while IFS= read -r line; do
+  case "$line" in
+  *'"control_request"'*)
+    id=$(echo "$line" | sed 's/.*"request_id":"\([^"]*\)".*/\1/')
+    echo '{"type":"control_response","response":{"subtype":"success","request_id":"'"$id"'","response":{}}}'
+    ;;
+  *'"type":"user"'*)
+    text=$(echo "$line" | sed 's/.*"content":"\([^"]*\)".*/\1/')
+    echo '{"type":"assistant","model":"sonnet","content":[{"type":"text","text":"'"$text"'"}]}'
+    echo '{"type":"result","subtype":"success","result":"'"$text"'","session_id":"s1"}'
+    ;;
+  esac
+done
+`
+
+// versionCommand answers the `claude --version` probe run through a command
+// factory. Returns nil for any other command.
+func versionCommand(ctx context.Context, args []string) *exec.Cmd {
+	if !slices.Contains(args, "--version") {
+		return nil
+	}
+	return exec.CommandContext(ctx, "echo", "2.0.0 (Claude Code)")
+}
+
+func TestQueryStreamingPrompt(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	var args []string
+	options := types.NewOptions().
+		WithCLIPath("/nonexistent/claude").
+		WithCommandFactory(func(ctx context.Context, path string, cliArgs []string) *exec.Cmd {
+			args = cliArgs
+			return exec.CommandContext(ctx, "sh", "-c", echoCLI)
+		})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	prompts := make(chan interface{})
+	go func() {
+		prompts <- "first"
+		prompts <- "second"
+		close(prompts)
+	}()
+
+	// Both turns are answered and the query ends when the CLI exits
+	messages, err := Query(ctx, prompts, options)
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	var texts []string
+	for msg := range messages {
+		if result, ok := msg.(*types.ResultMessage); ok {
+			texts = append(texts, *result.Result)
+		}
+	}
+	if !slices.Equal(texts, []string{"first", "second"}) {
+		t.Errorf("results = %v, want one per prompt", texts)
+	}
+
+	if i := slices.Index(args, "--input-format"); i < 0 || args[i+1] != "stream-json" {
+		t.Errorf("args missing --input-format stream-json: %v", args)
+	}
+}
+
+func TestPromptMessage(t *testing.T) {
+	message, err := promptMessage([]types.ContentBlock{&types.TextBlock{Text: "hi"}})
+	if err != nil {
+		t.Fatalf("promptMessage: %v", err)
+	}
+	content := message["message"].(map[string]interface{})["content"].([]interface{})
+	if len(content) != 1 || content[0].(map[string]interface{})["text"] != "hi" {
+		t.Errorf("unexpected content: %v", content)
+	}
+
+	if _, err := promptMessage(42); err == nil {
+		t.Error("expected an error for an unsupported item")
+	}
+}
+
+func TestQueryCLIExitsEarly(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	options := types.NewOptions().
+		WithCLIPath("/nonexistent/claude").
+		WithCommandFactory(func(ctx context.Context, path string, args []string) *exec.Cmd {
+			return exec.CommandContext(ctx, "sh", "-c", `read -r prompt; echo '{"type":"system","subtype":"init","session_id":"s1"}'; echo 'out of memory' >&2; exit 3`)
+		})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	messages, err := QuerySync(ctx, "hi", options)
+	if err == nil || !strings.Contains(err.Error(), "out of memory") {
+		t.Fatalf("expected the exit error, got %v", err)
+	}
+	if len(messages) != 2 {
+		t.Errorf("expected the init message before the error, got %v", messages)
+	}
+}
+
+func TestQueryClosesStdinAfterPrompt(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	// The CLI answers only once its input has ended
+	options := types.NewOptions().
+		WithCLIPath("/nonexistent/claude").
+		WithCommandFactory(func(ctx context.Context, path string, args []string) *exec.Cmd {
+			return exec.CommandContext(ctx, "sh", "-c", `cat >/dev/null; echo '{"type":"result","subtype":"success","result":"done","session_id":"s1"}'`)
+		})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := QuerySync(ctx, "hi", options); err != nil {
+		t.Errorf("QuerySync: %v", err)
+	}
+
+	short, cancelShort := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancelShort()
+	if messages, _ := QuerySync(short, "hi", options.WithKeepStdinOpen()); len(messages) != 0 {
+		t.Errorf("expected the CLI to wait for input with KeepStdinOpen, got %v", messages)
+	}
+}
@@ -2,6 +2,7 @@ package claudecode
 
 import (
 	"context"
+	"iter"
 	"os"
 
 	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
@@ -22,6 +23,10 @@ import (
 //   - Simple: Fire-and-forget style, no connection management
 //   - No interrupts: Cannot interrupt or send follow-up messages
 //
+// CanUseTool and hooks, such as those installed by WithGuardrails, are
+// consulted as they are by ClaudeSDKClient; a string prompt is then sent as
+// a stream-json message.
+//
 // When to use Query():
 //   - Simple one-off questions ("What is 2+2?")
 //   - Batch processing of independent prompts
@@ -48,10 +53,9 @@ import (
 //
 // Example - With options:
 //
-//	options := &types.ClaudeCodeOptions{
-//	    SystemPrompt: stringPtr("You are an expert Python developer"),
-//	    CWD: stringPtr("/home/user/project"),
-//	}
+//	options := NewOptions().
+//	    WithSystemPrompt("You are an expert Python developer").
+//	    WithCWD("/home/user/project")
 //	messages, err := Query(ctx, "Create a Python web server", options)
 //	if err != nil {
 //	    log.Fatal(err)
@@ -62,6 +66,10 @@ import (
 //
 // Example - Streaming mode (still unidirectional):
 //
+// Each item of a channel prompt is sent as a user message: a string, a
+// []ContentBlock, or a raw stream-json message map. Closing the channel ends
+// the input; the CLI then answers the remaining messages and exits.
+//
 //	prompts := make(chan interface{})
 //	go func() {
 //	    prompts <- "Hello"
@@ -84,110 +92,314 @@ func Query(ctx context.Context, prompt interface{}, options *types.ClaudeCodeOpt
 	os.Setenv("CLAUDE_CODE_ENTRYPOINT", "sdk-go")
 
 	// Create channels
-	messages := make(chan types.Message, 100)
+	messages := make(chan types.Message, messageBufferSize(options))
 
 	// Start query in goroutine
 	go func() {
 		defer close(messages)
 
-		// Create transport
-		t := transport.NewSubprocessTransport(prompt, options, "")
-
-		// Connect
-		if err := t.Connect(ctx); err != nil {
-			messages <- &types.SystemMessage{
-				Subtype: "error",
-				Data: map[string]interface{}{
-					"error": err.Error(),
-				},
+		runQuery(ctx, prompt, options, "", func(msg types.Message, err error) bool {
+			if err != nil {
+				// Cancellation ends the stream silently
+				if ctx.Err() != nil {
+					return false
+				}
+				msg = errorMessage(err)
 			}
-			return
+			messages <- msg
+			return true
+		})
+	}()
+
+	return messages, nil
+}
+
+// QueryIter performs a query like Query but returns an iterator that yields
+// each message together with a real error value instead of wrapping errors
+// into SystemMessage{Subtype: "error"}.
+//
+// Iteration stops after the ResultMessage, when ctx is done (yielding
+// ctx.Err()) or when the loop body breaks, which also shuts down the CLI.
+//
+// Example:
+//
+//	for msg, err := range claudecode.QueryIter(ctx, "What is 2+2?", nil) {
+//	    if err != nil {
+//	        log.Printf("error: %v", err)
+//	        continue
+//	    }
+//	    fmt.Println(msg)
+//	}
+func QueryIter(ctx context.Context, prompt interface{}, options *types.ClaudeCodeOptions) iter.Seq2[types.Message, error] {
+	return func(yield func(types.Message, error) bool) {
+		if options == nil {
+			options = &types.ClaudeCodeOptions{}
 		}
-		defer t.Close()
 
-		// Create query handler
-		isStreaming := false
-		if _, ok := prompt.(chan interface{}); ok {
-			isStreaming = true
+		// Set environment variable
+		os.Setenv("CLAUDE_CODE_ENTRYPOINT", "sdk-go")
+
+		runQuery(ctx, prompt, options, "", yield)
+	}
+}
+
+// runQuery drives a single query, passing every message or error to yield.
+// An empty cliPath looks up the CLI.
+// It returns once the conversation ends or yield returns false.
+func runQuery(ctx context.Context, prompt interface{}, options *types.ClaudeCodeOptions, cliPath string, yield func(types.Message, error) bool) {
+	if err := options.Validate(); err != nil {
+		yield(nil, err)
+		return
+	}
+	if _, ok := prompt.(string); !ok && options.OutputFormat == types.OutputFormatJSON {
+		yield(nil, errors.NewValidationError("OutputFormat", "json output requires a string prompt"))
+		return
+	}
+
+	// A channel prompt is consumed by the first attempt and cannot be retried
+	if _, ok := prompt.(chan interface{}); ok || options.Retry == nil {
+		runAttempt(ctx, prompt, options, cliPath, yield)
+		return
+	}
+	retryQuery(ctx, prompt, options, cliPath, yield)
+}
+
+// runAttempt starts the CLI once and drives the query like runQuery
+func runAttempt(ctx context.Context, prompt interface{}, options *types.ClaudeCodeOptions, cliPath string, yield func(types.Message, error) bool) {
+	// Streamed prompts are not known up front and are limited as a whole
+	text, _ := prompt.(string)
+	done, err := acquireTurn(ctx, options, text)
+	if err != nil {
+		yield(nil, err)
+		return
+	}
+	var usage *types.Usage
+	defer func() { done(usage) }()
+
+	if options.OutputFormat == types.OutputFormatJSON {
+		usage = runJSONOutput(ctx, text, options, cliPath, yield)
+		return
+	}
+
+	// CanUseTool and hooks are asked over the control protocol, so a string
+	// prompt is streamed like a channel one and stdin kept open for them
+	control := options.CanUseTool != nil || len(options.Hooks) > 0
+	if options.CanUseTool != nil {
+		opts := *options
+		opts.PermissionPromptToolName = stringPtr("stdio")
+		options = &opts
+	}
+	if _, ok := prompt.(string); ok && control {
+		ch := make(chan interface{}, 1)
+		ch <- text
+		close(ch)
+		prompt = ch
+	}
+
+	// Create transport
+	t := transport.NewSubprocessTransport(prompt, options, cliPath)
+
+	// Connect
+	if err := t.Connect(ctx); err != nil {
+		yield(nil, err)
+		return
+	}
+
+	// Create query handler
+	isStreaming := false
+	if _, ok := prompt.(chan interface{}); ok {
+		isStreaming = true
+	}
+
+	progress := newProgressTracker(options)
+	dead := newDeadLetterSink(options)
+	parser := newMessageParser(options)
+	telemetry := newTelemetry(options)
+	telemetry.startQuery(ctx)
+	defer telemetry.end()
+
+	query := internal.NewQuery(
+		ctx,
+		t,
+		isStreaming,
+		options.CanUseTool,
+		options.Hooks,
+		extractSDKMCPServers(options),
+	)
+
+	query.SetLogger(options.Logger)
+	query.SetTelemetry(telemetry.controlRequests())
+	query.SetMaxLineSize(options.MaxMessageSize)
+	query.SetRawOutput(options.RawOutput)
+
+	// Start query
+	if err := query.Start(); err != nil {
+		t.Close()
+		yield(nil, err)
+		return
+	}
+	// Close the transport first so the read loop unblocks before Stop
+	defer func() {
+		t.Close()
+		query.Stop()
+	}()
+
+	// Initialize
+	if err := query.Initialize(); err != nil {
+		yield(nil, err)
+		return
+	}
+
+	// A streamed prompt starts a turn with every message sent
+	stall := newStallMonitor(options)
+	if !isStreaming {
+		stall.turnStarted()
+	}
+	ticks, stopTicks := stallTicks(stall)
+	defer stopTicks()
+
+	// Stream a channel prompt. SDK MCP servers, CanUseTool and hooks are
+	// served over stdin, so it stays open until the first result.
+	var inputErrs chan error
+	var firstResult chan struct{}
+	if ch, ok := prompt.(chan interface{}); ok {
+		inputCtx, stopInput := context.WithCancel(ctx)
+		defer stopInput()
+
+		var hold <-chan struct{}
+		firstResult = make(chan struct{})
+		if control || len(extractSDKMCPServers(options)) > 0 {
+			hold = firstResult
 		}
+		inputErrs = make(chan error)
+		go streamInput(inputCtx, t, ch, hold, stall.turnStarted, inputErrs)
+	}
 
-		query := internal.NewQuery(
-			t,
-			isStreaming,
-			nil, // No canUseTool for one-shot queries
-			nil, // No hooks for one-shot queries
-			nil, // No SDK MCP servers for one-shot queries
-		)
-
-		// Start query
-		if err := query.Start(); err != nil {
-			messages <- &types.SystemMessage{
-				Subtype: "error",
-				Data: map[string]interface{}{
-					"error": err.Error(),
-				},
+	// handle passes on a message read from the CLI. Returns false once the
+	// query is over.
+	var costs costDelta
+	handle := func(data map[string]interface{}) bool {
+		msg, err := parser.parse(optionsLogger(options), data)
+		if err != nil {
+			optionsLogger(options).Warn("failed to parse message", "type", data["type"], "error", err)
+			if dead.parseFailed(data, err) {
+				return true
 			}
-			return
+			return yield(nil, err)
+		}
+
+		if options.Transcript != nil {
+			options.Transcript.Record(data)
 		}
-		defer query.Stop()
-
-		// Initialize
-		if err := query.Initialize(); err != nil {
-			messages <- &types.SystemMessage{
-				Subtype: "error",
-				Data: map[string]interface{}{
-					"error": err.Error(),
-				},
+		if result, isResult := msg.(*types.ResultMessage); isResult && result.TotalCostUSD != nil {
+			result.TurnCostUSD = costs.next(*result.TotalCostUSD)
+		}
+		progress.observe(msg)
+		telemetry.observe(msg)
+		stall.observe(msg)
+		if !yield(msg, nil) {
+			return false
+		}
+
+		// Check if we got a result message (end of conversation). A
+		// streamed prompt ends once the CLI exits after its last turn.
+		if result, isResult := msg.(*types.ResultMessage); isResult {
+			if firstResult == nil {
+				usage = result.Usage
+				return false
 			}
-			return
+			if usage == nil {
+				close(firstResult)
+				usage = &types.Usage{}
+			}
+			usage.Add(result.Usage)
+		}
+		return true
+	}
+
+	// fail passes on an error reading from the CLI
+	fail := func(err error) bool {
+		if dead.readFailed(err) {
+			return true
 		}
+		return yield(nil, err)
+	}
 
-		// Process messages
-		for {
-			select {
-			case <-ctx.Done():
+	// Process messages
+	for {
+		select {
+		case <-ctx.Done():
+			yield(nil, ctx.Err())
+			return
+		case data, ok := <-query.ReceiveMessages():
+			if !ok || !handle(data) {
 				return
-			case data, ok := <-query.ReceiveMessages():
-				if !ok {
-					return
-				}
-
-				msg, err := internal.ParseMessage(data)
-				if err != nil {
-					messages <- &types.SystemMessage{
-						Subtype: "error",
-						Data: map[string]interface{}{
-							"error": err.Error(),
-						},
+			}
+		case <-ticks:
+			if err := checkStall(stall, query); err != nil {
+				yield(nil, err)
+				return
+			}
+		case err := <-inputErrs:
+			if !yield(nil, err) {
+				return
+			}
+		case err, ok := <-query.Errors():
+			if !ok || !fail(err) {
+				return
+			}
+		case <-query.Done():
+			// The CLI exited; pass on what it wrote before that
+			for {
+				select {
+				case data := <-query.ReceiveMessages():
+					if !handle(data) {
+						return
+					}
+				case err := <-query.Errors():
+					if !fail(err) {
+						return
+					}
+				default:
+					if usage == nil {
+						yield(nil, exitedEarly(ctx, t))
 					}
-					continue
-				}
-
-				messages <- msg
-
-				// Check if we got a result message (end of conversation)
-				if _, isResult := msg.(*types.ResultMessage); isResult {
-					return
-				}
-			case err, ok := <-query.Errors():
-				if !ok {
 					return
 				}
-
-				messages <- &types.SystemMessage{
-					Subtype: "error",
-					Data: map[string]interface{}{
-						"error": err.Error(),
-					},
-				}
 			}
 		}
-	}()
+	}
+}
 
-	return messages, nil
+// exitedEarly returns the error of a CLI that stopped writing before any
+// result
+func exitedEarly(ctx context.Context, t *transport.SubprocessTransport) error {
+	select {
+	case <-t.Exited():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if err := t.GetExitError(); err != nil {
+		return err
+	}
+	return errors.NewCLIConnectionError("CLI exited before the result", nil)
 }
 
-// QuerySync performs a synchronous query and collects all messages
+// errorMessage wraps an error into the SystemMessage form used by Query
+func errorMessage(err error) *types.SystemMessage {
+	return &types.SystemMessage{
+		Subtype: "error",
+		Data: map[string]interface{}{
+			"error": err.Error(),
+		},
+	}
+}
+
+// QuerySync performs a synchronous query and collects all messages.
+//
+// A failed result is returned as an error along with the messages: a
+// MaxTurnsExceededError when MaxTurns was reached, an ExecutionError
+// otherwise (see ResultMessage.Err).
 func QuerySync(ctx context.Context, prompt string, options *types.ClaudeCodeOptions) ([]types.Message, error) {
 	msgChan, err := Query(ctx, prompt, options)
 	if err != nil {
@@ -204,6 +416,11 @@ func QuerySync(ctx context.Context, prompt string, options *types.ClaudeCodeOpti
 				return messages, errors.NewCLIConnectionError(errStr, nil)
 			}
 		}
+		if result, ok := msg.(*types.ResultMessage); ok {
+			if err := result.Err(); err != nil {
+				return messages, err
+			}
+		}
 	}
 
 	return messages, nil
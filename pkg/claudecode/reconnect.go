@@ -0,0 +1,183 @@
+package claudecode
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/internal"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// Reconnect defaults
+const (
+	defaultReconnectRetries = 3
+	defaultReconnectBackoff = 500 * time.Millisecond
+	defaultReconnectMaxWait = 30 * time.Second
+)
+
+// writeUserMessage writes a user message sent with the given session_id
+// and, with a reconnect policy, remembers it until a result acknowledges
+// the turn. Fails with a BudgetExceededError once a budget is spent.
+func (c *ClaudeSDKClient) writeUserMessage(ctx context.Context, sessionID string, data []byte) error {
+	if err := c.budget.check(); err != nil {
+		return err
+	}
+
+	// Started first, as the turn's messages may arrive before write returns
+	t := c.startTurn(sessionID)
+	if err := c.write(ctx, data); err != nil {
+		c.abortTurn(t)
+		return err
+	}
+	c.turnsSent.Add(1)
+	c.telemetry.startQuery(ctx)
+	c.stall.turnStarted()
+
+	if c.options.Reconnect != nil {
+		c.resumeMu.Lock()
+		c.unacked = append(c.unacked, data)
+		c.resumeMu.Unlock()
+	}
+	return nil
+}
+
+// trackSession remembers the CLI session to resume and forgets user
+// messages once their turn has a result
+func (c *ClaudeSDKClient) trackSession(msg types.Message) {
+	c.resumeMu.Lock()
+	defer c.resumeMu.Unlock()
+
+	switch m := msg.(type) {
+	case *types.InitMessage:
+		if m.SessionID != "" {
+			c.sessionID = m.SessionID
+		}
+	case *types.ResultMessage:
+		if m.SessionID != "" {
+			c.sessionID = m.SessionID
+		}
+		c.unacked = nil
+	}
+}
+
+// reconnect replaces a query whose transport ended with a freshly spawned
+// CLI resuming the same session, following the ReconnectPolicy
+func (c *ClaudeSDKClient) reconnect(old *internal.Query) (*internal.Query, error) {
+	policy := c.options.Reconnect
+
+	retries := policy.MaxRetries
+	if retries == 0 {
+		retries = defaultReconnectRetries
+	}
+	backoff := policy.InitialBackoff
+	if backoff == 0 {
+		backoff = defaultReconnectBackoff
+	}
+	maxBackoff := policy.MaxBackoff
+	if maxBackoff == 0 {
+		maxBackoff = defaultReconnectMaxWait
+	}
+
+	c.logger().Warn("CLI connection lost, reconnecting", "max_retries", retries)
+
+	// Tear down the dead connection
+	c.mu.Lock()
+	if !c.connected {
+		c.mu.Unlock()
+		return nil, errors.NewCLIConnectionError("client closed", nil)
+	}
+	c.transport.Close()
+	old.Stop()
+	c.mu.Unlock()
+
+	var lastErr error
+	for attempt := 1; attempt <= retries; attempt++ {
+		select {
+		case <-time.After(backoff):
+		case <-c.ctx.Done():
+			return nil, c.ctx.Err()
+		}
+
+		query, replayed, err := c.respawn()
+		if err == nil {
+			c.resumeMu.Lock()
+			sessionID := c.sessionID
+			c.resumeMu.Unlock()
+
+			c.logger().Info("reconnected", "attempt", attempt, "replayed", replayed)
+
+			event := &types.ReconnectedMessage{
+				SystemMessage: types.SystemMessage{Subtype: types.SystemSubtypeReconnected},
+				Attempt:       attempt,
+				SessionID:     sessionID,
+				Replayed:      replayed,
+			}
+			if !c.sendMessage(event) {
+				return nil, c.ctx.Err()
+			}
+			return query, nil
+		}
+		lastErr = err
+		c.logger().Warn("reconnect attempt failed", "attempt", attempt, "error", err)
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+
+	return nil, errors.NewCLIConnectionError(fmt.Sprintf("failed to reconnect after %d attempts", retries), lastErr)
+}
+
+// respawn connects a new transport that resumes the current session and
+// sends the unacknowledged user messages again
+func (c *ClaudeSDKClient) respawn() (*internal.Query, int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.connected {
+		return nil, 0, errors.NewCLIConnectionError("client closed", nil)
+	}
+
+	c.resumeMu.Lock()
+	sessionID := c.sessionID
+	pending := append([][]byte(nil), c.unacked...)
+	c.resumeMu.Unlock()
+
+	if c.customTransport != nil {
+		c.transport = c.customTransport
+		if c.options.Logger != nil {
+			c.transport.SetLogger(c.options.Logger)
+		}
+	} else {
+		options := *c.options
+		if sessionID != "" {
+			options.Resume = &sessionID
+			options.ContinueConversation = false
+			options.ForkSession = false
+		}
+		c.transport = transport.NewSubprocessTransport(nil, &options, "")
+	}
+
+	if err := c.transport.Connect(c.ctx); err != nil {
+		return nil, 0, err
+	}
+
+	if err := c.startQuery(); err != nil {
+		c.transport.Close()
+		return nil, 0, err
+	}
+
+	for _, data := range pending {
+		if err := c.wire.Write(c.ctx, data); err != nil {
+			c.transport.Close()
+			c.query.Stop()
+			return nil, 0, err
+		}
+	}
+
+	return c.query, len(pending), nil
+}
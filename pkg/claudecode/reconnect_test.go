@@ -0,0 +1,79 @@
+package claudecode
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/transporttest"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+func TestReconnectReplaysUnacknowledgedMessages(t *testing.T) {
+	mock := transporttest.NewMockTransport()
+	mock.RespondOnce(transporttest.MatchType("user"),
+		map[string]interface{}{"type": "system", "subtype": "init", "session_id": "s1"},
+	)
+	mock.Respond(transporttest.MatchType("user"),
+		map[string]interface{}{"type": "result", "subtype": "success", "session_id": "s1"},
+	)
+
+	options := &types.ClaudeCodeOptions{
+		Reconnect: &types.ReconnectPolicy{InitialBackoff: time.Millisecond},
+	}
+	client := NewClaudeSDKClientWithTransport(options, mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.SendMessage("hello", "default"); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+
+	next := func() types.Message {
+		t.Helper()
+		select {
+		case msg := <-client.Messages():
+			return msg
+		case err := <-client.Errors():
+			t.Fatalf("unexpected error: %v", err)
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for message")
+		}
+		return nil
+	}
+
+	if _, ok := next().(*types.InitMessage); !ok {
+		t.Fatal("expected init message")
+	}
+
+	// The CLI dies before the turn has a result
+	mock.Disconnect(nil)
+
+	reconnected, ok := next().(*types.ReconnectedMessage)
+	if !ok {
+		t.Fatal("expected reconnected message")
+	}
+	if reconnected.Attempt != 1 || reconnected.SessionID != "s1" || reconnected.Replayed != 1 {
+		t.Errorf("unexpected reconnected message: %+v", reconnected)
+	}
+
+	if _, ok := next().(*types.ResultMessage); !ok {
+		t.Fatal("expected result after replay")
+	}
+
+	users := 0
+	for _, msg := range mock.WrittenMessages() {
+		if msg["type"] == "user" {
+			users++
+		}
+	}
+	if users != 2 {
+		t.Errorf("wrote %d user messages, want 2", users)
+	}
+}
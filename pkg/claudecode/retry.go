@@ -0,0 +1,121 @@
+package claudecode
+
+import (
+	"context"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// Retry defaults
+const (
+	defaultRetryAttempts = 3
+	defaultRetryBackoff  = time.Second
+	defaultRetryMaxWait  = 30 * time.Second
+)
+
+// retryQuery runs attempts following options.Retry until one succeeds,
+// produces output, fails permanently or the attempts are used up. Messages of
+// an attempt are held back until Claude produces output, so the caller never
+// sees the start of an attempt that was retried.
+func retryQuery(ctx context.Context, prompt interface{}, options *types.ClaudeCodeOptions, cliPath string, yield func(types.Message, error) bool) {
+	policy := options.Retry
+
+	attempts := policy.MaxAttempts
+	if attempts == 0 {
+		attempts = defaultRetryAttempts
+	}
+	backoff := policy.InitialBackoff
+	if backoff == 0 {
+		backoff = defaultRetryBackoff
+	}
+	maxBackoff := policy.MaxBackoff
+	if maxBackoff == 0 {
+		maxBackoff = defaultRetryMaxWait
+	}
+	retryable := policy.Retryable
+	if retryable == nil {
+		retryable = errors.IsTransient
+	}
+
+	for attempt := 1; ; attempt++ {
+		a := &queryAttempt{yield: yield, retryable: retryable, final: attempt == attempts}
+		runAttempt(ctx, prompt, options, cliPath, a.add)
+		if a.failure == nil {
+			a.flush()
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		optionsLogger(options).Warn("query failed, retrying", "attempt", attempt, "delay", backoff, "error", a.failure)
+		if policy.OnRetry != nil {
+			policy.OnRetry(attempt+1, a.failure, backoff)
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			yield(nil, ctx.Err())
+			return
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// queryAttempt filters the messages of one attempt
+type queryAttempt struct {
+	yield     func(types.Message, error) bool
+	retryable func(error) bool
+	final     bool
+
+	held    []types.Message
+	started bool  // Claude produced output; the attempt can no longer be retried
+	failure error // Retryable error that ended the attempt
+}
+
+// add is the yield function of the attempt
+func (a *queryAttempt) add(msg types.Message, err error) bool {
+	if a.started {
+		return a.yield(msg, err)
+	}
+
+	failure := err
+	switch m := msg.(type) {
+	case *types.ResultMessage:
+		failure = m.Err()
+	case *types.AssistantMessage, *types.StreamEvent:
+	default:
+		if err == nil {
+			a.held = append(a.held, msg)
+			return true
+		}
+	}
+
+	if failure != nil && !a.final && a.retryable(failure) {
+		a.failure = failure
+		return false
+	}
+
+	// Output, a permanent error or the last attempt: pass everything on
+	return a.flush() && a.yield(msg, err)
+}
+
+// flush passes on the held messages. Returns false if the caller stopped.
+func (a *queryAttempt) flush() bool {
+	a.started = true
+	held := a.held
+	a.held = nil
+	for _, msg := range held {
+		if !a.yield(msg, nil) {
+			return false
+		}
+	}
+	return true
+}
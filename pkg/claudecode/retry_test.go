@@ -0,0 +1,81 @@
+package claudecode
+
+import (
+	"context"
+	stderrors "errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// flakyCLI fails with an overloaded API on its first run and answers on the
+// next one
+const flakyCLI = `
+n=$(cat "$COUNT" 2>/dev/null || echo 0); n=$((n+1)); echo $n > "$COUNT"
+echo '{"type":"system","subtype":"init","session_id":"s1","model":"sonnet"}'
+if [ $n -lt 2 ]; then
+  echo '{"type":"result","subtype":"success","is_error":true,"result":"API Error: 529 Overloaded","session_id":"s1"}'
+else
+  echo '{"type":"assistant","model":"sonnet","content":[{"type":"text","text":"hello"}]}'
+  echo '{"type":"result","subtype":"success","result":"hello","session_id":"s1"}'
+fi
+cat >/dev/null
+`
+
+func TestQueryRetry(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	count := filepath.Join(t.TempDir(), "count")
+	var retries []int
+	options := types.NewOptions().
+		WithCLIPath("/nonexistent/claude").
+		WithCommandFactory(func(ctx context.Context, path string, args []string) *exec.Cmd {
+			if cmd := versionCommand(ctx, args); cmd != nil {
+				return cmd
+			}
+			cmd := exec.CommandContext(ctx, "sh", "-c", flakyCLI)
+			cmd.Env = append(cmd.Environ(), "COUNT="+count)
+			return cmd
+		}).
+		WithRetry(types.RetryPolicy{
+			InitialBackoff: time.Millisecond,
+			OnRetry: func(attempt int, err error, delay time.Duration) {
+				retries = append(retries, attempt)
+			},
+		})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	messages, err := QuerySync(ctx, "hi", options)
+	if err != nil {
+		t.Fatalf("QuerySync: %v", err)
+	}
+	if len(retries) != 1 || retries[0] != 2 {
+		t.Errorf("OnRetry calls = %v, want [2]", retries)
+	}
+
+	// Only the successful attempt is visible
+	if len(messages) != 3 {
+		t.Fatalf("got %d messages, want init, assistant and result: %v", len(messages), messages)
+	}
+	if _, ok := messages[1].(*types.AssistantMessage); !ok {
+		t.Errorf("unexpected messages: %v", messages)
+	}
+
+	// The last attempt is passed through
+	options.Retry.MaxAttempts = 1
+	if err := os.Remove(count); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := QuerySync(ctx, "hi", options); !stderrors.Is(err, ErrExecution) {
+		t.Errorf("expected the failed result of the only attempt, got %v", err)
+	}
+}
@@ -0,0 +1,237 @@
+// Package script drives a conversation through a fixed list of prompts and
+// checks every turn against expectations, e.g. to regression-test agent
+// behaviour when prompts, tools or models change.
+//
+//	runner := &script.Runner{
+//	    Client: client,
+//	    Steps: []script.Step{
+//	        {Prompt: "Read main.go", Checks: []script.Check{script.UsedTool("Read")}},
+//	        {Prompt: "What does it print?", Checks: []script.Check{script.TextContains("hello")}},
+//	    },
+//	    StepTimeout: 2 * time.Minute,
+//	}
+//	report, err := runner.Run(ctx)
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Print(report)
+package script
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// Client runs one turn of a conversation, e.g. a connected
+// *claudecode.ClaudeSDKClient
+type Client interface {
+	SendAndWait(ctx context.Context, prompt string, sessionID string) (*types.Turn, error)
+}
+
+// Check inspects a completed turn and returns why it is not what the step
+// expected, or nil
+type Check func(turn *types.Turn) error
+
+// Step is one prompt of a script and what its turn must satisfy
+type Step struct {
+	// Label in the report; defaults to "step N"
+	Name   string
+	Prompt string
+	Checks []Check
+
+	// How long the turn may take; 0 uses Runner.StepTimeout
+	Timeout time.Duration
+}
+
+// Runner sends the prompts of Steps in order, waiting for each turn to end
+// before checking it and sending the next
+type Runner struct {
+	Client Client
+	Steps  []Step
+
+	// Session the prompts are sent in (default "default")
+	SessionID string
+
+	// How long each turn may take unless the step sets its own (0 = no limit)
+	StepTimeout time.Duration
+
+	// Skip the remaining steps once a check fails. A turn that fails to
+	// complete always ends the run, since the conversation is then in an
+	// unknown state.
+	StopOnFailure bool
+}
+
+// StepResult reports how a step went
+type StepResult struct {
+	Name   string
+	Prompt string
+
+	// The turn, possibly partial, or nil if the step was skipped
+	Turn     *types.Turn
+	Duration time.Duration
+
+	// Why the turn did not complete: a send error, the step timeout or the
+	// CLI exiting
+	Err error
+
+	// Checks that failed
+	Failures []error
+
+	// The step was not run because an earlier one ended the run
+	Skipped bool
+}
+
+// Passed reports whether the turn completed and passed all checks
+func (r *StepResult) Passed() bool {
+	return !r.Skipped && r.Err == nil && len(r.Failures) == 0
+}
+
+// Report is the outcome of a run
+type Report struct {
+	Steps    []StepResult
+	Duration time.Duration
+
+	// Usage and cost of all turns
+	Usage   types.Usage
+	CostUSD float64
+}
+
+// Passed reports whether every step passed
+func (r *Report) Passed() bool {
+	for i := range r.Steps {
+		if !r.Steps[i].Passed() {
+			return false
+		}
+	}
+	return true
+}
+
+// String summarizes the report, one line per step followed by the failures
+func (r *Report) String() string {
+	var b strings.Builder
+	for i := range r.Steps {
+		step := &r.Steps[i]
+		switch {
+		case step.Skipped:
+			fmt.Fprintf(&b, "SKIP %s\n", step.Name)
+		case step.Passed():
+			fmt.Fprintf(&b, "PASS %s (%s)\n", step.Name, step.Duration.Round(time.Millisecond))
+		default:
+			fmt.Fprintf(&b, "FAIL %s (%s)\n", step.Name, step.Duration.Round(time.Millisecond))
+		}
+		if step.Err != nil {
+			fmt.Fprintf(&b, "    %v\n", step.Err)
+		}
+		for _, failure := range step.Failures {
+			fmt.Fprintf(&b, "    %v\n", failure)
+		}
+	}
+	fmt.Fprintf(&b, "%d steps in %s, $%.4f\n", len(r.Steps), r.Duration.Round(time.Millisecond), r.CostUSD)
+	return b.String()
+}
+
+// Run executes the steps and returns the report. The error is ctx.Err() if
+// ctx ended the run; failed steps are only reported.
+func (r *Runner) Run(ctx context.Context) (*Report, error) {
+	sessionID := r.SessionID
+	if sessionID == "" {
+		sessionID = "default"
+	}
+
+	report := &Report{Steps: make([]StepResult, len(r.Steps))}
+	started := time.Now()
+	defer func() { report.Duration = time.Since(started) }()
+
+	stopped := false
+	for i, step := range r.Steps {
+		result := &report.Steps[i]
+		result.Name = step.Name
+		if result.Name == "" {
+			result.Name = fmt.Sprintf("step %d", i+1)
+		}
+		result.Prompt = step.Prompt
+
+		if stopped {
+			result.Skipped = true
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			result.Skipped = true
+			stopped = true
+			continue
+		}
+
+		r.runStep(ctx, sessionID, step, result)
+		if result.Turn != nil {
+			report.Usage.Add(result.Turn.Usage)
+			report.CostUSD += result.Turn.CostUSD
+		}
+		if result.Err != nil || (r.StopOnFailure && len(result.Failures) > 0) {
+			stopped = true
+		}
+	}
+
+	return report, ctx.Err()
+}
+
+// runStep runs one turn and its checks
+func (r *Runner) runStep(ctx context.Context, sessionID string, step Step, result *StepResult) {
+	timeout := step.Timeout
+	if timeout == 0 {
+		timeout = r.StepTimeout
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	started := time.Now()
+	result.Turn, result.Err = r.Client.SendAndWait(ctx, step.Prompt, sessionID)
+	result.Duration = time.Since(started)
+	if result.Err != nil {
+		return
+	}
+
+	for _, check := range step.Checks {
+		if err := check(result.Turn); err != nil {
+			result.Failures = append(result.Failures, err)
+		}
+	}
+}
+
+// TextContains checks that the turn's text contains substr
+func TextContains(substr string) Check {
+	return func(turn *types.Turn) error {
+		if !strings.Contains(turn.Text(), substr) {
+			return fmt.Errorf("text does not contain %q", substr)
+		}
+		return nil
+	}
+}
+
+// UsedTool checks that the turn used the named tool
+func UsedTool(name string) Check {
+	return func(turn *types.Turn) error {
+		for _, toolUse := range turn.ToolUses {
+			if toolUse.Name == name {
+				return nil
+			}
+		}
+		return fmt.Errorf("tool %s was not used", name)
+	}
+}
+
+// Succeeded checks that the turn ended with a successful result
+func Succeeded() Check {
+	return func(turn *types.Turn) error {
+		if err := turn.Err(); err != nil {
+			return fmt.Errorf("turn failed: %w", err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,112 @@
+package script_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/script"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/transporttest"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+func TestRunner(t *testing.T) {
+	mock := transporttest.NewMockTransport()
+	total := 0.0
+	mock.RespondFunc(transporttest.MatchType("user"), func(msg map[string]interface{}) []interface{} {
+		prompt, _ := msg["message"].(map[string]interface{})["content"].(string)
+		if prompt == "hang" {
+			return nil
+		}
+		total += 0.5
+
+		content := []interface{}{map[string]interface{}{"type": "text", "text": "You said " + prompt}}
+		if prompt == "read" {
+			content = append(content, map[string]interface{}{"type": "tool_use", "id": "toolu_1", "name": "Read", "input": map[string]interface{}{}})
+		}
+		return []interface{}{
+			map[string]interface{}{"type": "assistant", "model": "sonnet", "content": content},
+			map[string]interface{}{"type": "result", "subtype": "success", "session_id": "s1", "total_cost_usd": total,
+				"usage": map[string]interface{}{"input_tokens": 10, "output_tokens": 5}},
+		}
+	})
+	client := claudecode.NewClaudeSDKClientWithTransport(nil, mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	runner := &script.Runner{
+		Client: client,
+		Steps: []script.Step{
+			{Name: "read", Prompt: "read", Checks: []script.Check{script.UsedTool("Read"), script.Succeeded()}},
+			{Prompt: "echo", Checks: []script.Check{script.TextContains("goodbye"), script.UsedTool("Bash")}},
+			{Prompt: "hang", Timeout: 50 * time.Millisecond},
+			{Prompt: "never sent"},
+		},
+	}
+	report, err := runner.Run(ctx)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	steps := report.Steps
+	if !steps[0].Passed() || steps[0].Turn.Text() != "You said read" {
+		t.Errorf("step 1 = %+v", steps[0])
+	}
+	if steps[1].Name != "step 2" || steps[1].Err != nil || len(steps[1].Failures) != 2 {
+		t.Errorf("step 2 = %+v, want two failed checks", steps[1])
+	}
+	if steps[2].Err != context.DeadlineExceeded {
+		t.Errorf("step 3 error = %v, want the step timeout", steps[2].Err)
+	}
+	if !steps[3].Skipped {
+		t.Error("step 4 ran after a turn failed to complete")
+	}
+
+	if report.Passed() || report.CostUSD != 1 || report.Usage.TotalTokens() != 30 {
+		t.Errorf("unexpected report: passed %v, cost %v, usage %+v", report.Passed(), report.CostUSD, report.Usage)
+	}
+	summary := report.String()
+	for _, line := range []string{"PASS read", "FAIL step 2", `text does not contain "goodbye"`, "FAIL step 3", "SKIP step 4"} {
+		if !strings.Contains(summary, line) {
+			t.Errorf("summary lacks %q:\n%s", line, summary)
+		}
+	}
+}
+
+func TestRunnerStopOnFailure(t *testing.T) {
+	turns := 0
+	client := clientFunc(func(ctx context.Context, prompt string, sessionID string) (*types.Turn, error) {
+		turns++
+		turn := &types.Turn{}
+		turn.Add(&types.ResultMessage{Subtype: types.ResultSubtypeErrorMaxTurns, IsError: true, SessionID: sessionID})
+		return turn, nil
+	})
+
+	runner := &script.Runner{
+		Client:        client,
+		Steps:         []script.Step{{Prompt: "a", Checks: []script.Check{script.Succeeded()}}, {Prompt: "b"}},
+		StopOnFailure: true,
+	}
+	report, err := runner.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if turns != 1 || len(report.Steps[0].Failures) != 1 || !report.Steps[1].Skipped {
+		t.Errorf("unexpected run: %d turns, %+v", turns, report.Steps)
+	}
+}
+
+// clientFunc adapts a function to script.Client
+type clientFunc func(ctx context.Context, prompt string, sessionID string) (*types.Turn, error)
+
+func (f clientFunc) SendAndWait(ctx context.Context, prompt string, sessionID string) (*types.Turn, error) {
+	return f(ctx, prompt, sessionID)
+}
@@ -0,0 +1,88 @@
+package claudecode
+
+import (
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/internal"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// SDKMCPTool is a Go function exposed as an MCP tool
+type SDKMCPTool = internal.SDKMCPTool
+
+// SDKMCPServer is an in-process MCP server
+type SDKMCPServer = internal.SDKMCPServer
+
+// Tool defines an MCP tool backed by a Go function.
+//
+// The schema is a JSON Schema object describing the tool arguments. A nil
+// schema advertises a tool without arguments.
+func Tool(name, description string, schema map[string]interface{}, handler types.MCPToolHandler) *SDKMCPTool {
+	return &SDKMCPTool{
+		Name:        name,
+		Description: description,
+		InputSchema: schema,
+		Handler:     handler,
+	}
+}
+
+// CreateSDKMCPServer creates an in-process MCP server.
+//
+// Unlike stdio servers, SDK servers run inside your Go program: the CLI relays
+// MCP requests over the control protocol and they are answered by the tool
+// handlers directly, without spawning a subprocess. Add the returned config to
+// ClaudeCodeOptions.MCPServers under the same name; its tools are available to
+// Claude as "mcp__<name>__<tool>".
+//
+// Example:
+//
+//	add := claudecode.Tool("add", "Add two numbers", map[string]interface{}{
+//	    "type": "object",
+//	    "properties": map[string]interface{}{
+//	        "a": map[string]interface{}{"type": "number"},
+//	        "b": map[string]interface{}{"type": "number"},
+//	    },
+//	}, func(ctx context.Context, args map[string]interface{}) (*types.MCPToolResult, error) {
+//	    sum := args["a"].(float64) + args["b"].(float64)
+//	    return &types.MCPToolResult{
+//	        Content: []types.MCPContent{{Type: "text", Text: fmt.Sprint(sum)}},
+//	    }, nil
+//	})
+//
+//	options := &types.ClaudeCodeOptions{
+//	    MCPServers: map[string]types.MCPServerConfig{
+//	        "calculator": claudecode.CreateSDKMCPServer("calculator", "1.0.0", add),
+//	    },
+//	    AllowedTools: []string{"mcp__calculator__add"},
+//	}
+func CreateSDKMCPServer(name, version string, tools ...*SDKMCPTool) types.MCPSDKServerConfig {
+	return types.MCPSDKServerConfig{
+		Type:     "sdk",
+		Name:     name,
+		Instance: internal.NewSDKMCPServer(name, version, tools...),
+	}
+}
+
+// TextResult builds a successful tool result with a single text item
+func TextResult(text string) *types.MCPToolResult {
+	return &types.MCPToolResult{
+		Content: []types.MCPContent{{Type: "text", Text: text}},
+	}
+}
+
+// ErrorResult builds a failed tool result with a single text item
+func ErrorResult(text string) *types.MCPToolResult {
+	return &types.MCPToolResult{
+		Content: []types.MCPContent{{Type: "text", Text: text}},
+		IsError: true,
+	}
+}
+
+// extractSDKMCPServers collects the in-process server instances by name
+func extractSDKMCPServers(options *types.ClaudeCodeOptions) map[string]interface{} {
+	servers := make(map[string]interface{})
+	for name, config := range options.MCPServers {
+		if sdkConfig, ok := config.(types.MCPSDKServerConfig); ok {
+			servers[name] = sdkConfig.Instance
+		}
+	}
+	return servers
+}
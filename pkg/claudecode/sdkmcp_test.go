@@ -0,0 +1,104 @@
+package claudecode
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// mcpCLI relays MCP requests for the "calc" SDK server once it gets a
+// prompt, reporting every control response as a system/mcp_reply message
+const mcpCLI = `
+while IFS= read -r line; do
+  case "$line" in
+  *'"subtype":"initialize"'*)
+    id=$(echo "$line" | sed 's/.*"request_id":"\([^"]*\)".*/\1/')
+    echo '{"type":"control_response","response":{"subtype":"success","request_id":"'"$id"'","response":{}}}'
+    ;;
+  *'"type":"user"'*)
+    for method in initialize notifications/initialized tools/list tools/call; do
+      params='{}'
+      [ "$method" = tools/call ] && params='{"name":"add","arguments":{"a":1,"b":2}}'
+      echo '{"type":"control_request","request_id":"'"$method"'","request":{"subtype":"mcp_message","server_name":"calc","message":{"jsonrpc":"2.0","id":1,"method":"'"$method"'","params":'"$params"'}}}'
+      IFS= read -r reply
+      echo '{"type":"system","subtype":"mcp_reply","data":'"$reply"'}'
+    done
+    echo '{"type":"result","subtype":"success","result":"done","session_id":"s1"}'
+    ;;
+  esac
+done
+`
+
+func TestQuerySDKMCPServer(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	add := Tool("add", "Add two numbers", nil, func(ctx context.Context, args map[string]interface{}) (*types.MCPToolResult, error) {
+		return TextResult(fmt.Sprint(args["a"].(float64) + args["b"].(float64))), nil
+	})
+	options := types.NewOptions().
+		WithCLIPath("/nonexistent/claude").
+		WithCommandFactory(func(ctx context.Context, path string, args []string) *exec.Cmd {
+			return exec.CommandContext(ctx, "sh", "-c", mcpCLI)
+		})
+	options.MCPServers = map[string]types.MCPServerConfig{
+		"calc": CreateSDKMCPServer("calc", "1.0.0", add),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	prompts := make(chan interface{}, 1)
+	prompts <- "add 1 and 2"
+	close(prompts)
+
+	messages, err := Query(ctx, prompts, options)
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+
+	// Control responses by request ID
+	replies := make(map[string]map[string]interface{})
+	for msg := range messages {
+		system, ok := msg.(*types.SystemMessage)
+		if !ok || system.Subtype != "mcp_reply" {
+			continue
+		}
+		response, _ := system.Data["response"].(map[string]interface{})
+		id, _ := response["request_id"].(string)
+		body, _ := response["response"].(map[string]interface{})
+		replies[id] = body
+	}
+
+	result := func(method string) map[string]interface{} {
+		reply, _ := replies[method]["mcp_response"].(map[string]interface{})
+		result, _ := reply["result"].(map[string]interface{})
+		return result
+	}
+
+	info, _ := result("initialize")["serverInfo"].(map[string]interface{})
+	if info["name"] != "calc" || info["version"] != "1.0.0" {
+		t.Errorf("initialize = %v", replies["initialize"])
+	}
+
+	// The notification is acknowledged without a JSON-RPC response
+	if reply, ok := replies["notifications/initialized"]; !ok || reply["mcp_response"] != nil {
+		t.Errorf("notifications/initialized = %v, %v", reply, ok)
+	}
+
+	tools, _ := result("tools/list")["tools"].([]interface{})
+	if len(tools) != 1 || tools[0].(map[string]interface{})["name"] != "add" {
+		t.Errorf("tools/list = %v", replies["tools/list"])
+	}
+
+	content, _ := result("tools/call")["content"].([]interface{})
+	if len(content) != 1 || content[0].(map[string]interface{})["text"] != "3" {
+		t.Errorf("tools/call = %v", replies["tools/call"])
+	}
+}
@@ -0,0 +1,76 @@
+package claudecode
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// recordInit keeps the session's system/init message for GetServerInfo
+func (c *ClaudeSDKClient) recordInit(msg types.Message) {
+	initMsg, ok := msg.(*types.InitMessage)
+	if !ok {
+		return
+	}
+
+	c.initMu.Lock()
+	c.initMsg = initMsg
+	c.initMu.Unlock()
+}
+
+// GetServerInfo returns what the connected Claude Code instance reported
+// about itself: the initialize handshake response (commands, output styles)
+// merged with the session's system/init message (model, tools, MCP servers,
+// API key source). The init message arrives with the first turn; before that
+// only the handshake fields are set.
+func (c *ClaudeSDKClient) GetServerInfo() (*types.ServerInfo, error) {
+	query, err := c.activeQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	c.initMu.Lock()
+	initMsg := c.initMsg
+	c.initMu.Unlock()
+
+	return newServerInfo(query.ServerInfo(), initMsg), nil
+}
+
+// newServerInfo merges the initialize response with the init message
+func newServerInfo(response map[string]interface{}, initMsg *types.InitMessage) *types.ServerInfo {
+	info := &types.ServerInfo{Raw: response}
+
+	if response != nil {
+		// Decoding is best effort; unknown shapes leave fields empty
+		if data, err := json.Marshal(response); err == nil {
+			json.Unmarshal(data, info)
+		}
+	}
+
+	if initMsg == nil {
+		return info
+	}
+
+	info.Model = initMsg.Model
+	info.PermissionMode = initMsg.PermissionMode
+	info.Tools = initMsg.Tools
+	info.MCPServers = initMsg.MCPServers
+	info.APIKeySource = initMsg.APIKeySource
+	info.SessionID = initMsg.SessionID
+	info.CWD = initMsg.CWD
+	if initMsg.OutputStyle != "" {
+		info.OutputStyle = initMsg.OutputStyle
+	}
+
+	// The init message only lists command names; add the ones the
+	// handshake did not describe
+	for _, name := range initMsg.Commands {
+		name = strings.TrimPrefix(name, "/")
+		if !info.HasCommand(name) {
+			info.Commands = append(info.Commands, types.SlashCommand{Name: name})
+		}
+	}
+
+	return info
+}
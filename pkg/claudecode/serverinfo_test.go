@@ -0,0 +1,47 @@
+package claudecode
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+func TestNewServerInfo(t *testing.T) {
+	response := map[string]interface{}{
+		"commands": []interface{}{
+			map[string]interface{}{"name": "compact", "description": "Compact the conversation", "argumentHint": "<instructions>"},
+		},
+		"output_style":            "default",
+		"available_output_styles": []interface{}{"default", "Explanatory"},
+	}
+	initMsg := &types.InitMessage{
+		SessionID:    "s1",
+		Model:        "claude-sonnet-4-5",
+		Tools:        []string{"Bash", "Read"},
+		Commands:     []string{"compact", "review"},
+		MCPServers:   []types.MCPServerStatus{{Name: "github", Status: "connected"}},
+		APIKeySource: "user",
+	}
+
+	info := newServerInfo(response, initMsg)
+
+	if len(info.Commands) != 2 || info.Commands[0].Description != "Compact the conversation" || info.Commands[1].Name != "review" {
+		t.Errorf("Commands = %+v", info.Commands)
+	}
+	if info.OutputStyle != "default" || !reflect.DeepEqual(info.OutputStyles, []string{"default", "Explanatory"}) {
+		t.Errorf("OutputStyle = %q, OutputStyles = %v", info.OutputStyle, info.OutputStyles)
+	}
+	if info.Model != "claude-sonnet-4-5" || info.SessionID != "s1" || info.APIKeySource != "user" {
+		t.Errorf("init fields not merged: %+v", info)
+	}
+	if !reflect.DeepEqual(info.Tools, []string{"Bash", "Read"}) || len(info.MCPServers) != 1 {
+		t.Errorf("Tools = %v, MCPServers = %v", info.Tools, info.MCPServers)
+	}
+
+	// Before the first turn only the handshake is known
+	early := newServerInfo(response, nil)
+	if early.Model != "" || !early.HasCommand("compact") {
+		t.Errorf("unexpected early info: %+v", early)
+	}
+}
@@ -0,0 +1,287 @@
+package claudecode
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// Session is a conversation multiplexed over a ClaudeSDKClient.
+//
+// Messages of the turns started by the session are delivered to the
+// session's own channel instead of the client's Messages() channel. Create
+// sessions with ClaudeSDKClient.NewSession.
+type Session struct {
+	id     string
+	client *ClaudeSDKClient
+
+	messages chan types.Message
+	done     chan struct{}
+	once     sync.Once
+
+	// Session ID the CLI reported for the session's turns, and the last
+	// turn sent, for Result
+	cliID string
+	last  *turn
+	mu    sync.Mutex
+}
+
+// turn is a user message sent to the CLI, waiting for its result. The CLI
+// answers turns in the order they were sent and stamps its messages with
+// its own session ID rather than the one sent, so messages are attributed
+// to the oldest turn still waiting.
+type turn struct {
+	session *Session // Session that sent it, nil for the client
+
+	result *types.ResultMessage
+	done   chan struct{} // Closed once result is set
+}
+
+// NewSession registers a session with the given ID. Messages sent through
+// the session are tagged with id, and the messages of their turns are
+// routed to the session.
+func (c *ClaudeSDKClient) NewSession(id string) (*Session, error) {
+	c.sessionsMu.Lock()
+	defer c.sessionsMu.Unlock()
+
+	if c.sessions == nil {
+		c.sessions = make(map[string]*Session)
+	}
+	if _, exists := c.sessions[id]; exists {
+		return nil, fmt.Errorf("session already exists: %s", id)
+	}
+
+	s := &Session{
+		id:       id,
+		client:   c,
+		messages: make(chan types.Message, messageBufferSize(c.options)),
+		done:     make(chan struct{}),
+	}
+	c.sessions[id] = s
+
+	return s, nil
+}
+
+// Session returns a registered session by ID
+func (c *ClaudeSDKClient) Session(id string) (*Session, bool) {
+	c.sessionsMu.RLock()
+	defer c.sessionsMu.RUnlock()
+
+	s, ok := c.sessions[id]
+	return s, ok
+}
+
+// ID returns the session ID
+func (s *Session) ID() string {
+	return s.id
+}
+
+// CLISessionID returns the session ID the CLI reported for this session's
+// turns, taken from the init message or the first result. It is empty
+// until then.
+func (s *Session) CLISessionID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.cliID
+}
+
+// Send sends a user message in this session
+func (s *Session) Send(prompt string) error {
+	return s.client.SendMessage(prompt, s.id)
+}
+
+// SendContent sends structured content blocks in this session
+func (s *Session) SendContent(ctx context.Context, blocks []types.ContentBlock) error {
+	return s.client.SendContent(ctx, s.id, blocks)
+}
+
+// Messages returns the channel of messages belonging to this session.
+// It is closed when the session is closed.
+func (s *Session) Messages() <-chan types.Message {
+	return s.messages
+}
+
+// Result waits for the ResultMessage of the last message sent in this
+// session. Earlier results are not returned, so call it after every Send
+// to see each one. The result is also delivered on Messages().
+func (s *Session) Result(ctx context.Context) (*types.ResultMessage, error) {
+	s.mu.Lock()
+	last := s.last
+	s.mu.Unlock()
+
+	if last == nil {
+		return nil, fmt.Errorf("no message sent in session: %s", s.id)
+	}
+	select {
+	case <-s.done:
+		return nil, fmt.Errorf("session closed: %s", s.id)
+	default:
+	}
+
+	select {
+	case <-last.done:
+		return last.result, nil
+	case <-s.done:
+		return nil, fmt.Errorf("session closed: %s", s.id)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Close unregisters the session. Later messages of its turns are delivered
+// on the client's Messages() channel.
+func (s *Session) Close() {
+	s.once.Do(func() {
+		close(s.done)
+
+		s.client.sessionsMu.Lock()
+		defer s.client.sessionsMu.Unlock()
+
+		delete(s.client.sessions, s.id)
+		close(s.messages)
+	})
+}
+
+// startTurn records a user message about to be sent with the given
+// session_id, attributing it to the session of that ID if there is one.
+// The turn must be passed to abortTurn if the message is not sent.
+func (c *ClaudeSDKClient) startTurn(label string) *turn {
+	t := &turn{done: make(chan struct{})}
+
+	c.sessionsMu.RLock()
+	t.session = c.sessions[label]
+	c.sessionsMu.RUnlock()
+
+	if t.session != nil {
+		t.session.mu.Lock()
+		t.session.last = t
+		t.session.mu.Unlock()
+	}
+
+	c.turnsMu.Lock()
+	c.turns = append(c.turns, t)
+	c.lastLabel = label
+	c.turnsMu.Unlock()
+	return t
+}
+
+// abortTurn forgets a turn whose message could not be sent
+func (c *ClaudeSDKClient) abortTurn(t *turn) {
+	c.turnsMu.Lock()
+	if i := slices.Index(c.turns, t); i >= 0 {
+		c.turns = slices.Delete(c.turns, i, i+1)
+	}
+	c.turnsMu.Unlock()
+
+	if s := t.session; s != nil {
+		s.mu.Lock()
+		if s.last == t {
+			s.last = nil
+		}
+		s.mu.Unlock()
+	}
+}
+
+// currentTurn returns the turn msg belongs to, the oldest one waiting, or
+// nil if none is. A result ends the turn.
+func (c *ClaudeSDKClient) currentTurn(msg types.Message) *turn {
+	c.turnsMu.Lock()
+	defer c.turnsMu.Unlock()
+
+	if len(c.turns) == 0 {
+		return nil
+	}
+	t := c.turns[0]
+	if result, ok := msg.(*types.ResultMessage); ok {
+		c.turns = c.turns[1:]
+		t.result = result
+		close(t.done)
+	}
+	return t
+}
+
+// lastSession returns the session_id of the last message sent, "default"
+// before any
+func (c *ClaudeSDKClient) lastSession() string {
+	c.turnsMu.Lock()
+	defer c.turnsMu.Unlock()
+
+	if c.lastLabel == "" {
+		return "default"
+	}
+	return c.lastLabel
+}
+
+// routeToSession delivers a message to the session whose turn it belongs
+// to. Without a turn waiting, a message is matched by its session_id
+// against the session IDs and the ones the CLI reported. Returns false
+// when the message does not belong to a registered session.
+func (c *ClaudeSDKClient) routeToSession(msg types.Message) bool {
+	t := c.currentTurn(msg)
+	id := messageSessionID(msg)
+
+	c.sessionsMu.RLock()
+	defer c.sessionsMu.RUnlock()
+
+	var s *Session
+	if t != nil {
+		s = t.session
+	} else if id != "" {
+		s = c.sessionByID(id)
+	}
+	// Closed sessions are gone from the map
+	if s == nil || c.sessions[s.id] != s {
+		return false
+	}
+
+	switch msg.(type) {
+	case *types.InitMessage, *types.ResultMessage:
+		s.mu.Lock()
+		if s.cliID == "" {
+			s.cliID = id
+		}
+		s.mu.Unlock()
+	}
+
+	c.deliver(s.messages, msg, s.done)
+	return true
+}
+
+// sessionByID finds a session by its ID or the one the CLI reported for
+// it. Called with sessionsMu held.
+func (c *ClaudeSDKClient) sessionByID(id string) *Session {
+	if s, ok := c.sessions[id]; ok {
+		return s
+	}
+	for _, s := range c.sessions {
+		s.mu.Lock()
+		cliID := s.cliID
+		s.mu.Unlock()
+		if cliID == id {
+			return s
+		}
+	}
+	return nil
+}
+
+// messageSessionID returns the session_id carried by a message
+func messageSessionID(msg types.Message) string {
+	switch m := msg.(type) {
+	case *types.UserMessage:
+		return m.SessionID
+	case *types.AssistantMessage:
+		return m.SessionID
+	case *types.ResultMessage:
+		return m.SessionID
+	case *types.StreamEvent:
+		return m.SessionID
+	case *types.InitMessage:
+		return m.SessionID
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,167 @@
+package claudecode
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/transporttest"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// cliTurn emits a turn the way the CLI answers it: stamped with its own
+// session ID, not the one the message was sent with
+func cliTurn(mock *transporttest.MockTransport, sessionID, text string) {
+	mock.Emit(map[string]interface{}{"type": "assistant", "model": "sonnet", "session_id": sessionID, "content": []interface{}{
+		map[string]interface{}{"type": "text", "text": text},
+	}})
+	mock.Emit(map[string]interface{}{"type": "result", "subtype": "success", "session_id": sessionID, "result": text})
+}
+
+func TestSessionRouting(t *testing.T) {
+	mock := transporttest.NewMockTransport()
+	client := NewClaudeSDKClientWithTransport(nil, mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	work, _ := client.NewSession("work")
+	chat, _ := client.NewSession("chat")
+	if _, err := client.NewSession("work"); err == nil {
+		t.Error("expected an error for a duplicate session")
+	}
+	if _, err := work.Result(ctx); err == nil {
+		t.Error("expected an error before anything was sent")
+	}
+
+	// Turns are answered in the order they were sent
+	if err := work.Send("build"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.SendMessage("hello", "default"); err != nil {
+		t.Fatal(err)
+	}
+	if err := chat.Send("joke"); err != nil {
+		t.Fatal(err)
+	}
+	mock.Emit(map[string]interface{}{"type": "system", "subtype": "init", "session_id": "cli-uuid"})
+	cliTurn(mock, "cli-uuid", "built")
+	cliTurn(mock, "cli-uuid", "hi")
+	cliTurn(mock, "cli-chat", "knock knock")
+
+	result, err := work.Result(ctx)
+	if err != nil || *result.Result != "built" {
+		t.Fatalf("work result = %v, %v", result, err)
+	}
+	result, err = chat.Result(ctx)
+	if err != nil || *result.Result != "knock knock" {
+		t.Fatalf("chat result = %v, %v", result, err)
+	}
+
+	// Each session saw its own turn; the client the one it sent
+	want := map[*Session][]string{work: {"init", "built", "built"}, chat: {"knock knock", "knock knock"}}
+	for s, texts := range want {
+		for _, text := range texts {
+			got := messageText(nextMessage(t, s.Messages()))
+			if got != text {
+				t.Errorf("%s got %q, want %q", s.ID(), got, text)
+			}
+		}
+	}
+	for _, text := range []string{"hi", "hi"} {
+		if got := messageText(nextMessage(t, client.Messages())); got != text {
+			t.Errorf("client got %q, want %q", got, text)
+		}
+	}
+
+	if id := work.CLISessionID(); id != "cli-uuid" {
+		t.Errorf("CLISessionID = %q, want cli-uuid", id)
+	}
+	if id := chat.CLISessionID(); id != "cli-chat" {
+		t.Errorf("CLISessionID = %q, want cli-chat", id)
+	}
+
+	// Without a turn waiting, messages are matched by the CLI's session ID
+	mock.Emit(map[string]interface{}{"type": "assistant", "model": "sonnet", "session_id": "cli-chat", "content": []interface{}{}})
+	if _, ok := nextMessage(t, chat.Messages()).(*types.AssistantMessage); !ok {
+		t.Error("expected the late message on the session with that CLI session ID")
+	}
+}
+
+func TestSessionResultPerSend(t *testing.T) {
+	mock := transporttest.NewMockTransport()
+	client := NewClaudeSDKClientWithTransport(nil, mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	s, _ := client.NewSession("work")
+	if err := s.Send("first"); err != nil {
+		t.Fatal(err)
+	}
+	cliTurn(mock, "cli-uuid", "first")
+	nextMessage(t, s.Messages())
+	nextMessage(t, s.Messages())
+
+	// The first result was never collected; Result waits for the second
+	if err := s.Send("second"); err != nil {
+		t.Fatal(err)
+	}
+	waitCtx, stop := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer stop()
+	if result, err := s.Result(waitCtx); err != context.DeadlineExceeded {
+		t.Fatalf("Result before the second turn ended = %v, %v", result, err)
+	}
+
+	cliTurn(mock, "cli-uuid", "second")
+	result, err := s.Result(ctx)
+	if err != nil || *result.Result != "second" {
+		t.Fatalf("Result = %v, %v", result, err)
+	}
+	// It can be read again
+	if again, _ := s.Result(ctx); again != result {
+		t.Error("Result changed on a second call")
+	}
+
+	s.Close()
+	if _, err := s.Result(ctx); err == nil {
+		t.Error("expected an error from a closed session")
+	}
+}
+
+// nextMessage receives from ch, failing the test if nothing arrives
+func nextMessage(t *testing.T, ch <-chan types.Message) types.Message {
+	t.Helper()
+	select {
+	case msg := <-ch:
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message delivered")
+		return nil
+	}
+}
+
+// messageText returns what identifies msg in the session tests
+func messageText(msg types.Message) string {
+	switch m := msg.(type) {
+	case *types.InitMessage:
+		return "init"
+	case *types.AssistantMessage:
+		if len(m.Content) > 0 {
+			return m.Content[0].(*types.TextBlock).Text
+		}
+	case *types.ResultMessage:
+		return *m.Result
+	}
+	return ""
+}
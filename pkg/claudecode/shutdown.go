@@ -0,0 +1,105 @@
+package claudecode
+
+import (
+	"context"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport"
+)
+
+// shutdownGrace is how long the CLI may take to exit after SIGTERM unless
+// TerminateGracePeriod is set
+const shutdownGrace = 5 * time.Second
+
+// Shutdown ends the conversation gracefully. Unlike Close, which stops the
+// CLI right away, Shutdown closes stdin so the CLI can finish in-flight
+// tool executions, waits until its remaining output (including the final
+// ResultMessage) has been delivered on Messages() and the process has
+// exited, and then releases the client.
+//
+// If ctx is done first, the CLI's process group is sent SIGTERM, killed if
+// it is still running after TerminateGracePeriod (default 5s), and
+// ctx.Err() is returned. Keep draining Messages() while Shutdown runs.
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+//	defer cancel()
+//	if err := client.Shutdown(ctx); err != nil {
+//	    log.Printf("forced shutdown: %v", err)
+//	}
+func (c *ClaudeSDKClient) Shutdown(ctx context.Context) error {
+	c.mu.RLock()
+	if !c.connected {
+		c.mu.RUnlock()
+		return nil
+	}
+	t := c.transport
+	outputDone := c.outputDone
+	c.mu.RUnlock()
+
+	// A CLI exiting now is expected, not a crash to recover from
+	c.shuttingDown.Store(true)
+	c.logger().InfoContext(ctx, "shutting down")
+
+	graceful, ok := t.(transport.GracefulCloser)
+	if !ok {
+		// No way to end input; wait for the other side to finish on its own
+		select {
+		case <-outputDone:
+			return c.Close()
+		case <-ctx.Done():
+			c.Close()
+			return ctx.Err()
+		}
+	}
+
+	if err := graceful.CloseInput(); err != nil {
+		c.Close()
+		return err
+	}
+
+	select {
+	case <-outputDone:
+		select {
+		case <-graceful.Exited():
+			return c.Close()
+		case <-ctx.Done():
+		}
+	case <-ctx.Done():
+	}
+
+	// Deadline passed; escalate
+	c.logger().Warn("shutdown deadline passed, terminating", "error", ctx.Err())
+	grace := shutdownGrace
+	if c.options != nil && c.options.TerminateGracePeriod > 0 {
+		grace = c.options.TerminateGracePeriod
+	}
+	graceful.Terminate(grace)
+	c.Close()
+	return ctx.Err()
+}
+
+// CloseStdin ends the input of the conversation. The CLI answers the
+// messages already sent, their output keeps arriving on Messages(), and then
+// it exits; sending fails from now on. Unlike Shutdown it returns right away
+// and leaves releasing the client to Close.
+func (c *ClaudeSDKClient) CloseStdin() error {
+	c.mu.RLock()
+	connected := c.connected
+	t := c.transport
+	c.mu.RUnlock()
+
+	if !connected {
+		return errors.NewCLIConnectionError("not connected. Call Connect() first", nil)
+	}
+	graceful, ok := t.(transport.GracefulCloser)
+	if !ok {
+		return errors.NewCLIConnectionError("transport cannot close its input", nil)
+	}
+
+	// A CLI exiting now is expected, not a crash to recover from
+	c.shuttingDown.Store(true)
+	return graceful.CloseInput()
+}
@@ -0,0 +1,179 @@
+package claudecode
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/transporttest"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+func TestShutdownDeliversFinalResult(t *testing.T) {
+	mock := transporttest.NewMockTransport()
+	client := NewClaudeSDKClientWithTransport(nil, mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+
+	// The CLI finishes its turn and exits while Shutdown is waiting
+	mock.Emit(map[string]interface{}{"type": "result", "subtype": "success", "session_id": "s1"})
+	mock.Disconnect(nil)
+
+	var result *types.ResultMessage
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+		for msg := range client.Messages() {
+			if r, ok := msg.(*types.ResultMessage); ok {
+				result = r
+				return
+			}
+		}
+	}()
+
+	if err := client.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	<-drained
+
+	if result == nil {
+		t.Fatal("expected the final result to be delivered")
+	}
+	if client.IsConnected() {
+		t.Error("expected client to be disconnected")
+	}
+}
+
+func TestShutdownDeadline(t *testing.T) {
+	mock := transporttest.NewMockTransport()
+	client := NewClaudeSDKClientWithTransport(nil, mock)
+
+	if err := client.Connect(context.Background(), nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := client.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Shutdown error = %v, want DeadlineExceeded", err)
+	}
+	if client.IsConnected() {
+		t.Error("expected client to be disconnected")
+	}
+}
+
+func TestCloseStdin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	options := types.NewOptions().
+		WithCLIPath("/nonexistent/claude").
+		WithCommandFactory(func(ctx context.Context, path string, args []string) *exec.Cmd {
+			return exec.CommandContext(ctx, "sh", "-c", echoCLI)
+		})
+	client := NewClaudeSDKClient(options)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// A string prompt is sent as the first user message
+	if err := client.Connect(ctx, "hello"); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.CloseStdin(); err != nil {
+		t.Fatalf("CloseStdin: %v", err)
+	}
+	if err := client.SendMessage("too late", "default"); err == nil {
+		t.Error("expected sending after CloseStdin to fail")
+	}
+
+	// The pending turn is still answered before the CLI exits
+	var results []string
+	for msg := range client.Messages() {
+		if result, ok := msg.(*types.ResultMessage); ok {
+			results = append(results, *result.Result)
+			break
+		}
+	}
+	if len(results) != 1 || results[0] != "hello" {
+		t.Errorf("results = %v, want the answer to the prompt", results)
+	}
+
+	if err := client.Shutdown(ctx); err != nil {
+		t.Errorf("Shutdown: %v", err)
+	}
+}
+
+func TestCloseWhileDelivering(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		mock := transporttest.NewMockTransport()
+		options := types.NewOptions().
+			WithMessageBuffer(1, types.OverflowBlock).
+			WithHook(types.HookEventPreToolUse, types.HookMatcher{
+				Hooks: []types.HookCallback{
+					func(map[string]interface{}, *string, *types.HookContext) (*types.HookJSONOutput, error) {
+						// Times out, so the handler reports an error while Close runs
+						time.Sleep(5 * time.Millisecond)
+						return nil, nil
+					},
+				},
+			})
+		options.CallbackTimeout = time.Millisecond
+		client := NewClaudeSDKClientWithTransport(options, mock)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := client.Connect(ctx, nil); err != nil {
+			t.Fatalf("Connect: %v", err)
+		}
+
+		// Nobody drains, so senders block until Close
+		for j := 0; j < 20; j++ {
+			mock.Emit(map[string]interface{}{
+				"type":       "control_request",
+				"request_id": fmt.Sprintf("cli_%d", j),
+				"request": map[string]interface{}{
+					"subtype":     "hook_callback",
+					"callback_id": "hook_0",
+					"input":       map[string]interface{}{},
+				},
+			})
+			mock.Emit(map[string]interface{}{"type": "system", "subtype": "status"})
+			mock.EmitMalformed()
+		}
+
+		closed := make(chan struct{})
+		for j := 0; j < 3; j++ {
+			go func() {
+				client.Close()
+				closed <- struct{}{}
+			}()
+		}
+		for j := 0; j < 3; j++ {
+			<-closed
+		}
+
+		for range client.Messages() {
+		}
+		for range client.Errors() {
+		}
+		if err := client.Close(); err != nil {
+			t.Errorf("second Close: %v", err)
+		}
+		if err := client.Connect(ctx, nil); err == nil {
+			t.Error("Connect after Close succeeded")
+		}
+		cancel()
+	}
+}
@@ -0,0 +1,120 @@
+package claudecode
+
+import (
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// Snapshot returns the state needed to resume the conversation in another
+// client, possibly in another process, with Restore. The session ID is known
+// once the CLI sent its init message or a result.
+//
+// Example:
+//
+//	data, _ := json.Marshal(client.Snapshot())
+//	// ... after a restart
+//	var state claudecode.ConversationState
+//	json.Unmarshal(data, &state)
+//	client, err := claudecode.Restore(&state)
+func (c *ClaudeSDKClient) Snapshot() *types.ConversationState {
+	c.resumeMu.Lock()
+	sessionID := c.sessionID
+	c.resumeMu.Unlock()
+
+	c.mu.RLock()
+	options := *c.options
+	// Connect sets the prompt tool for a CanUseTool callback; a restored
+	// client sets it again once its callback is supplied
+	if c.canUseTool != nil {
+		options.PermissionPromptToolName = nil
+	}
+	c.mu.RUnlock()
+
+	c.stateMu.Lock()
+	if c.model != nil {
+		options.Model = nil
+		if *c.model != "" {
+			model := *c.model
+			options.Model = &model
+		}
+	}
+	if c.mode != nil {
+		mode := *c.mode
+		options.PermissionMode = &mode
+	}
+	updates := append([]types.PermissionUpdate(nil), c.permissions...)
+	c.stateMu.Unlock()
+
+	return &types.ConversationState{
+		SessionID:         sessionID,
+		Options:           &options,
+		Usage:             c.TotalUsage(),
+		PermissionUpdates: updates,
+	}
+}
+
+// Restore creates a client that resumes the conversation of state once
+// connected. Usage accumulated so far carries over to TotalUsage, and
+// session-scoped permission updates are applied again on Connect.
+func Restore(state *types.ConversationState) (*ClaudeSDKClient, error) {
+	if state == nil || state.SessionID == "" {
+		return nil, errors.NewValidationError("session_id", "state has no session to resume")
+	}
+
+	options := types.ClaudeCodeOptions{}
+	if state.Options != nil {
+		options = *state.Options
+	}
+	sessionID := state.SessionID
+	options.Resume = &sessionID
+	options.ContinueConversation = false
+	options.ForkSession = false
+
+	client := NewClaudeSDKClient(&options)
+	client.sessionID = sessionID
+	client.usage.Add(&state.Usage)
+	client.permissions = append([]types.PermissionUpdate(nil), state.PermissionUpdates...)
+	return client, nil
+}
+
+// recordPermissions wraps a CanUseTool callback to remember the permission
+// updates its results apply, for Snapshot. Returns nil for a nil callback.
+func (c *ClaudeSDKClient) recordPermissions(next types.CanUseTool) types.CanUseTool {
+	if next == nil {
+		return nil
+	}
+
+	return func(toolName string, input map[string]interface{}, context *types.ToolPermissionContext) (types.PermissionResult, error) {
+		result, err := next(toolName, input, context)
+		if allow, ok := result.(*types.PermissionResultAllow); ok && err == nil {
+			c.permissionsApplied(allow.UpdatedPermissions)
+		}
+		return result, err
+	}
+}
+
+// permissionsApplied remembers permission updates the CLI accepted
+func (c *ClaudeSDKClient) permissionsApplied(updates []types.PermissionUpdate) {
+	if len(updates) == 0 {
+		return
+	}
+
+	c.stateMu.Lock()
+	c.permissions = append(c.permissions, updates...)
+	c.stateMu.Unlock()
+}
+
+// sessionPermissions returns the applied permission updates that only last
+// as long as the CLI process, to apply again after a respawn or restore
+func (c *ClaudeSDKClient) sessionPermissions() []types.PermissionUpdate {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	var updates []types.PermissionUpdate
+	for _, update := range c.permissions {
+		if update.Destination == nil || *update.Destination == types.PermissionDestinationSession {
+			updates = append(updates, update)
+		}
+	}
+	return updates
+}
@@ -0,0 +1,108 @@
+package claudecode
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/transporttest"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	mock := transporttest.NewMockTransport()
+	client := NewClaudeSDKClientWithTransport(types.NewOptions().WithModel("sonnet"), mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	mock.Emit(map[string]interface{}{
+		"type": "result", "subtype": "success", "session_id": "s1",
+		"usage": map[string]interface{}{"input_tokens": 10.0, "output_tokens": 5.0},
+	})
+	if _, err := WaitForResult(ctx, client.Messages()); err != nil {
+		t.Fatalf("WaitForResult: %v", err)
+	}
+
+	session := types.PermissionDestinationSession
+	project := types.PermissionDestinationProjectSettings
+	behavior := types.PermissionBehaviorAllow
+	rules := []types.PermissionRuleValue{{ToolName: "Bash"}}
+	if err := client.UpdatePermissions(ctx,
+		types.PermissionUpdate{Type: types.PermissionUpdateAddRules, Rules: rules, Behavior: &behavior, Destination: &session},
+		types.PermissionUpdate{Type: types.PermissionUpdateAddRules, Rules: rules, Behavior: &behavior, Destination: &project},
+	); err != nil {
+		t.Fatalf("UpdatePermissions: %v", err)
+	}
+	if err := client.SetModel(ctx, "haiku"); err != nil {
+		t.Fatalf("SetModel: %v", err)
+	}
+	if err := client.SetPermissionMode(ctx, types.PermissionModeAcceptEdits); err != nil {
+		t.Fatalf("SetPermissionMode: %v", err)
+	}
+
+	// The state survives encoding, e.g. in a database
+	data, err := json.Marshal(client.Snapshot())
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var state types.ConversationState
+	if err := json.Unmarshal(data, &state); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	restored, err := Restore(&state)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	options := restored.options
+	if options.Resume == nil || *options.Resume != "s1" {
+		t.Errorf("Resume = %v, want s1", options.Resume)
+	}
+	if options.Model == nil || *options.Model != "haiku" {
+		t.Errorf("Model = %v, want haiku", options.Model)
+	}
+	if options.PermissionMode == nil || *options.PermissionMode != types.PermissionModeAcceptEdits {
+		t.Errorf("PermissionMode = %v, want acceptEdits", options.PermissionMode)
+	}
+	if usage := restored.TotalUsage(); usage.InputTokens != 10 || usage.OutputTokens != 5 {
+		t.Errorf("TotalUsage = %+v", usage)
+	}
+
+	// Only the session-scoped update is applied again
+	next := transporttest.NewMockTransport()
+	restored.customTransport = next
+	if err := restored.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect restored client: %v", err)
+	}
+	defer restored.Close()
+
+	var replayed []interface{}
+	for _, msg := range next.WrittenMessages() {
+		request, _ := msg["request"].(map[string]interface{})
+		if request["subtype"] == "update_permissions" {
+			replayed = append(replayed, request["updates"].([]interface{})...)
+		}
+	}
+	if len(replayed) != 1 || replayed[0].(map[string]interface{})["destination"] != "session" {
+		t.Errorf("replayed permission updates = %v", replayed)
+	}
+	if len(restored.Snapshot().PermissionUpdates) != 2 {
+		t.Error("restored client lost permission updates")
+	}
+}
+
+func TestRestoreWithoutSession(t *testing.T) {
+	client := NewClaudeSDKClient(nil)
+	if _, err := Restore(client.Snapshot()); !stderrors.Is(err, errors.ErrInvalidOptions) {
+		t.Errorf("Restore error = %v, want ErrInvalidOptions", err)
+	}
+}
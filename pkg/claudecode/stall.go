@@ -0,0 +1,148 @@
+package claudecode
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/internal"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// stallMonitor tracks when the CLI last wrote something during a turn to
+// enforce StallTimeout. A stall is reported once per turn.
+type stallMonitor struct {
+	timeout   time.Duration
+	interrupt bool
+
+	mu       sync.Mutex
+	active   bool // A turn is in progress
+	last     time.Time
+	reported bool
+}
+
+// newStallMonitor returns nil when no StallTimeout is configured
+func newStallMonitor(options *types.ClaudeCodeOptions) *stallMonitor {
+	if options == nil || options.StallTimeout <= 0 {
+		return nil
+	}
+	return &stallMonitor{timeout: options.StallTimeout, interrupt: options.InterruptOnStall}
+}
+
+// interval is how often the monitor checks for a stall
+func (m *stallMonitor) interval() time.Duration {
+	return max(m.timeout/4, time.Millisecond)
+}
+
+// turnStarted starts the clock when a user message was sent
+func (m *stallMonitor) turnStarted() {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.active {
+		m.active = true
+		m.reported = false
+	}
+	m.last = time.Now()
+}
+
+// observe records output from the CLI; a result ends the turn
+func (m *stallMonitor) observe(msg types.Message) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.last = time.Now()
+	if _, ok := msg.(*types.ResultMessage); ok {
+		m.active = false
+	}
+}
+
+// check returns how long the CLI has been silent if that makes the current
+// turn stalled. Time spent in callbacks, while busy, does not count.
+func (m *stallMonitor) check(busy bool) (time.Duration, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if !m.active || m.reported {
+		return 0, false
+	}
+	if busy {
+		m.last = now
+		return 0, false
+	}
+
+	idle := now.Sub(m.last)
+	if idle < m.timeout {
+		return 0, false
+	}
+	m.reported = true
+	return idle, true
+}
+
+// watchStalls reports stalled turns on Errors() until the client closes,
+// interrupting them if InterruptOnStall is set
+func (c *ClaudeSDKClient) watchStalls(m *stallMonitor) {
+	ticker := time.NewTicker(m.interval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		query, err := c.activeQuery()
+		if err != nil {
+			continue
+		}
+		idle, stalled := m.check(query.CallbacksRunning())
+		if !stalled {
+			continue
+		}
+
+		interrupted := false
+		if m.interrupt {
+			ctx, cancel := context.WithTimeout(c.ctx, m.timeout)
+			err := query.InterruptAndWait(ctx)
+			cancel()
+			if err != nil {
+				c.logger().Warn("failed to interrupt stalled turn", "error", err)
+			}
+			interrupted = err == nil
+		}
+		c.logger().Warn("CLI stalled", "idle", idle, "interrupted", interrupted)
+
+		if !c.sendError(errors.NewStalledError(idle, interrupted)) {
+			return
+		}
+	}
+}
+
+// stallTicks returns the ticks on which runAttempt checks for a stall, nil
+// without a monitor
+func stallTicks(m *stallMonitor) (<-chan time.Time, func()) {
+	if m == nil {
+		return nil, func() {}
+	}
+	ticker := time.NewTicker(m.interval())
+	return ticker.C, ticker.Stop
+}
+
+// checkStall returns the StalledError of a one-shot query, or nil
+func checkStall(m *stallMonitor, query *internal.Query) error {
+	if idle, stalled := m.check(query.CallbacksRunning()); stalled {
+		return errors.NewStalledError(idle, false)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package claudecode
+
+import (
+	"context"
+	stderrors "errors"
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/transporttest"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+func TestStallInterrupt(t *testing.T) {
+	mock := transporttest.NewMockTransport()
+	client := NewClaudeSDKClientWithTransport(types.NewOptions().WithStallTimeout(50*time.Millisecond, true), mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	// No turn in progress, so silence is fine
+	time.Sleep(100 * time.Millisecond)
+	select {
+	case err := <-client.Errors():
+		t.Fatalf("unexpected error while idle: %v", err)
+	default:
+	}
+
+	if err := client.SendMessage("hang", "default"); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+
+	var stalled *StalledError
+	select {
+	case err := <-client.Errors():
+		if !stderrors.As(err, &stalled) || !stderrors.Is(err, ErrStalled) {
+			t.Fatalf("expected a StalledError, got %v", err)
+		}
+	case <-ctx.Done():
+		t.Fatal("stall not reported")
+	}
+	if !stalled.Interrupted || stalled.Idle < 50*time.Millisecond {
+		t.Errorf("unexpected stall: %+v", stalled)
+	}
+
+	interrupted := false
+	for _, msg := range mock.WrittenMessages() {
+		if request, ok := msg["request"].(map[string]interface{}); ok && request["subtype"] == "interrupt" {
+			interrupted = true
+		}
+	}
+	if !interrupted {
+		t.Error("expected the stalled turn to be interrupted")
+	}
+}
+
+func TestQueryStall(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	options := types.NewOptions().
+		WithCLIPath("/nonexistent/claude").
+		WithCommandFactory(func(ctx context.Context, path string, args []string) *exec.Cmd {
+			if cmd := versionCommand(ctx, args); cmd != nil {
+				return cmd
+			}
+			return exec.CommandContext(ctx, "sh", "-c", `echo '{"type":"system","subtype":"init","session_id":"s1"}'; sleep 10`)
+		}).
+		WithStallTimeout(100*time.Millisecond, false)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := QuerySync(ctx, "hi", options)
+	if err == nil || ctx.Err() != nil {
+		t.Fatalf("expected the query to end with a stall, got %v", err)
+	}
+}
@@ -0,0 +1,221 @@
+// Package stream assembles partial message updates into typed deltas.
+//
+// When ClaudeCodeOptions.IncludePartialMessages is enabled the CLI emits
+// StreamEvent messages carrying raw API stream events. An Accumulator turns
+// them into incremental deltas and the assembled AssistantMessage:
+//
+//	acc := stream.NewAccumulator()
+//	for msg := range client.Messages() {
+//	    if ev, ok := msg.(*types.StreamEvent); ok {
+//	        for _, delta := range acc.Add(ev) {
+//	            switch d := delta.(type) {
+//	            case *stream.TextDelta:
+//	                fmt.Print(d.Text)
+//	            case *stream.MessageComplete:
+//	                fmt.Println()
+//	            }
+//	        }
+//	    }
+//	}
+package stream
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// Delta is an incremental update produced by an Accumulator
+type Delta interface {
+	isDelta()
+}
+
+// TextDelta is a chunk of text appended to a text block
+type TextDelta struct {
+	Index int
+	Text  string
+}
+
+func (TextDelta) isDelta() {}
+
+// ThinkingDelta is a chunk of thinking appended to a thinking block
+type ThinkingDelta struct {
+	Index    int
+	Thinking string
+}
+
+func (ThinkingDelta) isDelta() {}
+
+// ToolInputDelta is a fragment of the JSON input of a tool use block
+type ToolInputDelta struct {
+	Index       int
+	ToolUseID   string
+	Name        string
+	PartialJSON string
+}
+
+func (ToolInputDelta) isDelta() {}
+
+// MessageComplete carries the fully assembled message
+type MessageComplete struct {
+	Message    *types.AssistantMessage
+	StopReason string
+}
+
+func (MessageComplete) isDelta() {}
+
+// block is a content block under construction
+type block struct {
+	kind      string // "text", "thinking" or "tool_use"
+	text      strings.Builder
+	signature string
+	id        string
+	name      string
+	input     strings.Builder
+}
+
+// Accumulator assembles stream events into deltas and complete messages.
+// It is safe for concurrent use.
+type Accumulator struct {
+	model           string
+	parentToolUseID *string
+	stopReason      string
+	blocks          map[int]*block
+
+	mu sync.Mutex
+}
+
+// NewAccumulator creates an empty Accumulator
+func NewAccumulator() *Accumulator {
+	return &Accumulator{
+		blocks: make(map[int]*block),
+	}
+}
+
+// Add consumes a stream event and returns the deltas it produced.
+// A MessageComplete delta is returned when the message stops.
+func (a *Accumulator) Add(ev *types.StreamEvent) []Delta {
+	event, err := ev.DecodeEvent()
+	if err != nil {
+		return nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	switch e := event.(type) {
+	case *types.MessageStartEvent:
+		a.reset()
+		a.parentToolUseID = ev.ParentToolUseID
+		a.model = e.Message.Model
+	case *types.ContentBlockStartEvent:
+		b := &block{kind: e.BlockType}
+		switch contentBlock := e.ContentBlock.(type) {
+		case *types.TextBlock:
+			b.text.WriteString(contentBlock.Text)
+		case *types.ThinkingBlock:
+			b.text.WriteString(contentBlock.Thinking)
+			b.signature = contentBlock.Signature
+		case *types.ToolUseBlock:
+			b.id = contentBlock.ID
+			b.name = contentBlock.Name
+		}
+		a.blocks[e.Index] = b
+	case *types.ContentBlockDeltaEvent:
+		return a.applyDelta(e.Index, e.Delta)
+	case *types.MessageDeltaEvent:
+		if e.Delta.StopReason != "" {
+			a.stopReason = e.Delta.StopReason
+		}
+	case *types.MessageStopEvent:
+		complete := &MessageComplete{
+			Message:    a.message(),
+			StopReason: a.stopReason,
+		}
+		a.reset()
+		return []Delta{complete}
+	}
+
+	return nil
+}
+
+// Message returns a snapshot of the message assembled so far
+func (a *Accumulator) Message() *types.AssistantMessage {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.message()
+}
+
+// applyDelta appends a content_block_delta to its block
+func (a *Accumulator) applyDelta(index int, delta types.StreamDelta) []Delta {
+	b, ok := a.blocks[index]
+	if !ok {
+		b = &block{}
+		a.blocks[index] = b
+	}
+
+	switch delta.Type {
+	case types.DeltaTypeText:
+		b.kind = types.ContentBlockTypeText
+		b.text.WriteString(delta.Text)
+		return []Delta{&TextDelta{Index: index, Text: delta.Text}}
+	case types.DeltaTypeThinking:
+		b.kind = types.ContentBlockTypeThinking
+		b.text.WriteString(delta.Thinking)
+		return []Delta{&ThinkingDelta{Index: index, Thinking: delta.Thinking}}
+	case types.DeltaTypeSignature:
+		b.signature += delta.Signature
+	case types.DeltaTypeInputJSON:
+		b.kind = types.ContentBlockTypeToolUse
+		b.input.WriteString(delta.PartialJSON)
+		return []Delta{&ToolInputDelta{Index: index, ToolUseID: b.id, Name: b.name, PartialJSON: delta.PartialJSON}}
+	}
+
+	return nil
+}
+
+// message builds an AssistantMessage from the blocks in index order
+func (a *Accumulator) message() *types.AssistantMessage {
+	indexes := make([]int, 0, len(a.blocks))
+	for index := range a.blocks {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	msg := &types.AssistantMessage{
+		Model:           a.model,
+		ParentToolUseID: a.parentToolUseID,
+		Content:         make([]types.ContentBlock, 0, len(indexes)),
+	}
+
+	for _, index := range indexes {
+		b := a.blocks[index]
+		switch b.kind {
+		case types.ContentBlockTypeText:
+			msg.Content = append(msg.Content, &types.TextBlock{Text: b.text.String()})
+		case types.ContentBlockTypeThinking:
+			msg.Content = append(msg.Content, &types.ThinkingBlock{Thinking: b.text.String(), Signature: b.signature})
+		case types.ContentBlockTypeToolUse:
+			input := make(map[string]interface{})
+			if raw := b.input.String(); raw != "" {
+				// Incomplete JSON leaves the input empty until the block finishes
+				json.Unmarshal([]byte(raw), &input)
+			}
+			msg.Content = append(msg.Content, &types.ToolUseBlock{ID: b.id, Name: b.name, Input: input})
+		}
+	}
+
+	return msg
+}
+
+// reset clears the state for the next message
+func (a *Accumulator) reset() {
+	a.model = ""
+	a.parentToolUseID = nil
+	a.stopReason = ""
+	a.blocks = make(map[int]*block)
+}
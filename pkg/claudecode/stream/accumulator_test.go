@@ -0,0 +1,99 @@
+package stream
+
+import (
+	"testing"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+func event(data map[string]interface{}) *types.StreamEvent {
+	return &types.StreamEvent{UUID: "u", SessionID: "s", Event: data}
+}
+
+func TestAccumulator(t *testing.T) {
+	acc := NewAccumulator()
+
+	events := []map[string]interface{}{
+		{"type": "message_start", "message": map[string]interface{}{"model": "claude-sonnet-4"}},
+		{"type": "content_block_start", "index": float64(0), "content_block": map[string]interface{}{"type": "text", "text": ""}},
+		{"type": "content_block_delta", "index": float64(0), "delta": map[string]interface{}{"type": "text_delta", "text": "Hello"}},
+		{"type": "content_block_delta", "index": float64(0), "delta": map[string]interface{}{"type": "text_delta", "text": " world"}},
+		{"type": "content_block_start", "index": float64(1), "content_block": map[string]interface{}{"type": "tool_use", "id": "t1", "name": "Read"}},
+		{"type": "content_block_delta", "index": float64(1), "delta": map[string]interface{}{"type": "input_json_delta", "partial_json": `{"file_path":`}},
+		{"type": "content_block_delta", "index": float64(1), "delta": map[string]interface{}{"type": "input_json_delta", "partial_json": `"a.go"}`}},
+		{"type": "message_delta", "delta": map[string]interface{}{"stop_reason": "tool_use"}},
+	}
+
+	var text string
+	toolDeltas := 0
+	for _, e := range events {
+		for _, delta := range acc.Add(event(e)) {
+			switch d := delta.(type) {
+			case *TextDelta:
+				text += d.Text
+			case *ToolInputDelta:
+				toolDeltas++
+				if d.Name != "Read" || d.ToolUseID != "t1" {
+					t.Errorf("Unexpected tool delta: %+v", d)
+				}
+			}
+		}
+	}
+
+	if text != "Hello world" {
+		t.Errorf("Expected text 'Hello world', got %q", text)
+	}
+	if toolDeltas != 2 {
+		t.Errorf("Expected 2 tool input deltas, got %d", toolDeltas)
+	}
+
+	deltas := acc.Add(event(map[string]interface{}{"type": "message_stop"}))
+	if len(deltas) != 1 {
+		t.Fatalf("Expected 1 delta on message_stop, got %d", len(deltas))
+	}
+	complete, ok := deltas[0].(*MessageComplete)
+	if !ok {
+		t.Fatalf("Expected MessageComplete, got %T", deltas[0])
+	}
+	if complete.StopReason != "tool_use" || complete.Message.Model != "claude-sonnet-4" {
+		t.Errorf("Unexpected completion: %+v", complete)
+	}
+	if len(complete.Message.Content) != 2 {
+		t.Fatalf("Expected 2 content blocks, got %d", len(complete.Message.Content))
+	}
+	toolUse, ok := complete.Message.Content[1].(*types.ToolUseBlock)
+	if !ok || toolUse.Input["file_path"] != "a.go" {
+		t.Errorf("Unexpected tool use block: %+v", complete.Message.Content[1])
+	}
+}
+
+func TestAccumulatorThinking(t *testing.T) {
+	acc := NewAccumulator()
+
+	events := []map[string]interface{}{
+		{"type": "message_start", "message": map[string]interface{}{"model": "claude-sonnet-4"}},
+		{"type": "content_block_start", "index": float64(0), "content_block": map[string]interface{}{"type": "thinking", "thinking": "", "signature": ""}},
+		{"type": "content_block_delta", "index": float64(0), "delta": map[string]interface{}{"type": "thinking_delta", "thinking": "Check the "}},
+		{"type": "content_block_delta", "index": float64(0), "delta": map[string]interface{}{"type": "thinking_delta", "thinking": "tests first"}},
+		{"type": "content_block_delta", "index": float64(0), "delta": map[string]interface{}{"type": "signature_delta", "signature": "sig"}},
+		{"type": "content_block_start", "index": float64(1), "content_block": map[string]interface{}{"type": "text", "text": "Done"}},
+	}
+
+	var thinking string
+	for _, e := range events {
+		for _, delta := range acc.Add(event(e)) {
+			if d, ok := delta.(*ThinkingDelta); ok {
+				thinking += d.Thinking
+			}
+		}
+	}
+	if thinking != "Check the tests first" {
+		t.Errorf("Expected streamed thinking, got %q", thinking)
+	}
+
+	msg := acc.Message()
+	block, ok := msg.Content[0].(*types.ThinkingBlock)
+	if !ok || block.Thinking != thinking || block.Signature != "sig" {
+		t.Errorf("Unexpected thinking block: %+v", msg.Content[0])
+	}
+}
@@ -0,0 +1,73 @@
+package claudecode
+
+import (
+	"context"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// telemetry forwards the events of a conversation to options.Telemetry. A
+// nil *telemetry, used when none is configured, ignores them.
+type telemetry struct {
+	conversation types.ConversationTelemetry
+}
+
+// newTelemetry returns nil when options.Telemetry is not set
+func newTelemetry(options *types.ClaudeCodeOptions) *telemetry {
+	if options == nil || options.Telemetry == nil {
+		return nil
+	}
+	conversation := options.Telemetry.Conversation()
+	if conversation == nil {
+		return nil
+	}
+	return &telemetry{conversation: conversation}
+}
+
+// controlRequests returns the telemetry control requests are reported to
+func (t *telemetry) controlRequests() types.ConversationTelemetry {
+	if t == nil {
+		return nil
+	}
+	return t.conversation
+}
+
+// observeQueue reports the number of messages waiting in ch
+func (t *telemetry) observeQueue(ch chan types.Message) {
+	if t == nil {
+		return
+	}
+	t.conversation.ObserveQueue(func() int { return len(ch) })
+}
+
+// messageDropped counts a message discarded by the overflow policy
+func (t *telemetry) messageDropped() {
+	if t == nil {
+		return
+	}
+	t.conversation.MessageDropped()
+}
+
+// startQuery marks a prompt sent to the CLI
+func (t *telemetry) startQuery(ctx context.Context) {
+	if t == nil {
+		return
+	}
+	t.conversation.StartQuery(ctx)
+}
+
+// observe reports a received message
+func (t *telemetry) observe(msg types.Message) {
+	if t == nil {
+		return
+	}
+	t.conversation.Observe(msg)
+}
+
+// end ends the conversation, e.g. when the connection closes mid-query
+func (t *telemetry) end() {
+	if t == nil {
+		return
+	}
+	t.conversation.End()
+}
@@ -0,0 +1,96 @@
+package claudecode
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/transporttest"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// recordingTelemetry records the events of every conversation
+type recordingTelemetry struct {
+	mu     sync.Mutex
+	events []string
+	depth  func() int
+}
+
+func (r *recordingTelemetry) Conversation() types.ConversationTelemetry { return r }
+
+func (r *recordingTelemetry) record(event string) {
+	r.mu.Lock()
+	r.events = append(r.events, event)
+	r.mu.Unlock()
+}
+
+func (r *recordingTelemetry) StartQuery(context.Context) { r.record("query") }
+
+func (r *recordingTelemetry) Observe(msg types.Message) { r.record(fmt.Sprintf("%T", msg)) }
+
+func (r *recordingTelemetry) ObserveQueue(depth func() int) {
+	r.mu.Lock()
+	r.depth = depth
+	r.mu.Unlock()
+}
+
+func (r *recordingTelemetry) MessageDropped() { r.record("dropped") }
+
+func (r *recordingTelemetry) ControlRequest(ctx context.Context, subtype string, requestID string) (context.Context, func(error)) {
+	r.record("control " + subtype)
+	return ctx, func(err error) { r.record(fmt.Sprintf("control done %v", err)) }
+}
+
+func (r *recordingTelemetry) End() { r.record("end") }
+
+func TestTelemetry(t *testing.T) {
+	mock := transporttest.NewMockTransport()
+	mock.Respond(transporttest.MatchType("user"),
+		map[string]interface{}{"type": "result", "subtype": "success", "session_id": "s1"},
+	)
+
+	r := &recordingTelemetry{}
+	client := NewClaudeSDKClientWithTransport(types.NewOptions().WithTelemetry(r), mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	if err := client.SendMessage("hello", "default"); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	if _, ok := nextMessage(t, client.Messages()).(*types.ResultMessage); !ok {
+		t.Fatal("expected the result")
+	}
+	client.Close()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	want := []string{"control initialize", "control done <nil>", "query", "*types.ResultMessage", "end"}
+	if !slices.Equal(r.events, want) {
+		t.Errorf("events = %v, want %v", r.events, want)
+	}
+	if r.depth == nil || r.depth() != 0 {
+		t.Error("expected the queue depth to be observed")
+	}
+}
+
+func TestTelemetryDisabled(t *testing.T) {
+	var none *telemetry
+	none.startQuery(context.Background())
+	none.observe(&types.ResultMessage{})
+	none.messageDropped()
+	none.end()
+	if none.controlRequests() != nil {
+		t.Error("expected no telemetry for control requests")
+	}
+	if newTelemetry(types.NewOptions()) != nil {
+		t.Error("expected nil telemetry without options.Telemetry")
+	}
+}
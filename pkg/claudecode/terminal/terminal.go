@@ -0,0 +1,245 @@
+// Package terminal asks the user on a terminal whether Claude may use a
+// tool, for CLI programs that want interactive approval without building
+// their own UI:
+//
+//	prompter := terminal.NewPrompter(os.Stdin, os.Stderr)
+//	options := claudecode.NewOptions().WithCanUseTool(prompter.CanUseTool)
+//
+// Every request shows the tool and its input, or the diff of the file
+// change for Write, Edit and MultiEdit, and takes one of y (allow once),
+// n (deny once), a (always allow) or v (never allow).
+package terminal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/tools"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// defaultMaxInputBytes is how much of a tool input is shown by default
+const defaultMaxInputBytes = 4096
+
+// Prompter implements CanUseTool by asking on a terminal. Requests are
+// asked one at a time.
+type Prompter struct {
+	out io.Writer
+
+	// Where "always" rules are saved when the CLI suggests none (default
+	// the session)
+	Destination types.PermissionUpdateDestination
+
+	// Longest input or diff shown; longer ones are cut (default 4KB)
+	MaxInputBytes int
+
+	// Lines read from the input; closed at EOF
+	answers   chan string
+	startRead sync.Once
+	in        io.Reader
+
+	mu      sync.Mutex // Held while asking
+	always  map[string]bool
+	never   map[string]bool
+	updates []types.PermissionUpdate
+}
+
+// NewPrompter creates a Prompter reading answers from in and writing
+// prompts to out, typically os.Stdin and os.Stderr
+func NewPrompter(in io.Reader, out io.Writer) *Prompter {
+	return &Prompter{
+		in:          in,
+		out:         out,
+		Destination: types.PermissionDestinationSession,
+		answers:     make(chan string),
+		always:      make(map[string]bool),
+		never:       make(map[string]bool),
+	}
+}
+
+// CanUseTool asks whether toolName may run with input. "Always" allows the
+// tool with the CLI's suggested rules, or a rule for the whole tool, which
+// the CLI applies so it stops asking. "Never" denies the tool without asking
+// for the rest of the Prompter's life. A request cancelled by the CLI, or
+// an input that reached EOF, is denied.
+func (p *Prompter) CanUseTool(toolName string, input map[string]interface{}, ctx *types.ToolPermissionContext) (types.PermissionResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Answered while this request waited for an earlier one
+	if p.always[toolName] {
+		return &types.PermissionResultAllow{Behavior: types.PermissionBehaviorAllow}, nil
+	}
+	if p.never[toolName] {
+		return deny(fmt.Sprintf("The user never allows %s", toolName)), nil
+	}
+
+	rules := p.alwaysRules(toolName, ctx)
+	fmt.Fprintf(p.out, "\nClaude wants to use %s\n%s\n", toolName, strings.TrimSuffix(p.formatInput(toolName, input), "\n"))
+	for {
+		fmt.Fprintf(p.out, "Allow? [y]es, [n]o, [a]lways (%s), ne[v]er: ", describeUpdates(rules))
+
+		answer, err := p.readAnswer(ctx)
+		if err != nil {
+			fmt.Fprintln(p.out)
+			return deny(err.Error()), nil
+		}
+
+		switch strings.ToLower(answer) {
+		case "y", "yes":
+			return &types.PermissionResultAllow{Behavior: types.PermissionBehaviorAllow}, nil
+		case "n", "no":
+			return deny("The user denied this tool use"), nil
+		case "a", "always":
+			if isToolRule(rules, toolName) {
+				p.always[toolName] = true
+			}
+			p.updates = append(p.updates, rules...)
+			return &types.PermissionResultAllow{Behavior: types.PermissionBehaviorAllow, UpdatedPermissions: rules}, nil
+		case "v", "never":
+			p.never[toolName] = true
+			return deny(fmt.Sprintf("The user never allows %s", toolName)), nil
+		}
+	}
+}
+
+// Updates returns the permission updates granted with "always" so far,
+// e.g. to save them with a conversation
+func (p *Prompter) Updates() []types.PermissionUpdate {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]types.PermissionUpdate(nil), p.updates...)
+}
+
+// alwaysRules returns the updates an "always" answer applies
+func (p *Prompter) alwaysRules(toolName string, ctx *types.ToolPermissionContext) []types.PermissionUpdate {
+	if ctx != nil && len(ctx.Suggestions) > 0 {
+		return ctx.Suggestions
+	}
+
+	behavior := types.PermissionBehaviorAllow
+	destination := p.Destination
+	return []types.PermissionUpdate{{
+		Type:        types.PermissionUpdateAddRules,
+		Rules:       []types.PermissionRuleValue{{ToolName: toolName}},
+		Behavior:    &behavior,
+		Destination: &destination,
+	}}
+}
+
+// readAnswer returns the next line of input, giving up when the request is
+// cancelled or the input ends
+func (p *Prompter) readAnswer(ctx *types.ToolPermissionContext) (string, error) {
+	p.startRead.Do(func() { go p.readLines() })
+
+	var done <-chan struct{}
+	if ctx != nil && ctx.Signal != nil {
+		done = ctx.Signal.Done()
+	}
+
+	select {
+	case answer, ok := <-p.answers:
+		if !ok {
+			return "", fmt.Errorf("no answer: the terminal input is closed")
+		}
+		return strings.TrimSpace(answer), nil
+	case <-done:
+		return "", fmt.Errorf("the permission request was cancelled")
+	}
+}
+
+// readLines feeds answers from the input. Reads cannot be cancelled, so
+// one goroutine reads for the life of the Prompter.
+func (p *Prompter) readLines() {
+	defer close(p.answers)
+
+	scanner := bufio.NewScanner(p.in)
+	for scanner.Scan() {
+		p.answers <- scanner.Text()
+	}
+}
+
+// formatInput renders input, cut to MaxInputBytes: the diff of the file
+// change for tools that edit files, pretty-printed JSON otherwise
+func (p *Prompter) formatInput(toolName string, input map[string]interface{}) string {
+	var text string
+	change, err := tools.ProposedChange(toolName, input)
+	if change != nil {
+		text = change.Diff()
+		if text == "" {
+			text = fmt.Sprintf("  (no change to %s)", change.Path)
+		}
+	} else {
+		data, jsonErr := json.MarshalIndent(input, "  ", "  ")
+		if jsonErr != nil {
+			text = fmt.Sprintf("  %v", input)
+		} else {
+			text = "  " + string(data)
+		}
+		if err != nil {
+			text += fmt.Sprintf("\n  (no preview: %v)", err)
+		}
+	}
+
+	limit := p.MaxInputBytes
+	if limit <= 0 {
+		limit = defaultMaxInputBytes
+	}
+	if len(text) > limit {
+		for limit > 0 && !utf8.RuneStart(text[limit]) {
+			limit--
+		}
+		text = fmt.Sprintf("%s\n  ... (%d more bytes)", text[:limit], len(text)-limit)
+	}
+	return text
+}
+
+// describeUpdates renders updates the way rules are written in settings,
+// e.g. "Bash(npm test:*)"
+func describeUpdates(updates []types.PermissionUpdate) string {
+	var parts []string
+	for _, update := range updates {
+		switch update.Type {
+		case types.PermissionUpdateSetMode:
+			if update.Mode != nil {
+				parts = append(parts, "mode "+string(*update.Mode))
+			}
+		case types.PermissionUpdateAddDirectories:
+			parts = append(parts, "directories "+strings.Join(update.Directories, ", "))
+		default:
+			for _, rule := range update.Rules {
+				if rule.RuleContent != nil {
+					parts = append(parts, fmt.Sprintf("%s(%s)", rule.ToolName, *rule.RuleContent))
+				} else {
+					parts = append(parts, rule.ToolName)
+				}
+			}
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// isToolRule reports whether updates allow the whole tool, so later
+// requests for it need not be asked
+func isToolRule(updates []types.PermissionUpdate, toolName string) bool {
+	for _, update := range updates {
+		if update.Type != types.PermissionUpdateAddRules || update.Behavior == nil || *update.Behavior != types.PermissionBehaviorAllow {
+			continue
+		}
+		for _, rule := range update.Rules {
+			if rule.ToolName == toolName && rule.RuleContent == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func deny(message string) *types.PermissionResultDeny {
+	return &types.PermissionResultDeny{Behavior: types.PermissionBehaviorDeny, Message: message}
+}
@@ -0,0 +1,100 @@
+package terminal_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/terminal"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+func TestPrompter(t *testing.T) {
+	var out bytes.Buffer
+	prompter := terminal.NewPrompter(strings.NewReader("maybe\ny\nn\na\nalways\nv\n"), &out)
+	ctx := &types.ToolPermissionContext{Signal: context.Background()}
+	input := map[string]interface{}{"command": "ls -la"}
+
+	// An unknown answer asks again; y allows once
+	result, _ := prompter.CanUseTool("Bash", input, ctx)
+	if _, ok := result.(*types.PermissionResultAllow); !ok {
+		t.Errorf("y = %#v, want allow", result)
+	}
+	if !strings.Contains(out.String(), "Claude wants to use Bash") || !strings.Contains(out.String(), `"command": "ls -la"`) {
+		t.Errorf("prompt lacks the tool or its input:\n%s", out.String())
+	}
+
+	result, _ = prompter.CanUseTool("Bash", input, ctx)
+	if _, ok := result.(*types.PermissionResultDeny); !ok {
+		t.Errorf("n = %#v, want deny", result)
+	}
+
+	// Always with a suggestion applies the suggestion and asks again later
+	content := "npm test:*"
+	suggested := &types.ToolPermissionContext{
+		Signal:      context.Background(),
+		Suggestions: []types.PermissionUpdate{{Type: types.PermissionUpdateAddRules, Rules: []types.PermissionRuleValue{{ToolName: "Bash", RuleContent: &content}}}},
+	}
+	result, _ = prompter.CanUseTool("Bash", input, suggested)
+	if allow, ok := result.(*types.PermissionResultAllow); !ok || len(allow.UpdatedPermissions) != 1 || allow.UpdatedPermissions[0].Rules[0].RuleContent != &content {
+		t.Errorf("a = %#v, want the suggested rule", result)
+	}
+	if !strings.Contains(out.String(), "[a]lways (Bash(npm test:*))") {
+		t.Errorf("prompt does not show the suggested rule:\n%s", out.String())
+	}
+
+	// Always without suggestions allows the whole tool without asking again
+	result, _ = prompter.CanUseTool("Read", input, ctx)
+	if allow, ok := result.(*types.PermissionResultAllow); !ok || *allow.UpdatedPermissions[0].Destination != types.PermissionDestinationSession {
+		t.Errorf("always = %#v, want a session rule for Read", result)
+	}
+	if result, _ := prompter.CanUseTool("Read", input, ctx); result.(*types.PermissionResultAllow).UpdatedPermissions != nil {
+		t.Error("Read was asked again after always")
+	}
+	if updates := prompter.Updates(); len(updates) != 2 {
+		t.Errorf("Updates = %+v, want both always answers", updates)
+	}
+
+	// Never denies without asking again
+	prompter.CanUseTool("Write", input, ctx)
+	if result, _ := prompter.CanUseTool("Write", input, ctx); result.(*types.PermissionResultDeny).Message != "The user never allows Write" {
+		t.Errorf("never = %#v", result)
+	}
+
+	// The input is used up
+	if result, _ := prompter.CanUseTool("Edit", input, ctx); !strings.Contains(result.(*types.PermissionResultDeny).Message, "closed") {
+		t.Errorf("EOF = %#v, want deny", result)
+	}
+}
+
+func TestPrompterCancelled(t *testing.T) {
+	var out bytes.Buffer
+	prompter := terminal.NewPrompter(strings.NewReader(""), &out)
+
+	signal, cancel := context.WithCancel(context.Background())
+	cancel()
+	// With no answer pending either way, cancellation or EOF deny the request
+	result, err := prompter.CanUseTool("Bash", nil, &types.ToolPermissionContext{Signal: signal})
+	if _, ok := result.(*types.PermissionResultDeny); !ok || err != nil {
+		t.Errorf("cancelled request = %#v, %v, want deny", result, err)
+	}
+}
+
+func TestPrompterDiff(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("draft\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	prompter := terminal.NewPrompter(strings.NewReader("y\n"), &out)
+	prompter.CanUseTool("Edit", map[string]interface{}{"file_path": path, "old_string": "draft", "new_string": "final"},
+		&types.ToolPermissionContext{Signal: context.Background()})
+
+	if !strings.Contains(out.String(), "-draft\n+final\n") {
+		t.Errorf("prompt lacks the diff:\n%s", out.String())
+	}
+}
@@ -0,0 +1,87 @@
+package claudecode
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// toolSubscription is a callback added with OnToolUse or OnToolResult;
+// exactly one of the functions is set
+type toolSubscription struct {
+	onUse    func(types.ToolUseBlock)
+	onResult func(types.ToolResultBlock)
+}
+
+// toolSubscriptions holds the tool callbacks in subscription order
+type toolSubscriptions struct {
+	subs []*toolSubscription
+	mu   sync.RWMutex
+}
+
+// OnToolUse calls fn for every tool Claude asks to run, as soon as the
+// assistant message requesting it arrives, e.g. to show a "Running Bash…"
+// indicator. Callbacks run in order on the goroutine reading messages, before
+// the message is delivered, and must return quickly. They fire whether or not
+// Messages() is drained. The returned function removes the subscription.
+func (c *ClaudeSDKClient) OnToolUse(fn func(types.ToolUseBlock)) (unsubscribe func()) {
+	return c.tools.add(&toolSubscription{onUse: fn})
+}
+
+// OnToolResult calls fn for every tool result reported by the CLI, under the
+// same rules as OnToolUse. ToolUseID links the result to its tool use.
+func (c *ClaudeSDKClient) OnToolResult(fn func(types.ToolResultBlock)) (unsubscribe func()) {
+	return c.tools.add(&toolSubscription{onResult: fn})
+}
+
+// add appends sub and returns the function removing it
+func (s *toolSubscriptions) add(sub *toolSubscription) func() {
+	s.mu.Lock()
+	s.subs = append(s.subs, sub)
+	s.mu.Unlock()
+
+	return func() {
+		s.mu.Lock()
+		// Copy so notify can keep iterating its snapshot
+		s.subs = slices.DeleteFunc(slices.Clone(s.subs), func(other *toolSubscription) bool { return other == sub })
+		s.mu.Unlock()
+	}
+}
+
+// notify fires the subscriptions for the tool blocks of msg. Callbacks run
+// without the lock held so they may unsubscribe.
+func (s *toolSubscriptions) notify(msg types.Message) {
+	s.mu.RLock()
+	subs := s.subs
+	s.mu.RUnlock()
+
+	if len(subs) == 0 {
+		return
+	}
+
+	var blocks []types.ContentBlock
+	switch m := msg.(type) {
+	case *types.AssistantMessage:
+		blocks = m.Content
+	case *types.UserMessage:
+		blocks, _ = m.Content.([]types.ContentBlock)
+	}
+
+	for _, block := range blocks {
+		switch b := block.(type) {
+		case *types.ToolUseBlock:
+			for _, sub := range subs {
+				if sub.onUse != nil {
+					sub.onUse(*b)
+				}
+			}
+		case *types.ToolResultBlock:
+			for _, sub := range subs {
+				if sub.onResult != nil {
+					sub.onResult(*b)
+				}
+			}
+		}
+	}
+}
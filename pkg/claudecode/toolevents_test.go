@@ -0,0 +1,70 @@
+package claudecode
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/transporttest"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+func TestToolSubscriptions(t *testing.T) {
+	mock := transporttest.NewMockTransport()
+	client := NewClaudeSDKClientWithTransport(nil, mock)
+
+	uses := make(chan types.ToolUseBlock, 10)
+	results := make(chan types.ToolResultBlock, 10)
+	stopUses := client.OnToolUse(func(block types.ToolUseBlock) { uses <- block })
+	client.OnToolResult(func(block types.ToolResultBlock) { results <- block })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	mock.Emit(map[string]interface{}{"type": "assistant", "model": "sonnet", "content": []interface{}{
+		map[string]interface{}{"type": "text", "text": "Listing files"},
+		map[string]interface{}{"type": "tool_use", "id": "tu_1", "name": "Bash", "input": map[string]interface{}{"command": "ls"}},
+	}})
+	mock.Emit(map[string]interface{}{"type": "user", "content": []interface{}{
+		map[string]interface{}{"type": "tool_result", "tool_use_id": "tu_1", "content": "main.go"},
+	}})
+
+	// Subscriptions fire without Messages() being drained
+	select {
+	case use := <-uses:
+		if use.ID != "tu_1" || use.Name != "Bash" || use.Input["command"] != "ls" {
+			t.Errorf("unexpected tool use: %+v", use)
+		}
+	case <-ctx.Done():
+		t.Fatal("no tool use notification")
+	}
+	select {
+	case result := <-results:
+		if result.ToolUseID != "tu_1" || result.Content != "main.go" {
+			t.Errorf("unexpected tool result: %+v", result)
+		}
+	case <-ctx.Done():
+		t.Fatal("no tool result notification")
+	}
+
+	// Both messages are still delivered
+	for range 2 {
+		<-client.Messages()
+	}
+
+	stopUses()
+	mock.Emit(map[string]interface{}{"type": "assistant", "model": "sonnet", "content": []interface{}{
+		map[string]interface{}{"type": "tool_use", "id": "tu_2", "name": "Read", "input": map[string]interface{}{}},
+	}})
+	<-client.Messages()
+	select {
+	case use := <-uses:
+		t.Errorf("unsubscribed callback fired for %+v", use)
+	default:
+	}
+}
@@ -0,0 +1,118 @@
+package claudecode
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"slices"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/internal"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// localToolsServerName is the SDK MCP server backing ClaudeSDKClient.RegisterTool
+const localToolsServerName = "sdk_local_tools"
+
+// MCPToolName returns the name Claude Code uses for a tool served by an MCP server
+func MCPToolName(serverName, toolName string) string {
+	return fmt.Sprintf("mcp__%s__%s", serverName, toolName)
+}
+
+// RegisterTool exposes a Go function as a tool Claude can use.
+//
+// The tool is advertised through an in-process SDK MCP server and is
+// automatically added to AllowedTools. The schema is a JSON Schema object
+// describing the tool input; its "description" entry, if present, is used as
+// the tool description. Tools must be registered before Connect.
+//
+// Example:
+//
+//	type weatherArgs struct {
+//	    City string `json:"city"`
+//	}
+//	client.RegisterTool("get_weather", schema, claudecode.TypedTool(
+//	    func(ctx context.Context, args weatherArgs) (string, error) {
+//	        return lookupWeather(args.City)
+//	    },
+//	))
+func (c *ClaudeSDKClient) RegisterTool(name string, schema map[string]interface{}, handler types.HostToolHandler) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.connected {
+		return stderrors.New("tools must be registered before Connect")
+	}
+	if name == "" || handler == nil {
+		return stderrors.New("tool name and handler are required")
+	}
+
+	if c.localTools == nil {
+		c.localTools = internal.NewSDKMCPServer(localToolsServerName, Version)
+	}
+
+	description, _ := schema["description"].(string)
+	c.localTools.AddTool(&internal.SDKMCPTool{
+		Name:        name,
+		Description: description,
+		InputSchema: schema,
+		Handler: func(ctx context.Context, args map[string]interface{}) (*types.MCPToolResult, error) {
+			text, err := handler(ctx, args)
+			if err != nil {
+				return nil, err
+			}
+			return TextResult(text), nil
+		},
+	})
+
+	return nil
+}
+
+// registerLocalTools adds the local tools server to a copy of the options
+// and allows its tools, so the caller's options are left alone and a
+// reconnect does not list them twice. Called from Connect with c.mu held.
+func (c *ClaudeSDKClient) registerLocalTools() {
+	if c.localTools == nil {
+		return
+	}
+
+	options := *c.options
+
+	servers := make(map[string]types.MCPServerConfig, len(options.MCPServers)+1)
+	for name, config := range options.MCPServers {
+		servers[name] = config
+	}
+	servers[localToolsServerName] = types.MCPSDKServerConfig{
+		Type:     "sdk",
+		Name:     localToolsServerName,
+		Instance: c.localTools,
+	}
+	options.MCPServers = servers
+
+	allowed := append([]string{}, options.AllowedTools...)
+	for _, tool := range c.localTools.Tools() {
+		name := MCPToolName(localToolsServerName, tool.Name)
+		if !slices.Contains(allowed, name) {
+			allowed = append(allowed, name)
+		}
+	}
+	options.AllowedTools = allowed
+
+	c.options = &options
+}
+
+// TypedTool adapts a function taking a typed argument struct into a
+// HostToolHandler. Tool input is decoded into T using encoding/json.
+func TypedTool[T any](fn func(ctx context.Context, args T) (string, error)) types.HostToolHandler {
+	return func(ctx context.Context, input map[string]interface{}) (string, error) {
+		var args T
+		data, err := json.Marshal(input)
+		if err != nil {
+			return "", err
+		}
+		if err := json.Unmarshal(data, &args); err != nil {
+			return "", fmt.Errorf("invalid tool input: %w", err)
+		}
+		return fn(ctx, args)
+	}
+}
@@ -0,0 +1,296 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around a change
+const diffContext = 3
+
+// maxEditDistance bounds the work of diffing. Files further apart are
+// shown as one block replaced by another.
+const maxEditDistance = 1000
+
+// FileChange is the change a Write, Edit or MultiEdit tool use would make
+// to a file
+type FileChange struct {
+	Path    string
+	Before  string // Current content, empty if the file does not exist
+	After   string
+	Created bool // The file does not exist yet
+}
+
+// ProposedChange reads the file a Write, Edit or MultiEdit input targets
+// and applies the input to it in memory, e.g. to show an approver what a
+// tool use would change:
+//
+//	change, err := tools.ProposedChange(toolName, input)
+//	if err == nil && change != nil {
+//	    fmt.Print(change.Diff())
+//	}
+//
+// Other tools return nil. Edits that would fail, e.g. because old_string
+// does not occur in the file, return an error.
+func ProposedChange(name string, input map[string]interface{}) (*FileChange, error) {
+	var path string
+	var apply func(content string) (string, error)
+
+	switch name {
+	case NameWrite:
+		write, err := DecodeAs[WriteInput](input)
+		if err != nil {
+			return nil, err
+		}
+		path = write.FilePath
+		apply = func(string) (string, error) { return write.Content, nil }
+	case NameEdit:
+		edit, err := DecodeAs[EditInput](input)
+		if err != nil {
+			return nil, err
+		}
+		path = edit.FilePath
+		apply = func(content string) (string, error) {
+			return applyEdit(content, EditOperation{OldString: edit.OldString, NewString: edit.NewString, ReplaceAll: edit.ReplaceAll})
+		}
+	case NameMultiEdit:
+		multi, err := DecodeAs[MultiEditInput](input)
+		if err != nil {
+			return nil, err
+		}
+		path = multi.FilePath
+		apply = func(content string) (string, error) {
+			for i, edit := range multi.Edits {
+				if content, err = applyEdit(content, edit); err != nil {
+					return "", fmt.Errorf("edit %d: %w", i+1, err)
+				}
+			}
+			return content, nil
+		}
+	default:
+		return nil, nil
+	}
+
+	change := &FileChange{Path: path}
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		change.Created = true
+	case err != nil:
+		return nil, err
+	default:
+		change.Before = string(data)
+	}
+
+	if change.After, err = apply(change.Before); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return change, nil
+}
+
+// applyEdit applies one edit the way the Edit tool does. An empty
+// old_string fills an empty file.
+func applyEdit(content string, edit EditOperation) (string, error) {
+	if edit.OldString == "" {
+		if content != "" {
+			return "", fmt.Errorf("old_string is empty but the file is not")
+		}
+		return edit.NewString, nil
+	}
+
+	switch count := strings.Count(content, edit.OldString); {
+	case count == 0:
+		return "", fmt.Errorf("old_string not found")
+	case count > 1 && !edit.ReplaceAll:
+		return "", fmt.Errorf("old_string occurs %d times; replace_all is not set", count)
+	case edit.ReplaceAll:
+		return strings.ReplaceAll(content, edit.OldString, edit.NewString), nil
+	default:
+		return strings.Replace(content, edit.OldString, edit.NewString, 1), nil
+	}
+}
+
+// Diff renders the change as a unified diff with three lines of context.
+// It is empty if the content does not change.
+func (c *FileChange) Diff() string {
+	ops := diffLines(splitLines(c.Before), splitLines(c.After))
+
+	var b strings.Builder
+	path := strings.TrimPrefix(c.Path, "/")
+	from := "a/" + path
+	if c.Created {
+		from = "/dev/null"
+	}
+
+	// Lines of each file before every op, for hunk headers
+	oldPos := make([]int, len(ops)+1)
+	newPos := make([]int, len(ops)+1)
+	for i, op := range ops {
+		oldPos[i+1], newPos[i+1] = oldPos[i], newPos[i]
+		if op.kind != '+' {
+			oldPos[i+1]++
+		}
+		if op.kind != '-' {
+			newPos[i+1]++
+		}
+	}
+
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+
+		// Merge changes separated by at most twice the context
+		start := max(i-diffContext, 0)
+		end := i + 1
+		for j := i + 1; j < len(ops) && j-end <= 2*diffContext; j++ {
+			if ops[j].kind != ' ' {
+				end = j + 1
+			}
+		}
+		stop := min(end+diffContext, len(ops))
+
+		if b.Len() == 0 {
+			fmt.Fprintf(&b, "--- %s\n+++ b/%s\n", from, path)
+		}
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n",
+			hunkRange(oldPos[start], oldPos[stop]-oldPos[start]),
+			hunkRange(newPos[start], newPos[stop]-newPos[start]))
+		for _, op := range ops[start:stop] {
+			fmt.Fprintf(&b, "%c%s\n", op.kind, op.line)
+		}
+		i = stop
+	}
+	return b.String()
+}
+
+// hunkRange formats the start and length of a hunk; an empty range starts
+// at the line before it
+func hunkRange(before, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", before)
+	}
+	return fmt.Sprintf("%d,%d", before+1, count)
+}
+
+// splitLines splits content into lines without their newlines
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
+
+// diffOp is a line kept (' '), removed ('-') or added ('+')
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// diffLines returns a shortest edit script turning a into b, using the
+// Myers algorithm on what remains after the common prefix and suffix
+func diffLines(a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []diffOp
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	ops = append(ops, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops
+}
+
+// myers returns the edit script of a and b. Beyond maxEditDistance all of
+// a is removed and all of b added.
+func myers(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	limit := min(n+m, maxEditDistance)
+
+	// v[k+offset] is the furthest x reached on diagonal k; trace keeps v
+	// before every round for the backtrack
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+	var trace [][]int
+	for d := 0; d <= limit; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(a, b, trace)
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, n+m)
+	for _, line := range a {
+		ops = append(ops, diffOp{'-', line})
+	}
+	for _, line := range b {
+		ops = append(ops, diffOp{'+', line})
+	}
+	return ops
+}
+
+// backtrack walks the rounds of myers back from the end of both inputs
+func backtrack(a, b []string, trace [][]int) []diffOp {
+	var ops []diffOp
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; d >= 0; d-- {
+		// trace[d] covers diagonals -d-1 to d+1
+		v := func(k int) int { return trace[d][k+d+1] }
+
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v(k-1) < v(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, diffOp{' ', a[x]})
+		}
+		if d > 0 {
+			if x == prevX {
+				ops = append(ops, diffOp{'+', b[prevY]})
+			} else {
+				ops = append(ops, diffOp{'-', a[prevX]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
@@ -0,0 +1,67 @@
+package tools_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/tools"
+)
+
+func TestProposedChange(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go")
+	var lines []string
+	for i := 1; i <= 20; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	change, err := tools.ProposedChange(tools.NameMultiEdit, map[string]interface{}{
+		"file_path": path,
+		"edits": []interface{}{
+			map[string]interface{}{"old_string": "line 2\n", "new_string": "line two\n"},
+			map[string]interface{}{"old_string": "line 18\n", "new_string": ""},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ProposedChange: %v", err)
+	}
+
+	name := strings.TrimPrefix(path, "/")
+	want := "--- a/" + name + "\n+++ b/" + name + "\n" +
+		"@@ -1,5 +1,5 @@\n line 1\n-line 2\n+line two\n line 3\n line 4\n line 5\n" +
+		"@@ -15,6 +15,5 @@\n line 15\n line 16\n line 17\n-line 18\n line 19\n line 20\n"
+	if diff := change.Diff(); diff != want {
+		t.Errorf("Diff =\n%s\nwant\n%s", diff, want)
+	}
+
+	// A new file
+	created, err := tools.ProposedChange(tools.NameWrite, map[string]interface{}{"file_path": filepath.Join(dir, "new.go"), "content": "package main\n"})
+	if err != nil || !created.Created || !strings.Contains(created.Diff(), "--- /dev/null\n") || !strings.Contains(created.Diff(), "@@ -0,0 +1,1 @@\n+package main\n") {
+		t.Errorf("new file: %v\n%s", err, created.Diff())
+	}
+
+	// Edits the tool would reject
+	if _, err := tools.ProposedChange(tools.NameEdit, map[string]interface{}{"file_path": path, "old_string": "line 1", "new_string": "x"}); err == nil {
+		t.Error("ambiguous old_string accepted")
+	}
+	if change, err := tools.ProposedChange(tools.NameBash, map[string]interface{}{"command": "ls"}); change != nil || err != nil {
+		t.Errorf("Bash = %v, %v, want no change", change, err)
+	}
+}
+
+func TestDiffInterleaved(t *testing.T) {
+	change := &tools.FileChange{Path: "f", Before: "a\nb\nc\nd\ne\n", After: "a\nc\nx\nd\ne\ny\n"}
+	want := "--- a/f\n+++ b/f\n@@ -1,5 +1,6 @@\n a\n-b\n c\n+x\n d\n e\n+y\n"
+	if diff := change.Diff(); diff != want {
+		t.Errorf("Diff =\n%s\nwant\n%s", diff, want)
+	}
+	if diff := (&tools.FileChange{Path: "f", Before: "same\n", After: "same\n"}).Diff(); diff != "" {
+		t.Errorf("unchanged file diff = %q", diff)
+	}
+}
@@ -0,0 +1,228 @@
+// Package tools provides typed inputs for the tools built into Claude Code.
+//
+// Tool inputs arrive as map[string]interface{} in ToolUseBlock and CanUseTool
+// callbacks. Decode converts them into the matching struct:
+//
+//	input, err := tools.Decode(toolName, rawInput)
+//	if bash, ok := input.(*tools.BashInput); ok && strings.Contains(bash.Command, "rm -rf") {
+//	    return &types.PermissionResultDeny{Behavior: types.PermissionBehaviorDeny, Message: "not allowed"}, nil
+//	}
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// Built-in tool names
+const (
+	NameBash         = "Bash"
+	NameBashOutput   = "BashOutput"
+	NameKillShell    = "KillShell"
+	NameRead         = "Read"
+	NameWrite        = "Write"
+	NameEdit         = "Edit"
+	NameMultiEdit    = "MultiEdit"
+	NameGlob         = "Glob"
+	NameGrep         = "Grep"
+	NameNotebookEdit = "NotebookEdit"
+	NameWebFetch     = "WebFetch"
+	NameWebSearch    = "WebSearch"
+	NameTodoWrite    = "TodoWrite"
+	NameTask         = "Task"
+	NameExitPlanMode = "ExitPlanMode"
+)
+
+// BashInput is the input of the Bash tool
+type BashInput struct {
+	Command         string `json:"command"`
+	Description     string `json:"description,omitempty"`
+	Timeout         *int   `json:"timeout,omitempty"` // Milliseconds
+	RunInBackground bool   `json:"run_in_background,omitempty"`
+}
+
+// BashOutputInput is the input of the BashOutput tool
+type BashOutputInput struct {
+	BashID string `json:"bash_id"`
+	Filter string `json:"filter,omitempty"`
+}
+
+// KillShellInput is the input of the KillShell tool
+type KillShellInput struct {
+	ShellID string `json:"shell_id"`
+}
+
+// ReadInput is the input of the Read tool
+type ReadInput struct {
+	FilePath string `json:"file_path"`
+	Offset   *int   `json:"offset,omitempty"`
+	Limit    *int   `json:"limit,omitempty"`
+}
+
+// WriteInput is the input of the Write tool
+type WriteInput struct {
+	FilePath string `json:"file_path"`
+	Content  string `json:"content"`
+}
+
+// EditInput is the input of the Edit tool
+type EditInput struct {
+	FilePath   string `json:"file_path"`
+	OldString  string `json:"old_string"`
+	NewString  string `json:"new_string"`
+	ReplaceAll bool   `json:"replace_all,omitempty"`
+}
+
+// EditOperation is a single edit of a MultiEdit call
+type EditOperation struct {
+	OldString  string `json:"old_string"`
+	NewString  string `json:"new_string"`
+	ReplaceAll bool   `json:"replace_all,omitempty"`
+}
+
+// MultiEditInput is the input of the MultiEdit tool
+type MultiEditInput struct {
+	FilePath string          `json:"file_path"`
+	Edits    []EditOperation `json:"edits"`
+}
+
+// GlobInput is the input of the Glob tool
+type GlobInput struct {
+	Pattern string `json:"pattern"`
+	Path    string `json:"path,omitempty"`
+}
+
+// GrepInput is the input of the Grep tool
+type GrepInput struct {
+	Pattern         string `json:"pattern"`
+	Path            string `json:"path,omitempty"`
+	Glob            string `json:"glob,omitempty"`
+	Type            string `json:"type,omitempty"`
+	OutputMode      string `json:"output_mode,omitempty"` // "content", "files_with_matches" or "count"
+	CaseInsensitive bool   `json:"-i,omitempty"`
+	LineNumbers     bool   `json:"-n,omitempty"`
+	After           *int   `json:"-A,omitempty"`
+	Before          *int   `json:"-B,omitempty"`
+	Context         *int   `json:"-C,omitempty"`
+	HeadLimit       *int   `json:"head_limit,omitempty"`
+	Multiline       bool   `json:"multiline,omitempty"`
+}
+
+// NotebookEditInput is the input of the NotebookEdit tool
+type NotebookEditInput struct {
+	NotebookPath string `json:"notebook_path"`
+	NewSource    string `json:"new_source"`
+	CellID       string `json:"cell_id,omitempty"`
+	CellType     string `json:"cell_type,omitempty"` // "code" or "markdown"
+	EditMode     string `json:"edit_mode,omitempty"` // "replace", "insert" or "delete"
+}
+
+// WebFetchInput is the input of the WebFetch tool
+type WebFetchInput struct {
+	URL    string `json:"url"`
+	Prompt string `json:"prompt"`
+}
+
+// WebSearchInput is the input of the WebSearch tool
+type WebSearchInput struct {
+	Query          string   `json:"query"`
+	AllowedDomains []string `json:"allowed_domains,omitempty"`
+	BlockedDomains []string `json:"blocked_domains,omitempty"`
+}
+
+// TodoWriteInput is the input of the TodoWrite tool
+type TodoWriteInput struct {
+	Todos []types.TodoItem `json:"todos"`
+}
+
+// TaskInput is the input of the Task tool used to launch subagents
+type TaskInput struct {
+	Description  string `json:"description"`
+	Prompt       string `json:"prompt"`
+	SubagentType string `json:"subagent_type"`
+}
+
+// ExitPlanModeInput is the input of the ExitPlanMode tool
+type ExitPlanModeInput struct {
+	Plan string `json:"plan"`
+}
+
+// newInput returns a pointer to an empty input struct for a built-in tool
+func newInput(name string) interface{} {
+	switch name {
+	case NameBash:
+		return &BashInput{}
+	case NameBashOutput:
+		return &BashOutputInput{}
+	case NameKillShell:
+		return &KillShellInput{}
+	case NameRead:
+		return &ReadInput{}
+	case NameWrite:
+		return &WriteInput{}
+	case NameEdit:
+		return &EditInput{}
+	case NameMultiEdit:
+		return &MultiEditInput{}
+	case NameGlob:
+		return &GlobInput{}
+	case NameGrep:
+		return &GrepInput{}
+	case NameNotebookEdit:
+		return &NotebookEditInput{}
+	case NameWebFetch:
+		return &WebFetchInput{}
+	case NameWebSearch:
+		return &WebSearchInput{}
+	case NameTodoWrite:
+		return &TodoWriteInput{}
+	case NameTask:
+		return &TaskInput{}
+	case NameExitPlanMode:
+		return &ExitPlanModeInput{}
+	default:
+		return nil
+	}
+}
+
+// Decode converts the raw input of a built-in tool into its typed struct,
+// e.g. *BashInput for "Bash". Unknown tools, including MCP tools, return
+// the input map unchanged.
+func Decode(name string, input map[string]interface{}) (interface{}, error) {
+	target := newInput(name)
+	if target == nil {
+		return input, nil
+	}
+
+	if err := decodeInto(input, target); err != nil {
+		return nil, fmt.Errorf("failed to decode %s input: %w", name, err)
+	}
+	return target, nil
+}
+
+// DecodeBlock converts the input of a ToolUseBlock into its typed struct
+func DecodeBlock(block *types.ToolUseBlock) (interface{}, error) {
+	return Decode(block.Name, block.Input)
+}
+
+// DecodeAs converts a raw tool input into T
+//
+//	edit, err := tools.DecodeAs[tools.EditInput](input)
+func DecodeAs[T any](input map[string]interface{}) (*T, error) {
+	var target T
+	if err := decodeInto(input, &target); err != nil {
+		return nil, err
+	}
+	return &target, nil
+}
+
+// decodeInto round-trips the input through JSON into target
+func decodeInto(input map[string]interface{}, target interface{}) error {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target)
+}
@@ -0,0 +1,121 @@
+package tools_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/tools"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+func intPtr(n int) *int { return &n }
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name  string
+		input map[string]interface{}
+		want  interface{}
+	}{
+		{tools.NameBash, map[string]interface{}{"command": "go test ./...", "timeout": float64(60000), "run_in_background": true},
+			&tools.BashInput{Command: "go test ./...", Timeout: intPtr(60000), RunInBackground: true}},
+		{tools.NameBashOutput, map[string]interface{}{"bash_id": "b1", "filter": "FAIL"},
+			&tools.BashOutputInput{BashID: "b1", Filter: "FAIL"}},
+		{tools.NameKillShell, map[string]interface{}{"shell_id": "b1"},
+			&tools.KillShellInput{ShellID: "b1"}},
+		{tools.NameRead, map[string]interface{}{"file_path": "/src/main.go", "offset": float64(10), "limit": float64(20)},
+			&tools.ReadInput{FilePath: "/src/main.go", Offset: intPtr(10), Limit: intPtr(20)}},
+		{tools.NameWrite, map[string]interface{}{"file_path": "/src/a.go", "content": "package a\n"},
+			&tools.WriteInput{FilePath: "/src/a.go", Content: "package a\n"}},
+		{tools.NameEdit, map[string]interface{}{"file_path": "/src/a.go", "old_string": "a", "new_string": "b", "replace_all": true},
+			&tools.EditInput{FilePath: "/src/a.go", OldString: "a", NewString: "b", ReplaceAll: true}},
+		{tools.NameMultiEdit, map[string]interface{}{"file_path": "/src/a.go", "edits": []interface{}{
+			map[string]interface{}{"old_string": "a", "new_string": "b"},
+			map[string]interface{}{"old_string": "c", "new_string": "d", "replace_all": true},
+		}}, &tools.MultiEditInput{FilePath: "/src/a.go", Edits: []tools.EditOperation{
+			{OldString: "a", NewString: "b"},
+			{OldString: "c", NewString: "d", ReplaceAll: true},
+		}}},
+		{tools.NameGlob, map[string]interface{}{"pattern": "**/*.go", "path": "/src"},
+			&tools.GlobInput{Pattern: "**/*.go", Path: "/src"}},
+		{tools.NameGrep, map[string]interface{}{"pattern": "TODO", "output_mode": "content", "-i": true, "-n": true, "-C": float64(2), "head_limit": float64(5)},
+			&tools.GrepInput{Pattern: "TODO", OutputMode: "content", CaseInsensitive: true, LineNumbers: true, Context: intPtr(2), HeadLimit: intPtr(5)}},
+		{tools.NameNotebookEdit, map[string]interface{}{"notebook_path": "/nb.ipynb", "new_source": "print(1)", "cell_id": "c1", "cell_type": "code", "edit_mode": "insert"},
+			&tools.NotebookEditInput{NotebookPath: "/nb.ipynb", NewSource: "print(1)", CellID: "c1", CellType: "code", EditMode: "insert"}},
+		{tools.NameWebFetch, map[string]interface{}{"url": "https://go.dev", "prompt": "Summarize"},
+			&tools.WebFetchInput{URL: "https://go.dev", Prompt: "Summarize"}},
+		{tools.NameWebSearch, map[string]interface{}{"query": "go generics", "allowed_domains": []interface{}{"go.dev"}},
+			&tools.WebSearchInput{Query: "go generics", AllowedDomains: []string{"go.dev"}}},
+		{tools.NameTodoWrite, map[string]interface{}{"todos": []interface{}{
+			map[string]interface{}{"content": "Write tests", "status": "in_progress", "activeForm": "Writing tests"},
+		}}, &tools.TodoWriteInput{Todos: []types.TodoItem{{Content: "Write tests", Status: "in_progress", ActiveForm: "Writing tests"}}}},
+		{tools.NameTask, map[string]interface{}{"description": "Review", "prompt": "Review the diff", "subagent_type": "reviewer"},
+			&tools.TaskInput{Description: "Review", Prompt: "Review the diff", SubagentType: "reviewer"}},
+		{tools.NameExitPlanMode, map[string]interface{}{"plan": "1. Fix the bug"},
+			&tools.ExitPlanModeInput{Plan: "1. Fix the bug"}},
+	}
+
+	for _, tt := range tests {
+		got, err := tools.Decode(tt.name, tt.input)
+		if err != nil {
+			t.Errorf("Decode(%s): %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Decode(%s) = %#v, want %#v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeUnknownTool(t *testing.T) {
+	input := map[string]interface{}{"city": "Oslo"}
+	for _, name := range []string{"mcp__weather__forecast", "FutureTool"} {
+		got, err := tools.Decode(name, input)
+		if err != nil {
+			t.Fatalf("Decode(%s): %v", name, err)
+		}
+		if m, ok := got.(map[string]interface{}); !ok || m["city"] != "Oslo" {
+			t.Errorf("Decode(%s) = %#v, want the input map", name, got)
+		}
+	}
+}
+
+func TestDecodeMalformed(t *testing.T) {
+	tests := []struct {
+		name  string
+		input map[string]interface{}
+	}{
+		{tools.NameBash, map[string]interface{}{"command": []interface{}{"ls"}}},
+		{tools.NameRead, map[string]interface{}{"file_path": "/a", "offset": "ten"}},
+		{tools.NameMultiEdit, map[string]interface{}{"edits": "replace everything"}},
+		{tools.NameTodoWrite, map[string]interface{}{"todos": map[string]interface{}{}}},
+		{tools.NameWrite, map[string]interface{}{"content": make(chan int)}},
+	}
+
+	for _, tt := range tests {
+		if got, err := tools.Decode(tt.name, tt.input); err == nil {
+			t.Errorf("Decode(%s, %v) = %#v, want an error", tt.name, tt.input, got)
+		}
+	}
+
+	// Missing fields are left empty rather than rejected
+	got, err := tools.Decode(tools.NameBash, nil)
+	if bash, ok := got.(*tools.BashInput); err != nil || !ok || bash.Command != "" {
+		t.Errorf("Decode(Bash, nil) = %#v, %v", got, err)
+	}
+}
+
+func TestDecodeBlock(t *testing.T) {
+	block := &types.ToolUseBlock{ID: "toolu_1", Name: tools.NameGlob, Input: map[string]interface{}{"pattern": "*.md"}}
+	got, err := tools.DecodeBlock(block)
+	if glob, ok := got.(*tools.GlobInput); err != nil || !ok || glob.Pattern != "*.md" {
+		t.Errorf("DecodeBlock = %#v, %v", got, err)
+	}
+
+	edit, err := tools.DecodeAs[tools.EditInput](map[string]interface{}{"file_path": "/a", "old_string": "x", "new_string": "y"})
+	if err != nil || edit.FilePath != "/a" || edit.NewString != "y" {
+		t.Errorf("DecodeAs = %#v, %v", edit, err)
+	}
+	if _, err := tools.DecodeAs[tools.EditInput](map[string]interface{}{"replace_all": "yes"}); err == nil {
+		t.Error("DecodeAs should fail on malformed input")
+	}
+}
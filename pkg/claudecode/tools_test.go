@@ -0,0 +1,113 @@
+package claudecode
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/transporttest"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+type weatherArgs struct {
+	City string `json:"city"`
+	Days int    `json:"days"`
+}
+
+func TestTypedTool(t *testing.T) {
+	handler := TypedTool(func(ctx context.Context, args weatherArgs) (string, error) {
+		return fmt.Sprintf("%s for %d days", args.City, args.Days), nil
+	})
+
+	text, err := handler(context.Background(), map[string]interface{}{"city": "Oslo", "days": float64(3)})
+	if err != nil || text != "Oslo for 3 days" {
+		t.Errorf("handler = %q, %v", text, err)
+	}
+
+	if _, err := handler(context.Background(), map[string]interface{}{"days": "three"}); err == nil {
+		t.Error("Expected an error for input that does not decode")
+	}
+}
+
+func TestRegisterTool(t *testing.T) {
+	options := types.NewOptions().WithAllowedTools("Read")
+	mock := transporttest.NewMockTransport()
+	client := NewClaudeSDKClientWithTransport(options, mock)
+
+	handler := func(ctx context.Context, input map[string]interface{}) (string, error) {
+		return fmt.Sprintf("sunny in %v", input["city"]), nil
+	}
+	if err := client.RegisterTool("", nil, handler); err == nil {
+		t.Error("Expected an error without a name")
+	}
+	if err := client.RegisterTool("get_weather", map[string]interface{}{"description": "Weather by city"}, handler); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.RegisterTool("late", nil, handler); err == nil {
+		t.Error("Expected an error registering after Connect")
+	}
+
+	// The caller's options are left alone; the client's allow the tool once,
+	// even when registered again as on a reconnect
+	name := MCPToolName(localToolsServerName, "get_weather")
+	if len(options.AllowedTools) != 1 || options.MCPServers != nil {
+		t.Errorf("caller's options changed: %v, %v", options.AllowedTools, options.MCPServers)
+	}
+	client.mu.Lock()
+	client.registerLocalTools()
+	client.mu.Unlock()
+	if want := []string{"Read", name}; !slices.Equal(client.options.AllowedTools, want) {
+		t.Errorf("AllowedTools = %v, want %v", client.options.AllowedTools, want)
+	}
+
+	// Calls are routed to the handler
+	mock.Emit(map[string]interface{}{
+		"type":       "control_request",
+		"request_id": "cli_1",
+		"request": map[string]interface{}{
+			"subtype":     "mcp_message",
+			"server_name": localToolsServerName,
+			"message": map[string]interface{}{
+				"jsonrpc": "2.0",
+				"id":      float64(1),
+				"method":  "tools/call",
+				"params": map[string]interface{}{
+					"name":      "get_weather",
+					"arguments": map[string]interface{}{"city": "Oslo"},
+				},
+			},
+		},
+	})
+
+	var text interface{}
+	for text == nil && ctx.Err() == nil {
+		for _, msg := range mock.WrittenMessages() {
+			body, _ := msg["response"].(map[string]interface{})
+			if body["request_id"] != "cli_1" {
+				continue
+			}
+			response, _ := body["response"].(map[string]interface{})
+			reply, _ := response["mcp_response"].(map[string]interface{})
+			result, _ := reply["result"].(map[string]interface{})
+			content, _ := result["content"].([]interface{})
+			if len(content) == 1 {
+				text = content[0].(map[string]interface{})["text"]
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if text != "sunny in Oslo" {
+		t.Errorf("tool result = %v", text)
+	}
+}
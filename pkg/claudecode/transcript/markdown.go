@@ -0,0 +1,102 @@
+package transcript
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// WriteMarkdown renders the transcript as human-readable Markdown, one
+// section per session. Stream events and unknown message types are omitted.
+func (t *Transcript) WriteMarkdown(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	for i, sessionID := range t.Sessions() {
+		if i > 0 {
+			bw.WriteString("\n")
+		}
+		if sessionID == "" {
+			bw.WriteString("# Session\n")
+		} else {
+			fmt.Fprintf(bw, "# Session %s\n", sessionID)
+		}
+
+		for _, msg := range t.Messages(sessionID) {
+			writeMarkdownMessage(bw, msg)
+		}
+	}
+	return bw.Flush()
+}
+
+func writeMarkdownMessage(w *bufio.Writer, msg types.Message) {
+	switch m := msg.(type) {
+	case *types.InitMessage:
+		fmt.Fprintf(w, "\n_Session started with model %s_\n", m.Model)
+	case *types.UserMessage:
+		w.WriteString("\n## User\n")
+		switch content := m.Content.(type) {
+		case string:
+			fmt.Fprintf(w, "\n%s\n", content)
+		case []types.ContentBlock:
+			writeMarkdownBlocks(w, content)
+		}
+	case *types.AssistantMessage:
+		w.WriteString("\n## Assistant\n")
+		writeMarkdownBlocks(w, m.Content)
+	case *types.ResultMessage:
+		fmt.Fprintf(w, "\n---\n\n_Result: %s after %d turns", m.Subtype, m.NumTurns)
+		if m.TotalCostUSD != nil {
+			fmt.Fprintf(w, ", $%.4f", *m.TotalCostUSD)
+		}
+		w.WriteString("_\n")
+	}
+}
+
+func writeMarkdownBlocks(w *bufio.Writer, blocks []types.ContentBlock) {
+	for _, block := range blocks {
+		switch b := block.(type) {
+		case *types.TextBlock:
+			fmt.Fprintf(w, "\n%s\n", b.Text)
+		case *types.ThinkingBlock:
+			fmt.Fprintf(w, "\n> _Thinking:_ %s\n", strings.ReplaceAll(b.Thinking, "\n", "\n> "))
+		case *types.ToolUseBlock:
+			input, _ := json.MarshalIndent(b.Input, "", "  ")
+			fmt.Fprintf(w, "\n**Tool use: %s**\n\n```json\n%s\n```\n", b.Name, input)
+		case *types.ImageBlock:
+			fmt.Fprintf(w, "\n_[image: %s]_\n", b.Source.MediaType)
+		case *types.ToolResultBlock:
+			label := "Tool result"
+			if b.IsError != nil && *b.IsError {
+				label = "Tool error"
+			}
+			fmt.Fprintf(w, "\n**%s**\n\n```\n%s\n```\n", label, toolResultText(b.Content))
+		}
+	}
+}
+
+// toolResultText flattens tool result content to plain text
+func toolResultText(content interface{}) string {
+	switch c := content.(type) {
+	case nil:
+		return ""
+	case string:
+		return c
+	case []interface{}:
+		var parts []string
+		for _, item := range c {
+			if m, ok := item.(map[string]interface{}); ok {
+				if text, ok := m["text"].(string); ok {
+					parts = append(parts, text)
+				}
+			}
+		}
+		return strings.Join(parts, "\n")
+	default:
+		data, _ := json.Marshal(c)
+		return string(data)
+	}
+}
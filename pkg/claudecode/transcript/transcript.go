@@ -0,0 +1,272 @@
+// Package transcript records conversations for later export and replay.
+//
+// A Transcript stores every inbound message frame, grouped by session, and
+// can write them out as JSON, JSONL or Markdown. JSON and JSONL exports are
+// lossless and can be read back with ReadJSON and ReadJSONL:
+//
+//	t := transcript.New(transcript.RedactToolInput("Bash", "env"))
+//	options := claudecode.NewOptions().WithTranscript(t)
+//	// ... run the conversation ...
+//	f, _ := os.Create("session.jsonl")
+//	defer f.Close()
+//	t.WriteJSONL(f)
+package transcript
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/internal"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// Redacted replaces tool input values removed by RedactToolInput
+const Redacted = "[REDACTED]"
+
+// Redactor rewrites the input of a tool use before it is stored. It receives
+// a copy of the input and returns the value to keep.
+type Redactor func(toolName string, input map[string]interface{}) map[string]interface{}
+
+// RedactToolInput returns a Redactor that replaces the given input keys of
+// toolName with Redacted. An empty toolName matches every tool.
+func RedactToolInput(toolName string, keys ...string) Redactor {
+	return func(name string, input map[string]interface{}) map[string]interface{} {
+		if toolName != "" && name != toolName {
+			return input
+		}
+		for _, key := range keys {
+			if _, ok := input[key]; ok {
+				input[key] = Redacted
+			}
+		}
+		return input
+	}
+}
+
+// Entry is one recorded message frame
+type Entry struct {
+	SessionID string                 `json:"session_id"`
+	Time      time.Time              `json:"time"`
+	Frame     map[string]interface{} `json:"message"`
+}
+
+// Message parses the entry's frame into a typed message
+func (e Entry) Message() (types.Message, error) {
+	return internal.ParseMessage(e.Frame)
+}
+
+// Transcript is a concurrency-safe record of conversation messages. It
+// implements types.TranscriptRecorder.
+type Transcript struct {
+	mu          sync.RWMutex
+	entries     []Entry
+	redactors   []Redactor
+	lastSession string
+}
+
+// New creates an empty transcript. Redactors run in order on every tool use
+// input before it is stored.
+func New(redactors ...Redactor) *Transcript {
+	return &Transcript{redactors: redactors}
+}
+
+// Record stores a copy of frame, applying the transcript's redactors
+func (t *Transcript) Record(frame map[string]interface{}) {
+	t.add(Entry{Time: time.Now(), Frame: frame})
+}
+
+func (t *Transcript) add(e Entry) {
+	e.Frame = t.redact(copyFrame(e.Frame))
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if e.SessionID == "" {
+		e.SessionID, _ = e.Frame["session_id"].(string)
+	}
+	// Frames without a session belong to the conversation in progress
+	if e.SessionID == "" {
+		e.SessionID = t.lastSession
+	}
+	t.lastSession = e.SessionID
+	t.entries = append(t.entries, e)
+}
+
+// Entries returns every recorded entry in arrival order
+func (t *Transcript) Entries() []Entry {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return append([]Entry(nil), t.entries...)
+}
+
+// Sessions returns the recorded session IDs in the order first seen
+func (t *Transcript) Sessions() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var ids []string
+	for _, e := range t.entries {
+		if !seen[e.SessionID] {
+			seen[e.SessionID] = true
+			ids = append(ids, e.SessionID)
+		}
+	}
+	return ids
+}
+
+// Session returns the entries recorded for one session
+func (t *Transcript) Session(sessionID string) []Entry {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var entries []Entry
+	for _, e := range t.entries {
+		if e.SessionID == sessionID {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// Messages parses the entries of one session, skipping frames that no
+// longer parse (for example after a custom parser was unregistered)
+func (t *Transcript) Messages(sessionID string) []types.Message {
+	var messages []types.Message
+	for _, e := range t.Session(sessionID) {
+		if msg, err := e.Message(); err == nil {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+// WriteJSON writes all entries as one indented JSON array
+func (t *Transcript) WriteJSON(w io.Writer) error {
+	entries := t.Entries()
+	if entries == nil {
+		entries = []Entry{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
+
+// WriteJSONL writes one entry per line
+func (t *Transcript) WriteJSONL(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	for _, e := range t.Entries() {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadJSON reads a transcript written by WriteJSON
+func ReadJSON(r io.Reader, redactors ...Redactor) (*Transcript, error) {
+	var entries []Entry
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("transcript: %w", err)
+	}
+
+	t := New(redactors...)
+	for _, e := range entries {
+		t.add(e)
+	}
+	return t, nil
+}
+
+// ReadJSONL reads a transcript written by WriteJSONL
+func ReadJSONL(r io.Reader, redactors ...Redactor) (*Transcript, error) {
+	t := New(redactors...)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("transcript: line %d: %w", line, err)
+		}
+		t.add(e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("transcript: %w", err)
+	}
+	return t, nil
+}
+
+// redact runs the redactors over every tool_use block in frame
+func (t *Transcript) redact(frame map[string]interface{}) map[string]interface{} {
+	if len(t.redactors) == 0 {
+		return frame
+	}
+	walkToolUses(frame, func(block map[string]interface{}) {
+		name, _ := block["name"].(string)
+		input, _ := block["input"].(map[string]interface{})
+		for _, redactor := range t.redactors {
+			input = redactor(name, input)
+		}
+		block["input"] = input
+	})
+	return frame
+}
+
+// walkToolUses calls fn for each tool_use object nested anywhere in v, so
+// redaction does not depend on where the CLI places message content
+func walkToolUses(v interface{}, fn func(map[string]interface{})) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if v["type"] == "tool_use" {
+			if _, ok := v["input"].(map[string]interface{}); ok {
+				fn(v)
+				return
+			}
+		}
+		for _, child := range v {
+			walkToolUses(child, fn)
+		}
+	case []interface{}:
+		for _, child := range v {
+			walkToolUses(child, fn)
+		}
+	}
+}
+
+// copyFrame deep-copies a decoded JSON value so stored entries are not
+// affected by later changes to the caller's map
+func copyFrame(frame map[string]interface{}) map[string]interface{} {
+	copied, _ := copyValue(frame).(map[string]interface{})
+	return copied
+}
+
+func copyValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, child := range v {
+			m[k] = copyValue(child)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, child := range v {
+			s[i] = copyValue(child)
+		}
+		return s
+	default:
+		return v
+	}
+}
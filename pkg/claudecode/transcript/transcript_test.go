@@ -0,0 +1,108 @@
+package transcript_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transcript"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/transporttest"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+func TestTranscriptRecordsClientMessages(t *testing.T) {
+	tr := transcript.New(transcript.RedactToolInput("Bash", "env"))
+	mock := transporttest.NewMockTransport()
+	client := claudecode.NewClaudeSDKClientWithTransport(types.NewOptions().WithTranscript(tr), mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	mock.Emit(map[string]interface{}{
+		"type": "assistant", "model": "sonnet", "session_id": "s1",
+		"content": []interface{}{
+			map[string]interface{}{"type": "text", "text": "Listing files"},
+			map[string]interface{}{
+				"type": "tool_use", "id": "t1", "name": "Bash",
+				"input": map[string]interface{}{"command": "ls", "env": "TOKEN=secret"},
+			},
+		},
+	})
+	mock.Emit(map[string]interface{}{
+		"type": "result", "subtype": "success", "session_id": "s1", "num_turns": 1,
+	})
+
+	for msg := range client.Messages() {
+		if _, ok := msg.(*types.ResultMessage); ok {
+			break
+		}
+	}
+
+	var jsonl bytes.Buffer
+	if err := tr.WriteJSONL(&jsonl); err != nil {
+		t.Fatalf("WriteJSONL: %v", err)
+	}
+	if strings.Contains(jsonl.String(), "secret") {
+		t.Fatalf("tool input not redacted: %s", jsonl.String())
+	}
+
+	restored, err := transcript.ReadJSONL(&jsonl)
+	if err != nil {
+		t.Fatalf("ReadJSONL: %v", err)
+	}
+	if got := restored.Sessions(); len(got) != 1 || got[0] != "s1" {
+		t.Fatalf("sessions = %v", got)
+	}
+
+	messages := restored.Messages("s1")
+	if len(messages) != 2 {
+		t.Fatalf("got %d messages, want 2", len(messages))
+	}
+	assistant, ok := messages[0].(*types.AssistantMessage)
+	if !ok {
+		t.Fatalf("first message is %T", messages[0])
+	}
+	tool := assistant.Content[1].(*types.ToolUseBlock)
+	if tool.Input["command"] != "ls" || tool.Input["env"] != transcript.Redacted {
+		t.Fatalf("unexpected tool input after round trip: %v", tool.Input)
+	}
+
+	var md bytes.Buffer
+	if err := restored.WriteMarkdown(&md); err != nil {
+		t.Fatalf("WriteMarkdown: %v", err)
+	}
+	for _, want := range []string{"# Session s1", "## Assistant", "Listing files", "**Tool use: Bash**", "_Result: success after 1 turns_"} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("markdown missing %q:\n%s", want, md.String())
+		}
+	}
+}
+
+func TestReadJSON(t *testing.T) {
+	tr := transcript.New()
+	tr.Record(map[string]interface{}{"type": "user", "content": "hello", "session_id": "s1"})
+	tr.Record(map[string]interface{}{"type": "user", "content": "again"})
+
+	var buf bytes.Buffer
+	if err := tr.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON: %v", err)
+	}
+	restored, err := transcript.ReadJSON(&buf)
+	if err != nil {
+		t.Fatalf("ReadJSON: %v", err)
+	}
+
+	// Frames without a session ID join the current session
+	messages := restored.Messages("s1")
+	if len(messages) != 2 || messages[1].(*types.UserMessage).Content != "again" {
+		t.Fatalf("unexpected messages: %#v", messages)
+	}
+}
@@ -0,0 +1,220 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// CLIFeature names a CLI capability that depends on the installed version
+type CLIFeature string
+
+const (
+	FeatureIncludePartialMessages CLIFeature = "include-partial-messages"
+	FeatureForkSession            CLIFeature = "fork-session"
+	FeatureSDKMCP                 CLIFeature = "sdk-mcp"
+	FeatureDebugToStderr          CLIFeature = "debug-to-stderr"
+)
+
+// featureMinVersions is the capability matrix: the first CLI release
+// supporting each feature
+var featureMinVersions = map[CLIFeature]CLIVersion{
+	FeatureDebugToStderr:          {Major: 1, Minor: 0, Patch: 60},
+	FeatureIncludePartialMessages: {Major: 1, Minor: 0, Patch: 86},
+	FeatureForkSession:            {Major: 1, Minor: 0, Patch: 94},
+	FeatureSDKMCP:                 {Major: 1, Minor: 0, Patch: 98},
+}
+
+// versionTimeout bounds how long `claude --version` may take
+const versionTimeout = 10 * time.Second
+
+var versionPattern = regexp.MustCompile(`(\d+)\.(\d+)\.(\d+)`)
+
+// CLIVersion is a semantic version of the Claude CLI
+type CLIVersion struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+// ParseCLIVersion extracts the version from `claude --version` output,
+// e.g. "1.0.108 (Claude Code)"
+func ParseCLIVersion(output string) (CLIVersion, error) {
+	m := versionPattern.FindStringSubmatch(output)
+	if m == nil {
+		return CLIVersion{}, fmt.Errorf("no version found in %q", strings.TrimSpace(output))
+	}
+
+	major, _ := strconv.Atoi(m[1])
+	minor, _ := strconv.Atoi(m[2])
+	patch, _ := strconv.Atoi(m[3])
+	return CLIVersion{Major: major, Minor: minor, Patch: patch}, nil
+}
+
+// String returns the version as "major.minor.patch"
+func (v CLIVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Less reports whether v is older than other
+func (v CLIVersion) Less(other CLIVersion) bool {
+	if v.Major != other.Major {
+		return v.Major < other.Major
+	}
+	if v.Minor != other.Minor {
+		return v.Minor < other.Minor
+	}
+	return v.Patch < other.Patch
+}
+
+// CLIInfo describes an installed Claude CLI
+type CLIInfo struct {
+	Path    string
+	Version CLIVersion
+	// Capabilities reports for every known feature whether this CLI supports it
+	Capabilities map[CLIFeature]bool
+}
+
+// Supports reports whether the CLI supports a feature. Features missing
+// from the capability matrix are assumed to be supported.
+func (i *CLIInfo) Supports(feature CLIFeature) bool {
+	if i == nil {
+		return true
+	}
+	supported, known := i.Capabilities[feature]
+	return supported || !known
+}
+
+// Require returns an UnsupportedFeatureError if the CLI lacks a feature
+func (i *CLIInfo) Require(feature CLIFeature) error {
+	if i.Supports(feature) {
+		return nil
+	}
+	return errors.NewUnsupportedFeatureError(string(feature), featureMinVersions[feature].String(), i.Version.String())
+}
+
+// DetectCLI locates the Claude CLI (or uses cliPath if set), runs
+// `claude --version` and builds its capability matrix
+func DetectCLI(ctx context.Context, cliPath string) (*CLIInfo, error) {
+	if cliPath == "" {
+		cliPath = findCLI()
+	}
+	if cliPath == "" {
+		return nil, errors.NewCLINotFoundError(getCLINotFoundMessage())
+	}
+
+	return detectCLI(ctx, cliPath, func(ctx context.Context) *exec.Cmd {
+		return exec.CommandContext(ctx, cliPath, "--version")
+	})
+}
+
+// detectCLI runs the `claude --version` command built by command and
+// builds the capability matrix from its output
+func detectCLI(ctx context.Context, cliPath string, command func(ctx context.Context) *exec.Cmd) (*CLIInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, versionTimeout)
+	defer cancel()
+
+	cmd := command(ctx)
+	if cmd == nil {
+		return nil, errors.NewCLIConnectionError("command factory returned no command", nil)
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, errors.NewCLIConnectionError("failed to run claude --version", err)
+	}
+
+	version, err := ParseCLIVersion(string(output))
+	if err != nil {
+		return nil, errors.NewCLIConnectionError("failed to parse CLI version", err)
+	}
+
+	return newCLIInfo(cliPath, version), nil
+}
+
+// newCLIInfo evaluates the capability matrix for a version
+func newCLIInfo(path string, version CLIVersion) *CLIInfo {
+	capabilities := make(map[CLIFeature]bool, len(featureMinVersions))
+	for feature, minVersion := range featureMinVersions {
+		capabilities[feature] = !version.Less(minVersion)
+	}
+	return &CLIInfo{Path: path, Version: version, Capabilities: capabilities}
+}
+
+// detected caches DetectCLI results per path so that each Connect does not
+// spawn an extra process. Failed detections are cached as nil. CLIs run
+// through a CommandFactory or an ExecutionSandbox are probed once per
+// transport instead, as they may run a different binary for the same path.
+var detected sync.Map
+
+// cachedCLIInfo returns the detected CLI info for a path, or nil when the
+// version could not be determined
+func cachedCLIInfo(ctx context.Context, cliPath string) *CLIInfo {
+	if info, ok := detected.Load(cliPath); ok {
+		return info.(*CLIInfo)
+	}
+
+	info, err := DetectCLI(ctx, cliPath)
+	if err != nil {
+		// A cancelled Connect says nothing about the CLI; try again next time
+		if ctx.Err() != nil {
+			return nil
+		}
+		info = nil
+	}
+	detected.Store(cliPath, info)
+	return info
+}
+
+// omittableFeatures can be left out of a command line without changing
+// what the CLI does, e.g. where debug output goes
+var omittableFeatures = map[CLIFeature]bool{
+	FeatureDebugToStderr: true,
+}
+
+// unsupportedFeatures checks the options against the detected CLI. Options
+// requiring a newer CLI whose flags can be omitted are returned as omitted,
+// and their flags left out of the command line. Any other one is returned
+// as err: without --fork-session --resume continues the parent session, and
+// without SDK MCP support the caller's tools are gone.
+func unsupportedFeatures(options *types.ClaudeCodeOptions, info *CLIInfo) (omitted []error, err error) {
+	if options == nil || info == nil {
+		return nil, nil
+	}
+
+	var features []CLIFeature
+	if options.IncludePartialMessages {
+		features = append(features, FeatureIncludePartialMessages)
+	}
+	if options.ForkSession && options.Resume != nil {
+		features = append(features, FeatureForkSession)
+	}
+	for _, server := range options.MCPServers {
+		if isSDKServer(server) {
+			features = append(features, FeatureSDKMCP)
+			break
+		}
+	}
+	if options.DebugStderr != nil {
+		features = append(features, FeatureDebugToStderr)
+	}
+
+	for _, feature := range features {
+		unsupported := info.Require(feature)
+		switch {
+		case unsupported == nil:
+		case omittableFeatures[feature]:
+			omitted = append(omitted, unsupported)
+		default:
+			return nil, unsupported
+		}
+	}
+	return omitted, nil
+}
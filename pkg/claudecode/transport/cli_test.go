@@ -0,0 +1,222 @@
+package transport
+
+import (
+	"context"
+	stderrors "errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+func TestParseCLIVersion(t *testing.T) {
+	tests := []struct {
+		output  string
+		want    CLIVersion
+		wantErr bool
+	}{
+		{output: "1.0.108 (Claude Code)\n", want: CLIVersion{1, 0, 108}},
+		{output: "claude 2.1.3", want: CLIVersion{2, 1, 3}},
+		{output: "unknown", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseCLIVersion(tt.output)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseCLIVersion(%q) error = %v, wantErr %v", tt.output, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseCLIVersion(%q) = %v, want %v", tt.output, got, tt.want)
+		}
+	}
+}
+
+func TestCLIInfoCapabilities(t *testing.T) {
+	old := newCLIInfo("claude", CLIVersion{1, 0, 80})
+	if old.Supports(FeatureIncludePartialMessages) {
+		t.Error("1.0.80 should not support include-partial-messages")
+	}
+	if !old.Supports(FeatureDebugToStderr) {
+		t.Error("1.0.80 should support debug-to-stderr")
+	}
+	if !old.Supports(CLIFeature("not-in-matrix")) {
+		t.Error("unknown features should be assumed supported")
+	}
+
+	current := newCLIInfo("claude", CLIVersion{2, 0, 0})
+	for feature := range featureMinVersions {
+		if !current.Supports(feature) {
+			t.Errorf("2.0.0 should support %s", feature)
+		}
+	}
+
+	var unknown *CLIInfo
+	if !unknown.Supports(FeatureSDKMCP) {
+		t.Error("nil CLIInfo should assume support")
+	}
+}
+
+func TestUnsupportedFeatures(t *testing.T) {
+	old := newCLIInfo("claude", CLIVersion{1, 0, 50})
+
+	omitted, err := unsupportedFeatures(&types.ClaudeCodeOptions{DebugStderr: io.Discard}, old)
+	if err != nil || len(omitted) != 1 || !stderrors.Is(omitted[0], errors.ErrUnsupportedFeature) {
+		t.Fatalf("got %v, %v; want debug-to-stderr omitted", omitted, err)
+	}
+
+	_, err = unsupportedFeatures(&types.ClaudeCodeOptions{IncludePartialMessages: true, DebugStderr: io.Discard}, old)
+	var feature *errors.UnsupportedFeatureError
+	if !stderrors.As(err, &feature) {
+		t.Fatalf("expected UnsupportedFeatureError, got %v", err)
+	}
+	if feature.Feature != string(FeatureIncludePartialMessages) || feature.InstalledVersion != "1.0.50" {
+		t.Errorf("unexpected error fields: %+v", feature)
+	}
+
+	options := &types.ClaudeCodeOptions{IncludePartialMessages: true}
+	if omitted, err := unsupportedFeatures(options, nil); omitted != nil || err != nil {
+		t.Errorf("unknown CLI version should support everything: %v, %v", omitted, err)
+	}
+	if omitted, err := unsupportedFeatures(&types.ClaudeCodeOptions{}, old); omitted != nil || err != nil {
+		t.Errorf("default options should need nothing: %v, %v", omitted, err)
+	}
+}
+
+func TestUnsupportedFeaturesFailConnect(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	// Without --fork-session the CLI would continue the parent session
+	started := false
+	options := types.NewOptions().
+		WithCLIPath("/nonexistent/claude").
+		WithForkSession("session-1").
+		WithCommandFactory(func(ctx context.Context, path string, args []string) *exec.Cmd {
+			if slices.Contains(args, "--version") {
+				return exec.CommandContext(ctx, "echo", "1.0.90 (Claude Code)")
+			}
+			started = true
+			return exec.CommandContext(ctx, "sh", "-c", "cat >/dev/null")
+		})
+
+	tr := NewSubprocessTransport(nil, options, "")
+	err := tr.Connect(context.Background())
+	if err == nil {
+		tr.Close()
+	}
+	var feature *errors.UnsupportedFeatureError
+	if !stderrors.As(err, &feature) || feature.Feature != string(FeatureForkSession) {
+		t.Errorf("Connect = %v, want an UnsupportedFeatureError for fork-session", err)
+	}
+	if started {
+		t.Error("CLI started without --fork-session")
+	}
+}
+
+func TestUnsupportedFlagsOmitted(t *testing.T) {
+	options := &types.ClaudeCodeOptions{DebugStderr: io.Discard}
+
+	tr := NewSubprocessTransport(nil, options, "claude")
+	tr.cli = newCLIInfo("claude", CLIVersion{1, 0, 50})
+	if args := tr.buildCommandArgs(); slices.Contains(args, "--debug-to-stderr") {
+		t.Errorf("args contain --debug-to-stderr for 1.0.50: %v", args)
+	}
+
+	tr.cli = newCLIInfo("claude", CLIVersion{2, 0, 0})
+	if args := tr.buildCommandArgs(); !slices.Contains(args, "--debug-to-stderr") {
+		t.Errorf("args lack --debug-to-stderr for 2.0.0: %v", args)
+	}
+}
+
+func TestDetectCLI(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	dir := t.TempDir()
+	script := func(name, body string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	ctx := context.Background()
+	path := script("claude", `echo "1.0.90 (Claude Code)"`)
+	info, err := DetectCLI(ctx, path)
+	if err != nil {
+		t.Fatalf("DetectCLI: %v", err)
+	}
+	if info.Path != path || info.Version != (CLIVersion{1, 0, 90}) {
+		t.Errorf("DetectCLI = %+v", info)
+	}
+	if !info.Supports(FeatureIncludePartialMessages) || info.Supports(FeatureForkSession) {
+		t.Errorf("unexpected capabilities for 1.0.90: %v", info.Capabilities)
+	}
+
+	if _, err := DetectCLI(ctx, script("garbled", `echo "Claude Code"`)); err == nil {
+		t.Error("expected an error for output without a version")
+	}
+	if _, err := DetectCLI(ctx, script("failing", `exit 1`)); err == nil {
+		t.Error("expected an error for a failing CLI")
+	}
+	if _, err := DetectCLI(ctx, filepath.Join(dir, "missing")); err == nil {
+		t.Error("expected an error for a missing CLI")
+	}
+}
+
+func TestProbeCLIThroughCommandFactory(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	// The factory runs a different CLI than the one at the path
+	probes := 0
+	var args []string
+	options := types.NewOptions().
+		WithCLIPath("/nonexistent/claude").
+		WithCommandFactory(func(ctx context.Context, path string, cliArgs []string) *exec.Cmd {
+			if slices.Contains(cliArgs, "--version") {
+				probes++
+				return exec.CommandContext(ctx, "echo", "1.0.50 (Claude Code)")
+			}
+			args = cliArgs
+			return exec.CommandContext(ctx, "sh", "-c", "cat >/dev/null")
+		})
+
+	options.DebugStderr = io.Discard
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tr := NewSubprocessTransport(nil, options, "")
+	if err := tr.Connect(ctx); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer tr.Close()
+
+	if probes != 1 || tr.cli == nil || tr.cli.Version != (CLIVersion{1, 0, 50}) {
+		t.Fatalf("probes = %d, info = %+v; want the factory's CLI", probes, tr.cli)
+	}
+	if slices.Contains(args, "--debug-to-stderr") {
+		t.Errorf("args contain --debug-to-stderr for 1.0.50: %v", args)
+	}
+
+	// The result is kept for the life of the transport
+	tr.mu.Lock()
+	tr.probeCLI(ctx)
+	tr.mu.Unlock()
+	if probes != 1 {
+		t.Errorf("probes = %d after a second probe, want 1", probes)
+	}
+}
@@ -0,0 +1,119 @@
+package grpctransport
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/grpctransport/protocolpb"
+)
+
+// lineHeader holds the fields of a stream-json line used to build a Frame
+type lineHeader struct {
+	Type      string          `json:"type"`
+	Subtype   string          `json:"subtype"`
+	SessionID string          `json:"session_id"`
+	RequestID string          `json:"request_id"`
+	Request   json.RawMessage `json:"request"`
+	Response  json.RawMessage `json:"response"`
+}
+
+// controlResponse is the "response" object of a control_response line
+type controlResponse struct {
+	Subtype   string          `json:"subtype"`
+	RequestID string          `json:"request_id"`
+	Response  json.RawMessage `json:"response,omitempty"`
+	Error     string          `json:"error,omitempty"`
+}
+
+// lineToFrame wraps one stream-json line in a Frame. Lines that cannot be
+// decoded are passed through as a Message so the receiving parser reports
+// them exactly as it would for a local CLI.
+func lineToFrame(line []byte) *protocolpb.Frame {
+	var head lineHeader
+	if err := json.Unmarshal(line, &head); err != nil {
+		return messageFrame(&head, line)
+	}
+
+	switch head.Type {
+	case "control_request":
+		var request struct {
+			Subtype string `json:"subtype"`
+		}
+		if err := json.Unmarshal(head.Request, &request); err != nil {
+			return messageFrame(&head, line)
+		}
+		return &protocolpb.Frame{Payload: &protocolpb.Frame_ControlRequest{ControlRequest: &protocolpb.ControlRequest{
+			RequestId:   head.RequestID,
+			Subtype:     request.Subtype,
+			RequestJson: head.Request,
+		}}}
+	case "control_response":
+		var response controlResponse
+		if err := json.Unmarshal(head.Response, &response); err != nil {
+			return messageFrame(&head, line)
+		}
+		return &protocolpb.Frame{Payload: &protocolpb.Frame_ControlResponse{ControlResponse: &protocolpb.ControlResponse{
+			RequestId:    response.RequestID,
+			Subtype:      response.Subtype,
+			ResponseJson: response.Response,
+			Error:        response.Error,
+		}}}
+	case "control_cancel_request":
+		return &protocolpb.Frame{Payload: &protocolpb.Frame_ControlCancelRequest{ControlCancelRequest: &protocolpb.ControlCancelRequest{
+			RequestId: head.RequestID,
+		}}}
+	}
+
+	return messageFrame(&head, line)
+}
+
+func messageFrame(head *lineHeader, line []byte) *protocolpb.Frame {
+	return &protocolpb.Frame{Payload: &protocolpb.Frame_Message{Message: &protocolpb.Message{
+		Type:      head.Type,
+		Subtype:   head.Subtype,
+		SessionId: head.SessionID,
+		Json:      line,
+	}}}
+}
+
+// frameToLine converts a Frame back to a stream-json line without the
+// trailing newline
+func frameToLine(frame *protocolpb.Frame) ([]byte, error) {
+	switch payload := frame.GetPayload().(type) {
+	case *protocolpb.Frame_Message:
+		return payload.Message.GetJson(), nil
+	case *protocolpb.Frame_ControlRequest:
+		request := payload.ControlRequest
+		return json.Marshal(struct {
+			Type      string          `json:"type"`
+			RequestID string          `json:"request_id"`
+			Request   json.RawMessage `json:"request"`
+		}{"control_request", request.GetRequestId(), rawOrEmpty(request.GetRequestJson())})
+	case *protocolpb.Frame_ControlResponse:
+		response := payload.ControlResponse
+		return json.Marshal(struct {
+			Type     string          `json:"type"`
+			Response controlResponse `json:"response"`
+		}{"control_response", controlResponse{
+			Subtype:   response.GetSubtype(),
+			RequestID: response.GetRequestId(),
+			Response:  response.GetResponseJson(),
+			Error:     response.GetError(),
+		}})
+	case *protocolpb.Frame_ControlCancelRequest:
+		return json.Marshal(struct {
+			Type      string `json:"type"`
+			RequestID string `json:"request_id"`
+		}{"control_cancel_request", payload.ControlCancelRequest.GetRequestId()})
+	default:
+		return nil, fmt.Errorf("empty or unknown frame payload %T", payload)
+	}
+}
+
+// rawOrEmpty returns raw, or an empty object if raw is empty
+func rawOrEmpty(raw []byte) json.RawMessage {
+	if len(raw) == 0 {
+		return json.RawMessage("{}")
+	}
+	return raw
+}
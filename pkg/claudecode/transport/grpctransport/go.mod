@@ -0,0 +1,18 @@
+module github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/grpctransport
+
+go 1.24.3
+
+replace github.com/vinaayakha/claude-code-sdk-go => ../../../../
+
+require (
+	github.com/vinaayakha/claude-code-sdk-go v0.0.0
+	google.golang.org/grpc v1.76.0
+	google.golang.org/protobuf v1.36.10
+)
+
+require (
+	golang.org/x/net v0.42.0 // indirect
+	golang.org/x/sys v0.34.0 // indirect
+	golang.org/x/text v0.27.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250804133106-a7a43d27e69b // indirect
+)
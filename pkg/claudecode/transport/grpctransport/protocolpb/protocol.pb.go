@@ -0,0 +1,489 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: protocol.proto
+
+package protocolpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Frame is one line of the stream-json protocol
+type Frame struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*Frame_Message
+	//	*Frame_ControlRequest
+	//	*Frame_ControlResponse
+	//	*Frame_ControlCancelRequest
+	Payload       isFrame_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Frame) Reset() {
+	*x = Frame{}
+	mi := &file_protocol_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Frame) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Frame) ProtoMessage() {}
+
+func (x *Frame) ProtoReflect() protoreflect.Message {
+	mi := &file_protocol_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Frame.ProtoReflect.Descriptor instead.
+func (*Frame) Descriptor() ([]byte, []int) {
+	return file_protocol_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Frame) GetPayload() isFrame_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *Frame) GetMessage() *Message {
+	if x != nil {
+		if x, ok := x.Payload.(*Frame_Message); ok {
+			return x.Message
+		}
+	}
+	return nil
+}
+
+func (x *Frame) GetControlRequest() *ControlRequest {
+	if x != nil {
+		if x, ok := x.Payload.(*Frame_ControlRequest); ok {
+			return x.ControlRequest
+		}
+	}
+	return nil
+}
+
+func (x *Frame) GetControlResponse() *ControlResponse {
+	if x != nil {
+		if x, ok := x.Payload.(*Frame_ControlResponse); ok {
+			return x.ControlResponse
+		}
+	}
+	return nil
+}
+
+func (x *Frame) GetControlCancelRequest() *ControlCancelRequest {
+	if x != nil {
+		if x, ok := x.Payload.(*Frame_ControlCancelRequest); ok {
+			return x.ControlCancelRequest
+		}
+	}
+	return nil
+}
+
+type isFrame_Payload interface {
+	isFrame_Payload()
+}
+
+type Frame_Message struct {
+	Message *Message `protobuf:"bytes,1,opt,name=message,proto3,oneof"`
+}
+
+type Frame_ControlRequest struct {
+	ControlRequest *ControlRequest `protobuf:"bytes,2,opt,name=control_request,json=controlRequest,proto3,oneof"`
+}
+
+type Frame_ControlResponse struct {
+	ControlResponse *ControlResponse `protobuf:"bytes,3,opt,name=control_response,json=controlResponse,proto3,oneof"`
+}
+
+type Frame_ControlCancelRequest struct {
+	ControlCancelRequest *ControlCancelRequest `protobuf:"bytes,4,opt,name=control_cancel_request,json=controlCancelRequest,proto3,oneof"`
+}
+
+func (*Frame_Message) isFrame_Payload() {}
+
+func (*Frame_ControlRequest) isFrame_Payload() {}
+
+func (*Frame_ControlResponse) isFrame_Payload() {}
+
+func (*Frame_ControlCancelRequest) isFrame_Payload() {}
+
+// Message is a conversation message: user, assistant, system, result or
+// stream_event
+type Message struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The "type" field of the message
+	Type string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	// The "subtype" field, for system and result messages
+	Subtype   string `protobuf:"bytes,2,opt,name=subtype,proto3" json:"subtype,omitempty"`
+	SessionId string `protobuf:"bytes,3,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	// The complete message as a JSON object
+	Json          []byte `protobuf:"bytes,4,opt,name=json,proto3" json:"json,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Message) Reset() {
+	*x = Message{}
+	mi := &file_protocol_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Message) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Message) ProtoMessage() {}
+
+func (x *Message) ProtoReflect() protoreflect.Message {
+	mi := &file_protocol_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Message.ProtoReflect.Descriptor instead.
+func (*Message) Descriptor() ([]byte, []int) {
+	return file_protocol_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Message) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Message) GetSubtype() string {
+	if x != nil {
+		return x.Subtype
+	}
+	return ""
+}
+
+func (x *Message) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *Message) GetJson() []byte {
+	if x != nil {
+		return x.Json
+	}
+	return nil
+}
+
+// ControlRequest is a control_request line, sent by either side
+type ControlRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	RequestId string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	// The "subtype" field of the request, e.g. "interrupt" or "can_use_tool"
+	Subtype string `protobuf:"bytes,2,opt,name=subtype,proto3" json:"subtype,omitempty"`
+	// The "request" object as JSON, including its subtype
+	RequestJson   []byte `protobuf:"bytes,3,opt,name=request_json,json=requestJson,proto3" json:"request_json,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ControlRequest) Reset() {
+	*x = ControlRequest{}
+	mi := &file_protocol_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ControlRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ControlRequest) ProtoMessage() {}
+
+func (x *ControlRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protocol_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ControlRequest.ProtoReflect.Descriptor instead.
+func (*ControlRequest) Descriptor() ([]byte, []int) {
+	return file_protocol_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ControlRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *ControlRequest) GetSubtype() string {
+	if x != nil {
+		return x.Subtype
+	}
+	return ""
+}
+
+func (x *ControlRequest) GetRequestJson() []byte {
+	if x != nil {
+		return x.RequestJson
+	}
+	return nil
+}
+
+// ControlResponse answers a ControlRequest
+type ControlResponse struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	RequestId string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	// "success" or "error"
+	Subtype string `protobuf:"bytes,2,opt,name=subtype,proto3" json:"subtype,omitempty"`
+	// The "response" object of a successful response as JSON, if any
+	ResponseJson []byte `protobuf:"bytes,3,opt,name=response_json,json=responseJson,proto3" json:"response_json,omitempty"`
+	// The error of a failed response
+	Error         string `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ControlResponse) Reset() {
+	*x = ControlResponse{}
+	mi := &file_protocol_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ControlResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ControlResponse) ProtoMessage() {}
+
+func (x *ControlResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_protocol_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ControlResponse.ProtoReflect.Descriptor instead.
+func (*ControlResponse) Descriptor() ([]byte, []int) {
+	return file_protocol_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ControlResponse) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *ControlResponse) GetSubtype() string {
+	if x != nil {
+		return x.Subtype
+	}
+	return ""
+}
+
+func (x *ControlResponse) GetResponseJson() []byte {
+	if x != nil {
+		return x.ResponseJson
+	}
+	return nil
+}
+
+func (x *ControlResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// ControlCancelRequest abandons a pending ControlRequest
+type ControlCancelRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RequestId     string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ControlCancelRequest) Reset() {
+	*x = ControlCancelRequest{}
+	mi := &file_protocol_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ControlCancelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ControlCancelRequest) ProtoMessage() {}
+
+func (x *ControlCancelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_protocol_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ControlCancelRequest.ProtoReflect.Descriptor instead.
+func (*ControlCancelRequest) Descriptor() ([]byte, []int) {
+	return file_protocol_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ControlCancelRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+var File_protocol_proto protoreflect.FileDescriptor
+
+const file_protocol_proto_rawDesc = "" +
+	"\n" +
+	"\x0eprotocol.proto\x12\rclaudecode.v1\"\xba\x02\n" +
+	"\x05Frame\x122\n" +
+	"\amessage\x18\x01 \x01(\v2\x16.claudecode.v1.MessageH\x00R\amessage\x12H\n" +
+	"\x0fcontrol_request\x18\x02 \x01(\v2\x1d.claudecode.v1.ControlRequestH\x00R\x0econtrolRequest\x12K\n" +
+	"\x10control_response\x18\x03 \x01(\v2\x1e.claudecode.v1.ControlResponseH\x00R\x0fcontrolResponse\x12[\n" +
+	"\x16control_cancel_request\x18\x04 \x01(\v2#.claudecode.v1.ControlCancelRequestH\x00R\x14controlCancelRequestB\t\n" +
+	"\apayload\"j\n" +
+	"\aMessage\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x18\n" +
+	"\asubtype\x18\x02 \x01(\tR\asubtype\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x03 \x01(\tR\tsessionId\x12\x12\n" +
+	"\x04json\x18\x04 \x01(\fR\x04json\"l\n" +
+	"\x0eControlRequest\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x01 \x01(\tR\trequestId\x12\x18\n" +
+	"\asubtype\x18\x02 \x01(\tR\asubtype\x12!\n" +
+	"\frequest_json\x18\x03 \x01(\fR\vrequestJson\"\x85\x01\n" +
+	"\x0fControlResponse\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x01 \x01(\tR\trequestId\x12\x18\n" +
+	"\asubtype\x18\x02 \x01(\tR\asubtype\x12#\n" +
+	"\rresponse_json\x18\x03 \x01(\fR\fresponseJson\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\"5\n" +
+	"\x14ControlCancelRequest\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x01 \x01(\tR\trequestId2G\n" +
+	"\n" +
+	"ClaudeCode\x129\n" +
+	"\aSession\x12\x14.claudecode.v1.Frame\x1a\x14.claudecode.v1.Frame(\x010\x01B\\ZZgithub.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/grpctransport/protocolpbb\x06proto3"
+
+var (
+	file_protocol_proto_rawDescOnce sync.Once
+	file_protocol_proto_rawDescData []byte
+)
+
+func file_protocol_proto_rawDescGZIP() []byte {
+	file_protocol_proto_rawDescOnce.Do(func() {
+		file_protocol_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_protocol_proto_rawDesc), len(file_protocol_proto_rawDesc)))
+	})
+	return file_protocol_proto_rawDescData
+}
+
+var file_protocol_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_protocol_proto_goTypes = []any{
+	(*Frame)(nil),                // 0: claudecode.v1.Frame
+	(*Message)(nil),              // 1: claudecode.v1.Message
+	(*ControlRequest)(nil),       // 2: claudecode.v1.ControlRequest
+	(*ControlResponse)(nil),      // 3: claudecode.v1.ControlResponse
+	(*ControlCancelRequest)(nil), // 4: claudecode.v1.ControlCancelRequest
+}
+var file_protocol_proto_depIdxs = []int32{
+	1, // 0: claudecode.v1.Frame.message:type_name -> claudecode.v1.Message
+	2, // 1: claudecode.v1.Frame.control_request:type_name -> claudecode.v1.ControlRequest
+	3, // 2: claudecode.v1.Frame.control_response:type_name -> claudecode.v1.ControlResponse
+	4, // 3: claudecode.v1.Frame.control_cancel_request:type_name -> claudecode.v1.ControlCancelRequest
+	0, // 4: claudecode.v1.ClaudeCode.Session:input_type -> claudecode.v1.Frame
+	0, // 5: claudecode.v1.ClaudeCode.Session:output_type -> claudecode.v1.Frame
+	5, // [5:6] is the sub-list for method output_type
+	4, // [4:5] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_protocol_proto_init() }
+func file_protocol_proto_init() {
+	if File_protocol_proto != nil {
+		return
+	}
+	file_protocol_proto_msgTypes[0].OneofWrappers = []any{
+		(*Frame_Message)(nil),
+		(*Frame_ControlRequest)(nil),
+		(*Frame_ControlResponse)(nil),
+		(*Frame_ControlCancelRequest)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_protocol_proto_rawDesc), len(file_protocol_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_protocol_proto_goTypes,
+		DependencyIndexes: file_protocol_proto_depIdxs,
+		MessageInfos:      file_protocol_proto_msgTypes,
+	}.Build()
+	File_protocol_proto = out.File
+	file_protocol_proto_goTypes = nil
+	file_protocol_proto_depIdxs = nil
+}
@@ -0,0 +1,125 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.0
+// - protoc             (unknown)
+// source: protocol.proto
+
+package protocolpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ClaudeCode_Session_FullMethodName = "/claudecode.v1.ClaudeCode/Session"
+)
+
+// ClaudeCodeClient is the client API for ClaudeCode service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ClaudeCode exposes a Claude Code CLI behind a gateway
+type ClaudeCodeClient interface {
+	// Session opens one CLI session. The client sends user messages and
+	// control traffic; the server streams the CLI's output. Closing the send
+	// side closes the CLI's stdin; the session ends when the server returns.
+	Session(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[Frame, Frame], error)
+}
+
+type claudeCodeClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewClaudeCodeClient(cc grpc.ClientConnInterface) ClaudeCodeClient {
+	return &claudeCodeClient{cc}
+}
+
+func (c *claudeCodeClient) Session(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[Frame, Frame], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ClaudeCode_ServiceDesc.Streams[0], ClaudeCode_Session_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[Frame, Frame]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ClaudeCode_SessionClient = grpc.BidiStreamingClient[Frame, Frame]
+
+// ClaudeCodeServer is the server API for ClaudeCode service.
+// All implementations must embed UnimplementedClaudeCodeServer
+// for forward compatibility.
+//
+// ClaudeCode exposes a Claude Code CLI behind a gateway
+type ClaudeCodeServer interface {
+	// Session opens one CLI session. The client sends user messages and
+	// control traffic; the server streams the CLI's output. Closing the send
+	// side closes the CLI's stdin; the session ends when the server returns.
+	Session(grpc.BidiStreamingServer[Frame, Frame]) error
+	mustEmbedUnimplementedClaudeCodeServer()
+}
+
+// UnimplementedClaudeCodeServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedClaudeCodeServer struct{}
+
+func (UnimplementedClaudeCodeServer) Session(grpc.BidiStreamingServer[Frame, Frame]) error {
+	return status.Error(codes.Unimplemented, "method Session not implemented")
+}
+func (UnimplementedClaudeCodeServer) mustEmbedUnimplementedClaudeCodeServer() {}
+func (UnimplementedClaudeCodeServer) testEmbeddedByValue()                    {}
+
+// UnsafeClaudeCodeServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ClaudeCodeServer will
+// result in compilation errors.
+type UnsafeClaudeCodeServer interface {
+	mustEmbedUnimplementedClaudeCodeServer()
+}
+
+func RegisterClaudeCodeServer(s grpc.ServiceRegistrar, srv ClaudeCodeServer) {
+	// If the following call panics, it indicates UnimplementedClaudeCodeServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ClaudeCode_ServiceDesc, srv)
+}
+
+func _ClaudeCode_Session_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ClaudeCodeServer).Session(&grpc.GenericServerStream[Frame, Frame]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ClaudeCode_SessionServer = grpc.BidiStreamingServer[Frame, Frame]
+
+// ClaudeCode_ServiceDesc is the grpc.ServiceDesc for ClaudeCode service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ClaudeCode_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "claudecode.v1.ClaudeCode",
+	HandlerType: (*ClaudeCodeServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Session",
+			Handler:       _ClaudeCode_Session_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "protocol.proto",
+}
@@ -0,0 +1,120 @@
+package grpctransport
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"slices"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/grpctransport/protocolpb"
+)
+
+// TransportFactory creates the transport backing one session, typically a
+// SubprocessTransport. ctx is the session's context; it carries the
+// caller's metadata, e.g. for authorization.
+type TransportFactory func(ctx context.Context) (transport.Transport, error)
+
+// Server implements protocolpb.ClaudeCodeServer by relaying each session
+// to a transport from a TransportFactory
+type Server struct {
+	protocolpb.UnimplementedClaudeCodeServer
+
+	newTransport TransportFactory
+	logger       *slog.Logger
+}
+
+// NewServer creates a gateway server
+func NewServer(newTransport TransportFactory) *Server {
+	return &Server{
+		newTransport: newTransport,
+		logger:       nopLogger,
+	}
+}
+
+// SetLogger sets the logger for session events. It must be called before
+// the server is registered.
+func (s *Server) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		logger = nopLogger
+	}
+	s.logger = logger
+}
+
+// Session relays frames between the stream and a new transport until the
+// transport's output ends or the client goes away
+func (s *Server) Session(stream grpc.BidiStreamingServer[protocolpb.Frame, protocolpb.Frame]) error {
+	ctx := stream.Context()
+
+	t, err := s.newTransport(ctx)
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "failed to create transport: %v", err)
+	}
+	t.SetLogger(s.logger)
+	if err := t.Connect(ctx); err != nil {
+		return status.Errorf(codes.Unavailable, "failed to connect transport: %v", err)
+	}
+	defer t.Close()
+
+	s.logger.InfoContext(ctx, "gRPC session started")
+
+	inputErr := make(chan error, 1)
+	go func() {
+		err := forwardInput(ctx, stream, t)
+		inputErr <- err
+		if err != nil {
+			// Unblock the output loop
+			t.Close()
+		}
+	}()
+
+	err = readLines(t.Reader(), func(line []byte) error {
+		return stream.Send(lineToFrame(slices.Clone(line)))
+	})
+
+	select {
+	case inErr := <-inputErr:
+		if inErr != nil {
+			err = inErr
+		}
+	default:
+	}
+
+	s.logger.InfoContext(ctx, "gRPC session ended", "error", err)
+	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+// forwardInput writes received frames to t. When the client closes its send
+// side, the transport's input is closed if it supports it.
+func forwardInput(ctx context.Context, stream grpc.BidiStreamingServer[protocolpb.Frame, protocolpb.Frame], t transport.Transport) error {
+	for {
+		frame, err := stream.Recv()
+		if err == io.EOF {
+			if closer, ok := t.(transport.GracefulCloser); ok {
+				closer.CloseInput()
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		line, err := frameToLine(frame)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		if err := t.Write(ctx, append(line, '\n')); err != nil {
+			return status.Errorf(codes.Unavailable, "failed to write to transport: %v", err)
+		}
+	}
+}
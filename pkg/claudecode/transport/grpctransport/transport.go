@@ -0,0 +1,232 @@
+// Package grpctransport carries the stream-json protocol over gRPC, so the
+// CLI can run behind a gateway service while applications keep using the
+// same client API. It is a separate module, so the SDK itself does not
+// depend on gRPC.
+//
+// The gateway registers a Server, which runs one Transport (usually a
+// SubprocessTransport) per session:
+//
+//	server := grpc.NewServer()
+//	protocolpb.RegisterClaudeCodeServer(server, grpctransport.NewServer(
+//	    func(ctx context.Context) (transport.Transport, error) {
+//	        return transport.NewSubprocessTransport(options), nil
+//	    }))
+//
+// Clients dial the gateway with their own credentials and connect through a
+// Transport:
+//
+//	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+//	...
+//	client := claudecode.NewClaudeSDKClientWithTransport(options, grpctransport.NewTransport(conn))
+//
+// gRPC limits received messages to 4MB by default; pass
+// grpc.MaxCallRecvMsgSize to NewTransport and grpc.MaxRecvMsgSize to the
+// server when messages may carry large images or tool results.
+package grpctransport
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+
+	"google.golang.org/grpc"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/grpctransport/protocolpb"
+)
+
+// maxLineSize bounds a single stream-json line, as for the subprocess
+const maxLineSize = 1024 * 1024 * 16 // 16MB
+
+// nopLogger is used until SetLogger is called
+var nopLogger = slog.New(slog.DiscardHandler)
+
+// Transport implements transport.Transport over a ClaudeCode.Session
+// stream. Every outbound line is sent as one Frame and every inbound Frame
+// is exposed on Reader() as one newline-terminated line.
+type Transport struct {
+	client      protocolpb.ClaudeCodeClient
+	callOptions []grpc.CallOption
+
+	stream grpc.BidiStreamingClient[protocolpb.Frame, protocolpb.Frame]
+	cancel context.CancelFunc
+	reader *io.PipeReader
+	writer *io.PipeWriter
+
+	connected bool
+	logger    *slog.Logger
+
+	mu      sync.RWMutex
+	writeMu sync.Mutex
+}
+
+// NewTransport creates a transport that opens a session on conn. The call
+// options apply to the session stream, e.g. per-call credentials.
+func NewTransport(conn grpc.ClientConnInterface, opts ...grpc.CallOption) *Transport {
+	return &Transport{
+		client:      protocolpb.NewClaudeCodeClient(conn),
+		callOptions: opts,
+		logger:      nopLogger,
+	}
+}
+
+// Connect opens the session stream
+func (t *Transport) Connect(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.connected {
+		return nil
+	}
+
+	// The stream outlives ctx; it is bound to the transport until Close
+	streamCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(ctx, cancel)
+	stream, err := t.client.Session(streamCtx, t.callOptions...)
+	if !stop() && err == nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		cancel()
+		return errors.NewCLIConnectionError("failed to open gRPC session", err)
+	}
+
+	t.stream = stream
+	t.cancel = cancel
+	t.reader, t.writer = io.Pipe()
+	t.connected = true
+	t.logger.InfoContext(ctx, "gRPC session opened")
+
+	go t.recvLoop(stream, t.writer)
+
+	return nil
+}
+
+// Close ends the session
+func (t *Transport) Close() error {
+	t.mu.Lock()
+	if !t.connected {
+		t.mu.Unlock()
+		return nil
+	}
+	t.connected = false
+	stream := t.stream
+	cancel := t.cancel
+	writer := t.writer
+	t.mu.Unlock()
+
+	t.logger.Info("closing gRPC session")
+
+	t.writeMu.Lock()
+	stream.CloseSend()
+	t.writeMu.Unlock()
+
+	cancel()
+	return writer.Close()
+}
+
+// Write sends each line of data as a Frame
+func (t *Transport) Write(ctx context.Context, data []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	t.mu.RLock()
+	if !t.connected {
+		t.mu.RUnlock()
+		return errors.NewCLIConnectionError("transport not connected", nil)
+	}
+	stream := t.stream
+	logger := t.logger
+	t.mu.RUnlock()
+
+	if logger.Enabled(ctx, slog.LevelDebug) {
+		logger.DebugContext(ctx, "sent line", "line", string(bytes.TrimSpace(data)))
+	}
+
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+
+	for _, line := range bytes.Split(data, []byte{'\n'}) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		if err := stream.Send(lineToFrame(line)); err != nil {
+			return errors.NewCLIConnectionError("failed to send gRPC frame", err)
+		}
+	}
+
+	return nil
+}
+
+// Reader returns a reader yielding one line per inbound frame
+func (t *Transport) Reader() io.Reader {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.reader
+}
+
+// IsConnected returns true if connected
+func (t *Transport) IsConnected() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.connected
+}
+
+// SetLogger sets the logger for session events and sent lines
+func (t *Transport) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		logger = nopLogger
+	}
+	t.mu.Lock()
+	t.logger = logger
+	t.mu.Unlock()
+}
+
+// recvLoop forwards received frames to the pipe until the stream ends
+func (t *Transport) recvLoop(stream grpc.BidiStreamingClient[protocolpb.Frame, protocolpb.Frame], w *io.PipeWriter) {
+	for {
+		frame, err := stream.Recv()
+		if err == io.EOF {
+			w.Close()
+			return
+		}
+		if err != nil {
+			w.CloseWithError(errors.NewCLIConnectionError("gRPC session failed", err))
+			return
+		}
+
+		line, err := frameToLine(frame)
+		if err != nil {
+			t.mu.RLock()
+			logger := t.logger
+			t.mu.RUnlock()
+			logger.Warn("dropping gRPC frame", "error", err)
+			continue
+		}
+		if _, err := w.Write(append(line, '\n')); err != nil {
+			return
+		}
+	}
+}
+
+// readLines calls fn for every line read from r
+func readLines(r io.Reader, fn func(line []byte) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		if err := fn(line); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
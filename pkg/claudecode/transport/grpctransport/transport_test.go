@@ -0,0 +1,121 @@
+package grpctransport
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/grpctransport/protocolpb"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/transporttest"
+)
+
+func TestFrameRoundTrip(t *testing.T) {
+	lines := []string{
+		`{"type":"user","message":{"role":"user","content":"hi"},"session_id":"s1"}`,
+		`{"type":"result","subtype":"success","session_id":"s1","is_error":false}`,
+		`{"type":"control_request","request_id":"req_1","request":{"subtype":"interrupt"}}`,
+		`{"type":"control_response","response":{"subtype":"success","request_id":"req_1","response":{"ok":true}}}`,
+		`{"type":"control_response","response":{"subtype":"error","request_id":"req_2","error":"denied"}}`,
+		`{"type":"control_cancel_request","request_id":"req_3"}`,
+		`not json`,
+	}
+
+	for _, line := range lines {
+		frame := lineToFrame([]byte(line))
+		got, err := frameToLine(frame)
+		if err != nil {
+			t.Fatalf("frameToLine(%s): %v", line, err)
+		}
+		if !sameJSON(line, string(got)) {
+			t.Errorf("round trip changed line:\n got %s\nwant %s", got, line)
+		}
+	}
+
+	frame := lineToFrame([]byte(lines[2]))
+	if req := frame.GetControlRequest(); req.GetRequestId() != "req_1" || req.GetSubtype() != "interrupt" {
+		t.Errorf("unexpected control request frame: %v", frame)
+	}
+	frame = lineToFrame([]byte(lines[1]))
+	if msg := frame.GetMessage(); msg.GetType() != "result" || msg.GetSubtype() != "success" || msg.GetSessionId() != "s1" {
+		t.Errorf("unexpected message frame: %v", frame)
+	}
+}
+
+func sameJSON(a, b string) bool {
+	var va, vb interface{}
+	if json.Unmarshal([]byte(a), &va) != nil || json.Unmarshal([]byte(b), &vb) != nil {
+		return a == b
+	}
+	ja, _ := json.Marshal(va)
+	jb, _ := json.Marshal(vb)
+	return string(ja) == string(jb)
+}
+
+func TestTransportThroughGateway(t *testing.T) {
+	mock := transporttest.NewMockTransport()
+	mock.Respond(transporttest.MatchType("user"),
+		map[string]interface{}{"type": "result", "subtype": "success", "session_id": "s1"},
+	)
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	protocolpb.RegisterClaudeCodeServer(server, NewServer(func(ctx context.Context) (transport.Transport, error) {
+		return mock, nil
+	}))
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tr := NewTransport(conn)
+	if err := tr.Connect(ctx); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer tr.Close()
+
+	if err := tr.Write(ctx, []byte(`{"type":"user","message":{"role":"user","content":"hi"}}`+"\n")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	reader := bufio.NewReader(tr.Reader())
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		t.Fatalf("ReadString: %v", err)
+	}
+	if !strings.Contains(line, `"subtype":"success"`) {
+		t.Errorf("unexpected line: %q", line)
+	}
+
+	written := mock.WrittenMessages()
+	if len(written) != 1 || written[0]["type"] != "user" {
+		t.Errorf("unexpected writes on the gateway: %v", written)
+	}
+
+	if err := tr.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+	if tr.IsConnected() {
+		t.Error("expected transport to be disconnected")
+	}
+}
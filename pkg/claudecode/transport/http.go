@@ -0,0 +1,393 @@
+package transport
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+)
+
+// Defaults for HTTPTransportOptions
+const (
+	defaultHTTPStreamPath     = "/stream"
+	defaultHTTPMessagesPath   = "/messages"
+	defaultHTTPInitialBackoff = 500 * time.Millisecond
+	defaultHTTPMaxBackoff     = 30 * time.Second
+)
+
+// HTTPTransportOptions configures an HTTPTransport
+type HTTPTransportOptions struct {
+	// Sent with every request, e.g. Authorization
+	Header http.Header
+
+	// Client used for all requests (default http.DefaultClient). Its Timeout
+	// must be zero since it would also cut off the event stream.
+	Client *http.Client
+
+	// Paths relative to the base URL (default "/stream" and "/messages")
+	StreamPath   string
+	MessagesPath string
+
+	// Delay before the first reconnection attempt, doubled per failed
+	// attempt up to MaxBackoff (default 500ms and 30s). A retry field sent
+	// by the server replaces InitialBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	// Consecutive failed reconnection attempts before the stream is given
+	// up and Reader() returns an error (0 = retry until Close)
+	MaxReconnects int
+}
+
+// HTTPTransport implements Transport against a remote endpoint serving the
+// stream-json protocol over plain HTTP, for environments where the CLI cannot
+// be installed locally.
+//
+// Inbound messages are read from a Server-Sent Events stream (GET on the
+// stream path), one JSON message per event. Outbound lines are POSTed to the
+// messages path as application/x-ndjson. When the event stream drops it is
+// reopened with backoff, passing the last event id in Last-Event-ID so the
+// server can replay what was missed. A 204 No Content response ends the
+// stream for good.
+type HTTPTransport struct {
+	baseURL string
+	options HTTPTransportOptions
+
+	reader *io.PipeReader
+	writer *io.PipeWriter
+	cancel context.CancelFunc
+
+	lastEventID string
+	backoff     time.Duration
+
+	connected bool
+	logger    *slog.Logger
+
+	mu      sync.RWMutex
+	writeMu sync.Mutex
+}
+
+// NewHTTPTransport creates a transport for an http:// or https:// base URL
+func NewHTTPTransport(baseURL string, options HTTPTransportOptions) *HTTPTransport {
+	if options.Header == nil {
+		options.Header = http.Header{}
+	}
+	if options.Client == nil {
+		options.Client = http.DefaultClient
+	}
+	if options.StreamPath == "" {
+		options.StreamPath = defaultHTTPStreamPath
+	}
+	if options.MessagesPath == "" {
+		options.MessagesPath = defaultHTTPMessagesPath
+	}
+	if options.InitialBackoff <= 0 {
+		options.InitialBackoff = defaultHTTPInitialBackoff
+	}
+	if options.MaxBackoff <= 0 {
+		options.MaxBackoff = defaultHTTPMaxBackoff
+	}
+	return &HTTPTransport{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		options: options,
+		backoff: options.InitialBackoff,
+		logger:  nopLogger,
+	}
+}
+
+// Connect opens the event stream
+func (t *HTTPTransport) Connect(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.connected {
+		return nil
+	}
+
+	u, err := url.Parse(t.baseURL)
+	if err != nil {
+		return errors.NewCLIConnectionError("invalid HTTP transport URL", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errors.NewCLIConnectionError(fmt.Sprintf("unsupported HTTP transport scheme: %s", u.Scheme), nil)
+	}
+
+	// The stream outlives ctx; it is bound to the transport until Close
+	streamCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(ctx, cancel)
+	body, err := t.openStream(streamCtx)
+	if !stop() && err == nil {
+		body.Close()
+		err = ctx.Err()
+	}
+	if err != nil {
+		cancel()
+		return err
+	}
+	if body == nil {
+		cancel()
+		return errors.NewCLIConnectionError("HTTP event stream closed by server", nil)
+	}
+
+	t.cancel = cancel
+	t.reader, t.writer = io.Pipe()
+	t.connected = true
+	t.logger.InfoContext(ctx, "HTTP stream connected", "url", u.Redacted())
+
+	go t.streamLoop(streamCtx, body, t.writer)
+
+	return nil
+}
+
+// openStream issues the GET for the event stream. A nil body without error
+// means the server answered 204 No Content and the stream must not be
+// reopened.
+func (t *HTTPTransport) openStream(ctx context.Context) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, t.baseURL+t.options.StreamPath, nil)
+	if err != nil {
+		return nil, errors.NewCLIConnectionError("failed to create HTTP stream request", err)
+	}
+	req.Header = t.options.Header.Clone()
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+	if t.lastEventID != "" {
+		req.Header.Set("Last-Event-ID", t.lastEventID)
+	}
+
+	resp, err := t.options.Client.Do(req)
+	if err != nil {
+		return nil, errors.NewCLIConnectionError("failed to open HTTP event stream", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNoContent:
+		resp.Body.Close()
+		return nil, nil
+	case resp.StatusCode != http.StatusOK:
+		resp.Body.Close()
+		return nil, errors.NewCLIConnectionError(fmt.Sprintf("HTTP event stream failed: %s", resp.Status), nil)
+	case !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream"):
+		resp.Body.Close()
+		return nil, errors.NewCLIConnectionError(fmt.Sprintf("HTTP event stream has unexpected content type %q", resp.Header.Get("Content-Type")), nil)
+	}
+
+	return resp.Body, nil
+}
+
+// Close stops the event stream
+func (t *HTTPTransport) Close() error {
+	t.mu.Lock()
+	if !t.connected {
+		t.mu.Unlock()
+		return nil
+	}
+	t.connected = false
+	cancel := t.cancel
+	writer := t.writer
+	t.mu.Unlock()
+
+	t.logger.Info("closing HTTP stream", "url", t.baseURL)
+
+	cancel()
+	return writer.Close()
+}
+
+// Write POSTs data to the messages endpoint as newline-delimited JSON
+func (t *HTTPTransport) Write(ctx context.Context, data []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	t.mu.RLock()
+	if !t.connected {
+		t.mu.RUnlock()
+		return errors.NewCLIConnectionError("transport not connected", nil)
+	}
+	logger := t.logger
+	t.mu.RUnlock()
+
+	logSent(ctx, logger, data)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.baseURL+t.options.MessagesPath, bytes.NewReader(data))
+	if err != nil {
+		return errors.NewCLIConnectionError("failed to create HTTP message request", err)
+	}
+	req.Header = t.options.Header.Clone()
+	req.Header.Set("Content-Type", "application/x-ndjson")
+
+	// Serialize posts so the server receives lines in order
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+
+	resp, err := t.options.Client.Do(req)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return errors.NewCLIConnectionError("failed to POST HTTP message", err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.NewCLIConnectionError(fmt.Sprintf("HTTP message rejected: %s", resp.Status), nil)
+	}
+
+	return nil
+}
+
+// Reader returns a reader yielding one line per inbound event
+func (t *HTTPTransport) Reader() io.Reader {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.reader
+}
+
+// IsConnected returns true if connected
+func (t *HTTPTransport) IsConnected() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.connected
+}
+
+// SetLogger sets the logger for connection events and sent lines
+func (t *HTTPTransport) SetLogger(logger *slog.Logger) {
+	t.mu.Lock()
+	t.logger = loggerOrNop(logger)
+	t.mu.Unlock()
+}
+
+// streamLoop forwards events to the pipe and reopens the stream whenever
+// it drops, until ctx is cancelled by Close
+func (t *HTTPTransport) streamLoop(ctx context.Context, body io.ReadCloser, w *io.PipeWriter) {
+	failures := 0
+	for {
+		err := t.readEvents(body, w)
+		body.Close()
+		if ctx.Err() != nil {
+			return
+		}
+		if err == io.ErrClosedPipe {
+			return
+		}
+
+		t.mu.RLock()
+		logger := t.logger
+		t.mu.RUnlock()
+		logger.Warn("HTTP event stream dropped, reconnecting", "error", err, "last_event_id", t.lastEventID)
+
+		for {
+			failures++
+			if t.options.MaxReconnects > 0 && failures > t.options.MaxReconnects {
+				w.CloseWithError(errors.NewCLIConnectionError(
+					fmt.Sprintf("HTTP event stream lost after %d reconnection attempts", t.options.MaxReconnects), err))
+				return
+			}
+
+			delay := t.backoff << min(failures-1, 16)
+			if delay < 0 || delay > t.options.MaxBackoff {
+				delay = t.options.MaxBackoff
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+
+			body, err = t.openStream(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+			if err == nil && body == nil {
+				w.Close()
+				return
+			}
+			if err == nil {
+				logger.Info("HTTP event stream reconnected", "attempts", failures)
+				failures = 0
+				break
+			}
+		}
+	}
+}
+
+// readEvents parses the event stream and writes the data of each message
+// event to w as one line
+func (t *HTTPTransport) readEvents(body io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), maxBufferSize)
+
+	var event string
+	var data bytes.Buffer
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+
+		if line == "" {
+			// A blank line dispatches the event
+			if data.Len() > 0 && (event == "" || event == "message") {
+				if err := writeEventData(w, data.Bytes()); err != nil {
+					return err
+				}
+			}
+			event = ""
+			data.Reset()
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			// Comment, typically a keep-alive
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "data":
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(value)
+		case "event":
+			event = value
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				t.lastEventID = value
+			}
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil && ms >= 0 {
+				t.backoff = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return io.EOF
+}
+
+// writeEventData writes event data as a single newline-terminated line,
+// compacting JSON that was split over several data fields
+func writeEventData(w io.Writer, data []byte) error {
+	var line bytes.Buffer
+	if bytes.IndexByte(data, '\n') < 0 || json.Compact(&line, data) != nil {
+		line.Reset()
+		line.Write(data)
+	}
+	line.WriteByte('\n')
+
+	_, err := w.Write(line.Bytes())
+	return err
+}
@@ -0,0 +1,170 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// sseServer relays POSTed lines back over the event stream. The first
+// stream is dropped after one event to exercise reconnection.
+type sseServer struct {
+	lines chan string
+
+	mu          sync.Mutex
+	streams     int
+	lastEventID []string
+}
+
+func (s *sseServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer token" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	switch r.URL.Path {
+	case "/api/messages":
+		body, _ := io.ReadAll(r.Body)
+		for _, line := range strings.Split(strings.TrimSpace(string(body)), "\n") {
+			s.lines <- line
+		}
+		w.WriteHeader(http.StatusAccepted)
+	case "/api/stream":
+		s.mu.Lock()
+		s.streams++
+		stream := s.streams
+		s.lastEventID = append(s.lastEventID, r.Header.Get("Last-Event-ID"))
+		s.mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "retry: 10\n: keep-alive\n\n")
+		w.(http.Flusher).Flush()
+
+		for id := 1; ; id++ {
+			select {
+			case <-r.Context().Done():
+				return
+			case line := <-s.lines:
+				// Split each message over two data fields
+				half := strings.Index(line, ",") + 1
+				fmt.Fprintf(w, "id: %d-%d\ndata: %s\ndata: %s\n\n", stream, id, line[:half], line[half:])
+				w.(http.Flusher).Flush()
+				if stream == 1 {
+					return
+				}
+			}
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestHTTPTransportRoundTrip(t *testing.T) {
+	handler := &sseServer{lines: make(chan string, 10)}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	tr := NewHTTPTransport(server.URL+"/api/", HTTPTransportOptions{
+		Header: http.Header{"Authorization": []string{"Bearer token"}},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := tr.Connect(ctx); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer tr.Close()
+
+	if !tr.IsConnected() {
+		t.Fatal("expected transport to be connected")
+	}
+
+	first := `{"type":"user","n":1}`
+	second := `{"type":"user","n":2}`
+	if err := tr.Write(ctx, []byte(first+"\n")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	reader := bufio.NewReader(tr.Reader())
+	readLine := func(want string) {
+		t.Helper()
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("ReadString: %v", err)
+		}
+		if got := strings.TrimSuffix(line, "\n"); got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+	readLine(first)
+
+	// The first stream ends; the next message arrives after reconnecting
+	if err := tr.Write(ctx, []byte(second+"\n")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	readLine(second)
+
+	handler.mu.Lock()
+	ids := handler.lastEventID
+	handler.mu.Unlock()
+	if len(ids) != 2 || ids[0] != "" || ids[1] != "1-1" {
+		t.Errorf("unexpected Last-Event-ID headers: %q", ids)
+	}
+
+	if err := tr.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+	if err := tr.Write(ctx, []byte(first+"\n")); err == nil {
+		t.Error("expected Write after Close to fail")
+	}
+}
+
+func TestHTTPTransportRejected(t *testing.T) {
+	server := httptest.NewServer(&sseServer{lines: make(chan string)})
+	defer server.Close()
+
+	tr := NewHTTPTransport(server.URL+"/api", HTTPTransportOptions{})
+	if err := tr.Connect(context.Background()); err == nil {
+		tr.Close()
+		t.Fatal("expected Connect to fail without credentials")
+	}
+	if tr.IsConnected() {
+		t.Error("expected transport to be disconnected")
+	}
+}
+
+func TestHTTPTransportEndOfStream(t *testing.T) {
+	var streams int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		streams++
+		if streams > 1 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "retry: 0\nevent: ping\ndata: ignored\n\ndata: {\"type\":\"result\"}\n\n")
+	}))
+	defer server.Close()
+
+	tr := NewHTTPTransport(server.URL, HTTPTransportOptions{})
+	if err := tr.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer tr.Close()
+
+	data, err := io.ReadAll(tr.Reader())
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if string(data) != "{\"type\":\"result\"}\n" {
+		t.Errorf("unexpected stream contents: %q", data)
+	}
+}
@@ -0,0 +1,115 @@
+package transport
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// maxInlineMCPConfig is the largest --mcp-config passed inline. Larger
+// configs go to a temp file to stay clear of command line length limits.
+const maxInlineMCPConfig = 8 * 1024
+
+// mcpServerJSON converts a server config to the form the CLI expects
+func mcpServerJSON(name string, server types.MCPServerConfig) (interface{}, error) {
+	switch s := server.(type) {
+	case types.MCPSDKServerConfig:
+		// SDK servers run in-process; the CLI only needs their names and
+		// relays MCP traffic back over the control protocol
+		return map[string]interface{}{"type": "sdk", "name": name}, nil
+	case *types.MCPSDKServerConfig:
+		return map[string]interface{}{"type": "sdk", "name": name}, nil
+	case types.MCPStdioServerConfig:
+		if s.Type == "" {
+			s.Type = "stdio"
+		}
+		return s, nil
+	case *types.MCPStdioServerConfig:
+		return mcpServerJSON(name, *s)
+	case types.MCPSSEServerConfig:
+		s.Type = "sse"
+		return s, nil
+	case *types.MCPSSEServerConfig:
+		return mcpServerJSON(name, *s)
+	case types.MCPHTTPServerConfig:
+		s.Type = "http"
+		return s, nil
+	case *types.MCPHTTPServerConfig:
+		return mcpServerJSON(name, *s)
+	default:
+		return nil, fmt.Errorf("unsupported MCP server config for %q: %T", name, server)
+	}
+}
+
+// isSDKServer reports whether a server config is an in-process SDK server
+func isSDKServer(server types.MCPServerConfig) bool {
+	switch server.(type) {
+	case types.MCPSDKServerConfig, *types.MCPSDKServerConfig:
+		return true
+	default:
+		return false
+	}
+}
+
+// prepareMCPConfig serializes options.MCPServers into the --mcp-config value.
+// Small configs are passed inline; large ones are written to a temp file
+// that is removed by Close.
+func (t *SubprocessTransport) prepareMCPConfig() error {
+	t.mcpConfig = ""
+	if t.options == nil {
+		return nil
+	}
+
+	if t.options.MCPServersPath != nil {
+		t.mcpConfig = *t.options.MCPServersPath
+		return nil
+	}
+
+	if len(t.options.MCPServers) == 0 {
+		return nil
+	}
+
+	servers := make(map[string]interface{}, len(t.options.MCPServers))
+	for name, server := range t.options.MCPServers {
+		config, err := mcpServerJSON(name, server)
+		if err != nil {
+			return err
+		}
+		servers[name] = config
+	}
+
+	data, err := json.Marshal(map[string]interface{}{"mcpServers": servers})
+	if err != nil {
+		return fmt.Errorf("failed to serialize MCP servers: %w", err)
+	}
+
+	if len(data) <= maxInlineMCPConfig {
+		t.mcpConfig = string(data)
+		return nil
+	}
+
+	f, err := os.CreateTemp("", "claude-mcp-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to create MCP config file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(data); err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("failed to write MCP config file: %w", err)
+	}
+
+	t.mcpConfig = f.Name()
+	t.mcpConfigFile = f.Name()
+	return nil
+}
+
+// removeMCPConfigFile deletes the temp file written by prepareMCPConfig
+func (t *SubprocessTransport) removeMCPConfigFile() {
+	if t.mcpConfigFile != "" {
+		os.Remove(t.mcpConfigFile)
+		t.mcpConfigFile = ""
+	}
+}
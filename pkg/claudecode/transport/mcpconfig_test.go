@@ -0,0 +1,79 @@
+package transport
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+func TestPrepareMCPConfigInline(t *testing.T) {
+	options := &types.ClaudeCodeOptions{
+		MCPServers: map[string]types.MCPServerConfig{
+			"files":  types.MCPStdioServerConfig{Command: "mcp-files", Args: []string{"--root", "."}},
+			"search": types.MCPSSEServerConfig{URL: "https://example.com/sse"},
+			"docs":   &types.MCPHTTPServerConfig{URL: "https://example.com/mcp", Headers: map[string]string{"Authorization": "Bearer x"}},
+			"local":  types.MCPSDKServerConfig{Name: "local"},
+		},
+	}
+	tr := NewSubprocessTransport(nil, options, "claude")
+
+	if err := tr.prepareMCPConfig(); err != nil {
+		t.Fatalf("prepareMCPConfig: %v", err)
+	}
+	if tr.mcpConfigFile != "" {
+		t.Fatalf("small config should be inline, got file %s", tr.mcpConfigFile)
+	}
+
+	var config struct {
+		MCPServers map[string]map[string]interface{} `json:"mcpServers"`
+	}
+	if err := json.Unmarshal([]byte(tr.mcpConfig), &config); err != nil {
+		t.Fatalf("invalid JSON %q: %v", tr.mcpConfig, err)
+	}
+
+	want := map[string]string{"files": "stdio", "search": "sse", "docs": "http", "local": "sdk"}
+	for name, typ := range want {
+		if got := config.MCPServers[name]["type"]; got != typ {
+			t.Errorf("%s type = %v, want %s", name, got, typ)
+		}
+	}
+	if config.MCPServers["files"]["command"] != "mcp-files" {
+		t.Errorf("stdio command missing: %v", config.MCPServers["files"])
+	}
+
+	args := strings.Join(tr.buildCommandArgs(), " ")
+	if !strings.Contains(args, "--mcp-config "+tr.mcpConfig) {
+		t.Errorf("args missing --mcp-config: %s", args)
+	}
+}
+
+func TestPrepareMCPConfigTempFile(t *testing.T) {
+	options := &types.ClaudeCodeOptions{
+		MCPServers: map[string]types.MCPServerConfig{
+			"big": types.MCPStdioServerConfig{
+				Command: "mcp-big",
+				Env:     map[string]string{"PAYLOAD": strings.Repeat("x", maxInlineMCPConfig)},
+			},
+		},
+	}
+	tr := NewSubprocessTransport(nil, options, "claude")
+
+	if err := tr.prepareMCPConfig(); err != nil {
+		t.Fatalf("prepareMCPConfig: %v", err)
+	}
+	path := tr.mcpConfigFile
+	if path == "" || tr.mcpConfig != path {
+		t.Fatalf("large config should be written to a file, got %q", tr.mcpConfig)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("config file missing: %v", err)
+	}
+
+	tr.Close()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("config file not removed on Close: %v", err)
+	}
+}
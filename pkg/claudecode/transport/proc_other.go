@@ -0,0 +1,37 @@
+//go:build !unix && !windows
+
+package transport
+
+import (
+	"os"
+	"os/exec"
+)
+
+// processTree manages the CLI process. Platforms without process groups or
+// job objects can only stop the CLI itself.
+type processTree struct {
+	cmd *exec.Cmd
+}
+
+// newProcessTree configures cmd before it is started
+func newProcessTree(cmd *exec.Cmd) *processTree {
+	return &processTree{cmd: cmd}
+}
+
+// started is called once the CLI is running
+func (p *processTree) started() error {
+	return nil
+}
+
+// terminate asks the CLI to stop
+func (p *processTree) terminate() error {
+	return p.cmd.Process.Signal(os.Interrupt)
+}
+
+// kill ends the CLI
+func (p *processTree) kill() error {
+	return p.cmd.Process.Kill()
+}
+
+// release frees resources held for the process once it has exited
+func (p *processTree) release() {}
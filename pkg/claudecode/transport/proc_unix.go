@@ -0,0 +1,60 @@
+//go:build unix
+
+package transport
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// processTree manages the CLI and the node and MCP server processes it
+// starts. On Unix the CLI leads its own process group, which is signalled
+// as a whole.
+type processTree struct {
+	cmd *exec.Cmd
+}
+
+// newProcessTree configures cmd before it is started
+func newProcessTree(cmd *exec.Cmd) *processTree {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	// A session leader already leads its own group and may not change it
+	if !cmd.SysProcAttr.Setsid {
+		cmd.SysProcAttr.Setpgid = true
+		cmd.SysProcAttr.Pgid = 0
+	}
+
+	p := &processTree{cmd: cmd}
+	if cmd.Cancel != nil {
+		// Cancelling the context must not leave children behind either
+		cmd.Cancel = p.kill
+	}
+	return p
+}
+
+// started is called once the CLI is running
+func (p *processTree) started() error {
+	return nil
+}
+
+// terminate sends SIGTERM to the CLI's process group
+func (p *processTree) terminate() error {
+	return p.signal(syscall.SIGTERM)
+}
+
+// kill sends SIGKILL to the CLI's process group
+func (p *processTree) kill() error {
+	return p.signal(syscall.SIGKILL)
+}
+
+func (p *processTree) signal(sig syscall.Signal) error {
+	if err := syscall.Kill(-p.cmd.Process.Pid, sig); err != nil {
+		// The group is gone; make sure the CLI itself is too
+		return p.cmd.Process.Signal(sig)
+	}
+	return nil
+}
+
+// release frees resources held for the process once it has exited
+func (p *processTree) release() {}
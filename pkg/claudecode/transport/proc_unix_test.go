@@ -0,0 +1,70 @@
+//go:build unix
+
+package transport
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestProcessTreeKillsChildren(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		grace time.Duration
+	}{
+		{"kill", 0},
+		{"terminate", 50 * time.Millisecond},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r, w, err := os.Pipe()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+
+			// The shell ignores SIGTERM, so terminate has to escalate. Its
+			// background child inherits stdout, which only reaches EOF once
+			// both are gone.
+			cmd := exec.Command("sh", "-c", `trap '' TERM; echo started; sleep 30 & wait`)
+			cmd.Stdout = w
+			proc := newProcessTree(cmd)
+			if err := cmd.Start(); err != nil {
+				t.Fatal(err)
+			}
+			w.Close()
+			if err := proc.started(); err != nil {
+				t.Fatal(err)
+			}
+
+			started := make([]byte, len("started\n"))
+			if _, err := io.ReadFull(r, started); err != nil {
+				t.Fatalf("waiting for shell: %v", err)
+			}
+
+			exited := make(chan struct{})
+			go func() {
+				cmd.Wait()
+				proc.release()
+				close(exited)
+			}()
+
+			if err := stopProcess(proc, exited, tc.grace); err != nil {
+				t.Fatalf("stopProcess: %v", err)
+			}
+
+			eof := make(chan struct{})
+			go func() {
+				io.Copy(io.Discard, r)
+				close(eof)
+			}()
+			select {
+			case <-eof:
+			case <-time.After(5 * time.Second):
+				t.Fatal("child process survived")
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package transport
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	kernel32                     = syscall.NewLazyDLL("kernel32.dll")
+	procCreateJobObjectW         = kernel32.NewProc("CreateJobObjectW")
+	procSetInformationJobObject  = kernel32.NewProc("SetInformationJobObject")
+	procAssignProcessToJobObject = kernel32.NewProc("AssignProcessToJobObject")
+	procTerminateJobObject       = kernel32.NewProc("TerminateJobObject")
+)
+
+const (
+	createNewProcessGroup             = 0x00000200
+	jobObjectExtendedLimitInformation = 9
+	jobObjectLimitKillOnJobClose      = 0x00002000
+	processSetQuota                   = 0x0100
+	processTerminate                  = 0x0001
+)
+
+type jobObjectBasicLimitInformation struct {
+	PerProcessUserTimeLimit int64
+	PerJobUserTimeLimit     int64
+	LimitFlags              uint32
+	MinimumWorkingSetSize   uintptr
+	MaximumWorkingSetSize   uintptr
+	ActiveProcessLimit      uint32
+	Affinity                uintptr
+	PriorityClass           uint32
+	SchedulingClass         uint32
+}
+
+type ioCounters struct {
+	ReadOperationCount  uint64
+	WriteOperationCount uint64
+	OtherOperationCount uint64
+	ReadTransferCount   uint64
+	WriteTransferCount  uint64
+	OtherTransferCount  uint64
+}
+
+type jobObjectExtendedLimit struct {
+	BasicLimitInformation jobObjectBasicLimitInformation
+	IoInfo                ioCounters
+	ProcessMemoryLimit    uintptr
+	JobMemoryLimit        uintptr
+	PeakProcessMemoryUsed uintptr
+	PeakJobMemoryUsed     uintptr
+}
+
+// processTree manages the CLI and the node and MCP server processes it
+// starts. On Windows the CLI is placed in a job object that is terminated
+// as a whole and kills its members if the SDK itself exits.
+type processTree struct {
+	cmd *exec.Cmd
+	job syscall.Handle
+}
+
+// newProcessTree configures cmd before it is started
+func newProcessTree(cmd *exec.Cmd) *processTree {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.CreationFlags |= createNewProcessGroup
+	return &processTree{cmd: cmd}
+}
+
+// started places the running CLI in a job object. Without one, kill falls
+// back to taskkill.
+func (p *processTree) started() error {
+	job, _, err := procCreateJobObjectW.Call(0, 0)
+	if job == 0 {
+		return fmt.Errorf("CreateJobObject: %w", err)
+	}
+
+	info := jobObjectExtendedLimit{}
+	info.BasicLimitInformation.LimitFlags = jobObjectLimitKillOnJobClose
+	ok, _, err := procSetInformationJobObject.Call(job, jobObjectExtendedLimitInformation,
+		uintptr(unsafe.Pointer(&info)), unsafe.Sizeof(info))
+	if ok == 0 {
+		syscall.CloseHandle(syscall.Handle(job))
+		return fmt.Errorf("SetInformationJobObject: %w", err)
+	}
+
+	process, err := syscall.OpenProcess(processSetQuota|processTerminate, false, uint32(p.cmd.Process.Pid))
+	if err != nil {
+		syscall.CloseHandle(syscall.Handle(job))
+		return fmt.Errorf("OpenProcess: %w", err)
+	}
+	defer syscall.CloseHandle(process)
+
+	ok, _, err = procAssignProcessToJobObject.Call(job, uintptr(process))
+	if ok == 0 {
+		syscall.CloseHandle(syscall.Handle(job))
+		return fmt.Errorf("AssignProcessToJobObject: %w", err)
+	}
+
+	p.job = syscall.Handle(job)
+	return nil
+}
+
+// terminate asks the CLI to stop. Windows console processes cannot be sent
+// a termination request, so callers fall back to kill.
+func (p *processTree) terminate() error {
+	return syscall.EWINDOWS
+}
+
+// kill ends the CLI and every process it started
+func (p *processTree) kill() error {
+	if p.job != 0 {
+		if ok, _, _ := procTerminateJobObject.Call(uintptr(p.job), 1); ok != 0 {
+			return nil
+		}
+	}
+
+	// No job object; let taskkill walk the tree
+	err := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(p.cmd.Process.Pid)).Run()
+	if err != nil {
+		return p.cmd.Process.Kill()
+	}
+	return nil
+}
+
+// release frees the job object once the CLI has exited
+func (p *processTree) release() {
+	if p.job != 0 {
+		syscall.CloseHandle(p.job)
+		p.job = 0
+	}
+}
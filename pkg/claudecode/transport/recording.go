@@ -0,0 +1,215 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// Recording directions
+const (
+	RecordSend = "send"
+	RecordRecv = "recv"
+)
+
+// RecordedLine is one line of a recording, stored as a JSON object per line
+type RecordedLine struct {
+	// Direction is RecordSend for lines written to the CLI and RecordRecv
+	// for lines read from it
+	Direction string `json:"direction"`
+	// OffsetMS is the time since Connect in milliseconds
+	OffsetMS int64 `json:"offset_ms"`
+	// Line is the raw line without its trailing newline
+	Line string `json:"line"`
+}
+
+// RecordingTransport wraps a Transport and captures every line exchanged
+// with the CLI. Recordings can be played back with transporttest.ReplayTransport.
+//
+// Flush and GetExitError are forwarded to the wrapped transport when it
+// supports them. The constructors return a transport that also implements
+// GracefulCloser when the wrapped one does, so shutting down through a
+// recording ends the CLI's input as it would without it.
+type RecordingTransport struct {
+	inner  Transport
+	closer io.Closer
+
+	start time.Time
+	enc   *json.Encoder
+	mu    sync.Mutex
+}
+
+// gracefulRecordingTransport is a RecordingTransport whose wrapped
+// transport is a GracefulCloser
+type gracefulRecordingTransport struct {
+	*RecordingTransport
+	graceful GracefulCloser
+}
+
+// NewRecordingTransport records the traffic of inner to w
+func NewRecordingTransport(inner Transport, w io.Writer) Transport {
+	return wrapRecording(&RecordingTransport{
+		inner: inner,
+		enc:   json.NewEncoder(w),
+	})
+}
+
+// NewFileRecordingTransport records the traffic of inner to a file at path,
+// which is created or truncated. The file is closed by Close.
+func NewFileRecordingTransport(inner Transport, path string) (Transport, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return wrapRecording(&RecordingTransport{
+		inner:  inner,
+		closer: f,
+		enc:    json.NewEncoder(f),
+	}), nil
+}
+
+// wrapRecording adds the optional interfaces of the wrapped transport
+func wrapRecording(t *RecordingTransport) Transport {
+	if graceful, ok := t.inner.(GracefulCloser); ok {
+		return &gracefulRecordingTransport{RecordingTransport: t, graceful: graceful}
+	}
+	return t
+}
+
+// Connect connects the wrapped transport and starts the recording clock
+func (t *RecordingTransport) Connect(ctx context.Context) error {
+	t.mu.Lock()
+	t.start = time.Now()
+	t.mu.Unlock()
+
+	return t.inner.Connect(ctx)
+}
+
+// Close closes the wrapped transport and the recording file, if owned
+func (t *RecordingTransport) Close() error {
+	err := t.inner.Close()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closer != nil {
+		if closeErr := t.closer.Close(); err == nil {
+			err = closeErr
+		}
+		t.closer = nil
+	}
+	return err
+}
+
+// Write records each line of data and forwards it to the wrapped transport
+func (t *RecordingTransport) Write(ctx context.Context, data []byte) error {
+	for _, line := range bytes.Split(data, []byte{'\n'}) {
+		if len(bytes.TrimSpace(line)) > 0 {
+			t.record(RecordSend, line)
+		}
+	}
+	return t.inner.Write(ctx, data)
+}
+
+// Reader returns the wrapped reader, recording each line read from it
+func (t *RecordingTransport) Reader() io.Reader {
+	inner := t.inner.Reader()
+	if inner == nil {
+		return nil
+	}
+	return &recordingReader{t: t, r: inner}
+}
+
+// IsConnected returns true if the wrapped transport is connected
+func (t *RecordingTransport) IsConnected() bool {
+	return t.inner.IsConnected()
+}
+
+// SetLogger sets the logger of the wrapped transport
+func (t *RecordingTransport) SetLogger(logger *slog.Logger) {
+	t.inner.SetLogger(logger)
+}
+
+// Flush waits until the wrapped transport sent everything written before
+// the call, if it queues writes
+func (t *RecordingTransport) Flush(ctx context.Context) error {
+	if flusher, ok := t.inner.(Flusher); ok {
+		return flusher.Flush(ctx)
+	}
+	return nil
+}
+
+// GetExitError returns why the other side of the wrapped transport exited,
+// or nil if it cannot tell
+func (t *RecordingTransport) GetExitError() error {
+	if e, ok := t.inner.(interface{ GetExitError() error }); ok {
+		return e.GetExitError()
+	}
+	return nil
+}
+
+// CloseInput ends the input of the wrapped transport
+func (t *gracefulRecordingTransport) CloseInput() error {
+	return t.graceful.CloseInput()
+}
+
+// Exited returns the wrapped transport's exit channel
+func (t *gracefulRecordingTransport) Exited() <-chan struct{} {
+	return t.graceful.Exited()
+}
+
+// Terminate stops the other side of the wrapped transport
+func (t *gracefulRecordingTransport) Terminate(grace time.Duration) error {
+	return t.graceful.Terminate(grace)
+}
+
+// record appends a line to the recording. Recording errors are ignored so
+// that a full disk never breaks the conversation.
+func (t *RecordingTransport) record(direction string, line []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.enc.Encode(RecordedLine{
+		Direction: direction,
+		OffsetMS:  time.Since(t.start).Milliseconds(),
+		Line:      string(line),
+	})
+}
+
+// recordingReader records complete lines as they are read
+type recordingReader struct {
+	t       *RecordingTransport
+	r       io.Reader
+	partial []byte
+}
+
+// Read implements io.Reader
+func (r *recordingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+
+	r.partial = append(r.partial, p[:n]...)
+	for {
+		i := bytes.IndexByte(r.partial, '\n')
+		if i < 0 {
+			break
+		}
+		if line := bytes.TrimRight(r.partial[:i], "\r"); len(bytes.TrimSpace(line)) > 0 {
+			r.t.record(RecordRecv, line)
+		}
+		r.partial = r.partial[i+1:]
+	}
+
+	// A final line without a newline is still part of the conversation
+	if err != nil && len(bytes.TrimSpace(r.partial)) > 0 {
+		r.t.record(RecordRecv, r.partial)
+		r.partial = nil
+	}
+
+	return n, err
+}
@@ -0,0 +1,220 @@
+package transport_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/transporttest"
+)
+
+// readRecording decodes the lines of a recording
+func readRecording(t *testing.T, data []byte) []transport.RecordedLine {
+	t.Helper()
+
+	var lines []transport.RecordedLine
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for dec.More() {
+		var line transport.RecordedLine
+		if err := dec.Decode(&line); err != nil {
+			t.Fatalf("decode recording: %v", err)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestRecordingTransportRoundTrip(t *testing.T) {
+	mock := transporttest.NewMockTransport()
+	mock.SetAutoAck(false)
+	mock.Respond(transporttest.MatchType("user"),
+		map[string]interface{}{"type": "result", "session_id": "s1"},
+	)
+
+	var recording bytes.Buffer
+	tr := transport.NewRecordingTransport(mock, &recording)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := tr.Connect(ctx); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	reader := bufio.NewReader(tr.Reader())
+
+	if err := tr.Write(ctx, []byte(`{"type":"user","session_id":"s1"}`+"\n\n")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if _, err := reader.ReadString('\n'); err != nil {
+		t.Fatalf("ReadString: %v", err)
+	}
+	if err := tr.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	lines := readRecording(t, recording.Bytes())
+	want := []transport.RecordedLine{
+		{Direction: transport.RecordSend, Line: `{"type":"user","session_id":"s1"}`},
+		{Direction: transport.RecordRecv, Line: `{"session_id":"s1","type":"result"}`},
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("recorded %d lines, want %d: %v", len(lines), len(want), lines)
+	}
+	for i, line := range lines {
+		if line.Direction != want[i].Direction || line.Line != want[i].Line {
+			t.Errorf("line %d = %+v, want %+v", i, line, want[i])
+		}
+	}
+
+	// The recording plays back the same conversation
+	replay, err := transporttest.NewReplayTransport(bytes.NewReader(recording.Bytes()))
+	if err != nil {
+		t.Fatalf("NewReplayTransport: %v", err)
+	}
+	if err := replay.Connect(ctx); err != nil {
+		t.Fatalf("replay Connect: %v", err)
+	}
+	defer replay.Close()
+
+	if err := replay.Write(ctx, []byte(`{"type":"user","session_id":"s1"}`+"\n")); err != nil {
+		t.Fatalf("replay Write: %v", err)
+	}
+	line, err := bufio.NewReader(replay.Reader()).ReadString('\n')
+	if err != nil || strings.TrimSpace(line) != want[1].Line {
+		t.Errorf("replayed %q, %v, want %q", line, err, want[1].Line)
+	}
+}
+
+// chunkTransport serves a fixed output in small reads, without a trailing
+// newline
+type chunkTransport struct {
+	transporttest.MockTransport
+	out io.Reader
+}
+
+func (c *chunkTransport) Reader() io.Reader { return c.out }
+
+func TestRecordingTransportPartialLines(t *testing.T) {
+	inner := &chunkTransport{out: &oneByteReader{strings.NewReader("{\"a\":1}\r\n\n{\"b\":2}")}}
+
+	var recording bytes.Buffer
+	tr := transport.NewRecordingTransport(inner, &recording)
+
+	out, err := io.ReadAll(tr.Reader())
+	if err != nil || string(out) != "{\"a\":1}\r\n\n{\"b\":2}" {
+		t.Fatalf("ReadAll = %q, %v", out, err)
+	}
+
+	lines := readRecording(t, recording.Bytes())
+	if len(lines) != 2 || lines[0].Line != `{"a":1}` || lines[1].Line != `{"b":2}` {
+		t.Errorf("recorded %+v", lines)
+	}
+}
+
+type oneByteReader struct{ r io.Reader }
+
+func (o *oneByteReader) Read(p []byte) (int, error) {
+	if len(p) > 1 {
+		p = p[:1]
+	}
+	return o.r.Read(p)
+}
+
+func TestFileRecordingTransport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "conversation.jsonl")
+	tr, err := transport.NewFileRecordingTransport(transporttest.NewMockTransport(), path)
+	if err != nil {
+		t.Fatalf("NewFileRecordingTransport: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := tr.Connect(ctx); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	if err := tr.Write(ctx, []byte(`{"type":"user"}`+"\n")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := tr.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := readRecording(t, data); len(lines) != 1 || lines[0].Line != `{"type":"user"}` {
+		t.Errorf("recorded %+v", lines)
+	}
+
+	if _, err := transport.NewFileRecordingTransport(nil, filepath.Join(path, "missing", "x")); err == nil {
+		t.Error("expected an error for an unwritable path")
+	}
+}
+
+// gracefulTransport is a mock that supports an orderly shutdown
+type gracefulTransport struct {
+	*transporttest.MockTransport
+	calls  []string
+	exited chan struct{}
+}
+
+func (g *gracefulTransport) CloseInput() error {
+	g.calls = append(g.calls, "CloseInput")
+	return nil
+}
+
+func (g *gracefulTransport) Exited() <-chan struct{} { return g.exited }
+
+func (g *gracefulTransport) Terminate(grace time.Duration) error {
+	g.calls = append(g.calls, "Terminate")
+	return nil
+}
+
+func (g *gracefulTransport) Flush(ctx context.Context) error {
+	g.calls = append(g.calls, "Flush")
+	return nil
+}
+
+func (g *gracefulTransport) GetExitError() error { return errors.New("exit status 1") }
+
+func TestRecordingTransportForwards(t *testing.T) {
+	inner := &gracefulTransport{MockTransport: transporttest.NewMockTransport(), exited: make(chan struct{})}
+	tr := transport.NewRecordingTransport(inner, io.Discard)
+
+	graceful, ok := tr.(transport.GracefulCloser)
+	if !ok {
+		t.Fatal("expected a GracefulCloser when the wrapped transport is one")
+	}
+	graceful.CloseInput()
+	graceful.Terminate(time.Second)
+	if graceful.Exited() != inner.exited {
+		t.Error("Exited not forwarded")
+	}
+	tr.(transport.Flusher).Flush(context.Background())
+	if err := tr.(interface{ GetExitError() error }).GetExitError(); err == nil || err.Error() != "exit status 1" {
+		t.Errorf("GetExitError = %v", err)
+	}
+
+	want := []string{"CloseInput", "Terminate", "Flush"}
+	if strings.Join(inner.calls, ",") != strings.Join(want, ",") {
+		t.Errorf("calls = %v, want %v", inner.calls, want)
+	}
+
+	// Without support in the wrapped transport, none is claimed
+	plain := transport.NewRecordingTransport(transporttest.NewMockTransport(), io.Discard)
+	if _, ok := plain.(transport.GracefulCloser); ok {
+		t.Error("expected no GracefulCloser for a transport without one")
+	}
+	if err := plain.(transport.Flusher).Flush(context.Background()); err != nil {
+		t.Errorf("Flush = %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package transport
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"sort"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// sandboxEnv lists host variables always forwarded into a docker sandbox,
+// which unlike the other sandboxes does not inherit the environment
+var sandboxEnv = []string{"ANTHROPIC_API_KEY", "CLAUDE_CODE_ENTRYPOINT"}
+
+// sandboxMounts are the paths made visible inside a sandbox
+type sandboxMounts struct {
+	cwd      string
+	writable []string // cwd, AddDirs and WritablePaths
+	readOnly []string // Files the SDK hands to the CLI, e.g. the MCP config
+}
+
+// sandboxCommand wraps the CLI invocation in the configured sandbox
+func (t *SubprocessTransport) sandboxCommand(ctx context.Context, args []string) *exec.Cmd {
+	sandbox := t.options.ExecutionSandbox
+
+	mounts := sandboxMounts{cwd: t.cwd}
+	if mounts.cwd == "" {
+		mounts.cwd, _ = os.Getwd()
+	}
+	mounts.writable = append(mounts.writable, mounts.cwd)
+	mounts.writable = append(mounts.writable, t.options.AddDirs...)
+	mounts.writable = append(mounts.writable, sandbox.WritablePaths...)
+	if t.mcpConfigFile != "" {
+		mounts.readOnly = append(mounts.readOnly, t.mcpConfigFile)
+	}
+
+	var envNames []string
+	for name := range t.options.Env {
+		envNames = append(envNames, name)
+	}
+	sort.Strings(envNames)
+
+	argv := sandboxArgs(sandbox, t.cliPath, args, mounts, envNames)
+	return exec.CommandContext(ctx, argv[0], argv[1:]...)
+}
+
+// sandboxArgs builds the full command line running the CLI in sandbox
+func sandboxArgs(sandbox *types.ExecutionSandbox, cliPath string, args []string, mounts sandboxMounts, envNames []string) []string {
+	command := sandbox.Command
+	if command == "" {
+		command = string(sandbox.Kind)
+	}
+	argv := []string{command}
+
+	switch sandbox.Kind {
+	case types.SandboxBubblewrap:
+		argv = append(argv,
+			"--ro-bind", "/", "/",
+			"--dev", "/dev",
+			"--proc", "/proc",
+			"--tmpfs", "/tmp",
+			"--die-with-parent",
+		)
+		for _, path := range mounts.writable {
+			argv = append(argv, "--bind", path, path)
+		}
+		// Bound after the /tmp tmpfs, which would otherwise hide them
+		for _, path := range mounts.readOnly {
+			argv = append(argv, "--ro-bind", path, path)
+		}
+		argv = append(argv, "--chdir", mounts.cwd)
+		argv = append(argv, sandbox.Args...)
+		argv = append(argv, "--")
+
+	case types.SandboxFirejail:
+		argv = append(argv, "--quiet", "--read-only=/", "--read-write="+os.TempDir())
+		for _, path := range mounts.writable {
+			argv = append(argv, "--read-write="+path)
+		}
+		argv = append(argv, sandbox.Args...)
+
+	case types.SandboxDocker:
+		argv = append(argv, "run", "--rm", "-i", "--init", "-w", mounts.cwd)
+		for _, path := range mounts.writable {
+			argv = append(argv, "-v", path+":"+path)
+		}
+		for _, path := range mounts.readOnly {
+			argv = append(argv, "-v", path+":"+path+":ro")
+		}
+		for _, name := range append(append([]string(nil), sandboxEnv...), envNames...) {
+			argv = append(argv, "-e", name)
+		}
+		argv = append(argv, sandbox.Args...)
+		argv = append(argv, sandbox.Image)
+
+		// The CLI runs from the image, not the host
+		cliPath = sandbox.CLIPath
+		if cliPath == "" {
+			cliPath = "claude"
+		}
+	}
+
+	argv = append(argv, cliPath)
+	return append(argv, args...)
+}
@@ -0,0 +1,78 @@
+package transport
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+func TestSandboxArgs(t *testing.T) {
+	mounts := sandboxMounts{
+		cwd:      "/work",
+		writable: []string{"/work", "/data"},
+		readOnly: []string{"/tmp/mcp.json"},
+	}
+	cliArgs := []string{"--output-format", "stream-json"}
+
+	tests := []struct {
+		sandbox types.ExecutionSandbox
+		want    []string
+	}{
+		{
+			sandbox: types.ExecutionSandbox{Kind: types.SandboxBubblewrap, Args: []string{"--unshare-net"}},
+			want: []string{"bwrap", "--ro-bind", "/", "/", "--dev", "/dev", "--proc", "/proc", "--tmpfs", "/tmp",
+				"--die-with-parent", "--bind", "/work", "/work", "--bind", "/data", "/data",
+				"--ro-bind", "/tmp/mcp.json", "/tmp/mcp.json", "--chdir", "/work", "--unshare-net", "--",
+				"/usr/bin/claude", "--output-format", "stream-json"},
+		},
+		{
+			sandbox: types.ExecutionSandbox{Kind: types.SandboxFirejail, Command: "/usr/local/bin/firejail"},
+			want: []string{"/usr/local/bin/firejail", "--quiet", "--read-only=/", "--read-write=" + os.TempDir(),
+				"--read-write=/work", "--read-write=/data", "/usr/bin/claude", "--output-format", "stream-json"},
+		},
+		{
+			sandbox: types.ExecutionSandbox{Kind: types.SandboxDocker, Image: "claude:latest"},
+			want: []string{"docker", "run", "--rm", "-i", "--init", "-w", "/work",
+				"-v", "/work:/work", "-v", "/data:/data", "-v", "/tmp/mcp.json:/tmp/mcp.json:ro",
+				"-e", "ANTHROPIC_API_KEY", "-e", "CLAUDE_CODE_ENTRYPOINT", "-e", "DEBUG",
+				"claude:latest", "claude", "--output-format", "stream-json"},
+		},
+	}
+
+	for _, tt := range tests {
+		got := sandboxArgs(&tt.sandbox, "/usr/bin/claude", cliArgs, mounts, []string{"DEBUG"})
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s:\n got %q\nwant %q", tt.sandbox.Kind, got, tt.want)
+		}
+	}
+}
+
+func TestProbeCLIInSandbox(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	// The sandbox reports the version of the CLI it runs, whatever is at
+	// the host path
+	dir := t.TempDir()
+	firejail := filepath.Join(dir, "firejail")
+	script := "#!/bin/sh\nfor arg; do last=$arg; done\n[ \"$last\" = --version ] && echo \"1.0.80 (Claude Code)\"\n"
+	if err := os.WriteFile(firejail, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	options := types.NewOptions().WithCWD(dir)
+	options.ExecutionSandbox = &types.ExecutionSandbox{Kind: types.SandboxFirejail, Command: firejail}
+
+	tr := NewSubprocessTransport(nil, options, filepath.Join(dir, "missing"))
+	tr.mu.Lock()
+	info := tr.probeCLI(context.Background())
+	tr.mu.Unlock()
+	if info == nil || info.Version != (CLIVersion{1, 0, 80}) {
+		t.Errorf("probeCLI = %+v, want the sandboxed CLI", info)
+	}
+}
@@ -0,0 +1,64 @@
+package transport
+
+import (
+	"bufio"
+	"io"
+	"sync"
+)
+
+// defaultStderrBufferSize is how much CLI stderr output is retained
+const defaultStderrBufferSize = 64 * 1024
+
+// stderrBuffer keeps the most recent stderr output of the CLI
+type stderrBuffer struct {
+	buf  []byte
+	size int
+	mu   sync.Mutex
+}
+
+// newStderrBuffer creates a buffer retaining at most size bytes
+func newStderrBuffer(size int) *stderrBuffer {
+	if size <= 0 {
+		size = defaultStderrBufferSize
+	}
+	return &stderrBuffer{size: size}
+}
+
+// Write appends p, discarding the oldest output beyond the buffer size
+func (b *stderrBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.buf = append(b.buf, p...)
+	if overflow := len(b.buf) - b.size; overflow > 0 {
+		b.buf = append(b.buf[:0], b.buf[overflow:]...)
+	}
+	return len(p), nil
+}
+
+// String returns the retained output
+func (b *stderrBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return string(b.buf)
+}
+
+// drainStderr copies stderr line by line into the buffer and the optional
+// debug writer until the pipe is closed. Reading keeps the CLI from blocking
+// on a full stderr pipe.
+func drainStderr(stderr io.Reader, buffer *stderrBuffer, debug io.Writer) {
+	reader := bufio.NewReader(stderr)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			buffer.Write([]byte(line))
+			if debug != nil {
+				io.WriteString(debug, line)
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
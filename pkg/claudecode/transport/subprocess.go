@@ -3,15 +3,21 @@ package transport
 import (
 	"bufio"
 	"context"
+	"encoding/json"
+	stderrors "errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
 	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
@@ -19,6 +25,36 @@ import (
 
 const maxBufferSize = 1024 * 1024 * 16 // 16MB
 
+// connState is the lifecycle of a SubprocessTransport. Connect moves it from
+// Disconnected (or Closed, to start again) through Starting to Ready, and
+// Close through Closing to Closed.
+type connState int32
+
+const (
+	stateDisconnected connState = iota
+	stateStarting               // Process started, prompt not yet written
+	stateReady
+	stateClosing // Close is tearing the process down
+	stateClosed
+)
+
+func (s connState) String() string {
+	switch s {
+	case stateDisconnected:
+		return "disconnected"
+	case stateStarting:
+		return "starting"
+	case stateReady:
+		return "ready"
+	case stateClosing:
+		return "closing"
+	case stateClosed:
+		return "closed"
+	default:
+		return fmt.Sprintf("connState(%d)", int32(s))
+	}
+}
+
 // SubprocessTransport implements Transport using the Claude CLI subprocess
 type SubprocessTransport struct {
 	prompt  interface{} // string or channel for streaming
@@ -26,29 +62,58 @@ type SubprocessTransport struct {
 	cliPath string
 	cwd     string
 
+	// Detected CLI version and capabilities; nil if unknown. Probed once
+	// cliProbed is set.
+	cli       *CLIInfo
+	cliProbed bool
+
+	// --mcp-config value, and the temp file holding it if it was too large
+	// to pass inline
+	mcpConfig     string
+	mcpConfigFile string
+
 	cmd    *exec.Cmd
+	proc   *processTree // Kills the CLI along with the processes it starts
 	stdin  io.WriteCloser
+	writes *writeQueue // Serializes writes to stdin
 	stdout io.ReadCloser
 	stderr io.ReadCloser
 	reader *bufio.Reader
 
-	ready     bool
-	connected bool
+	// Captured stderr output, drained by a background goroutine
+	stderrBuf  *stderrBuffer
+	stderrDone chan struct{}
+
+	// Closed by monitorExit once the process has exited
+	exited chan struct{}
+
+	// Changed only while holding mu, but read without it
+	state atomic.Int32
+
 	exitError error
-	debug     bool
+	logger    *slog.Logger
 
 	mu sync.RWMutex
 }
 
-// NewSubprocessTransport creates a new subprocess transport
+// NewSubprocessTransport creates a new subprocess transport. An empty
+// cliPath uses options.CLIPath, then $CLAUDE_CODE_CLI_PATH, then searches for
+// the CLI.
 func NewSubprocessTransport(prompt interface{}, options *types.ClaudeCodeOptions, cliPath string) *SubprocessTransport {
+	if cliPath == "" && options != nil {
+		cliPath = options.CLIPath
+	}
 	if cliPath == "" {
 		cliPath = findCLI()
 	}
 
 	cwd := ""
-	if options != nil && options.CWD != nil {
-		cwd = *options.CWD
+	var logger *slog.Logger
+	if options != nil {
+		if options.CWD != nil {
+			cwd = *options.CWD
+		}
+		logger = options.Logger
 	}
 
 	return &SubprocessTransport{
@@ -56,54 +121,103 @@ func NewSubprocessTransport(prompt interface{}, options *types.ClaudeCodeOptions
 		options: options,
 		cliPath: cliPath,
 		cwd:     cwd,
+		logger:  loggerOrNop(logger),
 	}
 }
 
-// Connect establishes the connection to the CLI subprocess
+// Connect establishes the connection to the CLI subprocess. It does
+// nothing if the transport is already connected, and starts a new process
+// if it was closed.
 func (t *SubprocessTransport) Connect(ctx context.Context) error {
 	t.mu.Lock()
-	defer t.mu.Unlock()
-
-	if t.connected {
+	switch state := t.loadState(); state {
+	case stateDisconnected, stateClosed:
+	case stateReady:
+		running := t.running()
+		t.mu.Unlock()
+		if !running {
+			return errors.NewCLIConnectionError("CLI process exited; Close the transport before connecting again", nil)
+		}
 		return nil
+	default:
+		t.mu.Unlock()
+		return errors.NewCLIConnectionError(fmt.Sprintf("cannot connect while transport is %s", state), nil)
 	}
 
-	// Validate CLI path
-	if t.cliPath == "" {
-		return errors.NewCLINotFoundError(getCLINotFoundMessage())
+	t.setState(stateStarting)
+	err := t.start(ctx)
+	if err != nil {
+		t.setState(stateDisconnected)
+	}
+	t.mu.Unlock()
+	if err != nil {
+		return err
 	}
 
-	// Build command
-	args := t.buildCommandArgs()
-	t.cmd = exec.CommandContext(ctx, t.cliPath, args...)
+	// If we have a string prompt, write it immediately as a properly formatted message
+	if prompt, ok := t.prompt.(string); ok && prompt != "" {
+		// For non-streaming mode, we need to send the prompt as plain text
+		// The CLI expects the prompt directly when not in streaming mode
+		if err := t.Write(ctx, []byte(prompt+"\n")); err != nil {
+			t.Close()
+			return err
+		}
 
-	// Set working directory
-	if t.cwd != "" {
-		t.cmd.Dir = t.cwd
+		// The CLI may wait for EOF before it starts
+		if t.options == nil || !t.options.KeepStdinOpen {
+			t.CloseInput()
+		}
+	}
+
+	// Close may have run while the prompt was written
+	if !t.state.CompareAndSwap(int32(stateStarting), int32(stateReady)) {
+		return errors.NewCLIConnectionError("transport closed while connecting", nil)
 	}
+	return nil
+}
 
-	// Set environment
-	t.cmd.Env = os.Environ()
-	if t.options != nil && t.options.Env != nil {
-		for key, value := range t.options.Env {
-			t.cmd.Env = append(t.cmd.Env, fmt.Sprintf("%s=%s", key, value))
+// start starts the CLI process and the goroutines watching it. The caller
+// must hold t.mu.
+func (t *SubprocessTransport) start(ctx context.Context) error {
+	started := false
+	defer func() {
+		// Nothing will use the config file if the CLI never started
+		if !started {
+			t.removeMCPConfigFile()
 		}
+	}()
+
+	// A dry run starts nothing, not even `claude --version`
+	cmd, err := t.buildCommand(ctx, t.options == nil || !t.options.DryRun)
+	if err != nil {
+		return err
+	}
+
+	if t.options != nil && t.options.DryRun {
+		return errors.NewDryRunError(cmd.Args, cmd.Env, cmd.Dir)
 	}
+	t.cmd = cmd
+	t.logger.DebugContext(ctx, "starting CLI", "path", t.cmd.Path, "args", t.cmd.Args[1:], "cwd", t.cmd.Dir)
 
 	// Get pipes
-	var err error
 	t.stdin, err = t.cmd.StdinPipe()
 	if err != nil {
 		return errors.NewCLIConnectionError("failed to create stdin pipe", err)
 	}
 
-	t.stdout, err = t.cmd.StdoutPipe()
+	// A pipe of our own rather than StdoutPipe, which Wait closes as soon as
+	// the process exits and loses output not read yet
+	stdout, stdoutWriter, err := os.Pipe()
 	if err != nil {
 		return errors.NewCLIConnectionError("failed to create stdout pipe", err)
 	}
+	t.cmd.Stdout = stdoutWriter
+	t.stdout = stdout
 
 	t.stderr, err = t.cmd.StderrPipe()
 	if err != nil {
+		stdout.Close()
+		stdoutWriter.Close()
 		return errors.NewCLIConnectionError("failed to create stderr pipe", err)
 	}
 
@@ -111,63 +225,182 @@ func (t *SubprocessTransport) Connect(ctx context.Context) error {
 	t.reader = bufio.NewReaderSize(t.stdout, maxBufferSize)
 
 	// Start the process
-	if err := t.cmd.Start(); err != nil {
+	t.proc = newProcessTree(t.cmd)
+	err = t.cmd.Start()
+	// The CLI holds its own copy of the write end; reads see EOF once it exits
+	stdoutWriter.Close()
+	if err != nil {
+		stdout.Close()
+		t.logger.ErrorContext(ctx, "failed to start CLI", "path", t.cliPath, "error", err)
 		return errors.NewCLIConnectionError("failed to start CLI process", err)
 	}
+	started = true
+	startedAt := time.Now()
+	if err := t.proc.started(); err != nil {
+		t.logger.WarnContext(ctx, "CLI child processes may outlive Close", "error", err)
+	}
 
-	t.connected = true
+	var writeTimeout time.Duration
+	if t.options != nil {
+		writeTimeout = t.options.WriteTimeout
+	}
+	t.writes = newWriteQueue(t.stdin, writeTimeout)
+	version := "unknown"
+	if t.cli != nil {
+		version = t.cli.Version.String()
+	}
+	t.logger.InfoContext(ctx, "CLI started", "pid", t.cmd.Process.Pid, "version", version)
+
+	// Capture stderr so diagnostics are kept and the pipe never fills up
+	var debugStderr io.Writer
+	bufferSize := 0
+	if t.options != nil {
+		debugStderr = t.options.DebugStderr
+		bufferSize = t.options.StderrBufferSize
+	}
+	t.stderrBuf = newStderrBuffer(bufferSize)
+	t.stderrDone = make(chan struct{})
+	go func(stderr io.Reader, done chan struct{}) {
+		defer close(done)
+		drainStderr(stderr, t.stderrBuf, debugStderr)
+	}(t.stderr, t.stderrDone)
 
 	// Start monitoring process exit
-	go t.monitorExit()
+	t.exitError = nil
+	t.exited = make(chan struct{})
+	go t.monitorExit(t.cmd, t.proc, startedAt, t.stderrDone, t.exited)
 
-	// Unlock before writing to avoid deadlock
-	t.mu.Unlock()
+	return nil
+}
 
-	// If we have a string prompt, write it immediately as a properly formatted message
-	if prompt, ok := t.prompt.(string); ok && prompt != "" {
-		// For non-streaming mode, we need to send the prompt as plain text
-		// The CLI expects the prompt directly when not in streaming mode
-		if err := t.Write([]byte(prompt + "\n")); err != nil {
-			t.Close()
-			return err
+// CommandLine returns the command Connect would run, without starting it.
+// The CLI version is not checked, so options an older CLI would reject or
+// drop are included. Large MCP configurations are written to a temporary
+// file, which Close removes.
+func (t *SubprocessTransport) CommandLine(ctx context.Context) (*types.CommandLine, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	cmd, err := t.buildCommand(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	return &types.CommandLine{Args: cmd.Args, Env: cmd.Env, Dir: cmd.Dir}, nil
+}
+
+// buildCommand resolves the CLI, checks the options against its version and
+// builds the command that runs it. Without probe the CLI version is not
+// checked and no option is dropped. The caller must hold t.mu.
+func (t *SubprocessTransport) buildCommand(ctx context.Context, probe bool) (*exec.Cmd, error) {
+	// A docker sandbox runs the CLI installed in its image
+	inContainer := t.options != nil && t.options.ExecutionSandbox != nil &&
+		t.options.ExecutionSandbox.Kind == types.SandboxDocker
+
+	// Validate CLI path
+	if t.cliPath == "" && !inContainer {
+		return nil, errors.NewCLINotFoundError(getCLINotFoundMessage())
+	}
+
+	// Options that need a newer CLI than the one installed fail, unless
+	// their flags can be left out
+	if probe && !inContainer {
+		t.cli = t.probeCLI(ctx)
+		omitted, err := unsupportedFeatures(t.options, t.cli)
+		if err != nil {
+			return nil, err
+		}
+		for _, err := range omitted {
+			t.logger.WarnContext(ctx, "option ignored", "error", err)
 		}
 	}
 
-	// Re-lock to maintain the defer unlock behavior
-	t.mu.Lock()
+	if err := t.prepareMCPConfig(); err != nil {
+		return nil, errors.NewCLIConnectionError("invalid MCP server configuration", err)
+	}
 
-	return nil
+	// Build command
+	var cmd *exec.Cmd
+	args := t.buildCommandArgs()
+	if t.options != nil && t.options.CommandFactory != nil {
+		cmd = t.options.CommandFactory(ctx, t.cliPath, args)
+		if cmd == nil {
+			return nil, errors.NewCLIConnectionError("command factory returned no command", nil)
+		}
+	} else if t.options != nil && t.options.ExecutionSandbox != nil {
+		cmd = t.sandboxCommand(ctx, args)
+	} else {
+		cmd = exec.CommandContext(ctx, t.cliPath, args...)
+	}
+
+	// Set working directory
+	if t.cwd != "" && cmd.Dir == "" {
+		cmd.Dir = t.cwd
+	}
+
+	// Set environment, sorted so the command line is reproducible
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	if t.options != nil {
+		keys := make([]string, 0, len(t.options.Env))
+		for key := range t.options.Env {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", key, t.options.Env[key]))
+		}
+	}
+
+	return cmd, nil
 }
 
-// Close terminates the connection
+// Close terminates the connection. It may be called from any state and any
+// number of times; only the first call on a started transport stops the CLI.
 func (t *SubprocessTransport) Close() error {
 	t.mu.Lock()
-	
-	if !t.connected {
+
+	// The CLI reads its MCP config at startup, so the file can go even if
+	// the process already exited
+	t.removeMCPConfigFile()
+
+	switch t.loadState() {
+	case stateStarting, stateReady:
+	default:
 		t.mu.Unlock()
 		return nil
 	}
+	t.setState(stateClosing)
 
-	t.connected = false
-	
 	// Get references while holding lock
 	stdin := t.stdin
+	writes := t.writes
 	stdout := t.stdout
 	stderr := t.stderr
 	cmd := t.cmd
+	proc := t.proc
+	exited := t.exited
+	var grace time.Duration
+	if t.options != nil {
+		grace = t.options.TerminateGracePeriod
+	}
 	
 	// Clear references
 	t.stdin = nil
 	t.stdout = nil
 	t.stderr = nil
 	t.cmd = nil
-	
+
 	t.mu.Unlock()
 
-	// Close pipes without holding lock
+	// Close pipes without holding lock. Closing stdin first fails a write
+	// blocked on a CLI that stopped reading, so the queue can stop.
 	if stdin != nil {
 		stdin.Close()
 	}
+	if writes != nil {
+		writes.stop()
+	}
 	if stdout != nil {
 		stdout.Close()
 	}
@@ -177,17 +410,132 @@ func (t *SubprocessTransport) Close() error {
 
 	// Kill the process if it's still running
 	if cmd != nil && cmd.Process != nil {
-		cmd.Process.Kill()
-		cmd.Wait()
+		t.logger.Debug("stopping CLI", "pid", cmd.Process.Pid, "grace", grace)
+		stopProcess(proc, exited, grace)
+
+		// monitorExit owns cmd.Wait; a second concurrent Wait can block forever
+		if exited != nil {
+			<-exited
+		}
 	}
 
+	t.mu.Lock()
+	t.setState(stateClosed)
+	t.mu.Unlock()
+
 	return nil
 }
 
-// Write sends data to the subprocess
-func (t *SubprocessTransport) Write(data []byte) error {
+// CloseInput closes stdin so the CLI finishes its current work and exits.
+// Lines already queued by Write are written first.
+func (t *SubprocessTransport) CloseInput() error {
+	t.mu.Lock()
+	stdin := t.stdin
+	writes := t.writes
+	t.stdin = nil
+	t.mu.Unlock()
+
+	if stdin == nil {
+		return nil
+	}
+	if err := writes.close(); err != nil && err != errQueueClosed {
+		return err
+	}
+	return nil
+}
+
+// Flush waits until every line passed to Write before it has been written
+// to the CLI's stdin
+func (t *SubprocessTransport) Flush(ctx context.Context) error {
+	t.mu.RLock()
+	writes := t.writes
+	t.mu.RUnlock()
+
+	if writes == nil {
+		return nil
+	}
+	err := writes.flush(ctx)
+	if err == errQueueClosed {
+		return nil
+	}
+	return err
+}
+
+// Exited returns a channel that is closed once the CLI process has exited
+func (t *SubprocessTransport) Exited() <-chan struct{} {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if t.exited == nil {
+		// Never started; nothing to wait for
+		exited := make(chan struct{})
+		close(exited)
+		return exited
+	}
+	return t.exited
+}
+
+// Terminate sends SIGTERM to the CLI's process group and kills the whole
+// process tree if it has not exited after grace. Platforms without SIGTERM
+// are killed right away.
+func (t *SubprocessTransport) Terminate(grace time.Duration) error {
+	t.mu.RLock()
+	cmd := t.cmd
+	proc := t.proc
+	exited := t.exited
+	t.mu.RUnlock()
+
+	if cmd == nil || cmd.Process == nil || exited == nil {
+		return nil
+	}
+
+	t.logger.Info("terminating CLI", "pid", cmd.Process.Pid, "grace", grace)
+	return stopProcess(proc, exited, grace)
+}
+
+// stopProcess terminates proc, escalating to a kill if it has not exited
+// after grace. A zero grace kills right away.
+func stopProcess(proc *processTree, exited <-chan struct{}, grace time.Duration) error {
+	if grace <= 0 {
+		return proc.kill()
+	}
+
+	if err := proc.terminate(); err != nil {
+		// Already gone, or signals are unsupported
+		select {
+		case <-exited:
+			return nil
+		default:
+			return proc.kill()
+		}
+	}
+
+	select {
+	case <-exited:
+		// The CLI is gone, but children that ignored SIGTERM may not be
+		proc.kill()
+		return nil
+	case <-time.After(grace):
+		return proc.kill()
+	}
+}
+
+// Write sends data to the subprocess as one line. Concurrent writes are
+// queued and written whole in the order they were made; Write returns once
+// its line has been written. If ctx is done before the line is started it
+// is dropped; a line cut short by ctx or WriteTimeout leaves the stream
+// unusable, so every later write fails.
+func (t *SubprocessTransport) Write(ctx context.Context, data []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	t.mu.RLock()
-	if !t.connected {
+	switch state := t.loadState(); {
+	case state == stateClosing || state == stateClosed:
+		t.mu.RUnlock()
+		return errors.NewCLIConnectionError("transport closed", nil)
+	case state == stateDisconnected || !t.running():
 		t.mu.RUnlock()
 		return errors.NewCLIConnectionError("transport not connected", nil)
 	}
@@ -197,17 +545,31 @@ func (t *SubprocessTransport) Write(data []byte) error {
 		return errors.NewCLIConnectionError("stdin not available", nil)
 	}
 
-	// Get stdin reference while holding the lock
-	stdin := t.stdin
+	// Get the queue while holding the lock
+	writes := t.writes
+	logger := t.logger
 	t.mu.RUnlock()
 
-	// Write without holding the lock to avoid deadlocks
-	_, err := stdin.Write(data)
-	if err != nil {
+	logSent(ctx, logger, data)
+
+	err := writes.write(ctx, data)
+	switch {
+	case err == nil:
+		return nil
+	case err == errQueueClosed:
+		return errors.NewCLIConnectionError("stdin not available", nil)
+	case err == ctx.Err():
+		return err
+	case stderrors.Is(err, os.ErrDeadlineExceeded):
+		return errors.NewCLIConnectionError("timed out writing to stdin", err)
+	default:
 		return errors.NewCLIConnectionError("failed to write to stdin", err)
 	}
+}
 
-	return nil
+// writeDeadliner is implemented by pipes that support write deadlines
+type writeDeadliner interface {
+	SetWriteDeadline(t time.Time) error
 }
 
 // Reader returns the stdout reader
@@ -219,18 +581,57 @@ func (t *SubprocessTransport) Reader() io.Reader {
 	return reader
 }
 
-// IsConnected returns true if connected
+// IsConnected returns true once Connect has returned and until Close is
+// called or the CLI exits
 func (t *SubprocessTransport) IsConnected() bool {
+	if t.loadState() != stateReady {
+		return false
+	}
+
 	t.mu.RLock()
 	defer t.mu.RUnlock()
+	return t.running()
+}
 
-	return t.connected
+// loadState returns the current connection state
+func (t *SubprocessTransport) loadState() connState {
+	return connState(t.state.Load())
+}
+
+// setState moves to state. The caller must hold t.mu.
+func (t *SubprocessTransport) setState(state connState) {
+	t.state.Store(int32(state))
+}
+
+// running reports whether the CLI was started and has not exited. The
+// caller must hold t.mu.
+func (t *SubprocessTransport) running() bool {
+	if t.exited == nil {
+		return false
+	}
+	select {
+	case <-t.exited:
+		return false
+	default:
+		return true
+	}
 }
 
-// SetDebug enables/disables debug logging
-func (t *SubprocessTransport) SetDebug(debug bool) {
+// PID returns the process ID of the CLI, or 0 if it has not been started
+func (t *SubprocessTransport) PID() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if t.cmd == nil || t.cmd.Process == nil {
+		return 0
+	}
+	return t.cmd.Process.Pid
+}
+
+// SetLogger sets the logger for process lifecycle events and sent lines
+func (t *SubprocessTransport) SetLogger(logger *slog.Logger) {
 	t.mu.Lock()
-	t.debug = debug
+	t.logger = loggerOrNop(logger)
 	t.mu.Unlock()
 }
 
@@ -241,9 +642,60 @@ func (t *SubprocessTransport) GetExitError() error {
 	return t.exitError
 }
 
+// Stderr returns the most recent stderr output of the CLI process
+func (t *SubprocessTransport) Stderr() string {
+	t.mu.RLock()
+	buffer := t.stderrBuf
+	t.mu.RUnlock()
+
+	if buffer == nil {
+		return ""
+	}
+	return buffer.String()
+}
+
+// probeCLI detects the version of the CLI, running `claude --version` the
+// way the CLI itself is run. Returns nil when it could not be determined.
+// The caller must hold t.mu.
+func (t *SubprocessTransport) probeCLI(ctx context.Context) *CLIInfo {
+	if t.options == nil || (t.options.CommandFactory == nil && t.options.ExecutionSandbox == nil) {
+		return cachedCLIInfo(ctx, t.cliPath)
+	}
+	if t.cliProbed {
+		return t.cli
+	}
+
+	info, err := detectCLI(ctx, t.cliPath, func(ctx context.Context) *exec.Cmd {
+		if t.options.CommandFactory != nil {
+			return t.options.CommandFactory(ctx, t.cliPath, []string{"--version"})
+		}
+		// The sandbox may hide or replace the CLI found on the host
+		return t.sandboxCommand(ctx, []string{"--version"})
+	})
+	if err != nil {
+		// A cancelled Connect says nothing about the CLI; try again next time
+		if ctx.Err() != nil {
+			return nil
+		}
+		info = nil
+	}
+	t.cliProbed = true
+	return info
+}
+
 // buildCommandArgs builds the CLI command arguments
 func (t *SubprocessTransport) buildCommandArgs() []string {
 	args := []string{"--print", "--output-format", "stream-json", "--verbose"}
+	if t.options != nil && t.options.OutputFormat == types.OutputFormatJSON {
+		// Only the result, printed once the query is done
+		args = []string{"--print", "--output-format", "json"}
+	}
+
+	// Anything but a string prompt streams user messages and control
+	// requests as JSON lines on stdin
+	if _, ok := t.prompt.(string); !ok {
+		args = append(args, "--input-format", "stream-json")
+	}
 
 	if t.options == nil {
 		return args
@@ -273,6 +725,14 @@ func (t *SubprocessTransport) buildCommandArgs() []string {
 		args = append(args, "--model", *t.options.Model)
 	}
 
+	if t.options.FallbackModel != nil {
+		args = append(args, "--fallback-model", *t.options.FallbackModel)
+	}
+
+	if tokens, ok := t.options.ThinkingTokens(); ok {
+		args = append(args, "--max-thinking-tokens", strconv.Itoa(tokens))
+	}
+
 	if t.options.PermissionMode != nil {
 		args = append(args, "--permission-mode", string(*t.options.PermissionMode))
 	}
@@ -284,6 +744,10 @@ func (t *SubprocessTransport) buildCommandArgs() []string {
 		}
 	}
 
+	if t.options.SessionID != nil {
+		args = append(args, "--session-id", *t.options.SessionID)
+	}
+
 	if t.options.ContinueConversation {
 		args = append(args, "--continue-conversation")
 	}
@@ -292,29 +756,30 @@ func (t *SubprocessTransport) buildCommandArgs() []string {
 		args = append(args, "--settings", *t.options.Settings)
 	}
 
+	if t.options.SettingSources != nil {
+		sources := make([]string, len(t.options.SettingSources))
+		for i, source := range t.options.SettingSources {
+			sources[i] = string(source)
+		}
+		args = append(args, "--setting-sources", strings.Join(sources, ","))
+	}
+
 	if t.options.User != nil {
 		args = append(args, "--user", *t.options.User)
 	}
 
-	// MCP servers
-	if t.options.MCPServersPath != nil {
-		args = append(args, "--mcp-servers", *t.options.MCPServersPath)
-	} else if len(t.options.MCPServers) > 0 {
-		// For non-file MCP servers, we'll need to handle them differently
-		// This might require writing to a temp file or passing as JSON
-		// For now, skip SDK servers as they can't be passed via CLI
-		hasNonSDKServers := false
-		for _, server := range t.options.MCPServers {
-			if _, ok := server.(types.MCPSDKServerConfig); !ok {
-				hasNonSDKServers = true
-				break
-			}
-		}
-		if hasNonSDKServers {
-			// TODO: Implement JSON serialization of MCP servers
+	// Subagents, as a JSON object keyed by name
+	if len(t.options.Agents) > 0 {
+		if agents, err := json.Marshal(t.options.Agents); err == nil {
+			args = append(args, "--agents", string(agents))
 		}
 	}
 
+	// MCP servers, serialized by prepareMCPConfig
+	if t.mcpConfig != "" {
+		args = append(args, "--mcp-config", t.mcpConfig)
+	}
+
 	// Add directories
 	for _, dir := range t.options.AddDirs {
 		args = append(args, "--add-dir", dir)
@@ -330,19 +795,23 @@ func (t *SubprocessTransport) buildCommandArgs() []string {
 		args = append(args, "--include-partial-messages")
 	}
 
-	// Extra args
-	if t.options.ExtraArgs != nil {
-		for key, value := range t.options.ExtraArgs {
-			if value != nil {
-				args = append(args, key, *value)
-			} else {
-				args = append(args, key)
-			}
-		}
+	// Extra args, sorted so the command line is reproducible
+	names := make([]string, 0, len(t.options.ExtraArgs))
+	for name := range t.options.ExtraArgs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		args = append(args, types.CLIFlag{Name: name, Value: t.options.ExtraArgs[name]}.Args()...)
 	}
 
-	// Debug to stderr
-	if t.options.DebugStderr != nil {
+	for _, flag := range t.options.ExtraFlags {
+		args = append(args, flag.Args()...)
+	}
+
+	// Debug to stderr; debug output is best effort, so the flag is simply
+	// omitted for CLIs that don't know it
+	if t.options.DebugStderr != nil && t.cli.Supports(FeatureDebugToStderr) {
 		args = append(args, "--debug-to-stderr")
 	}
 
@@ -350,23 +819,57 @@ func (t *SubprocessTransport) buildCommandArgs() []string {
 }
 
 // monitorExit monitors the subprocess for exit
-func (t *SubprocessTransport) monitorExit() {
-	err := t.cmd.Wait()
+func (t *SubprocessTransport) monitorExit(cmd *exec.Cmd, proc *processTree, startedAt time.Time, stderrDone <-chan struct{}, exited chan struct{}) {
+	defer close(exited)
+
+	// Wait must not be called before all stderr output has been read
+	<-stderrDone
+	err := cmd.Wait()
+	elapsed := time.Since(startedAt)
+	proc.release()
 
 	t.mu.Lock()
 	if err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
-			t.exitError = errors.NewProcessError("CLI process exited", exitErr.ExitCode(), string(exitErr.Stderr))
+			t.exitError = &errors.ProcessError{
+				Message:  "CLI process exited",
+				ExitCode: exitErr.ExitCode(),
+				Stderr:   t.stderrBuf.String(),
+				Args:     cmd.Args,
+				Dir:      cmd.Dir,
+				Elapsed:  elapsed,
+			}
 		} else {
 			t.exitError = errors.NewCLIConnectionError("CLI process error", err)
 		}
 	}
-	t.connected = false
+	logger := t.logger
 	t.mu.Unlock()
+
+	if err != nil {
+		logger.Warn("CLI exited", "pid", cmd.Process.Pid, "exit_code", cmd.ProcessState.ExitCode(), "error", err)
+	} else {
+		logger.Info("CLI exited", "pid", cmd.Process.Pid, "exit_code", 0)
+	}
+}
+
+// FindCLI locates the Claude CLI binary named by $CLAUDE_CODE_CLI_PATH, on
+// PATH or in common install locations. Resolve it once and pass it to
+// NewSubprocessTransport when starting many transports.
+func FindCLI() (string, error) {
+	path := findCLI()
+	if path == "" {
+		return "", errors.NewCLINotFoundError(getCLINotFoundMessage())
+	}
+	return path, nil
 }
 
 // findCLI attempts to find the Claude CLI binary
 func findCLI() string {
+	if path := os.Getenv(types.CLIPathEnv); path != "" {
+		return path
+	}
+
 	// Check PATH
 	if path, err := exec.LookPath("claude"); err == nil {
 		return path
@@ -0,0 +1,303 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"os/exec"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+func TestCLIPathResolution(t *testing.T) {
+	t.Setenv(types.CLIPathEnv, "/opt/claude/bin/claude")
+
+	if got := NewSubprocessTransport(nil, nil, "").cliPath; got != "/opt/claude/bin/claude" {
+		t.Errorf("env path not used: %q", got)
+	}
+
+	options := types.NewOptions().WithCLIPath("/usr/bin/claude-beta")
+	if got := NewSubprocessTransport(nil, options, "").cliPath; got != "/usr/bin/claude-beta" {
+		t.Errorf("CLIPath option not used: %q", got)
+	}
+	if got := NewSubprocessTransport(nil, options, "/explicit").cliPath; got != "/explicit" {
+		t.Errorf("explicit path not used: %q", got)
+	}
+}
+
+func TestCommandFactory(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	var gotPath string
+	var gotArgs []string
+	options := types.NewOptions().
+		WithCLIPath("/nonexistent/claude").
+		WithCommandFactory(func(ctx context.Context, path string, args []string) *exec.Cmd {
+			gotPath, gotArgs = path, args
+			return exec.CommandContext(ctx, "sh", "-c", `echo '{"type":"ready"}'; cat >/dev/null`)
+		})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tr := NewSubprocessTransport(nil, options, "")
+	if err := tr.Connect(ctx); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer tr.Close()
+
+	if gotPath != "/nonexistent/claude" || !strings.Contains(strings.Join(gotArgs, " "), "--output-format stream-json") {
+		t.Errorf("factory called with %q %v", gotPath, gotArgs)
+	}
+
+	line, err := bufio.NewReader(tr.Reader()).ReadString('\n')
+	if err != nil || strings.TrimSpace(line) != `{"type":"ready"}` {
+		t.Errorf("read %q, %v from wrapped command", line, err)
+	}
+}
+
+func TestProcessErrorDetails(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	dir := t.TempDir()
+	options := types.NewOptions().
+		WithCLIPath("/nonexistent/claude").
+		WithCWD(dir).
+		WithCommandFactory(func(ctx context.Context, path string, args []string) *exec.Cmd {
+			return exec.CommandContext(ctx, "sh", "-c", `echo "error: unknown option '--bogus'" >&2; exit 2`, "claude")
+		})
+
+	tr := NewSubprocessTransport(nil, options, "")
+	if err := tr.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer tr.Close()
+	<-tr.Exited()
+
+	var procErr *errors.ProcessError
+	if !stderrors.As(tr.GetExitError(), &procErr) {
+		t.Fatalf("expected a ProcessError, got %v", tr.GetExitError())
+	}
+	if procErr.ExitCode != 2 || procErr.Dir != dir || procErr.Elapsed <= 0 || procErr.Args[0] != "sh" {
+		t.Errorf("unexpected process error: %+v", procErr)
+	}
+	msg := procErr.Error()
+	if !strings.Contains(msg, "unknown option '--bogus'") || !strings.Contains(msg, "dir: "+dir) {
+		t.Errorf("error lacks details: %s", msg)
+	}
+}
+
+func TestAgentsArg(t *testing.T) {
+	options := types.NewOptions().WithAgent("reviewer", types.AgentDefinition{
+		Description: "Reviews diffs for bugs",
+		Prompt:      "You are a careful code reviewer.",
+		Tools:       []string{"Read", "Grep"},
+		Model:       types.AgentModelHaiku,
+	})
+	args := NewSubprocessTransport(nil, options, "claude").buildCommandArgs()
+
+	for i, arg := range args {
+		if arg != "--agents" || i+1 == len(args) {
+			continue
+		}
+		var agents map[string]types.AgentDefinition
+		if err := json.Unmarshal([]byte(args[i+1]), &agents); err != nil {
+			t.Fatalf("invalid --agents JSON %q: %v", args[i+1], err)
+		}
+		if !reflect.DeepEqual(agents, options.Agents) {
+			t.Errorf("agents = %+v, want %+v", agents, options.Agents)
+		}
+		return
+	}
+	t.Errorf("args missing --agents: %v", args)
+}
+
+func TestSettingSourcesArg(t *testing.T) {
+	sourcesArg := func(options *types.ClaudeCodeOptions) (string, bool) {
+		args := NewSubprocessTransport(nil, options, "claude").buildCommandArgs()
+		i := slices.Index(args, "--setting-sources")
+		if i < 0 {
+			return "", false
+		}
+		return args[i+1], true
+	}
+
+	if _, ok := sourcesArg(types.NewOptions()); ok {
+		t.Error("expected no --setting-sources by default")
+	}
+	if got, _ := sourcesArg(types.NewOptions().WithSettingSources(types.SettingSourceProject, types.SettingSourceLocal)); got != "project,local" {
+		t.Errorf("--setting-sources = %q, want project,local", got)
+	}
+	// Hermetic: no settings files at all
+	if got, ok := sourcesArg(types.NewOptions().WithSettingSources()); !ok || got != "" {
+		t.Errorf("--setting-sources = %q, %v, want an empty value", got, ok)
+	}
+}
+
+func TestExtraFlagsOrder(t *testing.T) {
+	debug := "api"
+	options := &types.ClaudeCodeOptions{
+		ExtraArgs: map[string]*string{"verbose-tools": nil, "--debug": &debug},
+		ExtraFlags: []types.CLIFlag{
+			types.FlagWithValue("betas", "a"),
+			types.Flag("-x"),
+			types.FlagWithValue("--betas", "b"),
+		},
+	}
+	args := NewSubprocessTransport(nil, options, "claude").buildCommandArgs()
+
+	want := "--debug api --verbose-tools --betas a -x --betas b"
+	if got := strings.Join(args, " "); !strings.HasSuffix(got, want) {
+		t.Errorf("args = %s, want suffix %s", got, want)
+	}
+}
+
+func TestCommandLineAndDryRun(t *testing.T) {
+	// Neither runs anything, not even `claude --version`
+	probed := false
+	options := types.NewOptions().
+		WithCLIPath("/nonexistent/claude").
+		WithSystemPrompt("Be brief, don't ramble").
+		WithCWD("/work").
+		WithEnv("B_VAR", "2").
+		WithEnv("A_VAR", "1").
+		WithCommandFactory(func(ctx context.Context, path string, args []string) *exec.Cmd {
+			probed = probed || slices.Contains(args, "--version")
+			return exec.CommandContext(ctx, path, args...)
+		})
+
+	tr := NewSubprocessTransport(nil, options, "")
+	cmdline, err := tr.CommandLine(context.Background())
+	if err != nil {
+		t.Fatalf("CommandLine: %v", err)
+	}
+	if cmdline.Args[0] != "/nonexistent/claude" || cmdline.Dir != "/work" {
+		t.Errorf("unexpected command line: %+v", cmdline)
+	}
+	if env := cmdline.Env[len(cmdline.Env)-2:]; env[0] != "A_VAR=1" || env[1] != "B_VAR=2" {
+		t.Errorf("options env not appended in order: %v", env)
+	}
+	if !strings.Contains(cmdline.String(), `--system-prompt 'Be brief, don'\''t ramble'`) {
+		t.Errorf("unexpected quoting: %s", cmdline)
+	}
+
+	err = NewSubprocessTransport(nil, options.WithDryRun(), "").Connect(context.Background())
+	var dryRun *errors.DryRunError
+	if !stderrors.As(err, &dryRun) || !stderrors.Is(err, errors.ErrDryRun) {
+		t.Fatalf("expected a DryRunError, got %v", err)
+	}
+	if !reflect.DeepEqual(dryRun.Args, cmdline.Args) || dryRun.Dir != "/work" {
+		t.Errorf("dry run command differs: %+v", dryRun)
+	}
+	if probed {
+		t.Error("CLI version probed without starting the CLI")
+	}
+}
+
+func TestConnectionLifecycle(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	options := types.NewOptions().
+		WithCLIPath("/nonexistent/claude").
+		WithCommandFactory(func(ctx context.Context, path string, args []string) *exec.Cmd {
+			return exec.CommandContext(ctx, "sh", "-c", `cat >/dev/null`)
+		})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tr := NewSubprocessTransport(nil, options, "")
+	if err := tr.Write(ctx, []byte("{}")); err == nil {
+		t.Error("Write before Connect succeeded")
+	}
+
+	// A string prompt is written while starting, racing with Close
+	for i := 0; i < 20; i++ {
+		starting := NewSubprocessTransport("Hello", options, "")
+		go starting.Close()
+		if err := starting.Connect(ctx); err == nil && !starting.IsConnected() {
+			t.Error("Connect succeeded but transport is not connected")
+		}
+		starting.Close()
+		if starting.IsConnected() {
+			t.Error("connected after Close")
+		}
+	}
+
+	for round := 0; round < 2; round++ {
+		if err := tr.Connect(ctx); err != nil {
+			t.Fatalf("Connect (round %d): %v", round, err)
+		}
+		if !tr.IsConnected() || tr.loadState() != stateReady {
+			t.Fatalf("state after Connect = %s", tr.loadState())
+		}
+		if err := tr.Connect(ctx); err != nil {
+			t.Errorf("second Connect: %v", err)
+		}
+		if err := tr.Write(ctx, []byte("{}")); err != nil {
+			t.Errorf("Write: %v", err)
+		}
+
+		// Writes racing with Close either succeed or fail cleanly
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 50; i++ {
+				tr.Write(ctx, []byte("{}"))
+			}
+		}()
+		tr.Close()
+		tr.Close()
+		<-done
+
+		if tr.IsConnected() || tr.loadState() != stateClosed {
+			t.Fatalf("state after Close = %s", tr.loadState())
+		}
+		if err := tr.Write(ctx, []byte("{}")); err == nil || !strings.Contains(err.Error(), "transport closed") {
+			t.Errorf("Write after Close = %v", err)
+		}
+	}
+}
+
+func TestIsConnectedAfterExit(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+
+	options := types.NewOptions().
+		WithCLIPath("/nonexistent/claude").
+		WithCommandFactory(func(ctx context.Context, path string, args []string) *exec.Cmd {
+			return exec.CommandContext(ctx, "sh", "-c", `exit 0`)
+		})
+
+	tr := NewSubprocessTransport(nil, options, "")
+	if err := tr.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer tr.Close()
+	<-tr.Exited()
+
+	if tr.IsConnected() {
+		t.Error("connected after the CLI exited")
+	}
+	if err := tr.Write(context.Background(), []byte("{}")); err == nil {
+		t.Error("Write to an exited CLI succeeded")
+	}
+	if err := tr.Connect(context.Background()); err == nil {
+		t.Error("Connect on an exited transport succeeded without Close")
+	}
+}
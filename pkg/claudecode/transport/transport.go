@@ -1,8 +1,11 @@
 package transport
 
 import (
+	"bytes"
 	"context"
 	"io"
+	"log/slog"
+	"time"
 )
 
 // Transport defines the interface for communication with Claude Code
@@ -13,8 +16,9 @@ type Transport interface {
 	// Close terminates the connection
 	Close() error
 	
-	// Write sends data to the transport
-	Write(data []byte) error
+	// Write sends data to the transport. Cancelling ctx aborts a write
+	// that is blocked on the other end.
+	Write(ctx context.Context, data []byte) error
 	
 	// Reader returns a reader for receiving data
 	Reader() io.Reader
@@ -22,6 +26,45 @@ type Transport interface {
 	// IsConnected returns true if the transport is connected
 	IsConnected() bool
 	
-	// SetDebug enables/disables debug logging
-	SetDebug(debug bool)
-}
\ No newline at end of file
+	// SetLogger sets the logger for connection lifecycle events and, at
+	// debug level, the lines written. A nil logger disables logging.
+	SetLogger(logger *slog.Logger)
+}
+
+// GracefulCloser is implemented by transports that support an orderly
+// shutdown: ending input, waiting for the other side to finish and only then
+// forcing it to stop
+type GracefulCloser interface {
+	// CloseInput signals that no more data will be written
+	CloseInput() error
+
+	// Exited returns a channel that is closed once the other side has exited
+	Exited() <-chan struct{}
+
+	// Terminate asks the other side to stop and forces it after grace
+	Terminate(grace time.Duration) error
+}
+
+// Flusher is implemented by transports that queue writes
+type Flusher interface {
+	// Flush waits until everything written before the call was sent
+	Flush(ctx context.Context) error
+}
+
+// nopLogger is used until SetLogger is called
+var nopLogger = slog.New(slog.DiscardHandler)
+
+// loggerOrNop returns logger, or a logger that discards everything if nil
+func loggerOrNop(logger *slog.Logger) *slog.Logger {
+	if logger == nil {
+		return nopLogger
+	}
+	return logger
+}
+
+// logSent logs a line written to the other side at debug level
+func logSent(ctx context.Context, logger *slog.Logger, data []byte) {
+	if logger.Enabled(ctx, slog.LevelDebug) {
+		logger.DebugContext(ctx, "sent line", "line", string(bytes.TrimSpace(data)))
+	}
+}
@@ -0,0 +1,410 @@
+// Package transporttest provides an in-memory Transport for unit-testing code
+// built on the SDK without a real Claude CLI.
+//
+// A MockTransport records everything the SDK writes and delivers scripted
+// messages to the SDK. Failures such as disconnects, malformed JSON and slow
+// reads can be injected at any point.
+//
+// Example:
+//
+//	mock := transporttest.NewMockTransport()
+//	mock.Respond(transporttest.MatchType("user"),
+//	    map[string]interface{}{"type": "result", "subtype": "success", "session_id": "s1"},
+//	)
+//	client := claudecode.NewClaudeSDKClientWithTransport(nil, mock)
+package transporttest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+)
+
+// ErrInjected is a convenience error for failure injection
+var ErrInjected = stderrors.New("transporttest: injected failure")
+
+// Matcher selects written messages for a scripted response
+type Matcher func(msg map[string]interface{}) bool
+
+// MatchType matches messages with the given "type" field
+func MatchType(messageType string) Matcher {
+	return func(msg map[string]interface{}) bool {
+		return msg["type"] == messageType
+	}
+}
+
+// MatchControl matches control requests with the given subtype
+func MatchControl(subtype string) Matcher {
+	return func(msg map[string]interface{}) bool {
+		if msg["type"] != "control_request" {
+			return false
+		}
+		request, _ := msg["request"].(map[string]interface{})
+		return request["subtype"] == subtype
+	}
+}
+
+// MatchAny matches every message
+func MatchAny() Matcher {
+	return func(map[string]interface{}) bool { return true }
+}
+
+// responder is a scripted response registered with Respond or RespondOnce
+type responder struct {
+	match     Matcher
+	responses []interface{}
+	fn        func(msg map[string]interface{}) []interface{}
+	once      bool
+}
+
+// event is a single item delivered to the reader
+type event struct {
+	line []byte
+	err  error
+}
+
+// MockTransport is an in-memory Transport with scripted responses, recorded
+// writes and failure injection. The zero value is not usable; create one
+// with NewMockTransport.
+type MockTransport struct {
+	mu         sync.Mutex
+	connected  bool
+	writes     [][]byte
+	responders []*responder
+
+	// Control requests without a scripted response are answered with success
+	autoAck bool
+
+	// Injected failures
+	connectErr error
+	writeErr   error
+	readDelay  time.Duration
+
+	events chan event
+	done   chan struct{}
+	reader *mockReader
+}
+
+// NewMockTransport creates a mock transport. Control requests sent by the
+// SDK (interrupt, set_permission_mode, ...) are acknowledged with a success
+// response unless a scripted response matches them; see SetAutoAck.
+func NewMockTransport() *MockTransport {
+	m := &MockTransport{
+		autoAck: true,
+		events:  make(chan event, 1024),
+		done:    make(chan struct{}),
+	}
+	m.reader = &mockReader{m: m}
+	return m
+}
+
+// Connect marks the transport connected, or returns the error set with
+// FailConnect
+func (m *MockTransport) Connect(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.connectErr != nil {
+		return m.connectErr
+	}
+	if m.connected {
+		return nil
+	}
+
+	// Reconnecting after Close or Disconnect starts a fresh read stream
+	select {
+	case <-m.done:
+		m.done = make(chan struct{})
+	default:
+	}
+	m.reader = &mockReader{m: m}
+
+	m.connected = true
+	return nil
+}
+
+// Close disconnects the transport; pending reads return io.EOF
+func (m *MockTransport) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.connected {
+		return nil
+	}
+	m.connected = false
+	close(m.done)
+	return nil
+}
+
+// Write records data and emits the scripted responses of the first matching
+// responder for every JSON line in it
+func (m *MockTransport) Write(ctx context.Context, data []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	if !m.connected {
+		m.mu.Unlock()
+		return errors.NewCLIConnectionError("transport not connected", nil)
+	}
+	if m.writeErr != nil {
+		err := m.writeErr
+		m.mu.Unlock()
+		return err
+	}
+	m.writes = append(m.writes, append([]byte(nil), data...))
+	m.mu.Unlock()
+
+	for _, line := range bytes.Split(data, []byte{'\n'}) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var msg map[string]interface{}
+		if err := json.Unmarshal(line, &msg); err != nil {
+			continue
+		}
+		if err := m.respond(msg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// respond emits the responses scripted for a written message
+func (m *MockTransport) respond(msg map[string]interface{}) error {
+	m.mu.Lock()
+	var matched *responder
+	for i, r := range m.responders {
+		if r.match(msg) {
+			matched = r
+			if r.once {
+				m.responders = append(m.responders[:i], m.responders[i+1:]...)
+			}
+			break
+		}
+	}
+	autoAck := m.autoAck
+	m.mu.Unlock()
+
+	if matched != nil {
+		responses := matched.responses
+		if matched.fn != nil {
+			responses = matched.fn(msg)
+		}
+		for _, response := range responses {
+			if err := m.Emit(response); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if autoAck && msg["type"] == "control_request" {
+		return m.Emit(ControlSuccess(msg, nil))
+	}
+
+	return nil
+}
+
+// Reader returns the reader the SDK receives messages from
+func (m *MockTransport) Reader() io.Reader {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.reader
+}
+
+// IsConnected returns true if connected
+func (m *MockTransport) IsConnected() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.connected
+}
+
+// SetLogger is a no-op; inspect Writes and WrittenMessages instead
+func (m *MockTransport) SetLogger(logger *slog.Logger) {}
+
+// Respond registers responses emitted whenever a written message matches.
+// Responders are tried in registration order. Each response is marshaled to
+// JSON unless it is a string or []byte, which is emitted as-is.
+func (m *MockTransport) Respond(match Matcher, responses ...interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.responders = append(m.responders, &responder{match: match, responses: responses})
+}
+
+// RespondOnce is like Respond but the responder is removed after its first match
+func (m *MockTransport) RespondOnce(match Matcher, responses ...interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.responders = append(m.responders, &responder{match: match, responses: responses, once: true})
+}
+
+// RespondFunc registers a function computing the responses to a matching
+// message, e.g. to echo its request_id
+func (m *MockTransport) RespondFunc(match Matcher, fn func(msg map[string]interface{}) []interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.responders = append(m.responders, &responder{match: match, fn: fn})
+}
+
+// ControlSuccess builds a successful control_response to a control request
+func ControlSuccess(request map[string]interface{}, response map[string]interface{}) map[string]interface{} {
+	if response == nil {
+		response = map[string]interface{}{}
+	}
+	return map[string]interface{}{
+		"type": "control_response",
+		"response": map[string]interface{}{
+			"subtype":    "success",
+			"request_id": request["request_id"],
+			"response":   response,
+		},
+	}
+}
+
+// SetAutoAck enables/disables acknowledging unscripted control requests
+func (m *MockTransport) SetAutoAck(enabled bool) {
+	m.mu.Lock()
+	m.autoAck = enabled
+	m.mu.Unlock()
+}
+
+// Emit delivers a message to the SDK. A string or []byte is delivered as a
+// raw line, anything else is marshaled to JSON.
+func (m *MockTransport) Emit(message interface{}) error {
+	var line []byte
+	switch v := message.(type) {
+	case string:
+		line = []byte(v)
+	case []byte:
+		line = append([]byte(nil), v...)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		line = data
+	}
+
+	line = append(bytes.TrimRight(line, "\n"), '\n')
+	m.events <- event{line: line}
+	return nil
+}
+
+// EmitMalformed delivers a line that is not valid JSON
+func (m *MockTransport) EmitMalformed() {
+	m.events <- event{line: []byte("{not valid json\n")}
+}
+
+// Disconnect simulates the CLI going away. Reads return err after the
+// messages already emitted, or io.EOF when err is nil.
+func (m *MockTransport) Disconnect(err error) {
+	if err == nil {
+		err = io.EOF
+	}
+
+	m.mu.Lock()
+	m.connected = false
+	m.mu.Unlock()
+
+	m.events <- event{err: err}
+}
+
+// FailConnect makes Connect return err; nil restores normal behavior
+func (m *MockTransport) FailConnect(err error) {
+	m.mu.Lock()
+	m.connectErr = err
+	m.mu.Unlock()
+}
+
+// FailWrites makes Write return err; nil restores normal behavior
+func (m *MockTransport) FailWrites(err error) {
+	m.mu.Lock()
+	m.writeErr = err
+	m.mu.Unlock()
+}
+
+// SetReadDelay delays every delivered line by d to simulate a slow CLI
+func (m *MockTransport) SetReadDelay(d time.Duration) {
+	m.mu.Lock()
+	m.readDelay = d
+	m.mu.Unlock()
+}
+
+// Writes returns a copy of every Write call's data
+func (m *MockTransport) Writes() [][]byte {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	writes := make([][]byte, len(m.writes))
+	copy(writes, m.writes)
+	return writes
+}
+
+// WrittenMessages returns every JSON line written so far, decoded
+func (m *MockTransport) WrittenMessages() []map[string]interface{} {
+	var messages []map[string]interface{}
+	for _, data := range m.Writes() {
+		for _, line := range bytes.Split(data, []byte{'\n'}) {
+			var msg map[string]interface{}
+			if err := json.Unmarshal(line, &msg); err == nil {
+				messages = append(messages, msg)
+			}
+		}
+	}
+	return messages
+}
+
+// mockReader delivers emitted lines to the SDK
+type mockReader struct {
+	m   *MockTransport
+	buf []byte
+	err error
+}
+
+// Read implements io.Reader
+func (r *mockReader) Read(p []byte) (int, error) {
+	if len(r.buf) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+
+		r.m.mu.Lock()
+		done := r.m.done
+		delay := r.m.readDelay
+		r.m.mu.Unlock()
+
+		select {
+		case ev := <-r.m.events:
+			if ev.err != nil {
+				r.err = ev.err
+				return 0, ev.err
+			}
+			if delay > 0 {
+				time.Sleep(delay)
+			}
+			r.buf = ev.line
+		case <-done:
+			return 0, io.EOF
+		}
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
@@ -0,0 +1,110 @@
+package transporttest_test
+
+import (
+	"bufio"
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/transporttest"
+)
+
+var _ transport.Transport = (*transporttest.MockTransport)(nil)
+
+func TestMockTransportScriptedConversation(t *testing.T) {
+	mock := transporttest.NewMockTransport()
+	mock.Respond(transporttest.MatchType("user"),
+		map[string]interface{}{
+			"type":       "result",
+			"subtype":    "success",
+			"session_id": "s1",
+			"num_turns":  1,
+		},
+	)
+
+	client := claudecode.NewClaudeSDKClientWithTransport(nil, mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.SendMessage("hello", "s1"); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+
+	select {
+	case msg := <-client.Messages():
+		result, ok := msg.(*claudecode.ResultMessage)
+		if !ok {
+			t.Fatalf("got %T, want *ResultMessage", msg)
+		}
+		if result.SessionID != "s1" {
+			t.Errorf("SessionID = %q, want s1", result.SessionID)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for result")
+	}
+
+	// The initialize handshake precedes the user message
+	written := mock.WrittenMessages()
+	if len(written) != 2 || written[0]["type"] != "control_request" || written[1]["type"] != "user" {
+		t.Fatalf("unexpected writes: %v", written)
+	}
+}
+
+func TestMockTransportControlAutoAck(t *testing.T) {
+	mock := transporttest.NewMockTransport()
+	client := claudecode.NewClaudeSDKClientWithTransport(nil, mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.SetModel(ctx, "claude-sonnet-4-5"); err != nil {
+		t.Fatalf("SetModel: %v", err)
+	}
+}
+
+func TestMockTransportFailureInjection(t *testing.T) {
+	mock := transporttest.NewMockTransport()
+
+	mock.FailConnect(transporttest.ErrInjected)
+	if err := mock.Connect(context.Background()); !stderrors.Is(err, transporttest.ErrInjected) {
+		t.Fatalf("Connect error = %v, want ErrInjected", err)
+	}
+	mock.FailConnect(nil)
+
+	if err := mock.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+
+	mock.FailWrites(transporttest.ErrInjected)
+	if err := mock.Write(context.Background(), []byte("{}\n")); !stderrors.Is(err, transporttest.ErrInjected) {
+		t.Fatalf("Write error = %v, want ErrInjected", err)
+	}
+
+	mock.EmitMalformed()
+	mock.Disconnect(transporttest.ErrInjected)
+
+	reader := bufio.NewReader(mock.Reader())
+	if line, err := reader.ReadString('\n'); err != nil || line != "{not valid json\n" {
+		t.Fatalf("ReadString = %q, %v", line, err)
+	}
+	if _, err := reader.ReadString('\n'); !stderrors.Is(err, transporttest.ErrInjected) {
+		t.Fatalf("read error = %v, want ErrInjected", err)
+	}
+	if mock.IsConnected() {
+		t.Error("expected transport to be disconnected")
+	}
+}
@@ -0,0 +1,275 @@
+package transporttest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport"
+)
+
+// ReplayTransport plays back a recording made with transport.RecordingTransport.
+//
+// Received lines are delivered in recorded order. Before delivering a line
+// that was received after the SDK sent something, playback waits until the
+// SDK has written the same number of lines, so a replay follows the
+// conversation rather than racing ahead of it. Request IDs of control
+// responses are rewritten to the IDs the SDK actually used.
+//
+// Example:
+//
+//	replay, err := transporttest.LoadReplay("testdata/conversation.jsonl")
+//	if err != nil {
+//	    t.Fatal(err)
+//	}
+//	client := claudecode.NewClaudeSDKClientWithTransport(nil, replay)
+type ReplayTransport struct {
+	lines []transport.RecordedLine
+	speed float64
+
+	mu        sync.Mutex
+	connected bool
+	writes    [][]byte
+	sent      []map[string]interface{}
+	written   chan struct{}
+	done      chan struct{}
+
+	// Recorded control request IDs mapped to the IDs used during replay
+	requestIDs map[string]string
+
+	reader *io.PipeReader
+}
+
+// NewReplayTransport reads a recording from r
+func NewReplayTransport(r io.Reader) (*ReplayTransport, error) {
+	var lines []transport.RecordedLine
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var line transport.RecordedLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			return nil, fmt.Errorf("recording line %d: %w", n, err)
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return &ReplayTransport{
+		lines:      lines,
+		written:    make(chan struct{}, len(lines)+1),
+		requestIDs: make(map[string]string),
+	}, nil
+}
+
+// LoadReplay reads a recording from a file
+func LoadReplay(path string) (*ReplayTransport, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return NewReplayTransport(f)
+}
+
+// SetSpeed enables recorded timing. A speed of 1 replays in real time, 2
+// twice as fast. The default of 0 delivers lines without delay.
+func (t *ReplayTransport) SetSpeed(speed float64) {
+	t.mu.Lock()
+	t.speed = speed
+	t.mu.Unlock()
+}
+
+// Connect starts playback
+func (t *ReplayTransport) Connect(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.connected {
+		return nil
+	}
+
+	reader, writer := io.Pipe()
+	t.reader = reader
+	t.done = make(chan struct{})
+	t.connected = true
+
+	go t.play(writer, t.done, t.speed)
+
+	return nil
+}
+
+// Close stops playback
+func (t *ReplayTransport) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.connected {
+		return nil
+	}
+	t.connected = false
+	close(t.done)
+	t.reader.Close()
+	return nil
+}
+
+// Write records data and lets playback continue past recorded sends
+func (t *ReplayTransport) Write(ctx context.Context, data []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.connected {
+		return errors.NewCLIConnectionError("transport not connected", nil)
+	}
+	t.writes = append(t.writes, append([]byte(nil), data...))
+
+	for _, line := range bytes.Split(data, []byte{'\n'}) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var msg map[string]interface{}
+		json.Unmarshal(line, &msg)
+		t.sent = append(t.sent, msg)
+
+		select {
+		case t.written <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// Reader returns the reader the recorded lines are delivered on
+func (t *ReplayTransport) Reader() io.Reader {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.reader
+}
+
+// IsConnected returns true if connected
+func (t *ReplayTransport) IsConnected() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.connected
+}
+
+// SetLogger is a no-op; inspect Writes instead
+func (t *ReplayTransport) SetLogger(logger *slog.Logger) {}
+
+// Writes returns a copy of every Write call's data
+func (t *ReplayTransport) Writes() [][]byte {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	writes := make([][]byte, len(t.writes))
+	copy(writes, t.writes)
+	return writes
+}
+
+// play delivers the recorded lines, ending with io.EOF
+func (t *ReplayTransport) play(w *io.PipeWriter, done <-chan struct{}, speed float64) {
+	var lastOffset int64
+	sends := 0
+
+	for _, line := range t.lines {
+		if line.Direction == transport.RecordSend {
+			// Wait for the SDK to send its counterpart
+			select {
+			case <-t.written:
+			case <-done:
+				return
+			}
+			t.mapRequestID(line, sends)
+			sends++
+			continue
+		}
+
+		if speed > 0 && line.OffsetMS > lastOffset {
+			delay := time.Duration(float64(line.OffsetMS-lastOffset) / speed * float64(time.Millisecond))
+			select {
+			case <-time.After(delay):
+			case <-done:
+				return
+			}
+		}
+		lastOffset = line.OffsetMS
+
+		if _, err := w.Write([]byte(t.rewriteRequestID(line.Line) + "\n")); err != nil {
+			return
+		}
+	}
+
+	w.Close()
+}
+
+// mapRequestID pairs a recorded control request with the one the SDK sent
+func (t *ReplayTransport) mapRequestID(line transport.RecordedLine, index int) {
+	var recorded map[string]interface{}
+	if err := json.Unmarshal([]byte(line.Line), &recorded); err != nil {
+		return
+	}
+	recordedID, ok := recorded["request_id"].(string)
+	if !ok || recorded["type"] != "control_request" {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if index >= len(t.sent) || t.sent[index] == nil {
+		return
+	}
+	if liveID, ok := t.sent[index]["request_id"].(string); ok {
+		t.requestIDs[recordedID] = liveID
+	}
+}
+
+// rewriteRequestID replaces a recorded control response's request ID
+func (t *ReplayTransport) rewriteRequestID(line string) string {
+	var msg map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &msg); err != nil || msg["type"] != "control_response" {
+		return line
+	}
+	response, ok := msg["response"].(map[string]interface{})
+	if !ok {
+		return line
+	}
+	recordedID, _ := response["request_id"].(string)
+
+	t.mu.Lock()
+	liveID, ok := t.requestIDs[recordedID]
+	t.mu.Unlock()
+	if !ok {
+		return line
+	}
+
+	response["request_id"] = liveID
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return line
+	}
+	return string(data)
+}
@@ -0,0 +1,70 @@
+package transporttest_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/transporttest"
+)
+
+// converse runs a short conversation and returns the result's session ID
+func converse(t *testing.T, tr transport.Transport) string {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client := claudecode.NewClaudeSDKClientWithTransport(nil, tr)
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.SetModel(ctx, "claude-sonnet-4-5"); err != nil {
+		t.Fatalf("SetModel: %v", err)
+	}
+	if err := client.SendMessage("hello", "s1"); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+
+	select {
+	case msg := <-client.Messages():
+		result, ok := msg.(*claudecode.ResultMessage)
+		if !ok {
+			t.Fatalf("got %T, want *ResultMessage", msg)
+		}
+		return result.SessionID
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for result")
+		return ""
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	mock := transporttest.NewMockTransport()
+	mock.Respond(transporttest.MatchType("user"),
+		map[string]interface{}{"type": "result", "subtype": "success", "session_id": "s1"},
+	)
+
+	var recording bytes.Buffer
+	if got := converse(t, transport.NewRecordingTransport(mock, &recording)); got != "s1" {
+		t.Fatalf("recorded session = %q, want s1", got)
+	}
+
+	replay, err := transporttest.NewReplayTransport(bytes.NewReader(recording.Bytes()))
+	if err != nil {
+		t.Fatalf("NewReplayTransport: %v", err)
+	}
+	if got := converse(t, replay); got != "s1" {
+		t.Fatalf("replayed session = %q, want s1", got)
+	}
+
+	// initialize, set_model and the user message
+	if len(replay.Writes()) != 3 {
+		t.Errorf("replay saw %d writes, want 3", len(replay.Writes()))
+	}
+}
@@ -0,0 +1,419 @@
+package transport
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+)
+
+// WebSocket opcodes (RFC 6455 section 5.2)
+const (
+	wsOpContinuation = 0x0
+	wsOpText         = 0x1
+	wsOpBinary       = 0x2
+	wsOpClose        = 0x8
+	wsOpPing         = 0x9
+	wsOpPong         = 0xA
+)
+
+// wsAcceptGUID is appended to the handshake key (RFC 6455 section 1.3)
+const wsAcceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// WebSocketTransport implements Transport over a WebSocket connection to a
+// remote Claude Code instance or relay speaking the stream-json protocol.
+//
+// Every outbound line is sent as one text message and every inbound text
+// message is exposed on Reader() as one newline-terminated line.
+type WebSocketTransport struct {
+	url    string
+	header http.Header
+
+	conn   net.Conn
+	reader *io.PipeReader
+	writer *io.PipeWriter
+
+	connected bool
+	logger    *slog.Logger
+
+	mu      sync.RWMutex
+	writeMu sync.Mutex
+}
+
+// NewWebSocketTransport creates a transport for a ws:// or wss:// URL.
+// The header is sent with the opening handshake, e.g. for authentication.
+func NewWebSocketTransport(rawURL string, header http.Header) *WebSocketTransport {
+	if header == nil {
+		header = http.Header{}
+	}
+	return &WebSocketTransport{
+		url:    rawURL,
+		header: header,
+		logger: nopLogger,
+	}
+}
+
+// Connect dials the server and performs the WebSocket handshake
+func (t *WebSocketTransport) Connect(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.connected {
+		return nil
+	}
+
+	u, err := url.Parse(t.url)
+	if err != nil {
+		return errors.NewCLIConnectionError("invalid WebSocket URL", err)
+	}
+
+	host := u.Host
+	if u.Port() == "" {
+		switch u.Scheme {
+		case "ws":
+			host = net.JoinHostPort(u.Hostname(), "80")
+		case "wss":
+			host = net.JoinHostPort(u.Hostname(), "443")
+		}
+	}
+
+	var conn net.Conn
+	switch u.Scheme {
+	case "ws":
+		var d net.Dialer
+		conn, err = d.DialContext(ctx, "tcp", host)
+	case "wss":
+		d := tls.Dialer{Config: &tls.Config{ServerName: u.Hostname()}}
+		conn, err = d.DialContext(ctx, "tcp", host)
+	default:
+		return errors.NewCLIConnectionError(fmt.Sprintf("unsupported WebSocket scheme: %s", u.Scheme), nil)
+	}
+	if err != nil {
+		return errors.NewCLIConnectionError("failed to dial WebSocket server", err)
+	}
+
+	// Bound the handshake by ctx
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	br, err := t.handshake(conn, u)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	conn.SetDeadline(time.Time{})
+
+	t.conn = conn
+	t.reader, t.writer = io.Pipe()
+	t.connected = true
+	t.logger.InfoContext(ctx, "WebSocket connected", "url", u.Redacted())
+
+	go t.readLoop(conn, br, t.writer)
+
+	return nil
+}
+
+// handshake sends the HTTP upgrade request and validates the response
+func (t *WebSocketTransport) handshake(conn net.Conn, u *url.URL) (*bufio.Reader, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errors.NewCLIConnectionError("failed to generate WebSocket key", err)
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	req := &http.Request{
+		Method:     http.MethodGet,
+		URL:        &url.URL{Path: u.Path, RawQuery: u.RawQuery},
+		Host:       u.Host,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     t.header.Clone(),
+	}
+	if req.URL.Path == "" {
+		req.URL.Path = "/"
+	}
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+
+	if err := req.Write(conn); err != nil {
+		return nil, errors.NewCLIConnectionError("failed to send WebSocket handshake", err)
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		return nil, errors.NewCLIConnectionError("failed to read WebSocket handshake", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		return nil, errors.NewCLIConnectionError(fmt.Sprintf("WebSocket handshake failed: %s", resp.Status), nil)
+	}
+
+	sum := sha1.Sum([]byte(key + wsAcceptGUID))
+	if resp.Header.Get("Sec-WebSocket-Accept") != base64.StdEncoding.EncodeToString(sum[:]) {
+		return nil, errors.NewCLIConnectionError("WebSocket handshake failed: invalid Sec-WebSocket-Accept", nil)
+	}
+
+	return br, nil
+}
+
+// Close sends a close frame and closes the connection. It is safe to call
+// more than once and after the server closed the connection.
+func (t *WebSocketTransport) Close() error {
+	t.mu.Lock()
+	conn := t.conn
+	writer := t.writer
+	t.conn = nil
+	t.connected = false
+	t.mu.Unlock()
+
+	// Already closed, by Close or by the read loop
+	if conn == nil {
+		return nil
+	}
+
+	t.logger.Info("closing WebSocket", "url", t.url)
+
+	// Normal closure (1000); the server may already be gone. A write still
+	// in progress fails once the connection is closed below.
+	if t.writeMu.TryLock() {
+		payload := make([]byte, 2)
+		binary.BigEndian.PutUint16(payload, 1000)
+		conn.SetWriteDeadline(time.Now().Add(time.Second))
+		writeFrame(conn, wsOpClose, payload)
+		t.writeMu.Unlock()
+	}
+
+	writer.Close()
+	return conn.Close()
+}
+
+// drop marks the transport disconnected and closes conn when the read loop
+// ends, unless Close already did
+func (t *WebSocketTransport) drop(conn net.Conn) {
+	t.mu.Lock()
+	owned := t.conn == conn
+	if owned {
+		t.conn = nil
+		t.connected = false
+	}
+	t.mu.Unlock()
+
+	if owned {
+		conn.Close()
+	}
+}
+
+// Write sends each line of data as a text message
+func (t *WebSocketTransport) Write(ctx context.Context, data []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	t.mu.RLock()
+	if !t.connected {
+		t.mu.RUnlock()
+		return errors.NewCLIConnectionError("transport not connected", nil)
+	}
+	conn := t.conn
+	logger := t.logger
+	t.mu.RUnlock()
+
+	logSent(ctx, logger, data)
+
+	// Writes are serialized so the deadline set when ctx is done aborts
+	// this write only; it is cleared again before the next one starts
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+
+	aborted := make(chan struct{})
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetWriteDeadline(time.Now())
+		close(aborted)
+	})
+	defer func() {
+		if !stop() {
+			<-aborted
+			conn.SetWriteDeadline(time.Time{})
+		}
+	}()
+
+	for _, line := range bytes.Split(data, []byte{'\n'}) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		if err := writeFrame(conn, wsOpText, line); err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			return errors.NewCLIConnectionError("failed to write WebSocket message", err)
+		}
+	}
+
+	return nil
+}
+
+// Reader returns a reader yielding one line per inbound text message
+func (t *WebSocketTransport) Reader() io.Reader {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.reader
+}
+
+// IsConnected returns true if connected
+func (t *WebSocketTransport) IsConnected() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.connected
+}
+
+// SetLogger sets the logger for connection events and sent lines
+func (t *WebSocketTransport) SetLogger(logger *slog.Logger) {
+	t.mu.Lock()
+	t.logger = loggerOrNop(logger)
+	t.mu.Unlock()
+}
+
+// readLoop reads messages and forwards text messages to the pipe. When the
+// server closes the connection or a read fails, the connection is closed
+// and the transport reports itself disconnected.
+func (t *WebSocketTransport) readLoop(conn net.Conn, br *bufio.Reader, w *io.PipeWriter) {
+	defer t.drop(conn)
+
+	var message []byte
+	for {
+		fin, opcode, payload, err := readFrame(br)
+		if err != nil {
+			w.CloseWithError(err)
+			return
+		}
+
+		switch opcode {
+		case wsOpPing:
+			t.writeMu.Lock()
+			writeFrame(conn, wsOpPong, payload)
+			t.writeMu.Unlock()
+		case wsOpPong:
+			// Ignore unsolicited pongs
+		case wsOpClose:
+			w.Close()
+			return
+		case wsOpText, wsOpBinary, wsOpContinuation:
+			message = append(message, payload...)
+			if !fin {
+				continue
+			}
+			line := append(bytes.TrimRight(message, "\n"), '\n')
+			message = nil
+			if _, err := w.Write(line); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// writeFrame writes a single masked frame; clients must mask (RFC 6455 5.3).
+// Callers hold writeMu.
+func writeFrame(conn net.Conn, opcode byte, payload []byte) error {
+	header := []byte{0x80 | opcode}
+	switch n := len(payload); {
+	case n < 126:
+		header = append(header, 0x80|byte(n))
+	case n <= 0xFFFF:
+		header = append(header, 0x80|126, 0, 0)
+		binary.BigEndian.PutUint16(header[2:], uint16(n))
+	default:
+		header = append(header, 0x80|127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(header[2:], uint64(n))
+	}
+
+	mask := make([]byte, 4)
+	if _, err := rand.Read(mask); err != nil {
+		return err
+	}
+	header = append(header, mask...)
+
+	masked := make([]byte, len(payload))
+	for i, b := range payload {
+		masked[i] = b ^ mask[i%4]
+	}
+
+	if _, err := conn.Write(header); err != nil {
+		return err
+	}
+	_, err := conn.Write(masked)
+	return err
+}
+
+// readFrame reads a single frame from the server
+func readFrame(br *bufio.Reader) (fin bool, opcode byte, payload []byte, err error) {
+	var head [2]byte
+	if _, err = io.ReadFull(br, head[:]); err != nil {
+		return
+	}
+
+	fin = head[0]&0x80 != 0
+	opcode = head[0] & 0x0F
+	masked := head[1]&0x80 != 0
+	length := uint64(head[1] & 0x7F)
+
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(br, ext[:]); err != nil {
+			return
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(br, ext[:]); err != nil {
+			return
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+
+	if length > maxBufferSize {
+		err = errors.NewBufferOverflowError(maxBufferSize, int(length))
+		return
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err = io.ReadFull(br, mask[:]); err != nil {
+			return
+		}
+	}
+
+	payload = make([]byte, length)
+	if _, err = io.ReadFull(br, payload); err != nil {
+		return
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+
+	return
+}
@@ -0,0 +1,231 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// echoServer upgrades the connection and echoes text messages back unmasked
+func echoServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		conn, rw := upgrade(t, w, r)
+		if conn == nil {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			fin, opcode, payload, err := readFrame(rw.Reader)
+			if err != nil || opcode == wsOpClose {
+				return
+			}
+			writeServerFrame(rw.Writer, fin, opcode, payload)
+			rw.Flush()
+		}
+	}))
+}
+
+// upgrade hijacks the connection and completes the WebSocket handshake
+func upgrade(t *testing.T, w http.ResponseWriter, r *http.Request) (net.Conn, *bufio.ReadWriter) {
+	conn, rw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		t.Errorf("hijack: %v", err)
+		return nil, nil
+	}
+
+	sum := sha1.Sum([]byte(r.Header.Get("Sec-WebSocket-Key") + wsAcceptGUID))
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(sum[:]) + "\r\n\r\n")
+	rw.Flush()
+
+	return conn, rw
+}
+
+func writeServerFrame(w *bufio.Writer, fin bool, opcode byte, payload []byte) {
+	b0 := opcode
+	if fin {
+		b0 |= 0x80
+	}
+	if len(payload) < 126 {
+		w.Write([]byte{b0, byte(len(payload))})
+	} else {
+		head := []byte{b0, 126, 0, 0}
+		binary.BigEndian.PutUint16(head[2:], uint16(len(payload)))
+		w.Write(head)
+	}
+	w.Write(payload)
+}
+
+func TestWebSocketTransportRoundTrip(t *testing.T) {
+	server := echoServer(t)
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+	tr := NewWebSocketTransport(url, http.Header{"Authorization": []string{"Bearer token"}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := tr.Connect(ctx); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer tr.Close()
+
+	if !tr.IsConnected() {
+		t.Fatal("expected transport to be connected")
+	}
+
+	long := `{"type":"user","text":"` + strings.Repeat("x", 300) + `"}`
+	if err := tr.Write(ctx, []byte(`{"type":"ping"}`+"\n"+long+"\n")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	reader := bufio.NewReader(tr.Reader())
+	for _, want := range []string{`{"type":"ping"}`, long} {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("ReadString: %v", err)
+		}
+		if got := strings.TrimSuffix(line, "\n"); got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+}
+
+func TestWebSocketTransportHandshakeRejected(t *testing.T) {
+	server := echoServer(t)
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+	tr := NewWebSocketTransport(url, nil)
+
+	if err := tr.Connect(context.Background()); err == nil {
+		tr.Close()
+		t.Fatal("expected handshake to fail without credentials")
+	}
+	if tr.IsConnected() {
+		t.Error("expected transport to be disconnected")
+	}
+}
+
+func TestWebSocketTransportServerClose(t *testing.T) {
+	// The server closes the session and waits for the client to hang up
+	hungUp := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw := upgrade(t, w, r)
+		if conn == nil {
+			return
+		}
+		defer conn.Close()
+
+		writeServerFrame(rw.Writer, true, wsOpClose, []byte{0x03, 0xE8})
+		rw.Flush()
+		io.Copy(io.Discard, rw)
+		close(hungUp)
+	}))
+	defer server.Close()
+
+	tr := NewWebSocketTransport("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := tr.Connect(ctx); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	if _, err := io.ReadAll(tr.Reader()); err != nil {
+		t.Fatalf("Reader: %v", err)
+	}
+
+	select {
+	case <-hungUp:
+	case <-ctx.Done():
+		t.Fatal("connection left open after the server closed it")
+	}
+	if tr.IsConnected() {
+		t.Error("expected transport to be disconnected")
+	}
+	if err := tr.Close(); err != nil {
+		t.Errorf("Close after server close: %v", err)
+	}
+	if err := tr.Close(); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+}
+
+func TestWebSocketTransportCanceledWrite(t *testing.T) {
+	server := echoServer(t)
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+	tr := NewWebSocketTransport(url, http.Header{"Authorization": []string{"Bearer token"}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := tr.Connect(ctx); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer tr.Close()
+
+	canceled, stop := context.WithCancel(ctx)
+	stop()
+	if err := tr.Write(canceled, []byte(`{"type":"dropped"}`+"\n")); err != context.Canceled {
+		t.Errorf("Write with a canceled ctx = %v", err)
+	}
+
+	// Canceling one write does not abort the others
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			writeCtx, stop := context.WithCancel(ctx)
+			time.AfterFunc(time.Duration(i)*time.Microsecond, stop)
+			tr.Write(writeCtx, []byte(`{"type":"canceled"}`+"\n"))
+		}()
+	}
+	errs := make(chan error, 10)
+	for range 10 {
+		go func() { errs <- tr.Write(ctx, []byte(`{"type":"ping"}`+"\n")) }()
+	}
+	for range 10 {
+		if err := <-errs; err != nil {
+			t.Errorf("Write: %v", err)
+		}
+	}
+	wg.Wait()
+
+	if err := tr.Write(ctx, []byte(`{"type":"done"}`+"\n")); err != nil {
+		t.Fatalf("Write after canceled writes: %v", err)
+	}
+	reader := bufio.NewReader(tr.Reader())
+	for pings := 0; pings < 10; {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("ReadString: %v", err)
+		}
+		switch line {
+		case `{"type":"ping"}` + "\n":
+			pings++
+		case `{"type":"done"}` + "\n":
+			t.Fatalf("got %d pings, want 10", pings)
+		}
+	}
+}
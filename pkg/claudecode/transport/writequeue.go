@@ -0,0 +1,188 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	stderrors "errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// errQueueClosed is returned for writes after stdin was closed
+var errQueueClosed = stderrors.New("write queue closed")
+
+// writeQueue serializes writes to the CLI's stdin. A single goroutine writes
+// whole lines in the order they were submitted, so user messages and control
+// responses sent from different goroutines never interleave.
+type writeQueue struct {
+	w       io.WriteCloser
+	timeout time.Duration
+
+	requests chan *writeRequest
+	abort    chan struct{}
+	aborted  atomic.Bool
+	done     chan struct{} // Closed once the writer goroutine has exited
+
+	// First failed write. A line may have been written in part, so the
+	// stream is unusable from then on. Owned by the writer goroutine.
+	broken error
+
+	// Request being written, which ctx may interrupt
+	current *writeRequest
+	mu      sync.Mutex
+}
+
+// writeRequest is a line to write, a flush marker (no data) or the request
+// to close stdin
+type writeRequest struct {
+	data  []byte
+	close bool
+
+	// pending, then claimed by the writer or cancelled by the submitter
+	state  atomic.Int32
+	result chan error
+}
+
+const (
+	requestPending int32 = iota
+	requestClaimed
+	requestCancelled
+)
+
+func newWriteQueue(w io.WriteCloser, timeout time.Duration) *writeQueue {
+	q := &writeQueue{
+		w:        w,
+		timeout:  timeout,
+		requests: make(chan *writeRequest),
+		abort:    make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go q.run()
+	return q
+}
+
+// write queues data as one line and waits until it has been written. If ctx
+// is done before the writer gets to it the line is dropped, otherwise the
+// write is interrupted.
+func (q *writeQueue) write(ctx context.Context, data []byte) error {
+	line := make([]byte, len(data), len(data)+1)
+	copy(line, data)
+	if !bytes.HasSuffix(line, []byte("\n")) {
+		line = append(line, '\n')
+	}
+	return q.submit(ctx, &writeRequest{data: line})
+}
+
+// flush waits until every line queued before it has been written
+func (q *writeQueue) flush(ctx context.Context) error {
+	return q.submit(ctx, &writeRequest{})
+}
+
+// close closes stdin once the lines queued before it have been written
+func (q *writeQueue) close() error {
+	return q.submit(context.Background(), &writeRequest{close: true})
+}
+
+// stop ends the writer without waiting for queued lines, after stdin was
+// closed from outside
+func (q *writeQueue) stop() {
+	if q.aborted.CompareAndSwap(false, true) {
+		close(q.abort)
+	}
+	<-q.done
+}
+
+func (q *writeQueue) submit(ctx context.Context, req *writeRequest) error {
+	req.result = make(chan error, 1)
+
+	select {
+	case q.requests <- req:
+	case <-q.done:
+		return errQueueClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-req.result:
+		return err
+	case <-ctx.Done():
+		if req.state.CompareAndSwap(requestPending, requestCancelled) {
+			return ctx.Err()
+		}
+		// Already being written; cut it short
+		q.interrupt(req)
+		if err := <-req.result; err != nil {
+			return ctx.Err()
+		}
+		return nil
+	}
+}
+
+func (q *writeQueue) run() {
+	defer close(q.done)
+
+	for {
+		var req *writeRequest
+		select {
+		case req = <-q.requests:
+		case <-q.abort:
+			return
+		}
+		if !req.state.CompareAndSwap(requestPending, requestClaimed) {
+			continue
+		}
+
+		switch {
+		case req.close:
+			req.result <- q.w.Close()
+			return
+		case q.broken != nil:
+			req.result <- q.broken
+		case req.data == nil:
+			req.result <- nil
+		default:
+			if err := q.writeLine(req); err != nil {
+				q.broken = err
+				req.result <- err
+				continue
+			}
+			req.result <- nil
+		}
+	}
+}
+
+// writeLine writes the line of req, giving up after the write timeout
+func (q *writeQueue) writeLine(req *writeRequest) error {
+	d, ok := q.w.(writeDeadliner)
+	if ok {
+		q.mu.Lock()
+		q.current = req
+		if q.timeout > 0 {
+			d.SetWriteDeadline(time.Now().Add(q.timeout))
+		}
+		q.mu.Unlock()
+
+		defer func() {
+			q.mu.Lock()
+			q.current = nil
+			d.SetWriteDeadline(time.Time{})
+			q.mu.Unlock()
+		}()
+	}
+
+	_, err := q.w.Write(req.data)
+	return err
+}
+
+// interrupt aborts the write of req if it is still in progress
+func (q *writeQueue) interrupt(req *writeRequest) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.current == req {
+		q.w.(writeDeadliner).SetWriteDeadline(time.Now())
+	}
+}
@@ -0,0 +1,128 @@
+package transport
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWriteQueueWholeLines(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	var lines []string
+	read := make(chan struct{})
+	go func() {
+		defer close(read)
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(nil, 1<<20)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+	}()
+
+	q := newWriteQueue(w, 0)
+	ctx := context.Background()
+
+	// Lines larger than the pipe buffer must not interleave
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			line := fmt.Sprintf("%d:%s", i, strings.Repeat(fmt.Sprint(i), 100000))
+			if err := q.write(ctx, []byte(line)); err != nil {
+				t.Errorf("write: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if err := q.flush(ctx); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if err := q.close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	<-read
+
+	if len(lines) != 8 {
+		t.Fatalf("got %d lines, want 8", len(lines))
+	}
+	for _, line := range lines {
+		prefix, body, _ := strings.Cut(line, ":")
+		if body != strings.Repeat(prefix, 100000) {
+			t.Errorf("line %s... was interleaved", line[:10])
+		}
+	}
+
+	if err := q.write(ctx, []byte("late")); err != errQueueClosed {
+		t.Errorf("write after close = %v, want errQueueClosed", err)
+	}
+}
+
+func TestWriteQueueTimeout(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	// Nobody reads, so the line never fits into the pipe
+	q := newWriteQueue(w, 50*time.Millisecond)
+	defer q.stop()
+
+	big := bytes.Repeat([]byte("x"), 1<<20)
+	if err := q.write(context.Background(), big); !stderrors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("expected a deadline error, got %v", err)
+	}
+
+	// The partial line leaves the stream unusable
+	go io.Copy(io.Discard, r)
+	if err := q.write(context.Background(), []byte("next")); err == nil {
+		t.Error("expected writes after a timeout to fail")
+	}
+}
+
+func TestWriteQueueCancel(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	q := newWriteQueue(w, 0)
+	defer q.stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// The first write blocks on the full pipe, the second one waits behind it
+	errs := make(chan error, 2)
+	go func() { errs <- q.write(ctx, bytes.Repeat([]byte("x"), 1<<20)) }()
+	time.Sleep(10 * time.Millisecond)
+	go func() { errs <- q.write(ctx, []byte("queued")) }()
+
+	for range 2 {
+		select {
+		case err := <-errs:
+			if err != context.DeadlineExceeded {
+				t.Errorf("expected DeadlineExceeded, got %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("write did not return after ctx was done")
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package claudecode
+
+import (
+	"context"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// SendAndWait sends a user message and blocks until its ResultMessage,
+// returning everything Claude produced in between.
+//
+// The messages are consumed from Messages(), or from the session's channel
+// if sessionID belongs to a registered Session, so nothing else should read
+// that channel meanwhile. Errors keep flowing on Errors(). If ctx is done
+// or the CLI exits before the result, the partial turn is returned with the
+// error.
+//
+// Example:
+//
+//	turn, err := client.SendAndWait(ctx, "Summarize README.md", "default")
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Println(turn.Text(), turn.CostUSD)
+func (c *ClaudeSDKClient) SendAndWait(ctx context.Context, prompt string, sessionID string) (*types.Turn, error) {
+	c.mu.RLock()
+	outputDone := c.outputDone
+	c.mu.RUnlock()
+
+	source := c.messages
+	if s, ok := c.Session(sessionID); ok {
+		source = s.messages
+	}
+
+	done, err := acquireTurn(ctx, c.options, prompt)
+	if err != nil {
+		return nil, err
+	}
+	turn := &types.Turn{}
+	defer func() { done(turn.Usage) }()
+
+	if err := c.SendMessage(prompt, sessionID); err != nil {
+		return nil, err
+	}
+
+	for {
+		select {
+		case msg, ok := <-source:
+			if !ok {
+				return turn, errors.NewCLIConnectionError("message channel closed before result", nil)
+			}
+			if addToTurn(turn, msg) {
+				return turn, nil
+			}
+		case <-outputDone:
+			// Everything the CLI wrote is buffered by now
+			exited := errors.NewCLIConnectionError("CLI exited before the turn completed", nil)
+			for {
+				select {
+				case msg, ok := <-source:
+					if !ok {
+						return turn, exited
+					}
+					if addToTurn(turn, msg) {
+						return turn, nil
+					}
+				default:
+					return turn, exited
+				}
+			}
+		case <-ctx.Done():
+			return turn, ctx.Err()
+		}
+	}
+}
+
+// addToTurn adds msg to turn and reports whether it ended the turn
+func addToTurn(turn *types.Turn, msg types.Message) bool {
+	turn.Add(msg)
+	_, ok := msg.(*types.ResultMessage)
+	return ok
+}
+
+// SendAndWait sends a message in this session and waits for its result.
+// See ClaudeSDKClient.SendAndWait.
+func (s *Session) SendAndWait(ctx context.Context, prompt string) (*types.Turn, error) {
+	return s.client.SendAndWait(ctx, prompt, s.id)
+}
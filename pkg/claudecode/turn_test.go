@@ -0,0 +1,96 @@
+package claudecode
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/transporttest"
+)
+
+func TestSendAndWait(t *testing.T) {
+	// The CLI reports the session's running total with every result
+	mock := transporttest.NewMockTransport()
+	total := 0.0
+	mock.RespondFunc(transporttest.MatchType("user"), func(map[string]interface{}) []interface{} {
+		total += 0.25
+		return []interface{}{
+			map[string]interface{}{"type": "assistant", "model": "sonnet", "content": []interface{}{
+				map[string]interface{}{"type": "text", "text": "Let me check."},
+				map[string]interface{}{"type": "tool_use", "id": "toolu_1", "name": "Read", "input": map[string]interface{}{}},
+			}},
+			map[string]interface{}{"type": "assistant", "model": "sonnet", "content": []interface{}{
+				map[string]interface{}{"type": "text", "text": "Done."},
+			}},
+			map[string]interface{}{"type": "result", "subtype": "success", "session_id": "s1", "total_cost_usd": total,
+				"usage": map[string]interface{}{"input_tokens": 10, "output_tokens": 5}},
+		}
+	})
+	client := NewClaudeSDKClientWithTransport(nil, mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	turn, err := client.SendAndWait(ctx, "hello", "default")
+	if err != nil {
+		t.Fatalf("SendAndWait: %v", err)
+	}
+
+	if len(turn.Messages) != 3 || len(turn.Assistant) != 2 || turn.Result == nil {
+		t.Fatalf("unexpected turn: %+v", turn)
+	}
+	if len(turn.ToolUses) != 1 || turn.ToolUses[0].Name != "Read" {
+		t.Errorf("unexpected tool uses: %+v", turn.ToolUses)
+	}
+	if turn.Text() != "Let me check.\nDone." {
+		t.Errorf("Text() = %q", turn.Text())
+	}
+	if turn.CostUSD != 0.25 || turn.Usage.TotalTokens() != 15 {
+		t.Errorf("unexpected cost or usage: %v, %+v", turn.CostUSD, turn.Usage)
+	}
+
+	// The second turn costs what it added to the session
+	turn, err = client.SendAndWait(ctx, "again", "default")
+	if err != nil {
+		t.Fatalf("SendAndWait: %v", err)
+	}
+	if turn.CostUSD != 0.25 || turn.SessionCostUSD != 0.5 {
+		t.Errorf("second turn cost = %v, session %v; want 0.25, 0.5", turn.CostUSD, turn.SessionCostUSD)
+	}
+}
+
+func TestSendAndWaitCLIExit(t *testing.T) {
+	mock := transporttest.NewMockTransport()
+	mock.Respond(transporttest.MatchType("user"),
+		map[string]interface{}{"type": "assistant", "model": "sonnet", "content": []interface{}{
+			map[string]interface{}{"type": "text", "text": "partial"},
+		}},
+	)
+	client := NewClaudeSDKClientWithTransport(nil, mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		mock.Disconnect(nil)
+	}()
+
+	turn, err := client.SendAndWait(ctx, "hello", "default")
+	if err == nil {
+		t.Fatal("expected an error when the CLI exits mid-turn")
+	}
+	if turn == nil || turn.Text() != "partial" {
+		t.Errorf("expected the partial turn, got %+v", turn)
+	}
+}
@@ -0,0 +1,166 @@
+package claudecode
+
+import (
+	"context"
+	stderrors "errors"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+// exitErrorer is implemented by transports that know why the CLI exited
+type exitErrorer interface {
+	GetExitError() error
+}
+
+// Wait blocks until the conversation ends, because the CLI exited and was
+// not respawned, or the client is closed. It returns the error that ended
+// it: a *ProcessError for a CLI that failed, a connection error, the error
+// of a failed reconnect, or nil for a clean end. Errors the conversation
+// recovers from are not returned.
+//
+// Example:
+//
+//	client.CloseStdin()
+//	if err := client.Wait(ctx); err != nil {
+//	    log.Printf("conversation failed: %v", err)
+//	}
+func (c *ClaudeSDKClient) Wait(ctx context.Context) error {
+	c.mu.RLock()
+	outputDone := c.outputDone
+	c.mu.RUnlock()
+
+	if outputDone == nil {
+		return errors.NewCLIConnectionError("not connected. Call Connect() first", nil)
+	}
+
+	select {
+	case <-outputDone:
+	case <-c.ctx.Done():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	c.errsMu.Lock()
+	defer c.errsMu.Unlock()
+	return c.fatalErr
+}
+
+// holdTurnError keeps err for the next result with TurnErrors. Returns
+// false if errors are sent on Errors() instead.
+func (c *ClaudeSDKClient) holdTurnError(err error) bool {
+	if !c.options.TurnErrors {
+		return false
+	}
+
+	c.errsMu.Lock()
+	c.turnErrs = append(c.turnErrs, err)
+	c.errsMu.Unlock()
+	return true
+}
+
+// attachTurnErrors moves the errors held with TurnErrors to result
+func (c *ClaudeSDKClient) attachTurnErrors(result *types.ResultMessage) {
+	c.errsMu.Lock()
+	held := c.turnErrs
+	c.turnErrs = nil
+	c.errsMu.Unlock()
+
+	result.Errors = append(result.Errors, held...)
+}
+
+// noteConnError remembers a connection or process error, which ends the
+// conversation unless it is respawned
+func (c *ClaudeSDKClient) noteConnError(err error) {
+	if !stderrors.Is(err, errors.ErrCLIConnection) && !stderrors.Is(err, errors.ErrProcess) {
+		return
+	}
+
+	c.errsMu.Lock()
+	if c.connErr == nil {
+		c.connErr = err
+	}
+	c.errsMu.Unlock()
+}
+
+// reconnected forgets the connection error a respawn recovered from
+func (c *ClaudeSDKClient) reconnected() {
+	c.errsMu.Lock()
+	c.connErr = nil
+	c.errsMu.Unlock()
+}
+
+// endOutput records the error that ended the conversation and marks its
+// output complete. A nil err is derived from the CLI's exit and the
+// connection errors seen. With TurnErrors, errors no result has carried
+// yet are delivered in a final error result.
+func (c *ClaudeSDKClient) endOutput(err error) {
+	if err == nil {
+		err = c.exitError()
+	}
+
+	c.errsMu.Lock()
+	if err == nil {
+		err = c.connErr
+	}
+	if c.fatalErr == nil {
+		c.fatalErr = err
+	}
+	held := c.turnErrs
+	c.turnErrs = nil
+	c.errsMu.Unlock()
+
+	if c.options.TurnErrors && (len(held) > 0 || err != nil) {
+		if err != nil && !containsError(held, err) {
+			held = append(held, err)
+		}
+
+		c.resumeMu.Lock()
+		sessionID := c.sessionID
+		c.resumeMu.Unlock()
+
+		c.sendMessage(&types.ResultMessage{
+			Subtype:   types.ResultSubtypeErrorDuringExecution,
+			IsError:   true,
+			SessionID: sessionID,
+			Errors:    held,
+		})
+	}
+
+	c.outputOnce.Do(func() { close(c.outputDone) })
+}
+
+// exitError waits for the CLI to exit and returns why it failed. It is nil
+// for a clean exit, while shutting down and for transports that cannot tell.
+func (c *ClaudeSDKClient) exitError() error {
+	if c.shuttingDown.Load() {
+		return nil
+	}
+
+	c.mu.RLock()
+	t := c.transport
+	c.mu.RUnlock()
+
+	if graceful, ok := t.(transport.GracefulCloser); ok {
+		select {
+		case <-graceful.Exited():
+		case <-c.ctx.Done():
+			return nil
+		}
+	}
+	if e, ok := t.(exitErrorer); ok {
+		return e.GetExitError()
+	}
+	return nil
+}
+
+// containsError reports whether errs holds err itself
+func containsError(errs []error, err error) bool {
+	for _, e := range errs {
+		if e == err {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package claudecode
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/transport/transporttest"
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+func TestTurnErrorsAttachedToResult(t *testing.T) {
+	mock := transporttest.NewMockTransport()
+	client := NewClaudeSDKClientWithTransport(types.NewOptions().WithTurnErrors(), mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	mock.EmitMalformed()
+	mock.Emit(map[string]interface{}{"type": "result", "subtype": "success", "session_id": "s1"})
+
+	turn := &types.Turn{}
+	for turn.Result == nil {
+		select {
+		case msg := <-client.Messages():
+			turn.Add(msg)
+		case err := <-client.Errors():
+			t.Fatalf("error sent on Errors(): %v", err)
+		case <-ctx.Done():
+			t.Fatal("no result")
+		}
+	}
+
+	if len(turn.Result.Errors) != 1 || !stderrors.Is(turn.Err(), errors.ErrJSONDecode) {
+		t.Errorf("turn error = %v, want the decode error", turn.Err())
+	}
+	if turn.IsError() {
+		t.Error("a successful result with attached errors is not an error result")
+	}
+}
+
+func TestTurnErrorsFinalResult(t *testing.T) {
+	mock := transporttest.NewMockTransport()
+	client := NewClaudeSDKClientWithTransport(types.NewOptions().WithTurnErrors(), mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	// The connection breaks mid-turn
+	mock.Emit(map[string]interface{}{"type": "assistant", "model": "sonnet", "content": []interface{}{}})
+	mock.Disconnect(stderrors.New("connection reset"))
+
+	var result *types.ResultMessage
+	for result == nil {
+		select {
+		case msg := <-client.Messages():
+			result, _ = msg.(*types.ResultMessage)
+		case <-ctx.Done():
+			t.Fatal("no final result")
+		}
+	}
+	if !result.IsError || result.Subtype != types.ResultSubtypeErrorDuringExecution {
+		t.Errorf("unexpected final result: %+v", result)
+	}
+	if !stderrors.Is(result.Err(), errors.ErrCLIConnection) {
+		t.Errorf("result error = %v, want a connection error", result.Err())
+	}
+
+	if err := client.Wait(ctx); !stderrors.Is(err, errors.ErrCLIConnection) {
+		t.Errorf("Wait = %v, want a connection error", err)
+	}
+}
+
+func TestWaitCleanExit(t *testing.T) {
+	mock := transporttest.NewMockTransport()
+	client := NewClaudeSDKClientWithTransport(nil, mock)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Wait(ctx); err == nil {
+		t.Error("Wait before Connect succeeded")
+	}
+	if err := client.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	mock.Emit(map[string]interface{}{"type": "result", "subtype": "success", "session_id": "s1"})
+	mock.Disconnect(nil)
+	go func() {
+		for range client.Messages() {
+		}
+	}()
+
+	if err := client.Wait(ctx); err != nil {
+		t.Errorf("Wait = %v, want nil", err)
+	}
+}
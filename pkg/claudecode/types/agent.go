@@ -0,0 +1,27 @@
+package types
+
+// Models a subagent may run on. Any full model name is accepted as well.
+const (
+	AgentModelSonnet  = "sonnet"
+	AgentModelOpus    = "opus"
+	AgentModelHaiku   = "haiku"
+	AgentModelInherit = "inherit" // Same model as the main conversation
+)
+
+// AgentDefinition defines a custom subagent the main agent can delegate to
+// with the Task tool, as an alternative to a markdown file in
+// .claude/agents. It is passed to the CLI with --agents.
+type AgentDefinition struct {
+	// When the agent should be used; shown to the main agent
+	Description string `json:"description"`
+
+	// System prompt of the agent
+	Prompt string `json:"prompt"`
+
+	// Tools the agent may use; nil inherits all tools of the main agent
+	Tools []string `json:"tools,omitempty"`
+
+	// Model the agent runs on, e.g. AgentModelHaiku; empty uses the
+	// CLI's default for subagents
+	Model string `json:"model,omitempty"`
+}
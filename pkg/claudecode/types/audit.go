@@ -0,0 +1,57 @@
+package types
+
+import "time"
+
+// PermissionDecision is how a can_use_tool request was answered
+type PermissionDecision string
+
+const (
+	PermissionDecisionAllow PermissionDecision = "allow"
+	PermissionDecisionDeny  PermissionDecision = "deny"
+
+	// The CanUseTool callback returned an error; the CLI does not run the tool
+	PermissionDecisionError PermissionDecision = "error"
+
+	// CallbackTimeout expired and the tool use was denied
+	PermissionDecisionTimeout PermissionDecision = "timeout"
+)
+
+// PermissionAuditRecord is one can_use_tool request and the decision sent
+// back to the CLI. Records form a hash chain: Hash covers the record and
+// the previous record's Hash, so editing, removing or reordering records
+// of a log is detectable with claudecode.VerifyPermissionAudit.
+type PermissionAuditRecord struct {
+	// 1-based position in the client's audit trail
+	Seq       uint64    `json:"seq"`
+	Time      time.Time `json:"time"`
+	SessionID string    `json:"session_id,omitempty"`
+	RequestID string    `json:"request_id"`
+
+	ToolName  string `json:"tool_name"`
+	ToolUseID string `json:"tool_use_id,omitempty"`
+
+	// Hex SHA-256 of the JSON-encoded tool input, so the log does not
+	// store file contents or commands verbatim
+	InputHash string `json:"input_hash"`
+
+	Decision PermissionDecision `json:"decision"`
+
+	// The deny message, callback error or timeout
+	Reason    string `json:"reason,omitempty"`
+	Interrupt bool   `json:"interrupt,omitempty"`
+
+	// The callback allowed the tool with a modified input
+	InputUpdated bool `json:"input_updated,omitempty"`
+
+	Suggestions        []PermissionUpdate `json:"suggestions,omitempty"`
+	UpdatedPermissions []PermissionUpdate `json:"updated_permissions,omitempty"`
+
+	// How long the decision took
+	DurationMS int64 `json:"duration_ms"`
+
+	PrevHash string `json:"prev_hash"`
+	Hash     string `json:"hash"`
+}
+
+// PermissionAuditCallback receives every permission audit record
+type PermissionAuditCallback func(record *PermissionAuditRecord)
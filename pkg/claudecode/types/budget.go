@@ -0,0 +1,13 @@
+package types
+
+// BudgetUsage reports spend against the MaxCostUSD and MaxTokens budgets
+type BudgetUsage struct {
+	CostUSD    float64 `json:"cost_usd"`
+	Tokens     int     `json:"tokens"`
+	MaxCostUSD float64 `json:"max_cost_usd,omitempty"`
+	MaxTokens  int     `json:"max_tokens,omitempty"`
+}
+
+// BudgetWarningCallback is called once when spend first crosses
+// BudgetWarningRatio of a budget
+type BudgetWarningCallback func(usage BudgetUsage)
@@ -0,0 +1,25 @@
+package types
+
+// ChangeKind is what happened to a file over a session
+type ChangeKind string
+
+const (
+	ChangeCreated  ChangeKind = "created"
+	ChangeModified ChangeKind = "modified"
+	ChangeDeleted  ChangeKind = "deleted"
+)
+
+// ChangedFile is a file changed by Write, Edit, MultiEdit or NotebookEdit
+// tool uses in a session, e.g. to summarize or roll back Claude's work
+type ChangedFile struct {
+	Path string     `json:"path"`
+	Kind ChangeKind `json:"kind"`
+
+	// Content before the first change and after the last. Nil when the file
+	// did not exist, could not be read or was larger than the tracker keeps.
+	Before *string `json:"before,omitempty"`
+	After  *string `json:"after,omitempty"`
+
+	// The tool uses that changed the file, in order
+	ToolUseIDs []string `json:"tool_use_ids"`
+}
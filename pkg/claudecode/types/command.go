@@ -0,0 +1,40 @@
+package types
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+)
+
+// CLIPathEnv names the environment variable consulted for the CLI binary
+// when ClaudeCodeOptions.CLIPath is empty
+const CLIPathEnv = "CLAUDE_CODE_CLI_PATH"
+
+// CommandFactory builds the command that runs the CLI at path with args,
+// e.g. to wrap it in nice, a container or a sandbox. The SDK sets Dir and
+// Env when the factory leaves them empty and always attaches the standard
+// streams, so the factory must not set Stdin, Stdout or Stderr.
+type CommandFactory func(ctx context.Context, path string, args []string) *exec.Cmd
+
+// CommandLine is the command that runs the CLI, as built from the options
+type CommandLine struct {
+	Args []string // Program and arguments
+	Env  []string // Full environment, as KEY=value
+	Dir  string   // Working directory; empty for the current one
+}
+
+// String returns the arguments quoted for a POSIX shell
+func (c *CommandLine) String() string {
+	quoted := make([]string, len(c.Args))
+	for i, arg := range c.Args {
+		quoted[i] = shellQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+func shellQuote(arg string) string {
+	if arg != "" && strings.Trim(arg, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./=:,@+%") == "" {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
@@ -0,0 +1,16 @@
+package types
+
+// OverflowPolicy decides what happens to a message when the consumer falls
+// behind and the message channel is full
+type OverflowPolicy string
+
+const (
+	// OverflowBlock waits for the consumer, which also stops reading from the CLI
+	OverflowBlock OverflowPolicy = "block"
+
+	// OverflowDropOldest discards the oldest queued message to make room
+	OverflowDropOldest OverflowPolicy = "drop_oldest"
+
+	// OverflowError discards the new message and reports a QueueOverflowError
+	OverflowError OverflowPolicy = "error"
+)
@@ -0,0 +1,34 @@
+package types
+
+import "strings"
+
+// CLIFlag is an additional command line flag passed to the CLI
+type CLIFlag struct {
+	// Flag name; "--" is prepended to names without leading dashes
+	Name string `json:"name"`
+
+	// Nil for flags without a value
+	Value *string `json:"value,omitempty"`
+}
+
+// Flag returns a flag without a value
+func Flag(name string) CLIFlag {
+	return CLIFlag{Name: name}
+}
+
+// FlagWithValue returns a flag followed by value
+func FlagWithValue(name, value string) CLIFlag {
+	return CLIFlag{Name: name, Value: &value}
+}
+
+// Args returns the command line arguments of the flag
+func (f CLIFlag) Args() []string {
+	name := f.Name
+	if !strings.HasPrefix(name, "-") {
+		name = "--" + name
+	}
+	if f.Value == nil {
+		return []string{name}
+	}
+	return []string{name, *f.Value}
+}
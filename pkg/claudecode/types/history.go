@@ -0,0 +1,36 @@
+package types
+
+import "time"
+
+// ConfigDirEnv names the environment variable that moves the CLI's state
+// directory away from ~/.claude
+const ConfigDirEnv = "CLAUDE_CONFIG_DIR"
+
+// SessionInfo describes a conversation stored by the CLI that can be
+// continued with ClaudeCodeOptions.Resume
+type SessionInfo struct {
+	ID  string `json:"session_id"`
+	CWD string `json:"cwd"`
+
+	// Summary the CLI generated for the conversation, if any
+	Summary string `json:"summary,omitempty"`
+
+	// Text of the first prompt, e.g. for a picker when there is no summary
+	FirstPrompt string `json:"first_prompt,omitempty"`
+
+	GitBranch    string    `json:"git_branch,omitempty"`
+	Created      time.Time `json:"created"`
+	Updated      time.Time `json:"updated"`
+	MessageCount int       `json:"message_count"` // User and assistant messages
+
+	// Transcript file the session was read from
+	Path string `json:"path"`
+}
+
+// Title returns the summary, falling back to the first prompt
+func (s *SessionInfo) Title() string {
+	if s.Summary != "" {
+		return s.Summary
+	}
+	return s.FirstPrompt
+}
@@ -0,0 +1,86 @@
+package types
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// regexMeta are characters that make a matcher a regular expression rather
+// than a list of tool names and globs
+const regexMeta = `.^$+()[]{}\`
+
+// HookPattern is a compiled HookMatcher.Matcher. A matcher is one of:
+//
+//   - empty or "*", matching everything
+//   - tool names and globs separated by "|", e.g. "Edit|Write" or "mcp__github__*"
+//   - a regular expression matching the whole name, e.g. "mcp__.*__read_.+"
+type HookPattern struct {
+	re     *regexp.Regexp // nil matches everything
+	source string         // Matcher as written, when the CLI understands it
+}
+
+// CompileHookMatcher compiles a HookMatcher.Matcher, nil matching everything
+func CompileHookMatcher(matcher *string) (*HookPattern, error) {
+	if matcher == nil {
+		return &HookPattern{}, nil
+	}
+	pattern := strings.TrimSpace(*matcher)
+	if pattern == "" || pattern == "*" {
+		return &HookPattern{}, nil
+	}
+
+	expr, source := pattern, pattern
+	if !strings.ContainsAny(pattern, regexMeta) {
+		alternatives := strings.Split(pattern, "|")
+		for i, alternative := range alternatives {
+			alternative = strings.TrimSpace(alternative)
+			if alternative == "" {
+				return nil, fmt.Errorf("empty alternative in %q", pattern)
+			}
+			alternatives[i] = globExpr(alternative)
+		}
+		expr = strings.Join(alternatives, "|")
+		if strings.ContainsAny(pattern, "*?") {
+			source = ""
+		}
+	}
+
+	re, err := regexp.Compile("^(?:" + expr + ")$")
+	if err != nil {
+		return nil, err
+	}
+	return &HookPattern{re: re, source: source}, nil
+}
+
+// globExpr translates a tool name glob, where * matches any run of
+// characters and ? a single one, into a regular expression
+func globExpr(glob string) string {
+	expr := regexp.QuoteMeta(glob)
+	expr = strings.ReplaceAll(expr, `\*`, ".*")
+	return strings.ReplaceAll(expr, `\?`, ".")
+}
+
+// Match reports whether name, such as a tool name, matches the pattern
+func (p *HookPattern) Match(name string) bool {
+	return p.re == nil || p.re.MatchString(name)
+}
+
+// MatchesAll reports whether the pattern matches every name
+func (p *HookPattern) MatchesAll() bool {
+	return p.re == nil
+}
+
+// String returns the matcher to register with the CLI: the matcher as
+// written, or the anchored regular expression for globs, which the CLI does
+// not know. It is "" if the pattern matches everything.
+func (p *HookPattern) String() string {
+	switch {
+	case p.re == nil:
+		return ""
+	case p.source != "":
+		return p.source
+	default:
+		return p.re.String()
+	}
+}
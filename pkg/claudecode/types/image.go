@@ -0,0 +1,55 @@
+package types
+
+import "encoding/base64"
+
+// ImageSource is the encoded data of an image block
+type ImageSource struct {
+	Type      string `json:"type"`       // "base64"
+	MediaType string `json:"media_type"` // e.g. "image/png"
+	Data      string `json:"data"`       // Base64-encoded image bytes
+}
+
+// ImageBlock represents image content
+type ImageBlock struct {
+	Source ImageSource `json:"source"`
+}
+
+func (ImageBlock) isContentBlock() {}
+
+// NewImageBlock creates a base64 image block from raw image bytes
+func NewImageBlock(mediaType string, data []byte) *ImageBlock {
+	return &ImageBlock{Source: ImageSource{
+		Type:      "base64",
+		MediaType: mediaType,
+		Data:      base64.StdEncoding.EncodeToString(data),
+	}}
+}
+
+// Bytes decodes the image data
+func (b ImageBlock) Bytes() ([]byte, error) {
+	return base64.StdEncoding.DecodeString(b.Source.Data)
+}
+
+// Images returns the image blocks in a tool result's content, such as
+// screenshots returned by a tool
+func (b ToolResultBlock) Images() []*ImageBlock {
+	items, ok := b.Content.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	var images []*ImageBlock
+	for _, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok || m["type"] != "image" {
+			continue
+		}
+		source, _ := m["source"].(map[string]interface{})
+		image := &ImageBlock{}
+		image.Source.Type, _ = source["type"].(string)
+		image.Source.MediaType, _ = source["media_type"].(string)
+		image.Source.Data, _ = source["data"].(string)
+		images = append(images, image)
+	}
+	return images
+}
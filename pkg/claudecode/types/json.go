@@ -0,0 +1,287 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Content block discriminators, as used by the CLI
+const (
+	ContentBlockTypeText       = "text"
+	ContentBlockTypeThinking   = "thinking"
+	ContentBlockTypeToolUse    = "tool_use"
+	ContentBlockTypeToolResult = "tool_result"
+	ContentBlockTypeImage      = "image"
+)
+
+// Messages and content blocks marshal with the CLI's "type" (and, for system
+// messages, "subtype") discriminator so they can be decoded again with
+// UnmarshalMessage and UnmarshalContentBlock.
+
+func (b TextBlock) MarshalJSON() ([]byte, error) {
+	type alias TextBlock
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		alias
+	}{ContentBlockTypeText, alias(b)})
+}
+
+func (b ThinkingBlock) MarshalJSON() ([]byte, error) {
+	type alias ThinkingBlock
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		alias
+	}{ContentBlockTypeThinking, alias(b)})
+}
+
+func (b ToolUseBlock) MarshalJSON() ([]byte, error) {
+	type alias ToolUseBlock
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		alias
+	}{ContentBlockTypeToolUse, alias(b)})
+}
+
+func (b ToolResultBlock) MarshalJSON() ([]byte, error) {
+	type alias ToolResultBlock
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		alias
+	}{ContentBlockTypeToolResult, alias(b)})
+}
+
+func (b ImageBlock) MarshalJSON() ([]byte, error) {
+	type alias ImageBlock
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		alias
+	}{ContentBlockTypeImage, alias(b)})
+}
+
+// UnmarshalContentBlock decodes a content block by its "type" field
+func UnmarshalContentBlock(data []byte) (ContentBlock, error) {
+	var head struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &head); err != nil {
+		return nil, err
+	}
+
+	var block ContentBlock
+	switch head.Type {
+	case ContentBlockTypeText:
+		block = &TextBlock{}
+	case ContentBlockTypeThinking:
+		block = &ThinkingBlock{}
+	case ContentBlockTypeToolUse:
+		block = &ToolUseBlock{}
+	case ContentBlockTypeToolResult:
+		block = &ToolResultBlock{}
+	case ContentBlockTypeImage:
+		block = &ImageBlock{}
+	default:
+		return nil, fmt.Errorf("unknown content block type %q", head.Type)
+	}
+
+	if err := json.Unmarshal(data, block); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+func unmarshalContentBlocks(raw []json.RawMessage) ([]ContentBlock, error) {
+	blocks := make([]ContentBlock, 0, len(raw))
+	for _, data := range raw {
+		block, err := UnmarshalContentBlock(data)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
+
+func (m UserMessage) MarshalJSON() ([]byte, error) {
+	type alias UserMessage
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		alias
+	}{MessageTypeUser, alias(m)})
+}
+
+// UnmarshalJSON decodes content as either a string or a list of blocks
+func (m *UserMessage) UnmarshalJSON(data []byte) error {
+	type alias UserMessage
+	var raw struct {
+		alias
+		Content json.RawMessage `json:"content"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*m = UserMessage(raw.alias)
+	m.Content = nil
+	if len(raw.Content) == 0 || string(raw.Content) == "null" {
+		return nil
+	}
+
+	var text string
+	if err := json.Unmarshal(raw.Content, &text); err == nil {
+		m.Content = text
+		return nil
+	}
+
+	var blocks []json.RawMessage
+	if err := json.Unmarshal(raw.Content, &blocks); err != nil {
+		return fmt.Errorf("user message content: %w", err)
+	}
+	parsed, err := unmarshalContentBlocks(blocks)
+	if err != nil {
+		return err
+	}
+	m.Content = parsed
+	return nil
+}
+
+func (m AssistantMessage) MarshalJSON() ([]byte, error) {
+	type alias AssistantMessage
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		alias
+	}{MessageTypeAssistant, alias(m)})
+}
+
+// UnmarshalJSON decodes content blocks by their "type" field
+func (m *AssistantMessage) UnmarshalJSON(data []byte) error {
+	type alias AssistantMessage
+	var raw struct {
+		alias
+		Content []json.RawMessage `json:"content"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	blocks, err := unmarshalContentBlocks(raw.Content)
+	if err != nil {
+		return err
+	}
+	*m = AssistantMessage(raw.alias)
+	m.Content = blocks
+	return nil
+}
+
+// Types embedding SystemMessage inherit its MarshalJSON, so each system
+// message defines its own. Their aliases still embed SystemMessage; the
+// MarshalJSON field of the wrapper hides the promoted method so encoding/json
+// encodes the fields directly. Custom types embedding SystemMessage need a
+// MarshalJSON of their own for the same reason.
+
+func (m SystemMessage) MarshalJSON() ([]byte, error) {
+	type alias SystemMessage
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		alias
+	}{Type: MessageTypeSystem, alias: alias(m)})
+}
+
+func (m InitMessage) MarshalJSON() ([]byte, error) {
+	type alias InitMessage
+	return json.Marshal(struct {
+		Type        string   `json:"type"`
+		MarshalJSON struct{} `json:"-"`
+		alias
+	}{Type: MessageTypeSystem, alias: alias(m)})
+}
+
+func (m CompactBoundaryMessage) MarshalJSON() ([]byte, error) {
+	type alias CompactBoundaryMessage
+	return json.Marshal(struct {
+		Type        string   `json:"type"`
+		MarshalJSON struct{} `json:"-"`
+		alias
+	}{Type: MessageTypeSystem, alias: alias(m)})
+}
+
+func (m ErrorMessage) MarshalJSON() ([]byte, error) {
+	type alias ErrorMessage
+	return json.Marshal(struct {
+		Type        string   `json:"type"`
+		MarshalJSON struct{} `json:"-"`
+		alias
+	}{Type: MessageTypeSystem, alias: alias(m)})
+}
+
+func (m ReconnectedMessage) MarshalJSON() ([]byte, error) {
+	type alias ReconnectedMessage
+	return json.Marshal(struct {
+		Type        string   `json:"type"`
+		MarshalJSON struct{} `json:"-"`
+		alias
+	}{Type: MessageTypeSystem, alias: alias(m)})
+}
+
+func (m ResultMessage) MarshalJSON() ([]byte, error) {
+	type alias ResultMessage
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		alias
+	}{MessageTypeResult, alias(m)})
+}
+
+func (m StreamEvent) MarshalJSON() ([]byte, error) {
+	type alias StreamEvent
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		alias
+	}{MessageTypeStream, alias(m)})
+}
+
+// UnmarshalMessage decodes a message marshaled by this package, or a CLI
+// frame, into its typed form. Unknown types decode into a *CustomMessage.
+func UnmarshalMessage(data []byte) (Message, error) {
+	var head struct {
+		Type    string `json:"type"`
+		Subtype string `json:"subtype"`
+	}
+	if err := json.Unmarshal(data, &head); err != nil {
+		return nil, err
+	}
+
+	var msg Message
+	switch head.Type {
+	case MessageTypeUser:
+		msg = &UserMessage{}
+	case MessageTypeAssistant:
+		msg = &AssistantMessage{}
+	case MessageTypeResult:
+		msg = &ResultMessage{}
+	case MessageTypeStream, MessageTypeStreamEvent:
+		msg = &StreamEvent{}
+	case MessageTypeSystem:
+		switch head.Subtype {
+		case SystemSubtypeInit:
+			msg = &InitMessage{}
+		case SystemSubtypeCompactBoundary:
+			msg = &CompactBoundaryMessage{}
+		case SystemSubtypeError:
+			msg = &ErrorMessage{}
+		case SystemSubtypeReconnected:
+			msg = &ReconnectedMessage{}
+		case SystemSubtypeModelFallback:
+			msg = &ModelFallbackMessage{}
+		default:
+			msg = &SystemMessage{}
+		}
+	case "":
+		return nil, fmt.Errorf("message missing 'type' field")
+	default:
+		msg = &CustomMessage{}
+	}
+
+	if err := json.Unmarshal(data, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
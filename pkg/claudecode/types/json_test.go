@@ -0,0 +1,163 @@
+package types_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
+)
+
+func TestMessageJSONRoundTrip(t *testing.T) {
+	isError := false
+	result := "done"
+	cost := 0.5
+	parent := "toolu_0"
+	sent := time.Date(2025, 6, 1, 12, 30, 0, 0, time.UTC)
+
+	messages := []types.Message{
+		&types.UserMessage{Content: "hello", SessionID: "s1", UUID: "u0", Timestamp: sent},
+		&types.UserMessage{Content: []types.ContentBlock{
+			&types.ToolResultBlock{ToolUseID: "toolu_1", Content: "ok", IsError: &isError},
+			types.NewImageBlock("image/png", []byte("png")),
+		}},
+		&types.AssistantMessage{Model: "sonnet", ParentToolUseID: &parent, Content: []types.ContentBlock{
+			&types.TextBlock{Text: "hi"},
+			&types.ThinkingBlock{Thinking: "hmm", Signature: "sig"},
+			&types.ToolUseBlock{ID: "toolu_1", Name: "Read", Input: map[string]interface{}{"path": "a.go"}},
+		}},
+		&types.SystemMessage{Subtype: "status", Data: map[string]interface{}{"ok": true}, UUID: "u2"},
+		&types.InitMessage{
+			SystemMessage: types.SystemMessage{Subtype: "init", Data: map[string]interface{}{}, Timestamp: sent},
+			SessionID:     "s1",
+			Model:         "sonnet",
+			Tools:         []string{"Read"},
+			MCPServers:    []types.MCPServerStatus{{Name: "fs", Status: "connected"}},
+		},
+		&types.CompactBoundaryMessage{SystemMessage: types.SystemMessage{Subtype: "compact_boundary"}, Trigger: "auto", PreTokens: 100},
+		&types.ErrorMessage{SystemMessage: types.SystemMessage{Subtype: types.SystemSubtypeError}, Error: "overloaded", SessionID: "s1"},
+		&types.ReconnectedMessage{SystemMessage: types.SystemMessage{Subtype: types.SystemSubtypeReconnected}, Attempt: 2},
+		&types.ResultMessage{Subtype: "success", SessionID: "s1", NumTurns: 1, TotalCostUSD: &cost, Result: &result, UUID: "u3",
+			Usage: &types.Usage{InputTokens: 3, OutputTokens: 4}},
+		&types.StreamEvent{UUID: "u1", SessionID: "s1", Event: map[string]interface{}{"type": "message_stop"}},
+		&types.CustomMessage{Type: "heartbeat", Data: map[string]interface{}{"n": 1.0}},
+	}
+
+	for _, msg := range messages {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			t.Fatalf("marshal %T: %v", msg, err)
+		}
+
+		decoded, err := types.UnmarshalMessage(data)
+		if err != nil {
+			t.Fatalf("unmarshal %s: %v", data, err)
+		}
+		if !reflect.DeepEqual(decoded, msg) {
+			t.Errorf("%T did not round trip:\n got %#v\nwant %#v\njson %s", msg, decoded, msg, data)
+		}
+	}
+}
+
+func TestMarshalDiscriminators(t *testing.T) {
+	data, _ := json.Marshal(types.InitMessage{SystemMessage: types.SystemMessage{Subtype: "init"}, Model: "sonnet"})
+
+	var fields map[string]interface{}
+	json.Unmarshal(data, &fields)
+	if fields["type"] != "system" || fields["subtype"] != "init" || fields["model"] != "sonnet" {
+		t.Errorf("unexpected init JSON: %s", data)
+	}
+
+	data, _ = json.Marshal([]types.ContentBlock{types.TextBlock{Text: "a"}, &types.ToolUseBlock{ID: "t", Name: "Bash"}})
+	if string(data) != `[{"type":"text","text":"a"},{"type":"tool_use","id":"t","name":"Bash","input":null}]` {
+		t.Errorf("unexpected blocks JSON: %s", data)
+	}
+}
+
+func TestDecodeStreamEvent(t *testing.T) {
+	tests := []struct {
+		event map[string]interface{}
+		check func(types.APIStreamEvent) bool
+	}{
+		{
+			map[string]interface{}{"type": "message_start", "message": map[string]interface{}{
+				"id": "msg_1", "role": "assistant", "model": "sonnet", "usage": map[string]interface{}{"input_tokens": 12.0},
+			}},
+			func(e types.APIStreamEvent) bool {
+				m, ok := e.(*types.MessageStartEvent)
+				return ok && m.Message.Model == "sonnet" && m.Message.Usage.InputTokens == 12
+			},
+		},
+		{
+			map[string]interface{}{"type": "content_block_start", "index": 1.0, "content_block": map[string]interface{}{
+				"type": "tool_use", "id": "toolu_1", "name": "Read", "input": map[string]interface{}{},
+			}},
+			func(e types.APIStreamEvent) bool {
+				m, ok := e.(*types.ContentBlockStartEvent)
+				if !ok || m.Index != 1 || m.BlockType != types.ContentBlockTypeToolUse {
+					return false
+				}
+				block, ok := m.ContentBlock.(*types.ToolUseBlock)
+				return ok && block.Name == "Read"
+			},
+		},
+		{
+			map[string]interface{}{"type": "content_block_start", "index": 2.0, "content_block": map[string]interface{}{"type": "server_tool_use"}},
+			func(e types.APIStreamEvent) bool {
+				m, ok := e.(*types.ContentBlockStartEvent)
+				return ok && m.ContentBlock == nil && m.BlockType == "server_tool_use"
+			},
+		},
+		{
+			map[string]interface{}{"type": "content_block_delta", "index": 0.0, "delta": map[string]interface{}{"type": "text_delta", "text": "Hi"}},
+			func(e types.APIStreamEvent) bool {
+				m, ok := e.(*types.ContentBlockDeltaEvent)
+				return ok && m.Delta.Type == types.DeltaTypeText && m.Delta.Text == "Hi"
+			},
+		},
+		{
+			map[string]interface{}{"type": "content_block_stop", "index": 3.0},
+			func(e types.APIStreamEvent) bool {
+				m, ok := e.(*types.ContentBlockStopEvent)
+				return ok && m.Index == 3
+			},
+		},
+		{
+			map[string]interface{}{"type": "message_delta", "delta": map[string]interface{}{"stop_reason": "end_turn"},
+				"usage": map[string]interface{}{"output_tokens": 7.0}},
+			func(e types.APIStreamEvent) bool {
+				m, ok := e.(*types.MessageDeltaEvent)
+				return ok && m.Delta.StopReason == "end_turn" && m.Usage.OutputTokens == 7
+			},
+		},
+		{
+			map[string]interface{}{"type": "message_stop"},
+			func(e types.APIStreamEvent) bool {
+				_, ok := e.(*types.MessageStopEvent)
+				return ok
+			},
+		},
+		{
+			map[string]interface{}{"type": "ping"},
+			func(e types.APIStreamEvent) bool {
+				m, ok := e.(*types.UnknownStreamEvent)
+				return ok && m.EventType() == "ping"
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		event, err := types.StreamEvent{Event: tt.event}.DecodeEvent()
+		if err != nil {
+			t.Fatalf("DecodeEvent(%v): %v", tt.event, err)
+		}
+		if !tt.check(event) {
+			t.Errorf("unexpected decoding of %v: %#v", tt.event, event)
+		}
+	}
+
+	if _, err := (types.StreamEvent{Event: map[string]interface{}{}}).DecodeEvent(); err == nil {
+		t.Error("expected an error for an event without type")
+	}
+}
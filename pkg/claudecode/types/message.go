@@ -0,0 +1,35 @@
+package types
+
+import "strings"
+
+// Text returns the message's text blocks joined by newlines
+func (m *AssistantMessage) Text() string {
+	var parts []string
+	for _, block := range m.Content {
+		if text, ok := block.(*TextBlock); ok {
+			parts = append(parts, text.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// ToolUses returns the tools the message calls, in order
+func (m *AssistantMessage) ToolUses() []ToolUseBlock {
+	var uses []ToolUseBlock
+	for _, block := range m.Content {
+		if toolUse, ok := block.(*ToolUseBlock); ok {
+			uses = append(uses, *toolUse)
+		}
+	}
+	return uses
+}
+
+// HasThinking reports whether the message contains a thinking block
+func (m *AssistantMessage) HasThinking() bool {
+	for _, block := range m.Content {
+		if _, ok := block.(*ThinkingBlock); ok {
+			return true
+		}
+	}
+	return false
+}
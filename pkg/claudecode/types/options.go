@@ -0,0 +1,373 @@
+package types
+
+import (
+	"io"
+	"log/slog"
+	"time"
+)
+
+// NewOptions returns empty options to be configured with the With* methods.
+// Each method sets a field and returns the options, so calls can be chained
+// without pointer helpers:
+//
+//	options := types.NewOptions().
+//	    WithModel("sonnet").
+//	    WithSystemPrompt("You are a helpful coding assistant.").
+//	    WithAllowedTools("Read", "Write", "Edit").
+//	    WithMaxTurns(5)
+//
+// The result is an ordinary *ClaudeCodeOptions; fields without a builder
+// method can still be set directly.
+func NewOptions() *ClaudeCodeOptions {
+	return &ClaudeCodeOptions{}
+}
+
+// WithModel sets the model
+func (o *ClaudeCodeOptions) WithModel(model string) *ClaudeCodeOptions {
+	o.Model = &model
+	return o
+}
+
+// WithSystemPrompt replaces the system prompt
+func (o *ClaudeCodeOptions) WithSystemPrompt(prompt string) *ClaudeCodeOptions {
+	o.SystemPrompt = &prompt
+	return o
+}
+
+// WithAppendSystemPrompt appends to the default system prompt
+func (o *ClaudeCodeOptions) WithAppendSystemPrompt(prompt string) *ClaudeCodeOptions {
+	o.AppendSystemPrompt = &prompt
+	return o
+}
+
+// WithAllowedTools adds tools Claude may use without asking
+func (o *ClaudeCodeOptions) WithAllowedTools(tools ...string) *ClaudeCodeOptions {
+	o.AllowedTools = append(o.AllowedTools, tools...)
+	return o
+}
+
+// WithDisallowedTools adds tools Claude may not use
+func (o *ClaudeCodeOptions) WithDisallowedTools(tools ...string) *ClaudeCodeOptions {
+	o.DisallowedTools = append(o.DisallowedTools, tools...)
+	return o
+}
+
+// WithMaxTurns limits the number of agentic turns
+func (o *ClaudeCodeOptions) WithMaxTurns(turns int) *ClaudeCodeOptions {
+	o.MaxTurns = &turns
+	return o
+}
+
+// WithPermissionMode sets the permission mode
+func (o *ClaudeCodeOptions) WithPermissionMode(mode PermissionMode) *ClaudeCodeOptions {
+	o.PermissionMode = &mode
+	return o
+}
+
+// WithCWD sets the working directory of the CLI
+func (o *ClaudeCodeOptions) WithCWD(dir string) *ClaudeCodeOptions {
+	o.CWD = &dir
+	return o
+}
+
+// WithAddDirs adds directories Claude may access besides the working directory
+func (o *ClaudeCodeOptions) WithAddDirs(dirs ...string) *ClaudeCodeOptions {
+	o.AddDirs = append(o.AddDirs, dirs...)
+	return o
+}
+
+// WithResume resumes an existing session
+func (o *ClaudeCodeOptions) WithResume(sessionID string) *ClaudeCodeOptions {
+	o.Resume = &sessionID
+	return o
+}
+
+// WithForkSession resumes an existing session into a new session ID
+func (o *ClaudeCodeOptions) WithForkSession(sessionID string) *ClaudeCodeOptions {
+	o.Resume = &sessionID
+	o.ForkSession = true
+	return o
+}
+
+// WithOutputFormat selects how the CLI reports a query, e.g.
+// OutputFormatJSON when only the final result is needed
+func (o *ClaudeCodeOptions) WithOutputFormat(format OutputFormat) *ClaudeCodeOptions {
+	o.OutputFormat = format
+	return o
+}
+
+// WithSessionID starts the session under the given UUID instead of one
+// chosen by the CLI; combined with WithForkSession it names the fork
+func (o *ClaudeCodeOptions) WithSessionID(sessionID string) *ClaudeCodeOptions {
+	o.SessionID = &sessionID
+	return o
+}
+
+// WithContinueConversation continues the most recent conversation
+func (o *ClaudeCodeOptions) WithContinueConversation() *ClaudeCodeOptions {
+	o.ContinueConversation = true
+	return o
+}
+
+// WithSettings sets the settings file path or JSON
+func (o *ClaudeCodeOptions) WithSettings(settings string) *ClaudeCodeOptions {
+	o.Settings = &settings
+	return o
+}
+
+// WithUser sets the user identifier
+func (o *ClaudeCodeOptions) WithUser(user string) *ClaudeCodeOptions {
+	o.User = &user
+	return o
+}
+
+// WithEnv sets an environment variable for the CLI process
+func (o *ClaudeCodeOptions) WithEnv(key, value string) *ClaudeCodeOptions {
+	if o.Env == nil {
+		o.Env = make(map[string]string)
+	}
+	o.Env[key] = value
+	return o
+}
+
+// WithExtraArg passes an additional flag to the CLI. Use an empty value for
+// flags without a value. Flags are passed in the order added and may repeat.
+func (o *ClaudeCodeOptions) WithExtraArg(flag, value string) *ClaudeCodeOptions {
+	if value == "" {
+		o.ExtraFlags = append(o.ExtraFlags, Flag(flag))
+	} else {
+		o.ExtraFlags = append(o.ExtraFlags, FlagWithValue(flag, value))
+	}
+	return o
+}
+
+// WithMCPServer adds an MCP server
+func (o *ClaudeCodeOptions) WithMCPServer(name string, config MCPServerConfig) *ClaudeCodeOptions {
+	if o.MCPServers == nil {
+		o.MCPServers = make(map[string]MCPServerConfig)
+	}
+	o.MCPServers[name] = config
+	return o
+}
+
+// WithCanUseTool sets the tool permission callback
+func (o *ClaudeCodeOptions) WithCanUseTool(callback CanUseTool) *ClaudeCodeOptions {
+	o.CanUseTool = callback
+	return o
+}
+
+// WithHook adds a hook matcher for an event
+func (o *ClaudeCodeOptions) WithHook(event HookEvent, matcher HookMatcher) *ClaudeCodeOptions {
+	if o.Hooks == nil {
+		o.Hooks = make(map[HookEvent][]HookMatcher)
+	}
+	o.Hooks[event] = append(o.Hooks[event], matcher)
+	return o
+}
+
+// WithPartialMessages enables streaming of partial assistant messages
+func (o *ClaudeCodeOptions) WithPartialMessages() *ClaudeCodeOptions {
+	o.IncludePartialMessages = true
+	return o
+}
+
+// WithDebugStderr mirrors the CLI's debug output to w
+func (o *ClaudeCodeOptions) WithDebugStderr(w io.Writer) *ClaudeCodeOptions {
+	o.DebugStderr = w
+	return o
+}
+
+// WithRawOutput copies every line read from the CLI to w before parsing,
+// e.g. to ship protocol logs or debug a message the SDK fails to parse
+func (o *ClaudeCodeOptions) WithRawOutput(w io.Writer) *ClaudeCodeOptions {
+	o.RawOutput = w
+	return o
+}
+
+// WithStrictMessageTypes reports message types without a parser as parse
+// errors instead of passing them on as CustomMessage
+func (o *ClaudeCodeOptions) WithStrictMessageTypes() *ClaudeCodeOptions {
+	o.StrictMessageTypes = true
+	return o
+}
+
+// WithStrictParsing fails on messages missing optional fields or carrying
+// unknown content blocks instead of delivering them with a ParseWarning
+func (o *ClaudeCodeOptions) WithStrictParsing() *ClaudeCodeOptions {
+	o.StrictParsing = true
+	return o
+}
+
+// WithParseWarnings sends what lenient parsing tolerated to ch
+func (o *ClaudeCodeOptions) WithParseWarnings(ch chan<- ParseWarning) *ClaudeCodeOptions {
+	o.ParseWarnings = ch
+	return o
+}
+
+// WithFileChanges tracks the files Claude's tool uses change, reported by
+// ClaudeSDKClient.Changes
+func (o *ClaudeCodeOptions) WithFileChanges() *ClaudeCodeOptions {
+	o.TrackFileChanges = true
+	return o
+}
+
+// WithPermissionAudit records every tool permission decision as a JSON
+// line written to w, e.g. an append-only file
+func (o *ClaudeCodeOptions) WithPermissionAudit(w io.Writer) *ClaudeCodeOptions {
+	o.PermissionAuditLog = w
+	return o
+}
+
+// WithPermissionAuditCallback passes every tool permission decision to
+// callback
+func (o *ClaudeCodeOptions) WithPermissionAuditCallback(callback PermissionAuditCallback) *ClaudeCodeOptions {
+	o.OnPermissionAudit = callback
+	return o
+}
+
+// WithLogger sets the structured logger used by the client and transport
+func (o *ClaudeCodeOptions) WithLogger(logger *slog.Logger) *ClaudeCodeOptions {
+	o.Logger = logger
+	return o
+}
+
+// WithTelemetry sets the tracing and metrics implementation, e.g.
+// otel.New from the otel module
+func (o *ClaudeCodeOptions) WithTelemetry(telemetry Telemetry) *ClaudeCodeOptions {
+	o.Telemetry = telemetry
+	return o
+}
+
+// WithBudget limits the cost and tokens of the session (0 = unlimited)
+func (o *ClaudeCodeOptions) WithBudget(maxCostUSD float64, maxTokens int) *ClaudeCodeOptions {
+	o.MaxCostUSD = maxCostUSD
+	o.MaxTokens = maxTokens
+	return o
+}
+
+// WithProgress sets the progress callback
+func (o *ClaudeCodeOptions) WithProgress(callback ProgressCallback) *ClaudeCodeOptions {
+	o.OnProgress = callback
+	return o
+}
+
+// WithTranscript records every parsed message to recorder
+func (o *ClaudeCodeOptions) WithTranscript(recorder TranscriptRecorder) *ClaudeCodeOptions {
+	o.Transcript = recorder
+	return o
+}
+
+// WithMessageBuffer sets the Messages() channel capacity and overflow policy
+func (o *ClaudeCodeOptions) WithMessageBuffer(size int, policy OverflowPolicy) *ClaudeCodeOptions {
+	o.MessageBufferSize = size
+	o.OverflowPolicy = policy
+	return o
+}
+
+// WithCLIPath sets the CLI binary to run
+func (o *ClaudeCodeOptions) WithCLIPath(path string) *ClaudeCodeOptions {
+	o.CLIPath = path
+	return o
+}
+
+// WithCommandFactory sets a factory that builds the CLI command
+func (o *ClaudeCodeOptions) WithCommandFactory(factory CommandFactory) *ClaudeCodeOptions {
+	o.CommandFactory = factory
+	return o
+}
+
+// WithSandbox runs the CLI inside sandbox
+func (o *ClaudeCodeOptions) WithSandbox(sandbox ExecutionSandbox) *ClaudeCodeOptions {
+	o.ExecutionSandbox = &sandbox
+	return o
+}
+
+// WithTerminateGracePeriod sets how long the CLI may take to exit after
+// SIGTERM before it is killed
+func (o *ClaudeCodeOptions) WithTerminateGracePeriod(grace time.Duration) *ClaudeCodeOptions {
+	o.TerminateGracePeriod = grace
+	return o
+}
+
+// WithAgent defines the subagent name
+func (o *ClaudeCodeOptions) WithAgent(name string, agent AgentDefinition) *ClaudeCodeOptions {
+	if o.Agents == nil {
+		o.Agents = make(map[string]AgentDefinition)
+	}
+	o.Agents[name] = agent
+	return o
+}
+
+// WithDryRun makes Connect return the command line instead of starting the
+// CLI
+func (o *ClaudeCodeOptions) WithDryRun() *ClaudeCodeOptions {
+	o.DryRun = true
+	return o
+}
+
+// WithRetry retries queries that fail with a transient error before
+// producing output
+func (o *ClaudeCodeOptions) WithRetry(policy RetryPolicy) *ClaudeCodeOptions {
+	o.Retry = &policy
+	return o
+}
+
+// WithRateLimiter makes queries and turns wait for limiter before starting
+func (o *ClaudeCodeOptions) WithRateLimiter(limiter RateLimiter) *ClaudeCodeOptions {
+	o.RateLimiter = limiter
+	return o
+}
+
+// WithKeepStdinOpen leaves stdin open after a string prompt is written
+func (o *ClaudeCodeOptions) WithKeepStdinOpen() *ClaudeCodeOptions {
+	o.KeepStdinOpen = true
+	return o
+}
+
+// WithWriteTimeout fails writes to the CLI that take longer than timeout
+func (o *ClaudeCodeOptions) WithWriteTimeout(timeout time.Duration) *ClaudeCodeOptions {
+	o.WriteTimeout = timeout
+	return o
+}
+
+// WithStallTimeout reports a StalledError when the CLI is silent for timeout
+// during a turn, and interrupts the turn if interrupt is set
+func (o *ClaudeCodeOptions) WithStallTimeout(timeout time.Duration, interrupt bool) *ClaudeCodeOptions {
+	o.StallTimeout = timeout
+	o.InterruptOnStall = interrupt
+	return o
+}
+
+// WithMaxThinkingTokens limits the tokens Claude may spend on extended
+// thinking per turn; 0 disables thinking
+func (o *ClaudeCodeOptions) WithMaxThinkingTokens(tokens int) *ClaudeCodeOptions {
+	o.MaxThinkingTokens = &tokens
+	return o
+}
+
+// WithThinking configures extended thinking
+func (o *ClaudeCodeOptions) WithThinking(thinking ThinkingConfig) *ClaudeCodeOptions {
+	o.Thinking = &thinking
+	return o
+}
+
+// WithFallbackModel sets the model the CLI switches to when the primary
+// model is overloaded
+func (o *ClaudeCodeOptions) WithFallbackModel(model string) *ClaudeCodeOptions {
+	o.FallbackModel = &model
+	return o
+}
+
+// WithSettingSources loads only the given settings files; with none, the
+// CLI ignores all user and project settings
+func (o *ClaudeCodeOptions) WithSettingSources(sources ...SettingSource) *ClaudeCodeOptions {
+	o.SettingSources = append([]SettingSource{}, sources...)
+	return o
+}
+
+// WithTurnErrors attaches errors to the result of the turn they broke
+// instead of sending them on Errors()
+func (o *ClaudeCodeOptions) WithTurnErrors() *ClaudeCodeOptions {
+	o.TurnErrors = true
+	return o
+}
@@ -0,0 +1,15 @@
+package types
+
+// OutputFormat selects how the CLI reports a query
+type OutputFormat string
+
+const (
+	// OutputFormatStreamJSON streams every message as it happens (default)
+	OutputFormatStreamJSON OutputFormat = "stream-json"
+
+	// OutputFormatJSON prints only the ResultMessage once the query is done.
+	// It suits batch pipelines that need nothing else, but works only for
+	// Query with a string prompt and without callbacks, hooks or SDK MCP
+	// servers, which all need the streaming control protocol.
+	OutputFormatJSON OutputFormat = "json"
+)
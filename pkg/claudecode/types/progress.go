@@ -0,0 +1,44 @@
+package types
+
+import "time"
+
+// ProgressEventType identifies what a ProgressEvent reports
+type ProgressEventType string
+
+const (
+	ProgressEventToolStart  ProgressEventType = "tool_start"
+	ProgressEventToolStop   ProgressEventType = "tool_stop"
+	ProgressEventTodoUpdate ProgressEventType = "todo_update"
+	ProgressEventTurn       ProgressEventType = "turn"
+	ProgressEventDone       ProgressEventType = "done"
+)
+
+// TodoItem is a single entry of a TodoWrite tool call
+type TodoItem struct {
+	Content    string `json:"content"`
+	Status     string `json:"status"` // "pending", "in_progress" or "completed"
+	ActiveForm string `json:"activeForm,omitempty"`
+}
+
+// ProgressEvent describes progress made during a long-running turn
+type ProgressEvent struct {
+	Type ProgressEventType `json:"type"`
+
+	// Tool events
+	ToolName  string `json:"tool_name,omitempty"`
+	ToolUseID string `json:"tool_use_id,omitempty"`
+	IsError   bool   `json:"is_error,omitempty"`
+
+	// Todo updates
+	Todos []TodoItem `json:"todos,omitempty"`
+
+	// Turn accounting; MaxTurns is 0 when no limit is configured
+	Turn     int `json:"turn"`
+	MaxTurns int `json:"max_turns,omitempty"`
+
+	// Time since the first message of the query
+	Elapsed time.Duration `json:"elapsed"`
+}
+
+// ProgressCallback receives progress events
+type ProgressCallback func(event ProgressEvent)
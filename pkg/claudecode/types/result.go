@@ -0,0 +1,45 @@
+package types
+
+import (
+	stderrors "errors"
+	"strings"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+)
+
+// Err returns the error a failed result stands for: a MaxTurnsExceededError
+// for ResultSubtypeErrorMaxTurns, an ExecutionError for any other error
+// subtype or when IsError is set, and nil for a successful result. Errors
+// attached to the result are joined with it.
+func (m *ResultMessage) Err() error {
+	err := m.resultErr()
+	if len(m.Errors) == 0 {
+		return err
+	}
+	return stderrors.Join(append([]error{err}, m.Errors...)...)
+}
+
+// Cost returns the total cost of the session in USD, or 0 if the CLI did not
+// report it
+func (m *ResultMessage) Cost() float64 {
+	if m.TotalCostUSD == nil {
+		return 0
+	}
+	return *m.TotalCostUSD
+}
+
+// resultErr maps the subtype of a result to an error
+func (m *ResultMessage) resultErr() error {
+	if m.Subtype == ResultSubtypeErrorMaxTurns {
+		return errors.NewMaxTurnsExceededError(m.SessionID, m.NumTurns)
+	}
+	if !m.IsError && !strings.HasPrefix(m.Subtype, "error") {
+		return nil
+	}
+
+	var message string
+	if m.Result != nil {
+		message = *m.Result
+	}
+	return errors.NewExecutionError(m.SessionID, m.Subtype, message)
+}
@@ -0,0 +1,39 @@
+package types
+
+// SandboxKind selects the tool used to isolate the CLI process
+type SandboxKind string
+
+const (
+	SandboxBubblewrap SandboxKind = "bwrap"
+	SandboxFirejail   SandboxKind = "firejail"
+	SandboxDocker     SandboxKind = "docker"
+)
+
+// ExecutionSandbox runs the CLI inside an OS-level sandbox. The working
+// directory and AddDirs are mounted read-write; with bubblewrap and
+// firejail the rest of the file system is read-only.
+//
+// Example:
+//
+//	options.ExecutionSandbox = &types.ExecutionSandbox{
+//	    Kind:          types.SandboxBubblewrap,
+//	    WritablePaths: []string{filepath.Join(home, ".claude")},
+//	}
+type ExecutionSandbox struct {
+	Kind SandboxKind
+
+	// Sandbox binary; defaults to the name of Kind
+	Command string
+
+	// Container image with the CLI installed (docker only)
+	Image string
+
+	// CLI path inside the container (docker only, default "claude")
+	CLIPath string
+
+	// Further paths to mount read-write, e.g. the CLI's ~/.claude state
+	WritablePaths []string
+
+	// Extra arguments passed to the sandbox before the CLI command
+	Args []string
+}
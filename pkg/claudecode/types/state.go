@@ -0,0 +1,22 @@
+package types
+
+// ConversationState is a serializable snapshot of a client's conversation,
+// taken with ClaudeSDKClient.Snapshot and resumed with claudecode.Restore.
+// Fields of Options that do not encode to JSON, such as callbacks, hooks and
+// the logger, are lost when the state is encoded and must be set again
+// before restoring.
+type ConversationState struct {
+	// CLI session the restored client resumes
+	SessionID string `json:"session_id"`
+
+	// Options of the client, including model and permission mode changes
+	// made while connected
+	Options *ClaudeCodeOptions `json:"options,omitempty"`
+
+	// Token usage accumulated across the conversation's results
+	Usage Usage `json:"usage"`
+
+	// Permission updates applied while connected, with UpdatePermissions or
+	// by permission results. Session-scoped ones are applied again on restore.
+	PermissionUpdates []PermissionUpdate `json:"permission_updates,omitempty"`
+}
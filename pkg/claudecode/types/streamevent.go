@@ -0,0 +1,177 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Event types of the Anthropic Messages streaming API, carried in
+// StreamEvent.Event
+const (
+	StreamEventMessageStart      = "message_start"
+	StreamEventContentBlockStart = "content_block_start"
+	StreamEventContentBlockDelta = "content_block_delta"
+	StreamEventContentBlockStop  = "content_block_stop"
+	StreamEventMessageDelta      = "message_delta"
+	StreamEventMessageStop       = "message_stop"
+)
+
+// Delta types of a ContentBlockDeltaEvent
+const (
+	DeltaTypeText      = "text_delta"
+	DeltaTypeThinking  = "thinking_delta"
+	DeltaTypeSignature = "signature_delta"
+	DeltaTypeInputJSON = "input_json_delta"
+)
+
+// APIStreamEvent is a decoded API stream event, one of *MessageStartEvent,
+// *ContentBlockStartEvent, *ContentBlockDeltaEvent, *ContentBlockStopEvent,
+// *MessageDeltaEvent, *MessageStopEvent or *UnknownStreamEvent
+type APIStreamEvent interface {
+	EventType() string
+}
+
+// StreamMessage is the message announced by a MessageStartEvent
+type StreamMessage struct {
+	ID         string  `json:"id"`
+	Role       string  `json:"role"`
+	Model      string  `json:"model"`
+	StopReason *string `json:"stop_reason"`
+	Usage      *Usage  `json:"usage,omitempty"`
+}
+
+// MessageStartEvent begins a new assistant message
+type MessageStartEvent struct {
+	Message StreamMessage `json:"message"`
+}
+
+func (*MessageStartEvent) EventType() string { return StreamEventMessageStart }
+
+// ContentBlockStartEvent begins the content block at Index
+type ContentBlockStartEvent struct {
+	Index int `json:"index"`
+
+	// The block as it starts, usually empty. Nil for block types the SDK
+	// does not model, e.g. server tool uses.
+	ContentBlock ContentBlock `json:"-"`
+
+	// Type of the block, also set when ContentBlock is nil
+	BlockType string `json:"-"`
+}
+
+func (*ContentBlockStartEvent) EventType() string { return StreamEventContentBlockStart }
+
+func (e *ContentBlockStartEvent) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Index        int             `json:"index"`
+		ContentBlock json.RawMessage `json:"content_block"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	var head struct {
+		Type string `json:"type"`
+	}
+	if len(raw.ContentBlock) > 0 {
+		if err := json.Unmarshal(raw.ContentBlock, &head); err != nil {
+			return err
+		}
+	}
+
+	e.Index = raw.Index
+	e.BlockType = head.Type
+	e.ContentBlock, _ = UnmarshalContentBlock(raw.ContentBlock)
+	return nil
+}
+
+// StreamDelta is an increment to a content block. Type selects the field
+// that is set: Text, Thinking, Signature or PartialJSON.
+type StreamDelta struct {
+	Type        string `json:"type"`
+	Text        string `json:"text,omitempty"`
+	Thinking    string `json:"thinking,omitempty"`
+	Signature   string `json:"signature,omitempty"`
+	PartialJSON string `json:"partial_json,omitempty"`
+}
+
+// ContentBlockDeltaEvent extends the content block at Index
+type ContentBlockDeltaEvent struct {
+	Index int         `json:"index"`
+	Delta StreamDelta `json:"delta"`
+}
+
+func (*ContentBlockDeltaEvent) EventType() string { return StreamEventContentBlockDelta }
+
+// ContentBlockStopEvent ends the content block at Index
+type ContentBlockStopEvent struct {
+	Index int `json:"index"`
+}
+
+func (*ContentBlockStopEvent) EventType() string { return StreamEventContentBlockStop }
+
+// MessageDeltaEvent carries top-level changes to the message, such as the
+// stop reason, and the cumulative output usage
+type MessageDeltaEvent struct {
+	Delta struct {
+		StopReason   string  `json:"stop_reason,omitempty"`
+		StopSequence *string `json:"stop_sequence,omitempty"`
+	} `json:"delta"`
+	Usage *Usage `json:"usage,omitempty"`
+}
+
+func (*MessageDeltaEvent) EventType() string { return StreamEventMessageDelta }
+
+// MessageStopEvent ends the message
+type MessageStopEvent struct{}
+
+func (*MessageStopEvent) EventType() string { return StreamEventMessageStop }
+
+// UnknownStreamEvent carries event types the SDK does not model, e.g. ping
+type UnknownStreamEvent struct {
+	Type string
+	Data map[string]interface{}
+}
+
+func (e *UnknownStreamEvent) EventType() string { return e.Type }
+
+// DecodeEvent decodes Event into its typed form
+//
+// Example:
+//
+//	event, err := ev.DecodeEvent()
+//	if delta, ok := event.(*types.ContentBlockDeltaEvent); ok && delta.Delta.Type == types.DeltaTypeText {
+//	    fmt.Print(delta.Delta.Text)
+//	}
+func (e StreamEvent) DecodeEvent() (APIStreamEvent, error) {
+	eventType, _ := e.Event["type"].(string)
+
+	var event APIStreamEvent
+	switch eventType {
+	case StreamEventMessageStart:
+		event = &MessageStartEvent{}
+	case StreamEventContentBlockStart:
+		event = &ContentBlockStartEvent{}
+	case StreamEventContentBlockDelta:
+		event = &ContentBlockDeltaEvent{}
+	case StreamEventContentBlockStop:
+		event = &ContentBlockStopEvent{}
+	case StreamEventMessageDelta:
+		event = &MessageDeltaEvent{}
+	case StreamEventMessageStop:
+		return &MessageStopEvent{}, nil
+	case "":
+		return nil, fmt.Errorf("stream event missing 'type' field")
+	default:
+		return &UnknownStreamEvent{Type: eventType, Data: e.Event}, nil
+	}
+
+	data, err := json.Marshal(e.Event)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, event); err != nil {
+		return nil, fmt.Errorf("decoding %s event: %w", eventType, err)
+	}
+	return event, nil
+}
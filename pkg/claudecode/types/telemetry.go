@@ -0,0 +1,36 @@
+package types
+
+import "context"
+
+// Telemetry instruments conversations with tracing and metrics. The SDK
+// does not depend on a tracing library; the OpenTelemetry implementation
+// is the separate module github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/otel.
+type Telemetry interface {
+	// Conversation starts observing a connected client or a Query
+	Conversation() ConversationTelemetry
+}
+
+// ConversationTelemetry observes one conversation. Its methods may be
+// called from different goroutines.
+type ConversationTelemetry interface {
+	// StartQuery is called when a prompt is sent to the CLI
+	StartQuery(ctx context.Context)
+
+	// Observe is called for every message received from the CLI
+	Observe(msg Message)
+
+	// ObserveQueue is called once with a function returning the number of
+	// messages delivered but not yet received by the consumer
+	ObserveQueue(depth func() int)
+
+	// MessageDropped is called for every message discarded because the
+	// consumer fell behind
+	MessageDropped()
+
+	// ControlRequest is called when a control request is sent. The returned
+	// context is used for the request, and done is called with its outcome.
+	ControlRequest(ctx context.Context, subtype string, requestID string) (_ context.Context, done func(err error))
+
+	// End is called once the conversation is over
+	End()
+}
@@ -0,0 +1,48 @@
+package types
+
+// ThinkingType selects how Claude uses extended thinking
+type ThinkingType string
+
+const (
+	// Claude decides when to think, within MaxThinkingTokens (default 32000)
+	ThinkingAdaptive ThinkingType = "adaptive"
+	// Claude thinks within BudgetTokens
+	ThinkingEnabled ThinkingType = "enabled"
+	// No extended thinking
+	ThinkingDisabled ThinkingType = "disabled"
+)
+
+// defaultThinkingTokens is the budget of adaptive thinking unless
+// MaxThinkingTokens is set
+const defaultThinkingTokens = 32000
+
+// ThinkingConfig configures extended thinking. It takes precedence over
+// MaxThinkingTokens.
+type ThinkingConfig struct {
+	Type ThinkingType `json:"type"`
+	// Tokens Claude may spend thinking per turn, required with ThinkingEnabled
+	BudgetTokens int `json:"budget_tokens,omitempty"`
+}
+
+// ThinkingTokens returns the thinking budget passed to the CLI as
+// --max-thinking-tokens, and false if the CLI default applies
+func (o *ClaudeCodeOptions) ThinkingTokens() (int, bool) {
+	if o.Thinking == nil {
+		if o.MaxThinkingTokens == nil {
+			return 0, false
+		}
+		return *o.MaxThinkingTokens, true
+	}
+
+	switch o.Thinking.Type {
+	case ThinkingEnabled:
+		return o.Thinking.BudgetTokens, true
+	case ThinkingDisabled:
+		return 0, true
+	default:
+		if o.MaxThinkingTokens != nil {
+			return *o.MaxThinkingTokens, true
+		}
+		return defaultThinkingTokens, true
+	}
+}
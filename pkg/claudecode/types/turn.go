@@ -0,0 +1,69 @@
+package types
+
+import "strings"
+
+// Turn collects the messages produced in response to one user message, up
+// to and including its ResultMessage
+type Turn struct {
+	// Every message of the turn in arrival order, including the result
+	Messages []Message
+
+	Assistant []*AssistantMessage
+	ToolUses  []*ToolUseBlock
+	Result    *ResultMessage
+
+	// Usage reported by the result and the cost of this turn alone, nil or
+	// zero if absent
+	Usage   *Usage
+	CostUSD float64
+
+	// Running total cost of the session as of this turn
+	SessionCostUSD float64
+}
+
+// Add appends a message to the turn
+func (t *Turn) Add(msg Message) {
+	t.Messages = append(t.Messages, msg)
+
+	switch m := msg.(type) {
+	case *AssistantMessage:
+		t.Assistant = append(t.Assistant, m)
+		for _, block := range m.Content {
+			if toolUse, ok := block.(*ToolUseBlock); ok {
+				t.ToolUses = append(t.ToolUses, toolUse)
+			}
+		}
+	case *ResultMessage:
+		t.Result = m
+		t.Usage = m.Usage
+		t.CostUSD = m.TurnCostUSD
+		t.SessionCostUSD = m.Cost()
+	}
+}
+
+// Text returns the text blocks of all assistant messages, joined by newlines
+func (t *Turn) Text() string {
+	var parts []string
+	for _, msg := range t.Assistant {
+		for _, block := range msg.Content {
+			if text, ok := block.(*TextBlock); ok {
+				parts = append(parts, text.Text)
+			}
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// Err returns the error of the turn's result, including errors attached
+// with TurnErrors, or nil
+func (t *Turn) Err() error {
+	if t.Result == nil {
+		return nil
+	}
+	return t.Result.Err()
+}
+
+// IsError reports whether the turn ended with an error result
+func (t *Turn) IsError() bool {
+	return t.Result != nil && t.Result.IsError
+}
@@ -1,9 +1,12 @@
 package types
 
 import (
+	"context"
 	"encoding/json"
 	"io"
+	"log/slog"
 	"path/filepath"
+	"time"
 )
 
 // PermissionMode defines permission handling modes
@@ -16,6 +19,15 @@ const (
 	PermissionModeBypassPermissions PermissionMode = "bypassPermissions"
 )
 
+// SettingSource is a settings file the CLI loads
+type SettingSource string
+
+const (
+	SettingSourceUser    SettingSource = "user"    // ~/.claude/settings.json
+	SettingSourceProject SettingSource = "project" // .claude/settings.json
+	SettingSourceLocal   SettingSource = "local"   // .claude/settings.local.json
+)
+
 // Message types
 const (
 	MessageTypeUser      = "user"
@@ -23,6 +35,10 @@ const (
 	MessageTypeSystem    = "system"
 	MessageTypeResult    = "result"
 	MessageTypeStream    = "stream"
+
+	// Frame type the CLI uses for partial message updates; parsed into a
+	// StreamEvent like MessageTypeStream
+	MessageTypeStreamEvent = "stream_event"
 )
 
 // ContentBlock types
@@ -73,6 +89,11 @@ type Message interface {
 type UserMessage struct {
 	Content          interface{} `json:"content"` // string or []ContentBlock
 	ParentToolUseID  *string     `json:"parent_tool_use_id,omitempty"`
+	SessionID        string      `json:"session_id,omitempty"`
+
+	// Identity and creation time assigned by the CLI, empty if not sent
+	UUID      string    `json:"uuid,omitempty"`
+	Timestamp time.Time `json:"timestamp,omitzero"`
 }
 
 func (UserMessage) GetType() string { return MessageTypeUser }
@@ -83,20 +104,183 @@ type AssistantMessage struct {
 	Content          []ContentBlock `json:"content"`
 	Model            string         `json:"model"`
 	ParentToolUseID  *string        `json:"parent_tool_use_id,omitempty"`
+	SessionID        string         `json:"session_id,omitempty"`
+
+	// Identity and creation time assigned by the CLI, empty if not sent
+	UUID      string    `json:"uuid,omitempty"`
+	Timestamp time.Time `json:"timestamp,omitzero"`
 }
 
 func (AssistantMessage) GetType() string { return MessageTypeAssistant }
 func (AssistantMessage) isMessage() {}
 
-// SystemMessage represents a system message
+// Subtypes of system messages the parser decodes into typed messages.
+// Other subtypes are delivered as a plain *SystemMessage. Match
+// AnySystemMessage to handle both.
+const (
+	SystemSubtypeInit            = "init"             // *InitMessage
+	SystemSubtypeCompactBoundary = "compact_boundary" // *CompactBoundaryMessage
+	SystemSubtypeError           = "error"            // *ErrorMessage
+	SystemSubtypeModelFallback   = "model_fallback"   // *ModelFallbackMessage
+)
+
+// SystemMessage represents a system message. Typed system messages embed
+// it and share its UUID and Timestamp.
 type SystemMessage struct {
 	Subtype string                 `json:"subtype"`
 	Data    map[string]interface{} `json:"data"`
+
+	// Identity and creation time assigned by the CLI, empty if not sent
+	UUID      string    `json:"uuid,omitempty"`
+	Timestamp time.Time `json:"timestamp,omitzero"`
 }
 
 func (SystemMessage) GetType() string { return MessageTypeSystem }
 func (SystemMessage) isMessage() {}
 
+// System returns the message itself, or the SystemMessage a typed system
+// message embeds
+func (m *SystemMessage) System() *SystemMessage { return m }
+
+// AnySystemMessage is implemented by *SystemMessage and every typed system
+// message such as *InitMessage. A type switch on *SystemMessage only sees
+// subtypes without a typed form; use this to see them all:
+//
+//	case types.AnySystemMessage:
+//	    fmt.Println("system:", m.System().Subtype)
+type AnySystemMessage interface {
+	Message
+	System() *SystemMessage
+}
+
+// MCPServerStatus reports the connection state of an MCP server
+type MCPServerStatus struct {
+	Name   string `json:"name"`
+	Status string `json:"status"` // e.g. "connected", "failed", "pending"
+}
+
+// InitMessage is the system/init message sent at the start of a session.
+// It describes the tools, commands and servers available to Claude.
+type InitMessage struct {
+	SystemMessage
+	SessionID      string            `json:"session_id"`
+	CWD            string            `json:"cwd"`
+	Model          string            `json:"model"`
+	PermissionMode PermissionMode    `json:"permissionMode"`
+	Tools          []string          `json:"tools"`
+	Commands       []string          `json:"slash_commands"`
+	MCPServers     []MCPServerStatus `json:"mcp_servers"`
+	OutputStyle    string            `json:"output_style"`
+	APIKeySource   string            `json:"apiKeySource"`
+}
+
+// HasTool reports whether the named tool is available in the session
+func (m *InitMessage) HasTool(name string) bool {
+	for _, tool := range m.Tools {
+		if tool == name {
+			return true
+		}
+	}
+	return false
+}
+
+// HasCommand reports whether the named slash command is available
+func (m *InitMessage) HasCommand(name string) bool {
+	for _, command := range m.Commands {
+		if command == name {
+			return true
+		}
+	}
+	return false
+}
+
+// SlashCommand describes a slash command offered by the CLI
+type SlashCommand struct {
+	Name         string `json:"name"`
+	Description  string `json:"description,omitempty"`
+	ArgumentHint string `json:"argumentHint,omitempty"`
+}
+
+// ServerInfo describes the Claude Code instance a client is connected to.
+// It combines the initialize handshake response with the system/init
+// message of the session.
+type ServerInfo struct {
+	Commands       []SlashCommand    `json:"commands"`
+	OutputStyle    string            `json:"output_style"`
+	OutputStyles   []string          `json:"available_output_styles"`
+	Model          string            `json:"model"`
+	PermissionMode PermissionMode    `json:"permissionMode"`
+	Tools          []string          `json:"tools"`
+	MCPServers     []MCPServerStatus `json:"mcp_servers"`
+	APIKeySource   string            `json:"apiKeySource"`
+	SessionID      string            `json:"session_id"`
+	CWD            string            `json:"cwd"`
+
+	// Raw is the initialize response as received
+	Raw map[string]interface{} `json:"-"`
+}
+
+// HasCommand reports whether the named slash command is available
+func (i *ServerInfo) HasCommand(name string) bool {
+	for _, command := range i.Commands {
+		if command.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Compaction triggers
+const (
+	CompactTriggerManual = "manual"
+	CompactTriggerAuto   = "auto"
+)
+
+// CompactBoundaryMessage marks the point where the conversation was compacted
+type CompactBoundaryMessage struct {
+	SystemMessage
+	Trigger   string `json:"trigger"` // "manual" or "auto"
+	PreTokens int    `json:"pre_tokens"`
+}
+
+// ErrorMessage is a system/error message, reporting a failure outside of
+// a turn's result such as an API error the CLI gave up retrying
+type ErrorMessage struct {
+	SystemMessage
+	Error     string `json:"error"`
+	SessionID string `json:"session_id,omitempty"`
+}
+
+// ModelFallbackMessage announces that the CLI switched to FallbackModel,
+// e.g. because the primary model was overloaded. Later assistant messages
+// report the model that served them in AssistantMessage.Model.
+type ModelFallbackMessage struct {
+	SystemMessage
+	FromModel string `json:"from_model"`
+	ToModel   string `json:"to_model"`
+	Reason    string `json:"reason,omitempty"`
+	SessionID string `json:"session_id,omitempty"`
+}
+
+// SystemSubtypeReconnected is the subtype of ReconnectedMessage
+const SystemSubtypeReconnected = "reconnected"
+
+// ReconnectedMessage is emitted by ClaudeSDKClient after it respawned a CLI
+// that exited unexpectedly
+type ReconnectedMessage struct {
+	SystemMessage
+	Attempt   int    `json:"attempt"`    // Attempt that succeeded, starting at 1
+	SessionID string `json:"session_id"` // Session that was resumed, if known
+	Replayed  int    `json:"replayed"`   // Unacknowledged messages sent again
+}
+
+// Subtypes of result messages
+const (
+	ResultSubtypeSuccess              = "success"
+	ResultSubtypeErrorMaxTurns        = "error_max_turns"
+	ResultSubtypeErrorDuringExecution = "error_during_execution"
+)
+
 // ResultMessage represents a result message
 type ResultMessage struct {
 	Subtype        string                 `json:"subtype"`
@@ -106,24 +290,148 @@ type ResultMessage struct {
 	NumTurns       int                    `json:"num_turns"`
 	SessionID      string                 `json:"session_id"`
 	TotalCostUSD   *float64               `json:"total_cost_usd,omitempty"`
-	Usage          map[string]interface{} `json:"usage,omitempty"`
+	Usage          *Usage                 `json:"usage,omitempty"`
 	Result         *string                `json:"result,omitempty"`
+
+	// Identity and creation time assigned by the CLI, empty if not sent
+	UUID           string                 `json:"uuid,omitempty"`
+	Timestamp      time.Time              `json:"timestamp,omitzero"`
+
+	// Errors reported during the turn, attached with TurnErrors
+	Errors         []error                `json:"-"`
+
+	// Cost of this turn alone: how much TotalCostUSD, the running total of
+	// the session, grew since the previous result. Set by the client and
+	// Query.
+	TurnCostUSD    float64                `json:"-"`
 }
 
 func (ResultMessage) GetType() string { return MessageTypeResult }
 func (ResultMessage) isMessage() {}
 
+// Usage reports token consumption
+type Usage struct {
+	InputTokens              int    `json:"input_tokens"`
+	OutputTokens             int    `json:"output_tokens"`
+	CacheCreationInputTokens int    `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int    `json:"cache_read_input_tokens"`
+	ServiceTier              string `json:"service_tier,omitempty"`
+
+	// Per-model breakdown, keyed by model name
+	ByModel map[string]ModelUsage `json:"model_usage,omitempty"`
+
+	// The usage object as sent by the CLI
+	Raw map[string]interface{} `json:"-"`
+}
+
+// ModelUsage reports token consumption and cost of a single model
+type ModelUsage struct {
+	InputTokens              int     `json:"inputTokens"`
+	OutputTokens             int     `json:"outputTokens"`
+	CacheCreationInputTokens int     `json:"cacheCreationInputTokens"`
+	CacheReadInputTokens     int     `json:"cacheReadInputTokens"`
+	WebSearchRequests        int     `json:"webSearchRequests"`
+	CostUSD                  float64 `json:"costUSD"`
+}
+
+// ContextTokens returns the input tokens including cached ones
+func (u *Usage) ContextTokens() int {
+	if u == nil {
+		return 0
+	}
+	return u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens
+}
+
+// TotalTokens returns all input and output tokens
+func (u *Usage) TotalTokens() int {
+	if u == nil {
+		return 0
+	}
+	return u.ContextTokens() + u.OutputTokens
+}
+
+// Add accumulates other into u
+func (u *Usage) Add(other *Usage) {
+	if other == nil {
+		return
+	}
+
+	u.InputTokens += other.InputTokens
+	u.OutputTokens += other.OutputTokens
+	u.CacheCreationInputTokens += other.CacheCreationInputTokens
+	u.CacheReadInputTokens += other.CacheReadInputTokens
+
+	for model, usage := range other.ByModel {
+		if u.ByModel == nil {
+			u.ByModel = make(map[string]ModelUsage)
+		}
+		total := u.ByModel[model]
+		total.InputTokens += usage.InputTokens
+		total.OutputTokens += usage.OutputTokens
+		total.CacheCreationInputTokens += usage.CacheCreationInputTokens
+		total.CacheReadInputTokens += usage.CacheReadInputTokens
+		total.WebSearchRequests += usage.WebSearchRequests
+		total.CostUSD += usage.CostUSD
+		u.ByModel[model] = total
+	}
+}
+
 // StreamEvent represents a stream event for partial message updates
 type StreamEvent struct {
 	UUID            string                 `json:"uuid"`
 	SessionID       string                 `json:"session_id"`
 	Event           map[string]interface{} `json:"event"`
 	ParentToolUseID *string                `json:"parent_tool_use_id,omitempty"`
+
+	// Creation time assigned by the CLI, zero if not sent
+	Timestamp time.Time `json:"timestamp,omitzero"`
 }
 
 func (StreamEvent) GetType() string { return MessageTypeStream }
 func (StreamEvent) isMessage() {}
 
+// CustomMessage carries a message type the SDK does not model natively.
+// Types without a parser arrive as a CustomMessage holding the whole frame,
+// unless StrictMessageTypes is set. Embed it in your own struct to satisfy
+// the Message interface from a custom message parser.
+type CustomMessage struct {
+	Type string                 `json:"type"`
+	Data map[string]interface{} `json:"data"`
+}
+
+func (m CustomMessage) GetType() string { return m.Type }
+func (CustomMessage) isMessage() {}
+
+// ParseWarning reports a message that parsed leniently: it lacked optional
+// fields or carried content blocks the SDK does not know, which were skipped
+type ParseWarning struct {
+	MessageType string          // The message's type field
+	Warnings    []string        // What was tolerated, e.g. "thinking block missing 'signature' field"
+	Raw         json.RawMessage // The frame as received
+	Dropped     uint64          // Warnings dropped so far because the channel was full
+}
+
+// DeadLetter is an inbound frame that could not be decoded or parsed
+type DeadLetter struct {
+	Raw     json.RawMessage // The frame as received
+	Err     error           // The decode or parse error
+	Seq     uint64          // 1-based number of this dead letter
+	Dropped uint64          // Dead letters dropped so far because the channel was full
+}
+
+// TranscriptRecorder receives each inbound frame that parsed into a Message
+type TranscriptRecorder interface {
+	Record(frame map[string]interface{})
+}
+
+// RateLimiter is consulted before each turn, e.g. a *claudecode.Limiter
+// shared by concurrent queries
+type RateLimiter interface {
+	// Acquire blocks until a turn for prompt may start. done must be called
+	// once the turn ends, with its usage or nil if it failed.
+	Acquire(ctx context.Context, prompt string) (done func(usage *Usage), err error)
+}
+
 // MCP Server configs
 type MCPServerConfig interface {
 	isMCPServerConfig()
@@ -162,6 +470,23 @@ type MCPSDKServerConfig struct {
 
 func (MCPSDKServerConfig) isMCPServerConfig() {}
 
+// MCPContent is a content item returned by an MCP tool
+type MCPContent struct {
+	Type     string `json:"type"` // "text" or "image"
+	Text     string `json:"text,omitempty"`
+	Data     string `json:"data,omitempty"` // Base64 image data
+	MimeType string `json:"mimeType,omitempty"`
+}
+
+// MCPToolResult is the result of an MCP tool call
+type MCPToolResult struct {
+	Content []MCPContent `json:"content"`
+	IsError bool         `json:"isError,omitempty"`
+}
+
+// MCPToolHandler implements an in-process MCP tool
+type MCPToolHandler func(ctx context.Context, args map[string]interface{}) (*MCPToolResult, error)
+
 // Permission types
 type PermissionBehavior string
 
@@ -180,9 +505,11 @@ const (
 	PermissionDestinationSession         PermissionUpdateDestination = "session"
 )
 
+// PermissionRuleValue uses the CLI's camelCase keys so suggestions can be
+// echoed back unchanged in UpdatedPermissions
 type PermissionRuleValue struct {
-	ToolName    string  `json:"tool_name"`
-	RuleContent *string `json:"rule_content,omitempty"`
+	ToolName    string  `json:"toolName"`
+	RuleContent *string `json:"ruleContent,omitempty"`
 }
 
 type PermissionUpdateType string
@@ -207,7 +534,9 @@ type PermissionUpdate struct {
 
 // Tool permission context
 type ToolPermissionContext struct {
-	Signal      interface{}        `json:"-"` // Future: abort signal support
+	// Signal is done when the CLI cancels the request, the turn is
+	// interrupted or the client closes
+	Signal      context.Context    `json:"-"`
 	Suggestions []PermissionUpdate `json:"suggestions"`
 }
 
@@ -235,6 +564,22 @@ func (PermissionResultDeny) isPermissionResult() {}
 // CanUseTool is a callback function type for tool permission checks
 type CanUseTool func(toolName string, input map[string]interface{}, context *ToolPermissionContext) (PermissionResult, error)
 
+// Plan represents a plan proposed by Claude through the ExitPlanMode tool
+type Plan struct {
+	Content string                 `json:"plan"`
+	Input   map[string]interface{} `json:"-"` // Raw ExitPlanMode input
+}
+
+// PlanApprover is called when Claude finishes planning. Returning true
+// continues execution with acceptEdits, false aborts the turn.
+type PlanApprover func(plan *Plan) (bool, error)
+
+// HostToolHandler executes a tool in the SDK host. The returned string, or
+// the error's text, is what Claude sees: the tool result of a tool added
+// with ClaudeSDKClient.RegisterTool, or the message denying a tool use for
+// ClaudeCodeOptions.InterceptedTools.
+type HostToolHandler func(ctx context.Context, input map[string]interface{}) (string, error)
+
 // Hook types
 type HookEvent string
 
@@ -256,17 +601,91 @@ const (
 type HookJSONOutput struct {
 	Decision            *HookDecision  `json:"decision,omitempty"`
 	SystemMessage       *string        `json:"systemMessage,omitempty"`
+	// Reason explains a block decision to Claude
+	Reason              *string        `json:"reason,omitempty"`
+	// Continue set to false stops Claude after the hook runs
+	Continue            *bool          `json:"continue,omitempty"`
+	StopReason          *string        `json:"stopReason,omitempty"`
+	// One of the *HookSpecificOutput types below, matching the hook event
 	HookSpecificOutput  interface{}    `json:"hookSpecificOutput,omitempty"`
 }
 
+// PreToolUseHookSpecificOutput decides whether a pending tool call runs
+type PreToolUseHookSpecificOutput struct {
+	HookEventName            HookEvent          `json:"hookEventName"`
+	PermissionDecision       PermissionBehavior `json:"permissionDecision,omitempty"`
+	PermissionDecisionReason string             `json:"permissionDecisionReason,omitempty"`
+}
+
+// PostToolUseHookSpecificOutput adds context after a tool has run
+type PostToolUseHookSpecificOutput struct {
+	HookEventName     HookEvent `json:"hookEventName"`
+	AdditionalContext string    `json:"additionalContext,omitempty"`
+}
+
+// UserPromptSubmitHookSpecificOutput adds context to a submitted prompt
+type UserPromptSubmitHookSpecificOutput struct {
+	HookEventName     HookEvent `json:"hookEventName"`
+	AdditionalContext string    `json:"additionalContext,omitempty"`
+}
+
+// PreCompactHookInput is the typed input of a PreCompact hook
+type PreCompactHookInput struct {
+	SessionID          string `json:"session_id"`
+	TranscriptPath     string `json:"transcript_path"`
+	Trigger            string `json:"trigger"` // "manual" or "auto"
+	CustomInstructions string `json:"custom_instructions"`
+}
+
+// AutoCompactPolicy compacts the conversation once the context grows too large
+type AutoCompactPolicy struct {
+	// Compact when the input tokens reported by a result reach this value
+	ContextTokenThreshold int
+	// Optional instructions passed to /compact
+	Instructions string
+}
+
+// ReconnectPolicy makes ClaudeSDKClient respawn the CLI with --resume when
+// it exits mid-conversation
+type ReconnectPolicy struct {
+	// Attempts per outage before giving up (default 3)
+	MaxRetries int
+	// Delay before the first attempt, doubled after each failure (default 500ms)
+	InitialBackoff time.Duration
+	// Upper bound for the delay between attempts (default 30s)
+	MaxBackoff time.Duration
+}
+
+// RetryPolicy makes Query start the CLI again when an attempt fails with a
+// transient error before Claude produced any output
+type RetryPolicy struct {
+	// Attempts in total, including the first (default 3)
+	MaxAttempts int
+	// Delay before the first retry, doubled after each failure (default 1s)
+	InitialBackoff time.Duration
+	// Upper bound for the delay between attempts (default 30s)
+	MaxBackoff time.Duration
+	// Decides whether an error is worth another attempt (default
+	// errors.IsTransient). Failed results are passed as their Err().
+	Retryable func(err error) bool
+	// Called before each retry with the number of the attempt about to
+	// start (2 for the first retry), the error and the delay
+	OnRetry func(attempt int, err error, delay time.Duration)
+}
+
 type HookContext struct {
-	Signal interface{} `json:"-"` // Future: abort signal support
+	// Signal is done when the CLI cancels the callback, the turn is
+	// interrupted or the client closes
+	Signal context.Context `json:"-"`
 }
 
 // HookCallback is a function that processes hook events
 type HookCallback func(input map[string]interface{}, toolUseID *string, context *HookContext) (*HookJSONOutput, error)
 
+// HookMatcher routes hook events to callbacks
 type HookMatcher struct {
+	// Matcher selects the tools (or compaction triggers) the hooks run for;
+	// see CompileHookMatcher for the syntax. Nil matches everything.
 	Matcher *string        `json:"matcher,omitempty"`
 	Hooks   []HookCallback `json:"-"`
 }
@@ -284,27 +703,195 @@ type ClaudeCodeOptions struct {
 	MaxTurns                 *int                          `json:"max_turns,omitempty"`
 	DisallowedTools          []string                      `json:"disallowed_tools,omitempty"`
 	Model                    *string                       `json:"model,omitempty"`
+	// Model used when the primary model is overloaded or unavailable
+	FallbackModel            *string                       `json:"fallback_model,omitempty"`
+	MaxThinkingTokens        *int                          `json:"max_thinking_tokens,omitempty"`
+	Thinking                 *ThinkingConfig               `json:"thinking,omitempty"`
 	PermissionPromptToolName *string                       `json:"permission_prompt_tool_name,omitempty"`
 	CWD                      *string                       `json:"cwd,omitempty"`
 	Settings                 *string                       `json:"settings,omitempty"`
+	// Settings files to load; nil loads the CLI default, an empty non-nil
+	// slice none at all for hermetic runs
+	SettingSources           []SettingSource               `json:"setting_sources,omitempty"`
 	AddDirs                  []string                      `json:"add_dirs,omitempty"`
 	Env                      map[string]string             `json:"env,omitempty"`
+	// Deprecated: use ExtraFlags, which keeps flags in order. ExtraArgs are
+	// passed before ExtraFlags, sorted by name.
 	ExtraArgs                map[string]*string            `json:"extra_args,omitempty"`
+
+	// Additional flags passed to the CLI in order; flags may repeat
+	ExtraFlags               []CLIFlag                     `json:"extra_flags,omitempty"`
 	DebugStderr              io.Writer                     `json:"-"` // For debug output
+	StderrBufferSize         int                           `json:"-"` // Bytes of stderr kept for errors (default 64KB)
+	MaxMessageSize           int                           `json:"-"` // Largest JSON message accepted from the CLI (default 16MB)
+
+	// Receives every line read from the CLI, line ending included, before it
+	// is parsed; lines over MaxMessageSize are not copied. Write errors are
+	// ignored. A writer shared between clients must be safe for concurrent use.
+	RawOutput                io.Writer                     `json:"-"`
+
+	// Fail on message types without a built-in or registered parser instead
+	// of passing them on as CustomMessage
+	StrictMessageTypes       bool                          `json:"-"`
+
+	// Fail on messages missing optional fields, such as an assistant
+	// message's model or a thinking block's signature, or carrying unknown
+	// content blocks. By default such messages are delivered anyway, the
+	// unknown blocks skipped, and the problems logged and sent to
+	// ParseWarnings.
+	StrictParsing            bool                          `json:"-"`
+
+	// Receives what lenient parsing tolerated, one entry per message. The
+	// channel is owned by the caller and never closed; entries are dropped
+	// while it is full.
+	ParseWarnings            chan<- ParseWarning           `json:"-"`
+
+	// Structured logging of the CLI process, protocol lines (debug level),
+	// control requests and parse failures. Nil disables logging.
+	Logger                   *slog.Logger                  `json:"-"`
+
+	// Tracing and metrics, e.g. OpenTelemetry spans per query, turn, tool
+	// use and control request from the otel module. Nil disables them.
+	Telemetry                Telemetry                     `json:"-"`
 	
 	// Tool permission callback
 	CanUseTool               CanUseTool                    `json:"-"`
+
+	// Limits the number of CanUseTool callbacks running at once (0 = unlimited)
+	MaxConcurrentPermissionRequests int                    `json:"-"`
+
+	// Presents permission requests one at a time, in arrival order
+	SerializePermissionRequests bool                       `json:"-"`
+
+	// How long control requests (interrupt, set_permission_mode, ...) wait
+	// for the CLI to respond. Defaults to 60s; negative waits indefinitely.
+	ControlRequestTimeout    time.Duration                 `json:"-"`
+
+	// How long a CanUseTool or hook callback may run (0 = no limit). On
+	// expiry the tool use is denied and a CallbackTimeoutError is reported.
+	CallbackTimeout          time.Duration                 `json:"-"`
 	
+	// Tools the CLI asks permission for that the SDK host runs instead
+	// (ClaudeSDKClient only). The CLI's tool use is denied with the
+	// handler's output as the message, so Claude sees a denied tool whose
+	// error text is the output rather than a tool result; use RegisterTool
+	// for tools with real results. Tools the CLI runs without asking, as
+	// with AllowedTools, allow rules in settings or the acceptEdits and
+	// bypassPermissions modes, are never intercepted.
+	InterceptedTools         map[string]HostToolHandler    `json:"-"`
+
 	// Hook configurations
 	Hooks                    map[HookEvent][]HookMatcher   `json:"-"`
+
+	// Custom subagents by name
+	Agents                   map[string]AgentDefinition    `json:"agents,omitempty"`
 	
 	User                     *string                       `json:"user,omitempty"`
 	
 	// Partial message streaming support
 	IncludePartialMessages   bool                          `json:"include_partial_messages,omitempty"`
+
+	// How the CLI reports a query (default OutputFormatStreamJSON)
+	OutputFormat             OutputFormat                  `json:"output_format,omitempty"`
 	
 	// Fork session on resume
 	ForkSession              bool                          `json:"fork_session,omitempty"`
+
+	// ID of the session to start, a UUID. With Resume it names the fork
+	// and requires ForkSession.
+	SessionID                *string                       `json:"session_id,omitempty"`
+
+	// CLI binary to run; defaults to $CLAUDE_CODE_CLI_PATH, then a search of
+	// PATH and common install locations
+	CLIPath                  string                        `json:"-"`
+
+	// Builds the CLI command instead of exec.CommandContext
+	CommandFactory           CommandFactory                `json:"-"`
+
+	// Runs the CLI inside bubblewrap, firejail or docker
+	ExecutionSandbox         *ExecutionSandbox             `json:"-"`
+
+	// Build the command line but do not start the CLI, nor probe its
+	// version; Connect and Query return an *errors.DryRunError holding it
+	// instead
+	DryRun                   bool                          `json:"-"`
+
+	// How long the CLI's process group has to exit after SIGTERM before it
+	// is killed. Close kills right away unless this is set; Shutdown waits
+	// 5s by default.
+	TerminateGracePeriod     time.Duration                 `json:"-"`
+
+	// Leave stdin open after writing a string prompt instead of closing it
+	// to signal the end of input
+	KeepStdinOpen            bool                          `json:"-"`
+
+	// How long a line may take to be written to the CLI's stdin, e.g. while
+	// the CLI is not reading it. Unlimited by default.
+	WriteTimeout             time.Duration                 `json:"-"`
+
+	// Report a StalledError when the CLI writes nothing for this long while
+	// a turn is in progress, and with InterruptOnStall also interrupt the
+	// turn. Time spent in CanUseTool and hook callbacks does not count.
+	// Query ends with the error.
+	StallTimeout             time.Duration                 `json:"-"`
+	InterruptOnStall         bool                          `json:"-"`
+
+	// Capacity of the Messages() channel (default 100) and what to do when a
+	// slow consumer lets it fill up (default OverflowBlock, ClaudeSDKClient only)
+	MessageBufferSize        int                           `json:"-"`
+	OverflowPolicy           OverflowPolicy                `json:"-"`
+
+	// Mirror every inbound frame to ClaudeSDKClient.RawMessages before parsing
+	EnableRawMessages        bool                          `json:"-"`
+
+	// Hold parse, transport and callback errors for the next ResultMessage,
+	// where Err joins them, instead of sending them on Errors(). A
+	// conversation that ends before its result gets a final error result
+	// (ClaudeSDKClient only).
+	TurnErrors               bool                          `json:"-"`
+
+	// Track the files changed by Claude's tool uses for
+	// ClaudeSDKClient.Changes
+	TrackFileChanges         bool                          `json:"-"`
+
+	// Audit trail of tool permission decisions: every can_use_tool request
+	// a client answers is written to PermissionAuditLog as a JSON line and
+	// passed to OnPermissionAudit. Write errors are logged, not returned.
+	PermissionAuditLog       io.Writer                     `json:"-"`
+	OnPermissionAudit        PermissionAuditCallback       `json:"-"`
+
+	// Receives frames that could not be decoded or parsed, instead of the
+	// error channel. The channel is owned by the caller and never closed.
+	DeadLetters              chan<- DeadLetter             `json:"-"`
+
+	// Records every parsed inbound message, e.g. a *transcript.Transcript
+	Transcript               TranscriptRecorder            `json:"-"`
+
+	// Automatic compaction (ClaudeSDKClient only)
+	AutoCompact              *AutoCompactPolicy            `json:"-"`
+
+	// Respawn the CLI when it exits unexpectedly (ClaudeSDKClient only)
+	Reconnect                *ReconnectPolicy              `json:"-"`
+
+	// Retry queries failing with transient errors (Query, QueryIter,
+	// QuerySync and batches)
+	Retry                    *RetryPolicy                  `json:"-"`
+
+	// Consulted before every query and SendAndWait turn
+	RateLimiter              RateLimiter                   `json:"-"`
+
+	// Progress reporting callback
+	OnProgress               ProgressCallback              `json:"-"`
+
+	// Spend limits across the client's results (0 = unlimited). Once one is
+	// reached the session is interrupted and further messages fail with a
+	// BudgetExceededError (ClaudeSDKClient only).
+	MaxCostUSD               float64                       `json:"-"`
+	MaxTokens                int                           `json:"-"`
+
+	// Fraction of a budget at which OnBudgetWarning fires (default 0.8)
+	BudgetWarningRatio       float64                       `json:"-"`
+	OnBudgetWarning          BudgetWarningCallback         `json:"-"`
 }
 
 // SDK Control Protocol types
@@ -317,6 +904,8 @@ const (
 	SDKControlSetPermissionMode SDKControlRequestType = "set_permission_mode"
 	SDKControlHookCallback    SDKControlRequestType = "hook_callback"
 	SDKControlMCPMessage      SDKControlRequestType = "mcp_message"
+	SDKControlSetModel        SDKControlRequestType = "set_model"
+	SDKControlUpdatePermissions SDKControlRequestType = "update_permissions"
 )
 
 type SDKControlRequest struct {
@@ -347,6 +936,16 @@ type SDKControlSetPermissionModeRequest struct {
 	Mode    string `json:"mode"`
 }
 
+type SDKControlSetModelRequest struct {
+	Subtype string  `json:"subtype"` // "set_model"
+	Model   *string `json:"model"`   // nil resets to the default model
+}
+
+type SDKControlUpdatePermissionsRequest struct {
+	Subtype string             `json:"subtype"` // "update_permissions"
+	Updates []PermissionUpdate `json:"updates"`
+}
+
 type SDKHookCallbackRequest struct {
 	Subtype    string      `json:"subtype"` // "hook_callback"
 	CallbackID string      `json:"callback_id"`
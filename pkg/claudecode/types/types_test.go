@@ -1,9 +1,13 @@
 package types_test
 
 import (
+	"context"
 	"encoding/json"
+	stderrors "errors"
+	"reflect"
 	"testing"
 
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
 	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/types"
 )
 
@@ -78,6 +82,365 @@ func TestContentBlockTypes(t *testing.T) {
 	}
 }
 
+func TestMessageAccessors(t *testing.T) {
+	msg := &types.AssistantMessage{
+		Content: []types.ContentBlock{
+			&types.ThinkingBlock{Thinking: "Hmm...", Signature: "sig"},
+			&types.TextBlock{Text: "Reading both files"},
+			&types.ToolUseBlock{ID: "1", Name: "Read", Input: map[string]interface{}{"file_path": "a.go"}},
+			&types.ToolUseBlock{ID: "2", Name: "Read", Input: map[string]interface{}{"file_path": "b.go"}},
+			&types.TextBlock{Text: "Done"},
+		},
+	}
+
+	if got := msg.Text(); got != "Reading both files\nDone" {
+		t.Errorf("Text() = %q", got)
+	}
+	if uses := msg.ToolUses(); len(uses) != 2 || uses[0].ID != "1" || uses[1].ID != "2" {
+		t.Errorf("ToolUses() = %+v", uses)
+	}
+	if !msg.HasThinking() {
+		t.Error("HasThinking() = false, want true")
+	}
+
+	empty := &types.AssistantMessage{}
+	if empty.Text() != "" || empty.ToolUses() != nil || empty.HasThinking() {
+		t.Errorf("unexpected accessors for an empty message")
+	}
+
+	cost := 0.25
+	if got := (&types.ResultMessage{TotalCostUSD: &cost}).Cost(); got != 0.25 {
+		t.Errorf("Cost() = %v, want 0.25", got)
+	}
+	if got := (&types.ResultMessage{}).Cost(); got != 0 {
+		t.Errorf("Cost() without a reported cost = %v, want 0", got)
+	}
+}
+
+func TestUsageAdd(t *testing.T) {
+	total := &types.Usage{}
+	total.Add(&types.Usage{
+		InputTokens:  10,
+		OutputTokens: 5,
+		ByModel: map[string]types.ModelUsage{
+			"claude-sonnet-4": {InputTokens: 10, OutputTokens: 5, CostUSD: 0.01},
+		},
+	})
+	total.Add(&types.Usage{
+		InputTokens:          3,
+		CacheReadInputTokens: 7,
+		ByModel: map[string]types.ModelUsage{
+			"claude-sonnet-4": {InputTokens: 3, CostUSD: 0.02},
+		},
+	})
+
+	if total.ContextTokens() != 20 {
+		t.Errorf("Expected 20 context tokens, got %d", total.ContextTokens())
+	}
+	if total.TotalTokens() != 25 {
+		t.Errorf("Expected 25 total tokens, got %d", total.TotalTokens())
+	}
+	if model := total.ByModel["claude-sonnet-4"]; model.InputTokens != 13 || model.CostUSD < 0.0299 {
+		t.Errorf("Unexpected per-model usage: %+v", model)
+	}
+}
+
+func TestResultErr(t *testing.T) {
+	success := &types.ResultMessage{Subtype: types.ResultSubtypeSuccess, SessionID: "s1"}
+	if err := success.Err(); err != nil {
+		t.Errorf("Expected no error for a successful result, got %v", err)
+	}
+
+	maxTurns := &types.ResultMessage{Subtype: types.ResultSubtypeErrorMaxTurns, IsError: true, SessionID: "s1", NumTurns: 3}
+	err := maxTurns.Err()
+	var turnsErr *errors.MaxTurnsExceededError
+	if !stderrors.Is(err, errors.ErrMaxTurnsExceeded) || !stderrors.As(err, &turnsErr) || turnsErr.NumTurns != 3 {
+		t.Errorf("Unexpected max turns error: %v", err)
+	}
+
+	failed := &types.ResultMessage{Subtype: types.ResultSubtypeErrorDuringExecution, SessionID: "s1"}
+	flagged := &types.ResultMessage{Subtype: types.ResultSubtypeSuccess, IsError: true, Result: stringPtr("API Error: overloaded")}
+	for _, result := range []*types.ResultMessage{failed, flagged} {
+		err := result.Err()
+		var execErr *errors.ExecutionError
+		if !stderrors.Is(err, errors.ErrExecution) || !stderrors.As(err, &execErr) || execErr.Subtype != result.Subtype {
+			t.Errorf("Unexpected execution error for %+v: %v", result, err)
+		}
+	}
+	if got := flagged.Err().Error(); got != "execution error (success): API Error: overloaded" {
+		t.Errorf("Unexpected error message: %s", got)
+	}
+
+	// Errors attached with TurnErrors are joined with the result's own
+	decodeErr := errors.NewJSONDecodeError("failed to decode message", "{", nil)
+	maxTurns.Errors = []error{decodeErr}
+	err = maxTurns.Err()
+	if !stderrors.Is(err, errors.ErrMaxTurnsExceeded) || !stderrors.Is(err, errors.ErrJSONDecode) {
+		t.Errorf("Unexpected joined error: %v", err)
+	}
+}
+
 func stringPtr(s string) *string {
 	return &s
 }
+
+func TestValidateOptions(t *testing.T) {
+	resume := "session-1"
+	negative := -1
+	unknownMode := types.PermissionMode("yolo")
+	promptTool := "mcp__auth__prompt"
+	bypass := types.PermissionModeBypassPermissions
+	acceptEdits := types.PermissionModeAcceptEdits
+	handler := func(context.Context, map[string]interface{}) (string, error) { return "", nil }
+
+	tests := []struct {
+		name    string
+		options *types.ClaudeCodeOptions
+		fields  []string
+	}{
+		{name: "nil", options: nil},
+		{name: "empty", options: &types.ClaudeCodeOptions{}},
+		{
+			name:    "resume and continue",
+			options: &types.ClaudeCodeOptions{Resume: &resume, ContinueConversation: true},
+			fields:  []string{"Resume"},
+		},
+		{
+			name:    "negative max turns and unknown mode",
+			options: &types.ClaudeCodeOptions{MaxTurns: &negative, PermissionMode: &unknownMode},
+			fields:  []string{"MaxTurns", "PermissionMode"},
+		},
+		{
+			name: "can use tool with prompt tool",
+			options: &types.ClaudeCodeOptions{
+				CanUseTool: func(string, map[string]interface{}, *types.ToolPermissionContext) (types.PermissionResult, error) {
+					return nil, nil
+				},
+				PermissionPromptToolName: &promptTool,
+			},
+			fields: []string{"CanUseTool"},
+		},
+		{
+			name:    "fork without resume",
+			options: &types.ClaudeCodeOptions{ForkSession: true},
+			fields:  []string{"ForkSession"},
+		},
+		{
+			name: "intercepted tool in allowed tools",
+			options: &types.ClaudeCodeOptions{
+				AllowedTools:     []string{"Read", "Bash(git status)"},
+				InterceptedTools: map[string]types.HostToolHandler{"Bash": handler},
+			},
+			fields: []string{"InterceptedTools"},
+		},
+		{
+			name: "intercepted tool bypassing permissions",
+			options: &types.ClaudeCodeOptions{
+				PermissionMode:   &bypass,
+				InterceptedTools: map[string]types.HostToolHandler{"Bash": handler},
+			},
+			fields: []string{"InterceptedTools"},
+		},
+		{
+			name: "intercepted tool accepting edits",
+			options: &types.ClaudeCodeOptions{
+				PermissionMode:   &acceptEdits,
+				InterceptedTools: map[string]types.HostToolHandler{"Write": handler},
+			},
+			fields: []string{"InterceptedTools"},
+		},
+		{
+			name:    "session ID for a resumed session",
+			options: types.NewOptions().WithResume(resume).WithSessionID("not-a-uuid"),
+			fields:  []string{"SessionID", "SessionID"},
+		},
+		{
+			name:    "session ID naming a fork",
+			options: types.NewOptions().WithForkSession(resume).WithSessionID("0d3c2f6e-8a4b-4d1e-9f3a-5b7c1e2d4a6f"),
+		},
+		{
+			name:    "json output with partial messages and SDK MCP server",
+			options: types.NewOptions().WithOutputFormat(types.OutputFormatJSON).WithPartialMessages().WithMCPServer("calc", types.MCPSDKServerConfig{Name: "calc"}),
+			fields:  []string{"OutputFormat", "OutputFormat"},
+		},
+		{
+			name:    "unknown output format",
+			options: types.NewOptions().WithOutputFormat("text"),
+			fields:  []string{"OutputFormat"},
+		},
+		{
+			name:    "thinking enabled without budget",
+			options: types.NewOptions().WithThinking(types.ThinkingConfig{Type: types.ThinkingEnabled}),
+			fields:  []string{"Thinking"},
+		},
+		{
+			name:    "unknown setting source",
+			options: types.NewOptions().WithSettingSources(types.SettingSourceUser, "global"),
+			fields:  []string{"SettingSources"},
+		},
+		{
+			name: "malformed hook matcher",
+			options: &types.ClaudeCodeOptions{
+				Hooks: map[types.HookEvent][]types.HookMatcher{
+					types.HookEventPreToolUse: {{Matcher: stringPtr("mcp__(github")}},
+				},
+			},
+			fields: []string{"Hooks"},
+		},
+		{
+			name: "agent without prompt",
+			options: types.NewOptions().WithAgent("reviewer", types.AgentDefinition{
+				Description: "Reviews diffs",
+			}),
+			fields: []string{"Agents"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.options.Validate()
+			if len(tt.fields) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			if !stderrors.Is(err, errors.ErrInvalidOptions) {
+				t.Fatalf("expected ErrInvalidOptions, got %v", err)
+			}
+
+			joined, ok := err.(interface{ Unwrap() []error })
+			if !ok {
+				t.Fatalf("expected joined errors, got %T", err)
+			}
+			var fields []string
+			for _, e := range joined.Unwrap() {
+				var validation *errors.ValidationError
+				if stderrors.As(e, &validation) {
+					fields = append(fields, validation.Field)
+				}
+			}
+			if !reflect.DeepEqual(fields, tt.fields) {
+				t.Errorf("fields = %v, want %v", fields, tt.fields)
+			}
+		})
+	}
+}
+
+func TestOptionsBuilder(t *testing.T) {
+	options := types.NewOptions().
+		WithModel("sonnet").
+		WithSystemPrompt("Be brief").
+		WithAllowedTools("Read", "Grep").
+		WithAllowedTools("Edit").
+		WithMaxTurns(3).
+		WithPermissionMode(types.PermissionModeAcceptEdits).
+		WithForkSession("session-1").
+		WithEnv("FOO", "bar").
+		WithExtraArg("--verbose", "")
+
+	if options.Model == nil || *options.Model != "sonnet" {
+		t.Errorf("Model = %v, want sonnet", options.Model)
+	}
+	if options.SystemPrompt == nil || *options.SystemPrompt != "Be brief" {
+		t.Errorf("SystemPrompt = %v, want Be brief", options.SystemPrompt)
+	}
+	if !reflect.DeepEqual(options.AllowedTools, []string{"Read", "Grep", "Edit"}) {
+		t.Errorf("AllowedTools = %v", options.AllowedTools)
+	}
+	if options.MaxTurns == nil || *options.MaxTurns != 3 {
+		t.Errorf("MaxTurns = %v, want 3", options.MaxTurns)
+	}
+	if options.PermissionMode == nil || *options.PermissionMode != types.PermissionModeAcceptEdits {
+		t.Errorf("PermissionMode = %v, want acceptEdits", options.PermissionMode)
+	}
+	if options.Resume == nil || *options.Resume != "session-1" || !options.ForkSession {
+		t.Errorf("Resume = %v, ForkSession = %v", options.Resume, options.ForkSession)
+	}
+	if options.Env["FOO"] != "bar" {
+		t.Errorf("Env = %v", options.Env)
+	}
+	if len(options.ExtraFlags) != 1 || options.ExtraFlags[0].Name != "--verbose" || options.ExtraFlags[0].Value != nil {
+		t.Errorf("ExtraFlags = %v", options.ExtraFlags)
+	}
+	if err := options.Validate(); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+}
+
+func TestThinkingTokens(t *testing.T) {
+	tests := []struct {
+		name    string
+		options *types.ClaudeCodeOptions
+		tokens  int
+		ok      bool
+	}{
+		{name: "default", options: types.NewOptions()},
+		{name: "max tokens", options: types.NewOptions().WithMaxThinkingTokens(8000), tokens: 8000, ok: true},
+		{name: "adaptive", options: types.NewOptions().WithThinking(types.ThinkingConfig{Type: types.ThinkingAdaptive}), tokens: 32000, ok: true},
+		{
+			name:    "adaptive within max tokens",
+			options: types.NewOptions().WithMaxThinkingTokens(4000).WithThinking(types.ThinkingConfig{Type: types.ThinkingAdaptive}),
+			tokens:  4000,
+			ok:      true,
+		},
+		{
+			name:    "enabled",
+			options: types.NewOptions().WithMaxThinkingTokens(4000).WithThinking(types.ThinkingConfig{Type: types.ThinkingEnabled, BudgetTokens: 16000}),
+			tokens:  16000,
+			ok:      true,
+		},
+		{name: "disabled", options: types.NewOptions().WithThinking(types.ThinkingConfig{Type: types.ThinkingDisabled}), tokens: 0, ok: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, ok := tt.options.ThinkingTokens()
+			if tokens != tt.tokens || ok != tt.ok {
+				t.Errorf("ThinkingTokens() = %d, %v, want %d, %v", tokens, ok, tt.tokens, tt.ok)
+			}
+		})
+	}
+}
+
+func TestCompileHookMatcher(t *testing.T) {
+	tests := []struct {
+		matcher string
+		match   []string
+		noMatch []string
+	}{
+		{matcher: "", match: []string{"Bash", "Edit"}},
+		{matcher: "*", match: []string{"Bash", "mcp__github__list"}},
+		{matcher: "Bash", match: []string{"Bash"}, noMatch: []string{"BashOutput", "Edit"}},
+		{matcher: "Edit | Write", match: []string{"Edit", "Write"}, noMatch: []string{"MultiEdit"}},
+		{matcher: "mcp__github__*", match: []string{"mcp__github__list"}, noMatch: []string{"mcp__gitlab__list"}},
+		{matcher: "Web?etch", match: []string{"WebFetch"}, noMatch: []string{"WebSearch"}},
+		{matcher: "mcp__.*__read_.+", match: []string{"mcp__fs__read_file"}, noMatch: []string{"mcp__fs__write_file"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.matcher, func(t *testing.T) {
+			pattern, err := types.CompileHookMatcher(&tt.matcher)
+			if err != nil {
+				t.Fatalf("CompileHookMatcher: %v", err)
+			}
+			for _, name := range tt.match {
+				if !pattern.Match(name) {
+					t.Errorf("%q does not match %q", tt.matcher, name)
+				}
+			}
+			for _, name := range tt.noMatch {
+				if pattern.Match(name) {
+					t.Errorf("%q matches %q", tt.matcher, name)
+				}
+			}
+		})
+	}
+
+	for _, matcher := range []string{"Edit||Write", "mcp__(github"} {
+		if _, err := types.CompileHookMatcher(&matcher); err == nil {
+			t.Errorf("CompileHookMatcher(%q) succeeded, want error", matcher)
+		}
+	}
+}
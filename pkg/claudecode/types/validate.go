@@ -0,0 +1,258 @@
+package types
+
+import (
+	stderrors "errors"
+	"fmt"
+	"strings"
+
+	"github.com/vinaayakha/claude-code-sdk-go/pkg/claudecode/errors"
+)
+
+// Validate checks the options for invalid values and combinations that the
+// CLI would otherwise reject late or silently ignore. Every problem found is
+// reported as an *errors.ValidationError, joined into a single error.
+func (o *ClaudeCodeOptions) Validate() error {
+	if o == nil {
+		return nil
+	}
+
+	var errs []error
+	invalid := func(field, format string, args ...interface{}) {
+		errs = append(errs, errors.NewValidationError(field, fmt.Sprintf(format, args...)))
+	}
+
+	if o.Resume != nil && o.ContinueConversation {
+		invalid("Resume", "cannot be combined with ContinueConversation")
+	}
+
+	if o.ForkSession && o.Resume == nil {
+		invalid("ForkSession", "requires Resume")
+	}
+
+	if o.SessionID != nil {
+		if !isUUID(*o.SessionID) {
+			invalid("SessionID", "must be a UUID, got %q", *o.SessionID)
+		}
+		if (o.Resume != nil || o.ContinueConversation) && !o.ForkSession {
+			invalid("SessionID", "requires ForkSession when resuming a session")
+		}
+	}
+
+	if o.MaxTurns != nil && *o.MaxTurns < 0 {
+		invalid("MaxTurns", "must not be negative, got %d", *o.MaxTurns)
+	}
+
+	if o.FallbackModel != nil && o.Model != nil && *o.FallbackModel == *o.Model {
+		invalid("FallbackModel", "must differ from Model")
+	}
+
+	if o.MaxThinkingTokens != nil && *o.MaxThinkingTokens < 0 {
+		invalid("MaxThinkingTokens", "must not be negative, got %d", *o.MaxThinkingTokens)
+	}
+
+	if o.Thinking != nil {
+		switch o.Thinking.Type {
+		case ThinkingAdaptive, ThinkingDisabled:
+		case ThinkingEnabled:
+			if o.Thinking.BudgetTokens <= 0 {
+				invalid("Thinking", "BudgetTokens must be positive when thinking is enabled, got %d", o.Thinking.BudgetTokens)
+			}
+		default:
+			invalid("Thinking", "unknown thinking type %q", o.Thinking.Type)
+		}
+	}
+
+	if o.PermissionMode != nil {
+		switch *o.PermissionMode {
+		case PermissionModeDefault, PermissionModeAcceptEdits, PermissionModePlan, PermissionModeBypassPermissions:
+		default:
+			invalid("PermissionMode", "unknown permission mode %q", *o.PermissionMode)
+		}
+	}
+
+	for _, source := range o.SettingSources {
+		switch source {
+		case SettingSourceUser, SettingSourceProject, SettingSourceLocal:
+		default:
+			invalid("SettingSources", "unknown setting source %q", source)
+		}
+	}
+
+	// "stdio" is what the SDK itself sets when CanUseTool is used
+	if o.CanUseTool != nil && o.PermissionPromptToolName != nil && *o.PermissionPromptToolName != "stdio" {
+		invalid("CanUseTool", "cannot be combined with PermissionPromptToolName")
+	}
+
+	switch o.OutputFormat {
+	case "", OutputFormatStreamJSON:
+	case OutputFormatJSON:
+		if o.IncludePartialMessages {
+			invalid("OutputFormat", "json output cannot include partial messages")
+		}
+		if o.CanUseTool != nil || len(o.Hooks) > 0 || len(o.InterceptedTools) > 0 {
+			invalid("OutputFormat", "json output does not support CanUseTool, hooks or intercepted tools")
+		}
+		for name, config := range o.MCPServers {
+			if _, ok := config.(MCPSDKServerConfig); ok {
+				invalid("OutputFormat", "json output does not support SDK MCP server %q", name)
+			}
+		}
+	default:
+		invalid("OutputFormat", "unknown output format %q", o.OutputFormat)
+	}
+
+	if o.MCPServersPath != nil && len(o.MCPServers) > 0 {
+		invalid("MCPServersPath", "cannot be combined with MCPServers")
+	}
+
+	if o.MaxConcurrentPermissionRequests < 0 {
+		invalid("MaxConcurrentPermissionRequests", "must not be negative, got %d", o.MaxConcurrentPermissionRequests)
+	}
+
+	if o.CallbackTimeout < 0 {
+		invalid("CallbackTimeout", "must not be negative, got %s", o.CallbackTimeout)
+	}
+
+	for event, matchers := range o.Hooks {
+		for _, matcher := range matchers {
+			if _, err := CompileHookMatcher(matcher.Matcher); err != nil {
+				invalid("Hooks", "invalid %s matcher %q: %v", event, *matcher.Matcher, err)
+			}
+		}
+	}
+
+	for name, agent := range o.Agents {
+		switch {
+		case name == "":
+			invalid("Agents", "agent name must not be empty")
+		case agent.Description == "":
+			invalid("Agents", "agent %q has no description", name)
+		case agent.Prompt == "":
+			invalid("Agents", "agent %q has no prompt", name)
+		}
+	}
+
+	for _, flag := range o.ExtraFlags {
+		if strings.Trim(flag.Name, "-") == "" {
+			invalid("ExtraFlags", "flag name must not be empty")
+		}
+	}
+
+	if o.TerminateGracePeriod < 0 {
+		invalid("TerminateGracePeriod", "must not be negative, got %s", o.TerminateGracePeriod)
+	}
+	if o.WriteTimeout < 0 {
+		invalid("WriteTimeout", "must not be negative, got %s", o.WriteTimeout)
+	}
+	if o.StallTimeout < 0 {
+		invalid("StallTimeout", "must not be negative, got %s", o.StallTimeout)
+	}
+
+	if o.StderrBufferSize < 0 {
+		invalid("StderrBufferSize", "must not be negative, got %d", o.StderrBufferSize)
+	}
+
+	if o.MaxMessageSize < 0 {
+		invalid("MaxMessageSize", "must not be negative, got %d", o.MaxMessageSize)
+	}
+
+	if o.AutoCompact != nil && o.AutoCompact.ContextTokenThreshold < 0 {
+		invalid("AutoCompact", "ContextTokenThreshold must not be negative, got %d", o.AutoCompact.ContextTokenThreshold)
+	}
+
+	if o.MaxCostUSD < 0 {
+		invalid("MaxCostUSD", "must not be negative, got %g", o.MaxCostUSD)
+	}
+
+	if o.MaxTokens < 0 {
+		invalid("MaxTokens", "must not be negative, got %d", o.MaxTokens)
+	}
+
+	if o.BudgetWarningRatio < 0 || o.BudgetWarningRatio > 1 {
+		invalid("BudgetWarningRatio", "must be between 0 and 1, got %g", o.BudgetWarningRatio)
+	}
+
+	if sandbox := o.ExecutionSandbox; sandbox != nil {
+		switch sandbox.Kind {
+		case SandboxBubblewrap, SandboxFirejail:
+		case SandboxDocker:
+			if sandbox.Image == "" {
+				invalid("ExecutionSandbox", "Image is required for docker")
+			}
+		default:
+			invalid("ExecutionSandbox", "unknown sandbox kind %q", sandbox.Kind)
+		}
+		if o.CommandFactory != nil {
+			invalid("ExecutionSandbox", "cannot be combined with CommandFactory")
+		}
+	}
+
+	if o.MessageBufferSize < 0 {
+		invalid("MessageBufferSize", "must not be negative, got %d", o.MessageBufferSize)
+	}
+
+	switch o.OverflowPolicy {
+	case "", OverflowBlock, OverflowDropOldest, OverflowError:
+	default:
+		invalid("OverflowPolicy", "unknown policy %q", o.OverflowPolicy)
+	}
+
+	if o.Reconnect != nil {
+		if o.Reconnect.MaxRetries < 0 {
+			invalid("Reconnect", "MaxRetries must not be negative, got %d", o.Reconnect.MaxRetries)
+		}
+		if o.Reconnect.InitialBackoff < 0 || o.Reconnect.MaxBackoff < 0 {
+			invalid("Reconnect", "backoff must not be negative")
+		}
+	}
+
+	if o.Retry != nil {
+		if o.Retry.MaxAttempts < 0 {
+			invalid("Retry", "MaxAttempts must not be negative, got %d", o.Retry.MaxAttempts)
+		}
+		if o.Retry.InitialBackoff < 0 || o.Retry.MaxBackoff < 0 {
+			invalid("Retry", "backoff must not be negative")
+		}
+	}
+
+	for name, handler := range o.InterceptedTools {
+		if handler == nil {
+			invalid("InterceptedTools", "tool %q has no handler", name)
+		}
+	}
+	// Tools the CLI runs without asking for permission are never intercepted
+	if len(o.InterceptedTools) > 0 && o.PermissionMode != nil {
+		switch *o.PermissionMode {
+		case PermissionModeAcceptEdits, PermissionModeBypassPermissions:
+			invalid("InterceptedTools", "the CLI runs tools without asking in %s mode", *o.PermissionMode)
+		}
+	}
+	for _, rule := range o.AllowedTools {
+		name, _, _ := strings.Cut(rule, "(")
+		if _, ok := o.InterceptedTools[name]; ok {
+			invalid("InterceptedTools", "tool %q is in AllowedTools, so the CLI runs it without asking", name)
+		}
+	}
+
+	return stderrors.Join(errs...)
+}
+
+// isUUID reports whether s is a UUID in its canonical textual form
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i, r := range s {
+		switch i {
+		case 8, 13, 18, 23:
+			if r != '-' {
+				return false
+			}
+		default:
+			if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+				return false
+			}
+		}
+	}
+	return true
+}